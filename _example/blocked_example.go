@@ -29,3 +29,6 @@ func aBlockedImport() { // nolint: deadcode,unused
 
 	_, _ = homedir.Expand("~/something")
 }
+
+//go:generate go run github.com/uudashr/go-module/cmd/gomod@v0.0.0-20200529023307-c90a4239ad70
+//go:generate go run github.com/golang/mock/mockgen@v1.5.0 -source=blocked_example.go
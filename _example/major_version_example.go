@@ -0,0 +1,9 @@
+package gomodguard
+
+import (
+	"github.com/gofrs/uuid/v4"
+)
+
+func aMajorVersionMismatch() { // nolint: deadcode,unused
+	_ = uuid.Must(uuid.NewV4())
+}
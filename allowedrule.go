@@ -0,0 +1,248 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver"
+	"golang.org/x/mod/module"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+// Predicates of an allowed rule, in the order they are evaluated.
+const (
+	PredicatePath          = "path"
+	PredicateVersion       = "version"
+	PredicateLicense       = "license"
+	PredicateNotDeprecated = "not_deprecated"
+)
+
+// AllowedRule allows the modules matching all of its predicates, a module
+// path or domain, a semver version constraint, a list of licenses and not
+// being deprecated. Predicates that are not set always match.
+type AllowedRule struct {
+	Path          string   `yaml:"path" json:"path"`
+	Version       string   `yaml:"version" json:"version"`
+	Licenses      []string `yaml:"licenses" json:"licenses"`
+	NotDeprecated bool     `yaml:"not_deprecated" json:"not_deprecated"`
+}
+
+// PredicateResult is the outcome of a single predicate of an allowed rule.
+// Want is the configured value and Got the value of the module.
+type PredicateResult struct {
+	Predicate string
+	Want      string
+	Got       string
+	Matched   bool
+}
+
+// needsMetadata returns true if the rule has predicates on module metadata.
+func (r *AllowedRule) needsMetadata() bool {
+	return len(r.Licenses) > 0 || r.NotDeprecated
+}
+
+// Evaluate evaluates the predicates of the rule for the module version in
+// order, stopping at the first predicate that does not match. The metadata
+// is only looked up when a license or deprecation predicate is reached.
+func (r *AllowedRule) Evaluate(mod module.Version, lookup func() (*ModuleMetadata, error)) ([]PredicateResult, bool) {
+	results := []PredicateResult{}
+
+	add := func(predicate, want, got string, matched bool) bool {
+		results = append(results, PredicateResult{Predicate: predicate, Want: want, Got: got, Matched: matched})
+		return matched
+	}
+
+	if !add(PredicatePath, strings.TrimSpace(r.Path), mod.Path, match.Domain(r.Path, mod.Path)) {
+		return results, false
+	}
+
+	if r.Version != "" && !add(PredicateVersion, r.Version, mod.Version, isVersionInConstraint(r.Version, mod.Version)) {
+		return results, false
+	}
+
+	if !r.needsMetadata() {
+		return results, true
+	}
+
+	metadata, err := lookup()
+	if err != nil {
+		predicate, want := PredicateLicense, strings.Join(r.Licenses, ", ")
+		if len(r.Licenses) == 0 {
+			predicate, want = PredicateNotDeprecated, "true"
+		}
+
+		add(predicate, want, err.Error(), false)
+
+		return results, false
+	}
+
+	if len(r.Licenses) > 0 {
+		licenses := strings.Join(metadata.Licenses, ", ")
+		if !add(PredicateLicense, strings.Join(r.Licenses, ", "), licenses, hasOnlyLicenses(metadata, r.Licenses)) {
+			return results, false
+		}
+	}
+
+	if r.NotDeprecated && !add(PredicateNotDeprecated, "true", metadata.Deprecated, metadata.Deprecated == "") {
+		return results, false
+	}
+
+	return results, true
+}
+
+// isVersionInConstraint returns true if the version meets the semver
+// constraint. Invalid constraints and versions never match.
+func isVersionInConstraint(constraint, version string) bool {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+
+	return c.Check(v)
+}
+
+// hasOnlyLicenses returns true if the module has licenses and all of them
+// are in the given list.
+func hasOnlyLicenses(metadata *ModuleMetadata, licenses []string) bool {
+	if metadata.HasUnresolvableLicense() {
+		return false
+	}
+
+	for _, license := range metadata.Licenses {
+		found := false
+
+		for _, allowedLicense := range licenses {
+			if strings.EqualFold(strings.TrimSpace(license), strings.TrimSpace(allowedLicense)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isAllowedByRule returns true if an allowed rule matches the module version.
+// The metadata of the module is looked up at most once.
+func (p *Processor) isAllowedByRule(mod module.Version) bool {
+	if len(p.Config.Allowed.Rules) == 0 {
+		return false
+	}
+
+	canonical := module.Version{Path: p.Config.CanonicalModulePath(mod.Path), Version: mod.Version}
+	lookup := p.cachedMetadataLookup(mod)
+
+	for i := range p.Config.Allowed.Rules {
+		if _, ok := p.Config.Allowed.Rules[i].Evaluate(canonical, lookup); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cachedMetadataLookup returns a lookup of the metadata of the module
+// version that is only performed once.
+func (p *Processor) cachedMetadataLookup(mod module.Version) func() (*ModuleMetadata, error) {
+	var (
+		metadata *ModuleMetadata
+		err      error
+		done     bool
+	)
+
+	return func() (*ModuleMetadata, error) {
+		if !done {
+			metadata, err = p.metadataLookup()(mod.Path, mod.Version)
+			done = true
+		}
+
+		return metadata, err
+	}
+}
+
+// AllowedRuleExplanation is the evaluation of an allowed rule for a direct
+// module dependency whose path the rule matches.
+type AllowedRuleExplanation struct {
+	Module     string
+	Version    string
+	Rule       string
+	Predicates []PredicateResult
+	Allowed    bool
+}
+
+// ExplainAllowedRules evaluates the allowed rules for the direct module
+// dependencies and returns the predicates of every rule matching the path of
+// a module, sorted by module. Predicates after the first that did not match
+// are not evaluated.
+func (p *Processor) ExplainAllowedRules() []AllowedRuleExplanation {
+	explanations := []AllowedRuleExplanation{}
+
+	if p.Modfile == nil {
+		return explanations
+	}
+
+	for _, require := range p.Modfile.Require {
+		if require == nil || require.Indirect {
+			continue
+		}
+
+		mod := module.Version{Path: strings.TrimSpace(require.Mod.Path), Version: strings.TrimSpace(require.Mod.Version)}
+		canonical := module.Version{Path: p.Config.CanonicalModulePath(mod.Path), Version: mod.Version}
+		lookup := p.cachedMetadataLookup(mod)
+
+		for i := range p.Config.Allowed.Rules {
+			predicates, allowed := p.Config.Allowed.Rules[i].Evaluate(canonical, lookup)
+			if !predicates[0].Matched {
+				continue
+			}
+
+			explanations = append(explanations, AllowedRuleExplanation{
+				Module:     mod.Path,
+				Version:    mod.Version,
+				Rule:       strings.TrimSpace(p.Config.Allowed.Rules[i].Path),
+				Predicates: predicates,
+				Allowed:    allowed,
+			})
+		}
+	}
+
+	sort.SliceStable(explanations, func(i, j int) bool { return explanations[i].Module < explanations[j].Module })
+
+	return explanations
+}
+
+// WriteAllowedRuleExplanations writes the predicates of the explanations as
+// a table, one predicate per row.
+func WriteAllowedRuleExplanations(w io.Writer, explanations []AllowedRuleExplanation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, err := fmt.Fprintln(tw, "MODULE\tRULE\tPREDICATE\tWANT\tGOT\tMATCHED")
+	if err != nil {
+		return err
+	}
+
+	for i := range explanations {
+		for _, predicate := range explanations[i].Predicates {
+			_, err = fmt.Fprintf(tw, "%s@%s\t%s\t%s\t%s\t%s\t%t\n", explanations[i].Module, explanations[i].Version,
+				explanations[i].Rule, predicate.Predicate, predicate.Want, predicate.Got, predicate.Matched)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tw.Flush()
+}
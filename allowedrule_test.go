@@ -0,0 +1,150 @@
+package gomodguard_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+func TestAllowedRuleEvaluate(t *testing.T) {
+	rule := gomodguard.AllowedRule{
+		Path:          "github.com/foo",
+		Version:       ">= 1.2.0, < 2.0.0",
+		Licenses:      []string{"MIT", "Apache-2.0"},
+		NotDeprecated: true,
+	}
+
+	var tests = []struct {
+		testName       string
+		mod            module.Version
+		metadata       *gomodguard.ModuleMetadata
+		wantPredicates []string
+		wantAllowed    bool
+		wantLookups    int
+	}{
+		{
+			"all predicates match",
+			module.Version{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			&gomodguard.ModuleMetadata{Licenses: []string{"mit"}},
+			[]string{"path", "version", "license", "not_deprecated"},
+			true,
+			1,
+		},
+		{
+			"path does not match",
+			module.Version{Path: "github.com/baz/bar", Version: "v1.2.3"},
+			&gomodguard.ModuleMetadata{Licenses: []string{"MIT"}},
+			[]string{"path"},
+			false,
+			0,
+		},
+		{
+			"version does not match",
+			module.Version{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			&gomodguard.ModuleMetadata{Licenses: []string{"MIT"}},
+			[]string{"path", "version"},
+			false,
+			0,
+		},
+		{
+			"license not listed",
+			module.Version{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			&gomodguard.ModuleMetadata{Licenses: []string{"MIT", "GPL-3.0"}},
+			[]string{"path", "version", "license"},
+			false,
+			1,
+		},
+		{
+			"deprecated",
+			module.Version{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			&gomodguard.ModuleMetadata{Licenses: []string{"Apache-2.0"}, Deprecated: "use github.com/foo/baz"},
+			[]string{"path", "version", "license", "not_deprecated"},
+			false,
+			1,
+		},
+		{
+			"metadata not found",
+			module.Version{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			nil,
+			[]string{"path", "version", "license"},
+			false,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lookups := 0
+			lookup := func() (*gomodguard.ModuleMetadata, error) {
+				lookups++
+				if tt.metadata == nil {
+					return nil, errors.New("not found")
+				}
+
+				return tt.metadata, nil
+			}
+
+			predicates, allowed := rule.Evaluate(tt.mod, lookup)
+
+			got := []string{}
+			for _, predicate := range predicates {
+				got = append(got, predicate.Predicate)
+			}
+
+			if len(got) != len(tt.wantPredicates) || allowed != tt.wantAllowed || lookups != tt.wantLookups {
+				t.Errorf("got '%v' '%v' '%v' want '%v' '%v' '%v'", got, allowed, lookups, tt.wantPredicates, tt.wantAllowed, tt.wantLookups)
+			}
+
+			for i := range got {
+				if i < len(tt.wantPredicates) && got[i] != tt.wantPredicates[i] {
+					t.Errorf("got '%v' want '%v'", got, tt.wantPredicates)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessorAllowedRules(t *testing.T) {
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.2.3\n\tgithub.com/foo/baz v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor := gomodguard.Processor{
+		Config: &gomodguard.Configuration{
+			Allowed: gomodguard.Allowed{
+				Rules: []gomodguard.AllowedRule{{Path: "github.com/foo", Version: ">= 1.2.0"}},
+			},
+		},
+		Modfile: modFile,
+	}
+	processor.SetBlockedModules()
+
+	explanations := processor.ExplainAllowedRules()
+	if len(explanations) != 2 {
+		t.Fatalf("got '%+v' want 2 explanations", explanations)
+	}
+
+	var tests = []struct {
+		testName    string
+		explanation gomodguard.AllowedRuleExplanation
+		wantModule  string
+		wantAllowed bool
+	}{
+		{"version matches", explanations[0], "github.com/foo/bar", true},
+		{"version does not match", explanations[1], "github.com/foo/baz", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if tt.explanation.Module != tt.wantModule || tt.explanation.Allowed != tt.wantAllowed {
+				t.Errorf("got '%v' '%v' want '%v' '%v'", tt.explanation.Module, tt.explanation.Allowed, tt.wantModule, tt.wantAllowed)
+			}
+		})
+	}
+}
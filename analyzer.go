@@ -0,0 +1,284 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/ryancurrah/gomodguard/match"
+	"golang.org/x/tools/go/analysis"
+)
+
+// NewAnalyzer returns an analyzer linting the files of each package with
+// the configuration, for go vet -vettool, multichecker and golangci-lint.
+// The files parsed by the driver are linted instead of reading and parsing
+// them again. With a nil configuration the nearest .gomodguard.yaml file in
+// the directory of the package or its parents, up to the root of its module,
+// or in the home directory is read and its profile, if any, is applied. A
+// package without a configuration file is reported.
+// Import cycles span packages and are not reported by the analyzer.
+// Packages of the main module importing blocked packages, directly or
+// through other packages of the main module, are marked with a
+// BlockedImportsFact for downstream analyzers.
+func NewAnalyzer(config *Configuration) *analysis.Analyzer {
+	var (
+		mu         sync.Mutex
+		processors = map[analyzerConfigKey]*Processor{}
+	)
+
+	run := func(pass *analysis.Pass) (interface{}, error) {
+		if len(pass.Files) == 0 {
+			return nil, nil
+		}
+
+		// Drivers may run packages concurrently, a processor lints one
+		// package at a time.
+		mu.Lock()
+		defer mu.Unlock()
+
+		packageConfig, key := config, analyzerConfigKey{}
+
+		if packageConfig == nil {
+			packageDir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+			key.moduleDir = findModuleDir(packageDir, map[string]string{})
+
+			var err error
+
+			key.configFile, err = findConfigFile(packageDir, key.moduleDir)
+			if err == nil {
+				packageConfig, err = readAnalyzerConfig(key.configFile, key.moduleDir)
+			}
+
+			if err != nil {
+				pass.Reportf(pass.Files[0].Package, "%s", err)
+				return nil, nil
+			}
+		}
+
+		processor, ok := processors[key]
+		if !ok {
+			var err error
+
+			processor, err = NewProcessor(packageConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			processors[key] = processor
+		}
+
+		// Drivers run analyzers with facts on all dependencies, packages
+		// of other modules are not linted against the go.mod file.
+		if !processor.HasPolicyWork() || !processor.inMainModule(pass.Pkg.Path()) {
+			exportBlockedImportsFact(pass, nil)
+			return nil, nil
+		}
+
+		processor.Result = []Result{}
+		results := processor.ProcessASTFiles(pass.Fset, pass.Files)
+
+		for _, result := range results {
+			pass.Reportf(resultPos(pass, result), "%s", result.Reason)
+		}
+
+		exportBlockedImportsFact(pass, results)
+
+		return nil, nil
+	}
+
+	return &analysis.Analyzer{
+		Name:      "gomodguard",
+		Doc:       "check for blocked module dependencies",
+		Run:       run,
+		FactTypes: []analysis.Fact{new(BlockedImportsFact)},
+	}
+}
+
+// analyzerConfigKey identifies the processor of the packages read with a
+// configuration file in a module.
+type analyzerConfigKey struct {
+	configFile string
+	moduleDir  string
+}
+
+// findConfigFile returns the nearest configuration file in the directory or
+// its parents up to the module directory, or the configuration file in the
+// home directory.
+func findConfigFile(dir, moduleDir string) (string, error) {
+	for {
+		filename := filepath.Join(dir, configFile)
+		if fileExists(filename) {
+			return filename, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if moduleDir == "" || samePath(dir, moduleDir) || parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf(errFindingHomedir, err)
+	}
+
+	if filename := filepath.Join(home, configFile); fileExists(filename) {
+		return filename, nil
+	}
+
+	return "", fmt.Errorf("%w: %s in %s, its parents up to the module root or %s", errFindingConfigFile, configFile, dir, home)
+}
+
+// readAnalyzerConfig reads the configuration file and applies its profile.
+// Without a go_mod_path the go.mod file of the module directory is linted
+// against.
+func readAnalyzerConfig(filename, moduleDir string) (*Configuration, error) {
+	config, err := readConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.GoModPath == "" && moduleDir != "" {
+		config.GoModPath = filepath.Join(moduleDir, goModFilename)
+	}
+
+	if config.Profile != "" {
+		err = config.ApplyProfile(config.Profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// BlockedImport is a blocked package imported by a package. Via is the
+// package imported by the package the blocked package is reached through,
+// empty if the package imports the blocked package itself.
+type BlockedImport struct {
+	Package string
+	Module  string
+	Rule    string
+	Via     string
+}
+
+// BlockedImportsFact is the package fact of packages importing blocked
+// packages, so analyzers requiring gomodguard can check, for example, that
+// no blocked module is reachable from handler packages. Exempted imports and
+// warnings are not blocked.
+type BlockedImportsFact struct {
+	Imports []BlockedImport
+}
+
+// AFact marks BlockedImportsFact as a fact.
+func (*BlockedImportsFact) AFact() {}
+
+// String returns the blocked packages of the fact.
+func (f *BlockedImportsFact) String() string {
+	packages := make([]string, 0, len(f.Imports))
+	for _, imp := range f.Imports {
+		packages = append(packages, imp.Package)
+	}
+
+	return "blocked imports " + strings.Join(packages, ", ")
+}
+
+// exportBlockedImportsFact exports the fact of the blocked packages imported
+// by the package according to the results and the facts of its imports.
+// Nothing is exported for packages not reaching any blocked package.
+func exportBlockedImportsFact(pass *analysis.Pass, results []Result) {
+	fact := &BlockedImportsFact{}
+	seen := map[string]bool{}
+
+	for _, result := range results {
+		if result.Package == "" || result.IsWarning() || seen[result.Package] {
+			continue
+		}
+
+		seen[result.Package] = true
+		fact.Imports = append(fact.Imports, BlockedImport{Package: result.Package, Module: result.Module, Rule: result.Rule})
+	}
+
+	for _, imported := range pass.Pkg.Imports() {
+		var importedFact BlockedImportsFact
+		if !pass.ImportPackageFact(imported, &importedFact) {
+			continue
+		}
+
+		for _, imp := range importedFact.Imports {
+			if seen[imp.Package] {
+				continue
+			}
+
+			seen[imp.Package] = true
+			fact.Imports = append(fact.Imports, BlockedImport{Package: imp.Package, Module: imp.Module, Rule: imp.Rule, Via: imported.Path()})
+		}
+	}
+
+	if len(fact.Imports) == 0 {
+		return
+	}
+
+	sort.Slice(fact.Imports, func(i, j int) bool { return fact.Imports[i].Package < fact.Imports[j].Package })
+	pass.ExportPackageFact(fact)
+}
+
+// inMainModule returns true if the package belongs to the main module, or
+// the main module is unknown. External test packages belong to the module of
+// the package they test.
+func (p *Processor) inMainModule(packagePath string) bool {
+	if p.Modfile == nil || p.Modfile.Module == nil {
+		return true
+	}
+
+	return match.Module(p.Modfile.Module.Mod.Path, strings.TrimSuffix(packagePath, "_test"))
+}
+
+// resultPos returns the position of the result in the files of the pass,
+// the package clause of the file if the result has no position.
+func resultPos(pass *analysis.Pass, result Result) token.Pos {
+	for _, file := range pass.Files {
+		tokenFile := pass.Fset.File(file.Pos())
+		if tokenFile == nil || tokenFile.Name() != result.FileName {
+			continue
+		}
+
+		if result.Position.IsValid() && result.Position.Offset <= tokenFile.Size() {
+			return tokenFile.Pos(result.Position.Offset)
+		}
+
+		return file.Package
+	}
+
+	return token.NoPos
+}
+
+// ProcessASTFiles lints files parsed with comments by the caller, like
+// ProcessFiles but without reading and parsing them. The file set must be
+// the one the files were parsed with. Import cycles and modules duplicating a
+// category are not reported.
+func (p *Processor) ProcessASTFiles(fileSet *token.FileSet, files []*ast.File) []Result {
+	from := len(p.Result)
+
+	for _, file := range files {
+		filename := fileSet.Position(file.Package).Filename
+
+		p.emitProgress(progressStarted, filename, nil)
+		p.processSafely(filename, func() { p.processFile(fileSet, filename, file) })
+		p.emitProgress(progressFinished, filename, nil)
+	}
+
+	p.packageImports, p.mainPackages, p.categoryImports = nil, nil, nil
+
+	p.applyExemptions(from)
+	p.emitFindings(p.Result[from:])
+
+	return p.Result
+}
@@ -0,0 +1,263 @@
+package gomodguard_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestNewAnalyzer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n"
+
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := gomodguard.NewAnalyzer(&gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+		GoEnv:   map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+
+	// The files are never read, the parsed files of the driver are linted.
+	sources := map[string]string{
+		filepath.Join(dir, "main.go"):   "package main\n\nimport (\n\t\"fmt\"\n\t\"github.com/foo/bar\"\n)\n",
+		filepath.Join(dir, "exempt.go"): "//gomodguard:exempt\npackage main\n\nimport \"github.com/foo/bar\"\n",
+	}
+
+	fileSet := token.NewFileSet()
+	files := []*ast.File{}
+
+	for filename, source := range sources {
+		file, err := parser.ParseFile(fileSet, filename, source, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files = append(files, file)
+	}
+
+	diagnostics := []analysis.Diagnostic{}
+
+	pass := &analysis.Pass{
+		Analyzer:          analyzer,
+		Fset:              fileSet,
+		Files:             files,
+		Pkg:               types.NewPackage("github.com/ryancurrah/example", "main"),
+		Report:            func(d analysis.Diagnostic) { diagnostics = append(diagnostics, d) },
+		ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+		ExportPackageFact: func(analysis.Fact) {},
+	}
+
+	_, err = analyzer.Run(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("got '%+v' want 1 diagnostic", diagnostics)
+	}
+
+	position := fileSet.Position(diagnostics[0].Pos)
+	if position.Filename != filepath.Join(dir, "main.go") || position.Line != 5 {
+		t.Errorf("got '%v' want '%v'", position, filepath.Join(dir, "main.go")+":5:2")
+	}
+}
+
+func TestNewAnalyzerConfigLookup(t *testing.T) {
+	home, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer func(home string, disableCache bool) {
+		os.Setenv("HOME", home)
+		homedir.DisableCache = disableCache
+	}(os.Getenv("HOME"), homedir.DisableCache)
+
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	var tests = []struct {
+		testName   string
+		files      map[string]string
+		wantReason string
+	}{
+		{
+			"config in the module root",
+			map[string]string{
+				".gomodguard.yaml": "blocked:\n  modules:\n    - github.com/foo/bar: {}\n",
+				"sub/sub.go":       "package sub\n\nimport \"github.com/foo/bar\"\n",
+			},
+			"import of package `github.com/foo/bar` is blocked because the module is in the blocked modules list.",
+		},
+		{
+			"missing config",
+			map[string]string{
+				"sub/sub.go": "package sub\n\nimport \"github.com/foo/bar\"\n",
+			},
+			"could not find config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "gomodguard")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			tt.files["go.mod"] = "module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n"
+			filenames := writeTree(t, dir, tt.files)
+
+			fileSet := token.NewFileSet()
+
+			file, err := parser.ParseFile(fileSet, filenames[0], nil, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			analyzer := gomodguard.NewAnalyzer(nil)
+			diagnostics := []analysis.Diagnostic{}
+
+			pass := &analysis.Pass{
+				Analyzer:          analyzer,
+				Fset:              fileSet,
+				Files:             []*ast.File{file},
+				Pkg:               types.NewPackage("github.com/ryancurrah/example/sub", "sub"),
+				Report:            func(d analysis.Diagnostic) { diagnostics = append(diagnostics, d) },
+				ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+				ExportPackageFact: func(analysis.Fact) {},
+			}
+
+			_, err = analyzer.Run(pass)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, tt.wantReason) {
+				t.Errorf("got '%+v' want a diagnostic containing '%v'", diagnostics, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewAnalyzerFacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n"
+
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := gomodguard.NewAnalyzer(&gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+		GoEnv:   map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+
+	facts := map[*types.Package]*gomodguard.BlockedImportsFact{}
+	packages := map[string]*types.Package{}
+
+	// Packages are analyzed in dependency order, like drivers do.
+	var tests = []struct {
+		pkgPath string
+		imports []string
+		source  string
+		want    []gomodguard.BlockedImport
+	}{
+		{
+			"github.com/other/lib",
+			nil,
+			"package lib\n\nimport \"github.com/foo/bar\"\n",
+			nil,
+		},
+		{
+			"github.com/ryancurrah/example/store",
+			nil,
+			"package store\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n",
+			[]gomodguard.BlockedImport{{Package: "github.com/foo/bar", Module: "github.com/foo/bar", Rule: gomodguard.RuleInBlockedList}},
+		},
+		{
+			"github.com/ryancurrah/example/handler",
+			[]string{"github.com/ryancurrah/example/store", "github.com/other/lib"},
+			"package handler\n\nimport \"fmt\"\n",
+			[]gomodguard.BlockedImport{{Package: "github.com/foo/bar", Module: "github.com/foo/bar", Rule: gomodguard.RuleInBlockedList, Via: "github.com/ryancurrah/example/store"}},
+		},
+	}
+
+	for _, tt := range tests {
+		fileSet := token.NewFileSet()
+
+		file, err := parser.ParseFile(fileSet, filepath.Join(dir, filepath.Base(tt.pkgPath)+".go"), tt.source, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pkg := types.NewPackage(tt.pkgPath, file.Name.Name)
+		packages[tt.pkgPath] = pkg
+
+		imports := []*types.Package{}
+		for _, imported := range tt.imports {
+			imports = append(imports, packages[imported])
+		}
+
+		pkg.SetImports(imports)
+
+		pass := &analysis.Pass{
+			Analyzer: analyzer,
+			Fset:     fileSet,
+			Files:    []*ast.File{file},
+			Pkg:      pkg,
+			Report:   func(analysis.Diagnostic) {},
+			ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+				if facts[pkg] == nil {
+					return false
+				}
+
+				*fact.(*gomodguard.BlockedImportsFact) = *facts[pkg]
+
+				return true
+			},
+			ExportPackageFact: func(fact analysis.Fact) { facts[pkg] = fact.(*gomodguard.BlockedImportsFact) },
+		}
+
+		_, err = analyzer.Run(pass)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []gomodguard.BlockedImport
+		if facts[pkg] != nil {
+			got = facts[pkg].Imports
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got '%+v' want '%+v'", tt.pkgPath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package gomodguard
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// Kinds of suppressions recorded in the audit file.
+const (
+	// AuditKindExemption is a result suppressed by an exempt, ignore or
+	// nolint directive.
+	AuditKindExemption = "exemption"
+	// AuditKindBaseline is a result suppressed by a baseline entry.
+	AuditKindBaseline = "baseline"
+)
+
+const errReadingAuditFile = "unable to read audit file %s: %w"
+
+// AuditRecord records the use of a suppression. SuppressionFile and
+// SuppressionLine locate the directive or the baseline file suppressing the
+// result, Blame is the commit that added the suppression and Recorded is when
+// the use was first seen.
+type AuditRecord struct {
+	Kind            string    `json:"kind"`
+	FileName        string    `json:"file"`
+	Line            int       `json:"line,omitempty"`
+	Rule            string    `json:"rule"`
+	Module          string    `json:"module,omitempty"`
+	Package         string    `json:"package,omitempty"`
+	Reason          string    `json:"reason,omitempty"`
+	SuppressionFile string    `json:"suppression_file"`
+	SuppressionLine int       `json:"suppression_line,omitempty"`
+	Blame           *Blame    `json:"blame,omitempty"`
+	Recorded        time.Time `json:"recorded"`
+}
+
+// auditKey returns the use of the suppression the record is about. Lines are
+// not part of the key, so uses stay known when lines move.
+func auditKey(record AuditRecord) AuditRecord {
+	return AuditRecord{
+		Kind:            record.Kind,
+		FileName:        normalizePath(record.FileName),
+		Rule:            record.Rule,
+		Module:          record.Module,
+		Package:         record.Package,
+		SuppressionFile: normalizePath(record.SuppressionFile),
+	}
+}
+
+// exemptionAuditRecords returns the records of the results suppressed by the
+// exemptions, each attributed to the first exemption applying to it like
+// when the exemptions were applied.
+func (p *Processor) exemptionAuditRecords() []AuditRecord {
+	records := []AuditRecord{}
+
+	for i := range p.Suppressed {
+		for n := range p.Exemptions {
+			if !p.Exemptions[n].appliesTo(&p.Suppressed[i]) {
+				continue
+			}
+
+			records = append(records, AuditRecord{
+				Kind:            AuditKindExemption,
+				FileName:        p.Suppressed[i].FileName,
+				Line:            p.Suppressed[i].LineNumber,
+				Rule:            p.Suppressed[i].Rule,
+				Module:          p.Suppressed[i].Module,
+				Package:         p.Suppressed[i].Package,
+				Reason:          p.Exemptions[n].Reason,
+				SuppressionFile: p.Exemptions[n].FileName,
+				SuppressionLine: p.Exemptions[n].directiveLine,
+			})
+
+			break
+		}
+	}
+
+	return records
+}
+
+// baselineAuditRecord returns the record of a result suppressed by the
+// baseline file.
+func baselineAuditRecord(baselineFile string, result Result) AuditRecord {
+	return AuditRecord{
+		Kind:            AuditKindBaseline,
+		FileName:        result.FileName,
+		Line:            result.LineNumber,
+		Rule:            result.Rule,
+		Module:          result.Module,
+		Package:         result.Package,
+		SuppressionFile: baselineFile,
+	}
+}
+
+// NewAuditRecords returns the records of suppression uses that are not
+// recorded yet, recorded now and sorted by file, rule, module and package.
+func NewAuditRecords(recorded, records []AuditRecord, now time.Time) []AuditRecord {
+	known := make(map[AuditRecord]bool, len(recorded))
+	for _, record := range recorded {
+		known[auditKey(record)] = true
+	}
+
+	added := []AuditRecord{}
+
+	for _, record := range records {
+		key := auditKey(record)
+		if known[key] {
+			continue
+		}
+
+		known[key] = true
+
+		record.FileName = key.FileName
+		record.SuppressionFile = key.SuppressionFile
+		record.Recorded = now
+		added = append(added, record)
+	}
+
+	sort.SliceStable(added, func(i, j int) bool {
+		a, b := added[i], added[j]
+
+		switch {
+		case a.FileName != b.FileName:
+			return a.FileName < b.FileName
+		case a.Rule != b.Rule:
+			return a.Rule < b.Rule
+		case a.Module != b.Module:
+			return a.Module < b.Module
+		default:
+			return a.Package < b.Package
+		}
+	})
+
+	return added
+}
+
+// ReadAuditRecords reads an audit file of json lines. A missing file has no
+// records.
+func ReadAuditRecords(filename string) ([]AuditRecord, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []AuditRecord{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf(errReadingAuditFile, filename, err)
+	}
+
+	records := []AuditRecord{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record AuditRecord
+
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, fmt.Errorf(errReadingAuditFile, filename, err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf(errReadingAuditFile, filename, err)
+	}
+
+	return records, nil
+}
+
+// AppendAuditRecords appends the records to the audit file as json lines,
+// creating the file if it does not exist. Recorded lines are never changed.
+func AppendAuditRecords(filename string, records []AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(append(data, '\n'))
+	}
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestNewAuditRecords(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	recorded := []gomodguard.AuditRecord{
+		{Kind: gomodguard.AuditKindExemption, FileName: "main.go", Line: 3, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", SuppressionFile: "main.go", SuppressionLine: 3, Recorded: now.AddDate(0, 0, -30)},
+	}
+
+	records := []gomodguard.AuditRecord{
+		{Kind: gomodguard.AuditKindExemption, FileName: "./main.go", Line: 5, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", SuppressionFile: "main.go", SuppressionLine: 5},
+		{Kind: gomodguard.AuditKindExemption, FileName: "pkg/a.go", Line: 4, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Reason: "approved", SuppressionFile: "pkg/a.go", SuppressionLine: 4},
+		{Kind: gomodguard.AuditKindBaseline, FileName: "main.go", Line: 6, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", SuppressionFile: ".gomodguard-baseline.json"},
+		{Kind: gomodguard.AuditKindBaseline, FileName: "main.go", Line: 7, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", SuppressionFile: ".gomodguard-baseline.json"},
+	}
+
+	got := gomodguard.NewAuditRecords(recorded, records, now)
+
+	want := []gomodguard.AuditRecord{
+		{Kind: gomodguard.AuditKindBaseline, FileName: "main.go", Line: 6, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", SuppressionFile: ".gomodguard-baseline.json", Recorded: now},
+		{Kind: gomodguard.AuditKindExemption, FileName: "pkg/a.go", Line: 4, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Reason: "approved", SuppressionFile: "pkg/a.go", SuppressionLine: 4, Recorded: now},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+}
+
+func TestAuditFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "audit.jsonl")
+
+	records, err := gomodguard.ReadAuditRecords(filename)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("got '%+v' '%v' want no records for a missing file", records, err)
+	}
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	first := gomodguard.AuditRecord{Kind: gomodguard.AuditKindExemption, FileName: "main.go", Rule: gomodguard.RuleInBlockedList, SuppressionFile: "main.go", Recorded: now}
+	second := gomodguard.AuditRecord{
+		Kind:            gomodguard.AuditKindBaseline,
+		FileName:        "pkg/a.go",
+		Rule:            gomodguard.RuleNotInAllowedList,
+		SuppressionFile: ".gomodguard-baseline.json",
+		Blame:           &gomodguard.Blame{Author: "Jane Doe", Commit: "0123456789abcdef", Date: now},
+		Recorded:        now,
+	}
+
+	for _, record := range []gomodguard.AuditRecord{first, second} {
+		err = gomodguard.AppendAuditRecords(filename, []gomodguard.AuditRecord{record})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := gomodguard.ReadAuditRecords(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gomodguard.AuditRecord{first, second}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+}
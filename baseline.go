@@ -0,0 +1,152 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+const errReadingBaselineFile = "unable to read baseline file %s: %w"
+
+// BaselineEntry is a known finding recorded in a baseline file. Findings are
+// identified by file, rule, module and package so they stay known when lines
+// move. Count is the number of results of the finding in the file.
+type BaselineEntry struct {
+	FileName string `json:"file"`
+	Rule     string `json:"rule"`
+	Module   string `json:"module,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// baselineKey returns the finding of the result.
+func baselineKey(result Result) BaselineEntry {
+	return BaselineEntry{
+		FileName: normalizePath(result.FileName),
+		Rule:     result.Rule,
+		Module:   result.Module,
+		Package:  result.Package,
+	}
+}
+
+// NewBaseline returns the findings of the results, sorted by file, rule,
+// module and package.
+func NewBaseline(results []Result) []BaselineEntry {
+	counts := map[BaselineEntry]int{}
+
+	for i := range results {
+		if !results[i].NonSuppressible {
+			counts[baselineKey(results[i])]++
+		}
+	}
+
+	baseline := make([]BaselineEntry, 0, len(counts))
+
+	for key, count := range counts {
+		key.Count = count
+		baseline = append(baseline, key)
+	}
+
+	sort.Slice(baseline, func(i, j int) bool {
+		a, b := baseline[i], baseline[j]
+
+		switch {
+		case a.FileName != b.FileName:
+			return a.FileName < b.FileName
+		case a.Rule != b.Rule:
+			return a.Rule < b.Rule
+		case a.Module != b.Module:
+			return a.Module < b.Module
+		default:
+			return a.Package < b.Package
+		}
+	})
+
+	return baseline
+}
+
+// BaselineFilter filters out the results recorded in a baseline. Each
+// recorded finding filters out at most as many results as its count, so
+// adding another import of a known blocked package to a file is reported.
+type BaselineFilter struct {
+	remaining map[BaselineEntry]int
+}
+
+// NewBaselineFilter returns a filter of the findings of the baseline.
+func NewBaselineFilter(baseline []BaselineEntry) *BaselineFilter {
+	f := &BaselineFilter{remaining: make(map[BaselineEntry]int, len(baseline))}
+
+	for _, entry := range baseline {
+		count := entry.Count
+		entry.Count = 0
+		entry.FileName = normalizePath(entry.FileName)
+		f.remaining[entry] += count
+	}
+
+	return f
+}
+
+// IsKnown returns true if the result is recorded in the baseline. Results
+// of non-suppressible rules are never known.
+func (f *BaselineFilter) IsKnown(result Result) bool {
+	if result.NonSuppressible {
+		return false
+	}
+
+	key := baselineKey(result)
+
+	if f.remaining[key] <= 0 {
+		return false
+	}
+
+	f.remaining[key]--
+
+	return true
+}
+
+// Resolved returns the number of recorded results that no longer occur.
+func (f *BaselineFilter) Resolved() int {
+	resolved := 0
+
+	for _, count := range f.remaining {
+		resolved += count
+	}
+
+	return resolved
+}
+
+// ReadBaseline reads a baseline file. A missing file is an empty baseline and
+// false is returned.
+func ReadBaseline(filename string) ([]BaselineEntry, bool, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []BaselineEntry{}, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf(errReadingBaselineFile, filename, err)
+	}
+
+	baseline := []BaselineEntry{}
+
+	err = json.Unmarshal(data, &baseline)
+	if err != nil {
+		return nil, false, fmt.Errorf(errReadingBaselineFile, filename, err)
+	}
+
+	return baseline, true, nil
+}
+
+// WriteBaseline writes the baseline file.
+func WriteBaseline(filename string, baseline []BaselineEntry) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+}
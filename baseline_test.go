@@ -0,0 +1,158 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestBaselineFilter(t *testing.T) {
+	recorded := []gomodguard.Result{
+		{FileName: "main.go", LineNumber: 3, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+		{FileName: "pkg/a.go", LineNumber: 5, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", Package: "github.com/foo/baz/client"},
+		{FileName: "pkg/a.go", LineNumber: 9, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", Package: "github.com/foo/baz/client"},
+		{FileName: "fixed.go", LineNumber: 4, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+	}
+
+	baseline := gomodguard.NewBaseline(recorded)
+
+	wantBaseline := []gomodguard.BaselineEntry{
+		{FileName: "fixed.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar", Count: 1},
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar", Count: 1},
+		{FileName: "pkg/a.go", Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", Package: "github.com/foo/baz/client", Count: 2},
+	}
+
+	if !reflect.DeepEqual(baseline, wantBaseline) {
+		t.Fatalf("got '%+v' want '%+v'", baseline, wantBaseline)
+	}
+
+	var tests = []struct {
+		testName string
+		result   gomodguard.Result
+		want     bool
+	}{
+		{
+			"moved line",
+			gomodguard.Result{FileName: "main.go", LineNumber: 7, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+			true,
+		},
+		{
+			"second recorded result",
+			gomodguard.Result{FileName: "pkg/a.go", LineNumber: 5, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", Package: "github.com/foo/baz/client"},
+			true,
+		},
+		{
+			"more results than recorded",
+			gomodguard.Result{FileName: "main.go", LineNumber: 8, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+			false,
+		},
+		{
+			"new file",
+			gomodguard.Result{FileName: "new.go", LineNumber: 3, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+			false,
+		},
+		{
+			"new rule",
+			gomodguard.Result{FileName: "main.go", LineNumber: 3, Rule: gomodguard.RuleBlockedVersion, Module: "github.com/foo/bar", Package: "github.com/foo/bar"},
+			false,
+		},
+	}
+
+	filter := gomodguard.NewBaselineFilter(baseline)
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := filter.IsKnown(tt.result)
+			if got != tt.want {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+
+	if got := filter.Resolved(); got != 2 {
+		t.Errorf("got '%v' want '%v'", got, 2)
+	}
+}
+
+func TestBaselineFilterNormalizesPaths(t *testing.T) {
+	filter := gomodguard.NewBaselineFilter([]gomodguard.BaselineEntry{
+		{FileName: "./pkg/a.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Count: 1},
+		{FileName: "c:/src/repo/main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Count: 1},
+	})
+
+	var tests = []struct {
+		testName string
+		fileName string
+		want     bool
+	}{
+		{"leading dot recorded", "pkg/a.go", true},
+		{"lower case volume recorded", "C:/src/repo/main.go", true},
+		{"other file", "pkg/b.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := filter.IsKnown(gomodguard.Result{FileName: tt.fileName, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar"})
+			if got != tt.want {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineNonSuppressible(t *testing.T) {
+	results := []gomodguard.Result{
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar"},
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/baz", NonSuppressible: true},
+	}
+
+	baseline := gomodguard.NewBaseline(results)
+	if len(baseline) != 1 || baseline[0].Module != "github.com/foo/bar" {
+		t.Errorf("got '%v' want only the suppressible result", baseline)
+	}
+
+	filter := gomodguard.NewBaselineFilter([]gomodguard.BaselineEntry{
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/baz", Count: 1},
+	})
+
+	if filter.IsKnown(results[1]) {
+		t.Errorf("got '%v' want '%v'", true, false)
+	}
+}
+
+func TestBaselineFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "baseline.json")
+
+	baseline, exists, err := gomodguard.ReadBaseline(filename)
+	if err != nil || exists || len(baseline) != 0 {
+		t.Fatalf("got '%+v' '%v' '%v' want an empty baseline for a missing file", baseline, exists, err)
+	}
+
+	want := []gomodguard.BaselineEntry{
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", Package: "github.com/foo/bar", Count: 1},
+	}
+
+	err = gomodguard.WriteBaseline(filename, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, exists, err = gomodguard.ReadBaseline(filename)
+	if err != nil || !exists {
+		t.Fatalf("got '%v' '%v' want the baseline file to be read", exists, err)
+	}
+
+	if !reflect.DeepEqual(baseline, want) {
+		t.Errorf("got '%+v' want '%+v'", baseline, want)
+	}
+}
@@ -0,0 +1,157 @@
+package gomodguard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	errBlamingFile  = "unable to git blame %s: %w"
+	uncommittedHash = "0000000000000000000000000000000000000000"
+	blameDateLayout = "2006-01-02"
+)
+
+// Blame is the commit that last changed the line of a result.
+type Blame struct {
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Commit      string    `json:"commit"`
+	Date        time.Time `json:"date"`
+}
+
+// String returns the author, abbreviated commit and date of the blame.
+func (b *Blame) String() string {
+	commit := b.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	return fmt.Sprintf("%s in %s on %s", b.Author, commit, b.Date.Format(blameDateLayout))
+}
+
+// Blamer runs git blame on files, each file is blamed once.
+type Blamer struct {
+	files map[string][]*Blame
+}
+
+// NewBlamer returns a Blamer.
+func NewBlamer() *Blamer {
+	return &Blamer{files: map[string][]*Blame{}}
+}
+
+// Blame returns the commit that last changed the line of the file. Nil is
+// returned for lines that are not committed yet. Files that cannot be blamed
+// return an error once and nil afterwards.
+func (b *Blamer) Blame(filename string, line int) (*Blame, error) {
+	lines, ok := b.files[filename]
+	if !ok {
+		var err error
+
+		lines, err = blameFile(filename)
+		b.files[filename] = lines
+
+		if err != nil {
+			return nil, fmt.Errorf(errBlamingFile, filename, err)
+		}
+	}
+
+	if line < 1 || line > len(lines) {
+		return nil, nil
+	}
+
+	return lines[line-1], nil
+}
+
+// blameFile returns the blame of each line of the file, the line porcelain
+// output of git blame repeats the commit headers for every line.
+func blameFile(filename string) ([]*Blame, error) {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(filename))
+	cmd.Dir = filepath.Dir(filename)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return nil, err
+	}
+
+	lines := []*Blame{}
+	current := &Blame{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	header := true
+
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// The content of the line ends the headers of the line.
+			if current.Commit == uncommittedHash {
+				lines = append(lines, nil)
+			} else {
+				lines = append(lines, current)
+			}
+
+			current, header = &Blame{}, true
+		case header:
+			current.Commit = strings.Fields(text)[0]
+			header = false
+		case strings.HasPrefix(text, "author "):
+			current.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-mail "):
+			current.AuthorEmail = strings.Trim(strings.TrimPrefix(text, "author-mail "), "<>")
+		case strings.HasPrefix(text, "author-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64)
+			if err == nil {
+				current.Date = time.Unix(seconds, 0).UTC()
+			}
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// lastCommit returns the commit that last changed the file. Nil is returned
+// for files that are not committed yet.
+func lastCommit(filename string) (*Blame, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%H%x00%an%x00%ae%x00%at", "--", filepath.Base(filename))
+	cmd.Dir = filepath.Dir(filename)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf(errBlamingFile, filename, fmt.Errorf("%w: %s", err, msg))
+		}
+
+		return nil, fmt.Errorf(errBlamingFile, filename, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(out)), "\x00")
+	if len(fields) != 4 {
+		return nil, nil
+	}
+
+	seconds, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf(errBlamingFile, filename, err)
+	}
+
+	return &Blame{Author: fields[1], AuthorEmail: fields[2], Commit: fields[0], Date: time.Unix(seconds, 0).UTC()}, nil
+}
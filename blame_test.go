@@ -0,0 +1,110 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestBlamer(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir, err := ioutil.TempDir("", "gomodguard-blame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Jane Doe",
+			"GIT_AUTHOR_EMAIL=jane@example.com",
+			"GIT_AUTHOR_DATE=2020-01-02T03:04:05Z",
+			"GIT_COMMITTER_NAME=Jane Doe",
+			"GIT_COMMITTER_EMAIL=jane@example.com",
+			"GIT_COMMITTER_DATE=2020-01-02T03:04:05Z",
+		)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s: %s", args, err, out)
+		}
+	}
+
+	filename := filepath.Join(dir, "main.go")
+
+	err = ioutil.WriteFile(filename, []byte("package main\n\nimport \"github.com/foo/bar\"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "-q")
+	git("add", "main.go")
+	git("commit", "-q", "-m", "Add main")
+
+	err = ioutil.WriteFile(filename, []byte("package main\n\nimport \"github.com/foo/bar\"\nimport \"github.com/foo/baz\"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blamer := gomodguard.NewBlamer()
+
+	blame, err := blamer.Blame(filename, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blame == nil {
+		t.Fatalf("got '%v' want a blame", blame)
+	}
+
+	if blame.Author != "Jane Doe" || blame.AuthorEmail != "jane@example.com" || len(blame.Commit) != 40 {
+		t.Errorf("got '%+v' want author Jane Doe <jane@example.com>", blame)
+	}
+
+	wantDate := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !blame.Date.Equal(wantDate) {
+		t.Errorf("got '%v' want '%v'", blame.Date, wantDate)
+	}
+
+	blame, err = blamer.Blame(filename, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blame != nil {
+		t.Errorf("got '%v' want no blame of an uncommitted line", blame)
+	}
+
+	outside, err := ioutil.TempDir("", "gomodguard-blame-outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+
+	outsideFilename := filepath.Join(outside, "main.go")
+
+	err = ioutil.WriteFile(outsideFilename, []byte("package main\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = blamer.Blame(outsideFilename, 1)
+	if err == nil {
+		t.Errorf("got '%v' want an error blaming a file outside of a git repository", err)
+	}
+
+	_, err = blamer.Blame(outsideFilename, 1)
+	if err != nil {
+		t.Errorf("got '%v' want the error reported once", err)
+	}
+}
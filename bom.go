@@ -0,0 +1,174 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	bomTimeout        = 10 * time.Second
+	errReadingBOM     = "unable to read bill of materials %s: %w"
+	errBOMStatus      = "unexpected status code %d fetching bill of materials %s"
+	errFixingGoModBOM = "unable to pin %s to the bill of materials: %w"
+)
+
+var blockReasonBOMDrift = "import of package `%%s` is blocked because version `%s` of the module diverges from version `%s` mandated by the bill of materials."
+
+// BOM is a bill of materials mapping module paths to the versions the
+// organization mandates.
+type BOM map[string]string
+
+// ReadBOM reads a bill of materials from a file or an http(s) URL. It is a
+// YAML or JSON map of module paths to versions.
+func ReadBOM(source string) (BOM, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		data, err = fetchBOM(source)
+	} else {
+		data, err = ioutil.ReadFile(source)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(errReadingBOM, source, err)
+	}
+
+	bom := BOM{}
+
+	err = yaml.Unmarshal(data, &bom)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingBOM, source, err)
+	}
+
+	trimmed := make(BOM, len(bom))
+	for modulePath, version := range bom {
+		trimmed[strings.TrimSpace(modulePath)] = strings.TrimSpace(version)
+	}
+
+	return trimmed, nil
+}
+
+// fetchBOM returns the bill of materials served at the url.
+func fetchBOM(url string) ([]byte, error) {
+	client := &http.Client{Timeout: bomTimeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(errBOMStatus, resp.StatusCode, url)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Version returns the mandated version of the module, if any.
+func (b BOM) Version(moduleName string) (string, bool) {
+	version, ok := b[moduleName]
+	return version, ok && version != ""
+}
+
+// Drift returns the direct requirements of the go.mod file whose version
+// diverges from the bill of materials, with the mandated versions.
+func (b BOM) Drift(modFile *modfile.File) []module.Version {
+	drift := []module.Version{}
+
+	for _, require := range modFile.Require {
+		if require == nil || require.Indirect {
+			continue
+		}
+
+		if version, ok := b.Version(require.Mod.Path); ok && version != require.Mod.Version {
+			drift = append(drift, module.Version{Path: require.Mod.Path, Version: version})
+		}
+	}
+
+	return drift
+}
+
+// FixGoModBOM rewrites the direct requirements of the go.mod file diverging
+// from the bill of materials to the mandated versions. The previous versions
+// of the rewritten requirements are returned.
+func FixGoModBOM(filename string, bom BOM) ([]module.Version, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+	}
+
+	modFile, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+	}
+
+	previous := []module.Version{}
+
+	for _, mandated := range bom.Drift(modFile) {
+		for _, require := range modFile.Require {
+			if require.Mod.Path == mandated.Path && !require.Indirect {
+				previous = append(previous, require.Mod)
+			}
+		}
+
+		err = modFile.AddRequire(mandated.Path, mandated.Version)
+		if err != nil {
+			return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+		}
+	}
+
+	if len(previous) == 0 {
+		return previous, nil
+	}
+
+	modFile.Cleanup()
+
+	out, err := modFile.Format()
+	if err != nil {
+		return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+	}
+
+	err = writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(out)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf(errFixingGoModBOM, filename, err)
+	}
+
+	return previous, os.Chmod(filename, info.Mode().Perm())
+}
+
+// bomBlockReason returns a block reason if the version of the module
+// diverges from the bill of materials.
+func (p *Processor) bomBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion string) (blockReason, bool) {
+	mandated, ok := p.bom.Version(canonicalModuleName)
+	if !ok || mandated == lintedModuleVersion {
+		return blockReason{}, false
+	}
+
+	return blockReason{
+		rule:     RuleBOMDrift,
+		reason:   fmt.Sprintf(blockReasonBOMDrift, escapeReason(lintedModuleVersion), escapeReason(mandated)),
+		severity: SeverityError,
+		data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+	}, true
+}
@@ -0,0 +1,109 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/module"
+)
+
+func TestBOMDrift(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bomFilename := filepath.Join(dir, "bom.yaml")
+
+	err = ioutil.WriteFile(bomFilename, []byte("github.com/foo/bar: v1.2.0\ngithub.com/foo/baz: v1.0.0\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n\t\"github.com/foo/qux\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{
+		Mode: gomodguard.ModeBlock,
+		BOM:  bomFilename,
+	}, "github.com/ryancurrah/example", []module.Version{
+		{Path: "github.com/foo/bar", Version: "v1.1.0"},
+		{Path: "github.com/foo/baz", Version: "v1.0.0"},
+		{Path: "github.com/foo/qux", Version: "v0.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	if len(results) != 1 || results[0].Rule != gomodguard.RuleBOMDrift || results[0].Module != "github.com/foo/bar" {
+		t.Fatalf("got '%+v' want github.com/foo/bar to diverge from the bill of materials", results)
+	}
+
+	wantReason := "import of package `github.com/foo/bar` is blocked because version `v1.1.0` of the module diverges from version `v1.2.0` mandated by the bill of materials."
+	if results[0].Reason != wantReason {
+		t.Errorf("got '%v' want '%v'", results[0].Reason, wantReason)
+	}
+}
+
+func TestFixGoModBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "go.mod")
+
+	err = ioutil.WriteFile(filename, []byte(`module github.com/ryancurrah/example
+
+go 1.14
+
+require (
+	github.com/foo/bar v1.1.0
+	github.com/foo/baz v1.0.0
+	github.com/foo/qux v0.1.0 // indirect
+)
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bom := gomodguard.BOM{
+		"github.com/foo/bar": "v1.2.0",
+		"github.com/foo/baz": "v1.0.0",
+		"github.com/foo/qux": "v0.2.0",
+	}
+
+	previous, err := gomodguard.FixGoModBOM(filename, bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPrevious := []module.Version{{Path: "github.com/foo/bar", Version: "v1.1.0"}}
+	if !reflect.DeepEqual(previous, wantPrevious) {
+		t.Errorf("got '%+v' want '%+v'", previous, wantPrevious)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"github.com/foo/bar v1.2.0\n", "github.com/foo/baz v1.0.0\n", "github.com/foo/qux v0.1.0 // indirect\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("got '%s' want it to contain '%s'", data, want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+const errInvalidCategoryPattern = "invalid module %s of category %s: %w"
+
+var blockReasonDuplicateCategory = "import of package `%%s` is blocked because module `%s` of category `%s` duplicates %s in `%s`, at most one module of a category may be used per binary."
+
+// categoryImport is an import of a package of a module tagged with a category.
+type categoryImport struct {
+	category string
+	module   string
+	pkg      string
+	position token.Position
+}
+
+// validateCategories returns an error if a module of a category is a
+// malformed glob pattern.
+func validateCategories(config *Configuration) error {
+	for category, modules := range config.Categories {
+		for _, pattern := range modules {
+			if err := match.ValidateGlob(pattern); err != nil {
+				return fmt.Errorf(errInvalidCategoryPattern, strings.TrimSpace(pattern), category, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isCategoriesEnabled returns true if categories are configured and their
+// rule is enabled.
+func (p *Processor) isCategoriesEnabled() bool {
+	return p.Config != nil && len(p.Config.Categories) > 0 && p.Config.IsRuleEnabled(RuleDuplicateCategory)
+}
+
+// recordMainPackage records the package of a file of the linted module as
+// a binary if the file is in a main package.
+func (p *Processor) recordMainPackage(packagePath string, file *ast.File) {
+	if file.Name == nil || file.Name.Name != "main" {
+		return
+	}
+
+	if p.mainPackages == nil {
+		p.mainPackages = map[string]bool{}
+	}
+
+	p.mainPackages[packagePath] = true
+}
+
+// recordCategoryImport records the import of a package of a module tagged
+// with a category by a package of the linted module. A module tagged with
+// several categories is recorded for each.
+func (p *Processor) recordCategoryImport(packagePath, importedPkg string, position token.Position) {
+	categories := make([]string, 0, len(p.Config.Categories))
+	for category := range p.Config.Categories {
+		categories = append(categories, category)
+	}
+
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		for _, pattern := range p.Config.Categories[category] {
+			if !match.AnyMajorVersion(pattern, importedPkg, match.GlobModule) {
+				continue
+			}
+
+			if p.categoryImports == nil {
+				p.categoryImports = map[string][]categoryImport{}
+			}
+
+			p.categoryImports[packagePath] = append(p.categoryImports[packagePath], categoryImport{
+				category: strings.TrimSpace(category),
+				module:   strings.TrimSpace(pattern),
+				pkg:      importedPkg,
+				position: position,
+			})
+
+			break
+		}
+	}
+}
+
+// processCategories adds lint errors for the imports of modules of the same
+// category used by one binary. The packages of a binary are its main package
+// and the packages of the linted module it imports. Without main packages all
+// packages of the linted module are one binary. Each import is reported once,
+// for the first binary using it.
+func (p *Processor) processCategories() {
+	binaries := make([]string, 0, len(p.mainPackages))
+	for pkg := range p.mainPackages {
+		binaries = append(binaries, pkg)
+	}
+
+	sort.Strings(binaries)
+
+	if len(binaries) == 0 {
+		binaries = []string{""}
+	}
+
+	reported := map[token.Position]bool{}
+
+	for _, binary := range binaries {
+		imports := p.binaryCategoryImports(binary)
+
+		categories := make([]string, 0, len(imports))
+		for category := range imports {
+			categories = append(categories, category)
+		}
+
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			modules := make([]string, 0, len(imports[category]))
+			for moduleName := range imports[category] {
+				modules = append(modules, moduleName)
+			}
+
+			if len(modules) < 2 {
+				continue
+			}
+
+			sort.Strings(modules)
+
+			for _, moduleName := range modules {
+				others := make([]string, 0, len(modules)-1)
+
+				for _, other := range modules {
+					if other != moduleName {
+						others = append(others, fmt.Sprintf("`%s`", other))
+					}
+				}
+
+				for _, imp := range imports[category][moduleName] {
+					if reported[imp.position] {
+						continue
+					}
+
+					reported[imp.position] = true
+
+					p.addCategoryError(imp, binary, strings.Join(others, ", "))
+				}
+			}
+		}
+	}
+}
+
+// binaryCategoryImports returns the imports of modules with a category of the
+// packages of the binary, by category and module. The empty binary is all
+// packages of the linted module.
+func (p *Processor) binaryCategoryImports(binary string) map[string]map[string][]categoryImport {
+	packages := make([]string, 0, len(p.categoryImports))
+
+	if binary == "" {
+		for pkg := range p.categoryImports {
+			packages = append(packages, pkg)
+		}
+	} else {
+		packages = reachablePackages(p.packageImports, binary)
+	}
+
+	sort.Strings(packages)
+
+	imports := map[string]map[string][]categoryImport{}
+
+	for _, pkg := range packages {
+		for _, imp := range p.categoryImports[pkg] {
+			if imports[imp.category] == nil {
+				imports[imp.category] = map[string][]categoryImport{}
+			}
+
+			imports[imp.category][imp.module] = append(imports[imp.category][imp.module], imp)
+		}
+	}
+
+	return imports
+}
+
+// addCategoryError adds a lint error for the import of a module duplicating
+// the other modules of its category in the binary.
+func (p *Processor) addCategoryError(imp categoryImport, binary, others string) {
+	if binary == "" {
+		binary = strings.TrimSpace(p.Modfile.Module.Mod.Path)
+	}
+
+	r := blockReason{
+		rule:     RuleDuplicateCategory,
+		reason:   fmt.Sprintf(blockReasonDuplicateCategory, escapeReason(imp.module), escapeReason(imp.category), escapeReason(others), escapeReason(binary)),
+		severity: SeverityError,
+		data:     MessageData{Package: imp.pkg, Module: imp.module},
+		source:   []string{"categories", imp.category},
+	}
+
+	severity, ok := p.ruleSeverity(r)
+	if !ok {
+		return
+	}
+
+	p.Result = append(p.Result, Result{
+		FileName:   imp.position.Filename,
+		LineNumber: imp.position.Line,
+		Position:   imp.position,
+		Reason:     p.renderReason(r, imp.pkg),
+		Severity:   severity,
+		Rule:       r.rule,
+		Package:    imp.pkg,
+		Module:     imp.module,
+		Owner:      p.owner(r),
+		PolicyURL:  p.policyURL(r),
+		Provenance: p.provenance(r),
+		CodeOwners: p.codeOwners.Owners(imp.position.Filename),
+	})
+}
+
+// reachablePackages returns the package and the packages it imports directly
+// or indirectly in the import graph.
+func reachablePackages(graph map[string]map[string]token.Position, pkg string) []string {
+	seen := map[string]bool{pkg: true}
+	queue := []string{pkg}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for imported := range graph[current] {
+			if !seen[imported] {
+				seen[imported] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+
+	packages := make([]string, 0, len(seen))
+	for reachable := range seen {
+		packages = append(packages, reachable)
+	}
+
+	return packages
+}
@@ -0,0 +1,58 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorCategories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":                "module example.com/app\n\nrequire (\n\tgithub.com/go-resty/resty/v2 v2.7.0\n\tgithub.com/hashicorp/go-retryablehttp v0.7.0\n\tgithub.com/sirupsen/logrus v1.8.1\n\tgo.uber.org/zap v1.19.0\n)\n",
+		"cmd/api/main.go":       "package main\n\nimport (\n\t_ \"example.com/app/client\"\n\t_ \"net/http\"\n)\n",
+		"cmd/worker/main.go":    "package main\n\nimport (\n\t_ \"github.com/hashicorp/go-retryablehttp\"\n\t_ \"github.com/sirupsen/logrus\"\n)\n",
+		"client/client.go":      "package client\n\nimport _ \"github.com/go-resty/resty/v2\"\n",
+		"client/client_test.go": "package client\n\nimport _ \"github.com/hashicorp/go-retryablehttp\"\n",
+		"log/log.go":            "package log\n\nimport _ \"go.uber.org/zap\"\n",
+	}
+
+	filenames := writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{Domains: []string{"github.com", "go.uber.org"}},
+		Categories: map[string][]string{
+			"http-client": {"net/http", "github.com/go-resty/resty", "github.com/hashicorp/go-retryablehttp"},
+			"logging":     {"github.com/sirupsen/logrus", "go.uber.org/zap"},
+		},
+		GoEnv: map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles(filenames)
+
+	wantReasons := map[string]string{
+		filepath.Join(dir, "cmd/api/main.go"):  "import of package `net/http` is blocked because module `net/http` of category `http-client` duplicates `github.com/go-resty/resty` in `example.com/app/cmd/api`, at most one module of a category may be used per binary.",
+		filepath.Join(dir, "client/client.go"): "import of package `github.com/go-resty/resty/v2` is blocked because module `github.com/go-resty/resty` of category `http-client` duplicates `net/http` in `example.com/app/cmd/api`, at most one module of a category may be used per binary.",
+	}
+
+	if len(results) != len(wantReasons) {
+		t.Fatalf("got '%+v' want '%+v'", results, wantReasons)
+	}
+
+	for _, result := range results {
+		if result.Rule != gomodguard.RuleDuplicateCategory || result.Reason != wantReasons[result.FileName] {
+			t.Errorf("got '%s' '%s' want '%s'", result.Rule, result.Reason, wantReasons[result.FileName])
+		}
+	}
+}
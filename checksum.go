@@ -0,0 +1,89 @@
+package gomodguard
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	checksumLicensePrefix = "sha256:"
+	checksumGoSumPrefix   = "h1:"
+	goSumSuffix           = "/go.mod"
+)
+
+// IsAllowedChecksum returns true if one of the given checksums of a module is
+// in the allowed checksums list.
+func (a *Allowed) IsAllowedChecksum(checksums []string) bool {
+	for i := range a.Checksums {
+		for j := range checksums {
+			if strings.TrimSpace(a.Checksums[i]) == checksums[j] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// moduleChecksums returns the identities of a module version that survive a
+// rename of the module: the sha256 checksums of its license files in the
+// module cache and its hashes in the go.sum file, as recorded in the
+// checksum database.
+func (p *Processor) moduleChecksums(goSum map[module.Version][]string, mod module.Version) []string {
+	checksums := append([]string{}, goSum[mod]...)
+
+	moduleDir, ok := moduleCacheDir(p.modCache().dir, mod)
+	if !ok {
+		return checksums
+	}
+
+	for _, licenseFileName := range licenseFileNames {
+		data, err := ioutil.ReadFile(filepath.Join(moduleDir, licenseFileName))
+		if err != nil {
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		checksums = append(checksums, checksumLicensePrefix+hex.EncodeToString(sum[:]))
+	}
+
+	return checksums
+}
+
+// goSumFilename returns the go.sum file of the go.mod file of the main module.
+func (e goEnv) goSumFilename() string {
+	return strings.TrimSuffix(e.modFile(), ".mod") + ".sum"
+}
+
+// readGoSum returns the h1 hashes of the module versions in a go.sum file of
+// the file system.
+// Hashes of the go.mod file of a module version are included. A missing
+// go.sum file results in no hashes.
+func readGoSum(files fileSystem, filename string) map[module.Version][]string {
+	goSum := map[module.Version][]string{}
+
+	f, err := files.open(filename)
+	if err != nil {
+		return goSum
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], checksumGoSumPrefix) {
+			continue
+		}
+
+		mod := module.Version{Path: fields[0], Version: strings.TrimSuffix(fields[1], goSumSuffix)}
+		goSum[mod] = append(goSum[mod], fields[2])
+	}
+
+	return goSum
+}
@@ -0,0 +1,59 @@
+package gomodguard_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorAllowedChecksums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	license := "Copyright (c) 2021 Example\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\n"
+	licenseSum := sha256.Sum256([]byte(license))
+
+	// Notices are shared by unrelated modules, they do not identify a module.
+	notice := "This product includes software developed by Example.\n"
+	noticeSum := sha256.Sum256([]byte(notice))
+
+	files := map[string]string{
+		"go.mod":     "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/fork/lib v1.0.0\n\tgithub.com/foo/pinned v1.0.0\n\tgithub.com/foo/denied v1.0.0\n)\n",
+		"go.sum":     "github.com/foo/pinned v1.0.0 h1:pinnedzip=\ngithub.com/foo/pinned v1.0.0/go.mod h1:pinnedmod=\ngithub.com/foo/denied v1.0.0 h1:deniedzip=\n",
+		"example.go": "package example\n\nimport (\n\t\"github.com/fork/lib\"\n\t\"github.com/foo/pinned\"\n\t\"github.com/foo/denied\"\n)\n",
+		"modcache/github.com/fork/lib@v1.0.0/LICENSE":  license,
+		"modcache/github.com/foo/denied@v1.0.0/NOTICE": notice,
+	}
+
+	writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{
+			Checksums: []string{"sha256:" + hex.EncodeToString(licenseSum[:]), "sha256:" + hex.EncodeToString(noticeSum[:]), "h1:pinnedzip="},
+		},
+		GoEnv:   map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod"), "GOMODCACHE": filepath.Join(dir, "modcache")},
+		Offline: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filepath.Join(dir, "example.go")})
+
+	if len(results) != 1 {
+		t.Fatalf("got '%+v' want 1 result", results)
+	}
+
+	want := "github.com/foo/denied"
+	if results[0].Module != want {
+		t.Errorf("got '%v' want '%v'", results[0].Module, want)
+	}
+}
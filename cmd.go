@@ -1,17 +1,18 @@
 package gomodguard
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/go-xmlfmt/xmlfmt"
 	"github.com/mitchellh/go-homedir"
-	"github.com/phayes/checkstyle"
 	"gopkg.in/yaml.v2"
 )
 
@@ -23,19 +24,69 @@ const (
 
 var (
 	configFile           = ".gomodguard.yaml"
+	commands             = []string{"notice", "config", "coverage", "explain", "reach", "isolate", "fix-gomod"}
 	logger               = log.New(os.Stderr, "", 0)
 	errFindingConfigFile = fmt.Errorf("could not find config file")
 )
 
 // Run the gomodguard linter. Returns the exit code to use.
 func Run() int {
+	exitCode, err := run()
+
+	var uerr *usageError
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitClean
+	case errors.As(err, &uerr):
+		// The flag package already printed the error and the usage.
+		return UsageExitCode(exitCodeMode)
+	case err != nil:
+		logger.Printf("error: %s", err)
+		return FailureExitCode(exitCodeMode, err)
+	}
+
+	return exitCode
+}
+
+// run runs the gomodguard linter. Returns the exit code to use, or the error
+// the run failed with.
+func run() (int, error) {
 	var (
 		args           []string
 		help           bool
 		noTest         bool
-		report         string
-		reportFile     string
+		reports        = &reportFlags{}
 		issuesExitCode int
+		exitMode       string
+		enableRules    string
+		disableRules   string
+		offline        bool
+		resolution     string
+		fix            bool
+		fixCheck       bool
+		pinBOM         bool
+		lintGoMod      bool
+		indirect       bool
+		deep           bool
+		profile        string
+		blame          bool
+		maxResults     int
+		progressFD     int
+		ratchetFile    string
+		agesFile       string
+		auditFile      string
+		baselineFile   string
+		updateBaseline bool
+		modules        moduleFlags
+		discover       bool
+		modulesOnly    bool
+		goModPath      string
+		symlinks       string
+		workers        int
+		fullParse      bool
+		workDir        string
+		progress       io.Writer
 		cwd, _         = os.Getwd()
 	)
 
@@ -43,31 +94,76 @@ func Run() int {
 	flag.BoolVar(&help, "help", false, "")
 	flag.BoolVar(&noTest, "n", false, "Don't lint test files")
 	flag.BoolVar(&noTest, "no-test", false, "")
-	flag.StringVar(&report, "r", "", "Report results to one of the following formats: checkstyle. A report file destination must also be specified")
-	flag.StringVar(&report, "report", "", "")
-	flag.StringVar(&reportFile, "f", "", "Report results to the specified file. A report type must also be specified")
-	flag.StringVar(&reportFile, "file", "", "")
+	flag.Var(reportFormatValue{reports}, "r", "Report results to one of the following formats: "+strings.Join(ReportFormats, ", ")+". Can be repeated to write several reports")
+	flag.Var(reportFormatValue{reports}, "report", "")
+	flag.Var(reportFileValue{reports}, "f", "Report results of the preceding report to the specified file instead of stdout")
+	flag.Var(reportFileValue{reports}, "file", "")
+	flag.Var(reportFileValue{reports}, "output", "Alias of -f")
 	flag.IntVar(&issuesExitCode, "i", 2, "Exit code when issues were found")
 	flag.IntVar(&issuesExitCode, "issues-exit-code", 2, "")
-	flag.Parse()
+	flag.StringVar(&exitMode, "exit-code-mode", ExitCodeModeDefault, "Exit code mode: "+strings.Join(ExitCodeModes, ", ")+". The matrix mode exits with 0 when clean, 1 on errors, 2 on warnings only, 3 on a tool failure and 4 on an invalid configuration")
+	flag.StringVar(&enableRules, "enable-rules", "", "Comma separated rule families to enable: "+strings.Join(RuleFamilies(), ", "))
+	flag.StringVar(&disableRules, "disable-rules", "", "Comma separated rule families to disable")
+	flag.StringVar(&profile, "profile", "", "Apply the rule families and severities of this profile of the configuration")
+	flag.BoolVar(&fix, "fix", false, "Rewrite imports of blocked packages to the replacement package of their mapping")
+	flag.BoolVar(&fixCheck, "fix-check", false, "Build the packages of rewritten files and the module before writing them and keep the imports whose rewrite breaks compilation, implies -fix")
+	flag.BoolVar(&pinBOM, "pin-bom", false, "Rewrite requires of go.mod diverging from the bill of materials to the mandated versions before linting")
+	flag.BoolVar(&lintGoMod, "lint-gomod", false, "Report blocked modules at their require in go.mod, even if no file imports them")
+	flag.BoolVar(&indirect, "indirect", false, "Also lint indirect requires of go.mod, reported at their require with the chain of requires pulling them in")
+	flag.BoolVar(&deep, "deep", false, "Report allowed modules whose own go.mod requires blocked modules or versions")
+	flag.BoolVar(&offline, "offline", false, "Read module metadata from the module cache instead of the network")
+	flag.StringVar(&resolution, "resolution", "", "Resolve imported packages to modules with go.mod requires or go list, one of "+strings.Join(Resolutions, ", "))
+	flag.Var(&modules, "module", "Lint the module in this directory against its go.mod file, and its .gomodguard.yaml file if any. Can be repeated to lint several modules")
+	flag.StringVar(&goModPath, "gomod", "", "Lint against this go.mod file instead of the go.mod file of the go environment")
+	flag.StringVar(&workDir, "C", "", "Change to this directory before reading the configuration and linting")
+	flag.StringVar(&symlinks, "symlinks", "", "Handling of symlinks in directories of package patterns, one of "+strings.Join(SymlinkPolicies, ", ")+", defaults to files")
+	flag.BoolVar(&discover, "discover-modules", false, "Lint each file against the nearest go.mod file enclosing it, and the .gomodguard.yaml file of its module if any")
+	flag.BoolVar(&modulesOnly, "modules-only", false, "Only lint files enclosed by a go.mod file, silently ignoring the rest of the repository, implies -discover-modules")
+	flag.StringVar(&ratchetFile, "ratchet", "", "Only fail when the number of results of a directory increases over the counts recorded in this file")
+	flag.StringVar(&baselineFile, "baseline", "", "Only report results not recorded in this file, the results are recorded if the file does not exist")
+	flag.BoolVar(&updateBaseline, "update-baseline", false, "Record the current results in the baseline file")
+	flag.BoolVar(&blame, "blame", false, "Attach the author and date of the commit that last changed the line of each result with git blame")
+	flag.StringVar(&agesFile, "suppression-ages", "", "Record when each suppressed result was first seen in this file and report the oldest first")
+	flag.StringVar(&auditFile, "audit-file", "", "Append a record of each new use of an exemption or baseline entry to this file")
+	flag.IntVar(&progressFD, "progress-fd", 0, "Write progress events as JSON lines to this file descriptor, 0 disables progress events")
+	flag.BoolVar(&fullParse, "full-parse", false, "Parse whole files instead of only their imports, reporting syntax errors after the imports")
+	flag.IntVar(&workers, "workers", 0, "Read and parse this many files concurrently, 0 uses GOMAXPROCS")
+	flag.IntVar(&maxResults, "max-results-in-memory", 0, "Keep at most this many results in memory and spill the rest to a temporary file, 0 keeps all results in memory")
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+
+	err := parseFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		// Invalid flags exit by the mode given before them.
+		if exitMode == ExitCodeModeMatrix {
+			exitCodeMode = exitMode
+		}
 
-	report = strings.TrimSpace(strings.ToLower(report))
+		return 0, err
+	}
 
 	if help {
 		showHelp()
-		return 0
+		return 0, nil
 	}
 
-	if report != "" && report != "checkstyle" {
-		logger.Fatalf("error: invalid report type '%s'", report)
+	err = validateExitCodeMode(exitMode)
+	if err != nil {
+		return 0, invalidConfig(err)
 	}
 
-	if report != "" && reportFile == "" {
-		logger.Fatalf("error: a report file must be specified when a report is enabled")
+	exitCodeMode = exitMode
+
+	if workDir != "" {
+		err = os.Chdir(workDir)
+		if err != nil {
+			return 0, err
+		}
+
+		cwd, _ = os.Getwd()
 	}
 
-	if report == "" && reportFile != "" {
-		logger.Fatalf("error: a report type must be specified when a report file is enabled")
+	if reports.file != "" {
+		return 0, invalidConfig(errors.New("a report type must be specified when a report file is enabled"))
 	}
 
 	args = flag.Args()
@@ -75,47 +171,1002 @@ func Run() int {
 		args = []string{"./..."}
 	}
 
+	// Modules can bring their own configuration, so a main configuration is
+	// optional when linting modules.
 	config, err := GetConfig(configFile)
+	if errors.Is(err, errFindingConfigFile) && (len(modules) > 0 || discover || modulesOnly) {
+		config, err = &Configuration{}, nil
+	}
+
+	if err != nil {
+		return 0, invalidConfig(err)
+	}
+
+	applyFlags := func(config *Configuration) error {
+		name := profile
+		if name == "" {
+			name = config.Profile
+		}
+
+		if name != "" {
+			err := config.ApplyProfile(name)
+			if err != nil {
+				return invalidConfig(err)
+			}
+		}
+
+		config.SetRuleFamilies(enableRules, true)
+		config.SetRuleFamilies(disableRules, false)
+
+		if offline {
+			config.Offline = true
+		}
+
+		if resolution != "" {
+			config.Resolution = resolution
+		}
+
+		if workers != 0 {
+			config.Workers = workers
+		}
+
+		if fullParse {
+			config.FullParse = true
+		}
+
+		if lintGoMod {
+			config.LintGoMod = true
+		}
+
+		if indirect {
+			config.Indirect = true
+		}
+
+		if deep {
+			config.Deep = true
+		}
+
+		return nil
+	}
+
+	err = applyFlags(config)
+	if err != nil {
+		return 0, err
+	}
+
+	if ratchetFile != "" {
+		config.Ratchet = ratchetFile
+	}
+
+	if agesFile != "" {
+		config.SuppressionAges = agesFile
+	}
+
+	if auditFile != "" {
+		config.AuditFile = auditFile
+	}
+
+	if baselineFile != "" {
+		config.Baseline = baselineFile
+	}
+
+	if blame {
+		config.Blame = true
+	}
+
+	if discover {
+		config.DiscoverModules = true
+	}
+
+	if modulesOnly {
+		config.ModulesOnly = true
+	}
+
+	// Files outside of the modules are dropped, the remaining files are
+	// linted against the module enclosing them.
+	if config.ModulesOnly {
+		config.DiscoverModules = true
+	}
+
+	if goModPath != "" {
+		config.GoModPath = goModPath
+	}
+
+	if symlinks != "" {
+		config.Symlinks = symlinks
+	}
+
+	err = validateSymlinks(config)
+	if err != nil {
+		return 0, invalidConfig(err)
+	}
+
+	filteredFiles := func(args []string) []string {
+		files := GetFilteredFilesSymlinks(cwd, noTest, config.Symlinks, args)
+		if config.ModulesOnly {
+			return FilterModuleFiles(files)
+		}
+
+		return files
+	}
+
+	if updateBaseline && config.Baseline == "" {
+		return 0, invalidConfig(errors.New("a baseline file must be specified when updating the baseline"))
+	}
+
+	if len(reports.reports) > 0 {
+		config.Reports = reports.reports
+	}
+
+	err = validateReports(config.Reports)
+	if err != nil {
+		return 0, invalidConfig(err)
+	}
+
+	// Files and directories named like a command are linted, as before the
+	// commands were added.
+	command := args[0]
+	if containsString(commands, command) && pathExists(command) {
+		logger.Printf("info: linting %s instead of running the %s command, it is an existing path", command, command)
+		command = ""
+	}
+
+	switch command {
+	case "notice":
+		return runNotice(config, args[1:])
+	case "config":
+		return runConfig(config, args[1:])
+	case "coverage":
+		return runCoverage(config, filteredFiles(coverageArgs(args[1:])))
+	case "explain":
+		return runExplain(config)
+	case "reach":
+		return runReach(config, args[1:], filteredFiles)
+	case "isolate":
+		return runIsolate(config, args[1:], filteredFiles)
+	case "fix-gomod":
+		return runFixGoModCommand(config, cwd, noTest, args[1:])
+	}
+
+	if progressFD > 0 {
+		progress = os.NewFile(uintptr(progressFD), "progress")
+	}
+
+	results := NewResultStream(maxResults)
+	defer results.Close()
+
+	suppressed := []Result{}
+	audit := []AuditRecord{}
+	linted := []string{}
+
+	lint := func(config *Configuration, files func() []string) error {
+		moduleSuppressed, moduleAudit, err := lintModule(config, func() []string {
+			moduleFiles := files()
+			linted = append(linted, moduleFiles...)
+
+			return moduleFiles
+		}, progress, results)
+		if err != nil {
+			return err
+		}
+
+		suppressed = append(suppressed, moduleSuppressed...)
+		audit = append(audit, moduleAudit...)
+
+		return nil
+	}
+
+	lintModuleDir := func(dir string, files func() []string) error {
+		moduleConfig, err := moduleConfiguration(config, dir)
+		if err != nil {
+			return invalidConfig(err)
+		}
+
+		err = applyFlags(moduleConfig)
+		if err != nil {
+			return err
+		}
+
+		if pinBOM {
+			err = runPinBOM(moduleConfig)
+			if err != nil {
+				return err
+			}
+		}
+
+		logger.Printf("info: linting module %s", dir)
+
+		return lint(moduleConfig, files)
+	}
+
+	switch {
+	case len(modules) > 0:
+		for _, dir := range modules {
+			moduleArgs := make([]string, 0, len(args))
+			for _, arg := range args {
+				moduleArgs = append(moduleArgs, filepath.Join(dir, arg))
+			}
+
+			err = lintModuleDir(dir, func() []string { return filteredFiles(moduleArgs) })
+			if err != nil {
+				return 0, err
+			}
+		}
+	case config.DiscoverModules:
+		groups := GroupFilesByModule(filteredFiles(args))
+
+		for _, dir := range sortedModuleDirs(groups) {
+			files := groups[dir]
+
+			if dir == "" {
+				logger.Printf("warning: %d files are outside of a module, linting them against the current directory", len(files))
+				err = lint(config, func() []string { return files })
+				if err != nil {
+					return 0, err
+				}
+
+				continue
+			}
+
+			err = lintModuleDir(dir, func() []string { return files })
+			if err != nil {
+				return 0, err
+			}
+		}
+	default:
+		if pinBOM {
+			err = runPinBOM(config)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = lint(config, func() []string { return filteredFiles(args) })
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if fix || fixCheck {
+		unfixed, err := runFix(results, maxResults, fixCheck)
+		if err != nil {
+			return 0, err
+		}
+		defer unfixed.Close()
+
+		results = unfixed
+	}
+
+	if config.Baseline != "" {
+		unknown, err := runBaseline(config.Baseline, results, updateBaseline, maxResults, func(r Result) {
+			if config.SuppressionAges != "" {
+				suppressed = append(suppressed, r)
+			}
+
+			if config.AuditFile != "" {
+				audit = append(audit, baselineAuditRecord(config.Baseline, r))
+			}
+		})
+		if err != nil {
+			return 0, err
+		}
+		defer unknown.Close()
+
+		results = unknown
+	}
+
+	if config.Blame {
+		blamed, err := runBlame(results, maxResults)
+		if err != nil {
+			return 0, err
+		}
+		defer blamed.Close()
+
+		results = blamed
+	}
+
+	err = WriteReportsStream(stdoutReports(config.Reports), results)
+	if err != nil {
+		return 0, err
+	}
+
+	errorCount, warningCount := 0, 0
+	ratchet := Ratchet{}
+	codeOwnerCounts := CodeOwnerCounts{}
+
+	err = results.Each(func(r Result) error {
+		if r.IsWarning() {
+			warningCount++
+		} else {
+			errorCount++
+		}
+
+		ratchet.Add(r)
+		codeOwnerCounts.Add(r)
+
+		return nil
+	})
 	if err != nil {
-		logger.Fatalf("error: %s", err)
+		return 0, err
+	}
+
+	if config.CodeOwners != "" {
+		for _, summary := range codeOwnerCounts.Summary() {
+			logger.Printf("info: %s owns %d results", summary.Owner, summary.Results)
+		}
+	}
+
+	if config.SuppressionAges != "" {
+		err = runSuppressionAges(config.SuppressionAges, suppressed)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if config.AuditFile != "" {
+		err = runAudit(config.AuditFile, audit)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if config.Ratchet != "" {
+		return runRatchet(config.Ratchet, ratchet, linted, ResultsExitCode(exitCodeMode, issuesExitCode, 1, 0))
+	}
+
+	return ResultsExitCode(exitCodeMode, issuesExitCode, errorCount, warningCount), nil
+}
+
+// reportFlags collects the report and report file flags. A report file
+// applies to the preceding report, or the next one if it comes first.
+type reportFlags struct {
+	reports []Report
+	file    string
+}
+
+// reportFormatValue adds a report for each report flag.
+type reportFormatValue struct {
+	*reportFlags
+}
+
+func (v reportFormatValue) String() string {
+	return ""
+}
+
+func (v reportFormatValue) Set(format string) error {
+	v.reports = append(v.reports, Report{Format: strings.TrimSpace(strings.ToLower(format)), File: v.file})
+	v.file = ""
+
+	return nil
+}
+
+// reportFileValue sets the file of a report for each report file flag.
+type reportFileValue struct {
+	*reportFlags
+}
+
+func (v reportFileValue) String() string {
+	return ""
+}
+
+func (v reportFileValue) Set(file string) error {
+	if len(v.reports) > 0 && v.reports[len(v.reports)-1].File == "" {
+		v.reports[len(v.reports)-1].File = file
+		return nil
 	}
 
-	filteredFiles := GetFilteredFiles(cwd, noTest, args)
+	v.file = file
 
+	return nil
+}
+
+// stdoutReports returns the reports with the text report to stdout added
+// if no other report is written to stdout.
+func stdoutReports(reports []Report) []Report {
+	for i := range reports {
+		if reports[i].IsStdout() {
+			return reports
+		}
+	}
+
+	return append([]Report{{Format: ReportText}}, reports...)
+}
+
+// lintModule lints the files of a module and adds the results to the
+// stream. Files are only collected if there is policy work. The results
+// suppressed by exemptions are returned.
+func lintModule(config *Configuration, files func() []string, progress io.Writer, results *ResultStream) ([]Result, []AuditRecord, error) {
 	processor, err := NewProcessor(config)
 	if err != nil {
-		logger.Fatalf("error: %s", err)
+		return nil, nil, err
 	}
 
+	processor.Progress = progress
+
 	logger.Printf("info: allowed modules, %+v", config.Allowed.Modules)
 	logger.Printf("info: allowed module domains, %+v", config.Allowed.Domains)
 	logger.Printf("info: blocked modules, %+v", config.Blocked.Modules.Get())
 	logger.Printf("info: blocked modules with version constraints, %+v", config.Blocked.Versions.Get())
 
-	results := processor.ProcessFiles(filteredFiles)
+	if processor.HasPolicyWork() {
+		err = processor.ProcessFilesStream(files(), results)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		logger.Printf("info: no blocked modules in go.mod and no file rules configured, skipping files")
+	}
+
+	for _, exemption := range processor.Exemptions {
+		logger.Printf("info: %s exempt from rules %+v, %d results exempted, reason: %s",
+			exemption.Location(), exemption.Rules, exemption.Exempted, exemption.Reason)
+	}
+
+	return processor.Suppressed, processor.exemptionAuditRecords(), nil
+}
+
+// moduleConfiguration returns the configuration of the module in the
+// directory. The .gomodguard.yaml file of the module, if any, replaces the
+// configuration. The module is linted against its own go.mod file.
+func moduleConfiguration(config *Configuration, dir string) (*Configuration, error) {
+	moduleConfig := *config
+
+	if filename := filepath.Join(dir, configFile); fileExists(filename) {
+		fileConfig, err := readConfigFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		moduleConfig = *fileConfig
+	}
+
+	goEnv := map[string]string{}
+	for key, value := range moduleConfig.GoEnv {
+		goEnv[key] = value
+	}
+
+	goEnv["GOMOD"] = filepath.Join(dir, goModFilename)
+	moduleConfig.GoEnv = goEnv
+	moduleConfig.GoModPath = ""
+
+	return &moduleConfig, nil
+}
+
+// moduleFlags collects the repeatable module flag.
+type moduleFlags []string
+
+func (m *moduleFlags) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *moduleFlags) Set(dir string) error {
+	*m = append(*m, dir)
+	return nil
+}
+
+// runRatchet compares the counts of the run with the recorded counts. The
+// run fails if a count increased, otherwise the counts of the directories of
+// the linted files are recorded.
+func runRatchet(filename string, ratchet Ratchet, linted []string, issuesExitCode int) (int, error) {
+	recorded, exists, err := ReadRatchet(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	if regressions := ratchet.Regressions(recorded); exists && len(regressions) > 0 {
+		for _, regression := range regressions {
+			logger.Printf("error: ratchet regression, %s", regression)
+		}
+
+		return issuesExitCode, nil
+	}
+
+	err = WriteRatchet(filename, ratchet.Merge(recorded, linted))
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// runFix rewrites the imports of the results with a replacement package and
+// returns a stream of the results that were not fixed. With check, rewrites
+// breaking the compilation of their package are not written.
+func runFix(results *ResultStream, maxResults int, check bool) (*ResultStream, error) {
+	fixable := []Result{}
+
+	err := results.Each(func(r Result) error {
+		if _, ok := r.Replacement.PackagePath(r.Package); ok {
+			fixable = append(fixable, r)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fixed  []Result
+		broken []BrokenFix
+	)
+
+	if check {
+		fixed, broken, err = FixImportsChecked(fixable)
+	} else {
+		fixed, err = FixImports(fixable)
+	}
+
+	for i := range broken {
+		replacement, _ := broken[i].Result.Replacement.PackagePath(broken[i].Result.Package)
+		logger.Printf("warning: %s:%d import of %s not fixed, the rewrite to %s breaks compilation: %s",
+			broken[i].Result.FileName, broken[i].Result.LineNumber, broken[i].Result.Package, replacement, strings.Join(broken[i].Errors, "; "))
+	}
+
+	type fixKey struct {
+		fileName string
+		line     int
+		pkg      string
+	}
+
+	isFixed := make(map[fixKey]bool, len(fixed))
+
+	for i := range fixed {
+		replacement, _ := fixed[i].Replacement.PackagePath(fixed[i].Package)
+		logger.Printf("info: %s:%d fixed import of %s to %s", fixed[i].FileName, fixed[i].LineNumber, fixed[i].Package, replacement)
+		isFixed[fixKey{fixed[i].FileName, fixed[i].LineNumber, fixed[i].Package}] = true
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	unfixed := NewResultStream(maxResults)
+
+	err = results.Each(func(r Result) error {
+		if isFixed[fixKey{r.FileName, r.LineNumber, r.Package}] {
+			return nil
+		}
+
+		return unfixed.Add(r)
+	})
+	if err != nil {
+		unfixed.Close()
+		return nil, err
+	}
+
+	return unfixed, nil
+}
+
+// runPinBOM pins the requires of the go.mod file of the module to the
+// versions mandated by the bill of materials before it is linted. Unlike the
+// fix-gomod command the files are not read, so only versions are changed.
+func runPinBOM(config *Configuration) error {
+	if config.BOM == "" {
+		return invalidConfig(errors.New("a bill of materials must be configured to fix go.mod"))
+	}
+
+	bom, err := ReadBOM(config.BOM)
+	if err != nil {
+		return err
+	}
+
+	filename := goModFile(config)
+
+	previous, err := FixGoModBOM(filename, bom)
+	if err != nil {
+		return err
+	}
+
+	for _, require := range previous {
+		mandated, _ := bom.Version(require.Path)
+		logger.Printf("info: %s pinned %s from %s to %s", filename, require.Path, require.Version, mandated)
+	}
+
+	return nil
+}
+
+// runFixGoModCommand drops unused blocked requires, adds mandated replaces and
+// pins versions per policy in the go.mod file, printing the diff.
+func runFixGoModCommand(config *Configuration, cwd string, noTest bool, args []string) (int, error) {
+	var dryRun bool
+
+	flags := flag.NewFlagSet("fix-gomod", flag.ContinueOnError)
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the diff without writing the go.mod file")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return 0, err
+	}
+
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	// The imports of the files tell which blocked requires are unused.
+	processor.ProcessFiles(GetFilteredFilesSymlinks(cwd, noTest, config.Symlinks, coverageArgs(flags.Args())))
+
+	filename := goModFile(config)
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	out, edits, err := processor.FixGoMod(filename, data)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, edit := range edits {
+		logger.Printf("info: %s %s", filename, edit)
+	}
+
+	err = WriteDiff(os.Stdout, filename, data, out)
+	if err != nil {
+		return 0, err
+	}
+
+	if dryRun || len(edits) == 0 {
+		return 0, nil
+	}
+
+	err = writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(out)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = os.Chmod(filename, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// goModFile returns the go.mod file of the main module of the configuration,
+// falling back to the go.mod file in the current directory.
+func goModFile(config *Configuration) string {
+	filename := configGoEnv(config).modFile()
+	if !fileExists(filename) {
+		filename = goModFilename
+	}
+
+	return filename
+}
+
+// runBaseline returns a stream of the results not recorded in the baseline
+// file, the results recorded in it are passed to onKnown. If the file does
+// not exist or the baseline is updated, the results are recorded and none are
+// returned.
+func runBaseline(filename string, results *ResultStream, update bool, maxResults int, onKnown func(Result)) (*ResultStream, error) {
+	baseline, exists, err := ReadBaseline(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || update {
+		// Only the fields identifying a finding are kept, so large result
+		// streams are not read into memory.
+		findings := []Result{}
+
+		err = results.Each(func(r Result) error {
+			findings = append(findings, Result{FileName: r.FileName, Rule: r.Rule, Module: r.Module, Package: r.Package, NonSuppressible: r.NonSuppressible})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = WriteBaseline(filename, NewBaseline(findings))
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Printf("info: recorded %d results in baseline %s", len(findings), filename)
+
+		return NewResultStream(maxResults), nil
+	}
+
+	unknown := NewResultStream(maxResults)
+
+	filter := NewBaselineFilter(baseline)
+	known := 0
+
+	err = results.Each(func(r Result) error {
+		if filter.IsKnown(r) {
+			known++
+			onKnown(r)
+
+			return nil
+		}
+
+		return unknown.Add(r)
+	})
+	if err != nil {
+		unknown.Close()
+		return nil, err
+	}
+
+	logger.Printf("info: %d results recorded in baseline %s not reported", known, filename)
+
+	if resolved := filter.Resolved(); resolved > 0 {
+		logger.Printf("info: %d results recorded in baseline %s no longer occur, update it with -update-baseline", resolved, filename)
+	}
+
+	return unknown, nil
+}
+
+// runBlame returns a stream of the results with the commit that last changed
+// their line attached. Files that cannot be blamed, such as files outside of
+// a git repository, are logged once and their results kept without blame.
+func runBlame(results *ResultStream, maxResults int) (*ResultStream, error) {
+	blamer := NewBlamer()
+	blamed := NewResultStream(maxResults)
+
+	err := results.Each(func(r Result) error {
+		blame, err := blamer.Blame(r.FileName, r.LineNumber)
+		if err != nil {
+			logger.Printf("warning: %s", err)
+		}
+
+		r.Blame = blame
+
+		return blamed.Add(r)
+	})
+	if err != nil {
+		blamed.Close()
+		return nil, err
+	}
+
+	return blamed, nil
+}
+
+// runSuppressionAges reports how long each suppressed result has existed,
+// oldest first, and records the first seen times of new suppressions.
+func runSuppressionAges(filename string, suppressed []Result) error {
+	recorded, err := ReadSuppressionAges(filename)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ages := UpdateSuppressionAges(recorded, suppressed, now)
+
+	for _, age := range ages {
+		logger.Printf("info: %s suppressed for %d days", age, age.Days(now))
+	}
+
+	return WriteSuppressionAges(filename, ages)
+}
+
+// runAudit appends the suppression uses not recorded yet to the audit file,
+// with the commit that added the directive or last changed the baseline.
+func runAudit(filename string, audit []AuditRecord) error {
+	recorded, err := ReadAuditRecords(filename)
+	if err != nil {
+		return err
+	}
+
+	records := NewAuditRecords(recorded, audit, time.Now().UTC().Truncate(time.Second))
+	blamer := NewBlamer()
+	baselineCommits := map[string]*Blame{}
+
+	for i := range records {
+		switch records[i].Kind {
+		case AuditKindExemption:
+			records[i].Blame, err = blamer.Blame(records[i].SuppressionFile, records[i].SuppressionLine)
+		case AuditKindBaseline:
+			commit, ok := baselineCommits[records[i].SuppressionFile]
+			if !ok {
+				commit, err = lastCommit(records[i].SuppressionFile)
+				baselineCommits[records[i].SuppressionFile] = commit
+			}
+
+			records[i].Blame = commit
+		}
 
-	if report == "checkstyle" {
-		err := WriteCheckstyle(reportFile, results)
 		if err != nil {
-			logger.Fatalf("error: %s", err)
+			logger.Printf("warning: %s", err)
+			err = nil
 		}
 	}
 
-	for _, r := range results {
-		fmt.Println(r.String())
+	err = AppendAuditRecords(filename, records)
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("info: recorded %d new suppressions in audit file %s", len(records), filename)
+
+	return nil
+}
+
+// runNotice writes a NOTICE file for the allowed direct module dependencies.
+func runNotice(config *Configuration, args []string) (int, error) {
+	var noticeFile string
+
+	flags := flag.NewFlagSet("notice", flag.ContinueOnError)
+	flags.StringVar(&noticeFile, "o", "", "Write the notice to the specified file instead of stdout")
+	flags.StringVar(&noticeFile, "output", "", "")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return 0, err
+	}
+
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	attributions := processor.Attributions()
+
+	if noticeFile == "" {
+		err = WriteNotice(os.Stdout, attributions)
+	} else {
+		err = writeFileAtomic(noticeFile, func(w io.Writer) error {
+			return WriteNotice(w, attributions)
+		})
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// runCoverage prints which direct module dependencies and how many imports
+// each allowed modules and domains entry covers.
+func runCoverage(config *Configuration, filenames []string) (int, error) {
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	processor.ProcessFiles(filenames)
+
+	err = WriteAllowedCoverage(os.Stdout, processor.AllowedCoverage())
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// runReach prints the chains of first-party imports from the packages of the
+// module reaching the module given as first argument.
+func runReach(config *Configuration, args []string, filteredFiles func([]string) []string) (int, error) {
+	if len(args) == 0 {
+		return 0, invalidConfig(errors.New("the reach command requires a module"))
+	}
+
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	chains := processor.ReachingPackages(filteredFiles(coverageArgs(args[1:])), args[0])
+
+	err = WriteImportChains(os.Stdout, chains)
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// runIsolate prints the packages of the module that, if wrapped, isolate the
+// module given as first argument.
+func runIsolate(config *Configuration, args []string, filteredFiles func([]string) []string) (int, error) {
+	if len(args) == 0 {
+		return 0, invalidConfig(errors.New("the isolate command requires a module"))
+	}
+
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	points := processor.IsolationPoints(filteredFiles(coverageArgs(args[1:])), args[0])
+
+	err = WriteIsolationPoints(os.Stdout, points)
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// runExplain prints the predicates of the allowed rules matching each
+// direct module dependency.
+func runExplain(config *Configuration) (int, error) {
+	processor, err := NewProcessor(config)
+	if err != nil {
+		return 0, err
+	}
+
+	err = WriteAllowedRuleExplanations(os.Stdout, processor.ExplainAllowedRules())
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// coverageArgs returns the files to compute the coverage for, defaulting to ./...
+func coverageArgs(args []string) []string {
+	if len(args) == 0 {
+		return []string{"./..."}
+	}
+
+	return args
+}
+
+// runConfig runs the config sub commands.
+func runConfig(config *Configuration, args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, invalidConfig(errors.New("unknown config command, must be one of print, schema"))
+	}
+
+	switch args[0] {
+	case "print":
+		return runConfigPrint(config, args[1:])
+	case "schema":
+		return runConfigSchema()
+	default:
+		return 0, invalidConfig(errors.New("unknown config command, must be one of print, schema"))
+	}
+}
+
+// runConfigPrint prints the effective configuration.
+func runConfigPrint(config *Configuration, args []string) (int, error) {
+	var format string
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	flags.StringVar(&format, "format", "yaml", "Print the configuration in one of the following formats: yaml, json")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved, err := config.Resolve()
+	if err != nil {
+		return 0, invalidConfig(err)
 	}
 
-	if len(results) > 0 {
-		return issuesExitCode
+	out, err := resolved.Marshal(format)
+	if err != nil {
+		return 0, err
 	}
 
-	return 0
+	fmt.Println(strings.TrimRight(string(out), "\n"))
+
+	return 0, nil
+}
+
+// runConfigSchema prints the JSON Schema of the configuration file.
+func runConfigSchema() (int, error) {
+	out, err := ConfigurationSchema()
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Println(string(out))
+
+	return 0, nil
 }
 
 // GetConfig from YAML file.
 func GetConfig(configFile string) (*Configuration, error) {
-	config := Configuration{}
-
 	home, err := homedir.Dir()
 	if err != nil {
 		return nil, fmt.Errorf(errFindingHomedir, err)
@@ -133,7 +1184,14 @@ func GetConfig(configFile string) (*Configuration, error) {
 		return nil, fmt.Errorf("%w: %s %s", errFindingConfigFile, configFile, homeDirCfgFile)
 	}
 
-	data, err := ioutil.ReadFile(cfgFile)
+	return readConfigFile(cfgFile)
+}
+
+// readConfigFile reads and parses a configuration file.
+func readConfigFile(filename string) (*Configuration, error) {
+	config := Configuration{}
+
+	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf(errReadingConfigFile, err)
 	}
@@ -143,11 +1201,20 @@ func GetConfig(configFile string) (*Configuration, error) {
 		return nil, fmt.Errorf(errParsingConfigFile, err)
 	}
 
+	config.source = parseConfigSource(filename, data)
+
 	return &config, nil
 }
 
 // GetFilteredFiles returns files based on search string arguments and filters.
 func GetFilteredFiles(cwd string, skipTests bool, args []string) []string {
+	return GetFilteredFilesSymlinks(cwd, skipTests, SymlinksFiles, args)
+}
+
+// GetFilteredFilesSymlinks returns files based on search string arguments and
+// filters, handling symlinks in directories of package patterns according to
+// the symlink policy.
+func GetFilteredFilesSymlinks(cwd string, skipTests bool, symlinks string, args []string) []string {
 	var (
 		foundFiles    = []string{}
 		filteredFiles = []string{}
@@ -157,7 +1224,7 @@ func GetFilteredFiles(cwd string, skipTests bool, args []string) []string {
 		if strings.HasSuffix(f, "/...") {
 			dir, _ := filepath.Split(f)
 
-			foundFiles = append(foundFiles, expandGoWildcard(dir)...)
+			foundFiles = append(foundFiles, walkGoFiles(dir, symlinks)...)
 
 			continue
 		}
@@ -190,7 +1257,24 @@ func GetFilteredFiles(cwd string, skipTests bool, args []string) []string {
 // showHelp text for command line.
 func showHelp() {
 	helpText := `Usage: gomodguard <file> [files...]
+       gomodguard notice [-o file]
+       gomodguard config print [-format yaml|json]
+       gomodguard config schema
+       gomodguard coverage [files...]
+       gomodguard explain
+       gomodguard reach <module> [files...]
+       gomodguard isolate <module> [files...]
+       gomodguard fix-gomod [-dry-run] [files...]
 Also supports package syntax but will use it in relative path, i.e. ./pkg/...
+A file or directory named like a command is linted instead of running the command.
+The notice command writes attributions for the allowed direct module dependencies.
+The config print command prints the effective configuration.
+The config schema command prints the JSON Schema of the configuration file.
+The coverage command prints the modules and imports each allowed entry covers.
+The explain command prints the predicates of the allowed rules matching each direct module dependency.
+The reach command prints the chains of imports from the packages of the module reaching a package of the given module.
+The isolate command prints the packages of the module that, if wrapped, isolate the given module from the rest of the module.
+The fix-gomod command drops unused blocked requires, adds mandated replaces and pins versions in go.mod and prints the diff.
 Flags:`
 	fmt.Println(helpText)
 	flag.PrintDefaults()
@@ -198,21 +1282,13 @@ Flags:`
 
 // WriteCheckstyle takes the results and writes them to a checkstyle formated file.
 func WriteCheckstyle(checkstyleFilePath string, results []Result) error {
-	check := checkstyle.New()
-
-	for i := range results {
-		file := check.EnsureFile(results[i].FileName)
-		file.AddError(checkstyle.NewError(results[i].LineNumber, 1, checkstyle.SeverityError, results[i].Reason, "gomodguard"))
-	}
-
-	checkstyleXML := fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n%s", check.String())
-
-	err := ioutil.WriteFile(checkstyleFilePath, []byte(xmlfmt.FormatXML(checkstyleXML, "", "  ")), 0644) // nolint:gosec
-	if err != nil {
-		return err
-	}
+	return writeReport(Report{Format: ReportCheckstyle, File: checkstyleFilePath}, results)
+}
 
-	return nil
+// pathExists returns true if a file or directory exists at the path.
+func pathExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
 }
 
 // fileExists returns true if the file path provided exists.
@@ -224,21 +1300,3 @@ func fileExists(filename string) bool {
 
 	return !info.IsDir()
 }
-
-// expandGoWildcard path provided.
-func expandGoWildcard(root string) []string {
-	foundFiles := []string{}
-
-	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		// Only append go foundFiles.
-		if !strings.HasSuffix(info.Name(), ".go") {
-			return nil
-		}
-
-		foundFiles = append(foundFiles, path)
-
-		return nil
-	})
-
-	return foundFiles
-}
@@ -0,0 +1,14 @@
+// Command gomodguard-vet runs gomodguard as a go vet tool so package loading,
+// build tags and caching are handled by the go command.
+//
+//	go vet -vettool=$(which gomodguard-vet) ./...
+package main
+
+import (
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(gomodguard.NewAnalyzer(nil))
+}
@@ -0,0 +1,18 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command gomodguard-wasm registers the gomodguardCheck function for
+// JavaScript hosts.
+//
+//	GOOS=js GOARCH=wasm go build -o gomodguard.wasm ./cmd/gomodguard-wasm
+package main
+
+import (
+	"github.com/ryancurrah/gomodguard"
+)
+
+func main() {
+	gomodguard.RegisterJSFunctions()
+
+	select {}
+}
@@ -1,6 +1,14 @@
 package gomodguard_test
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/ryancurrah/gomodguard"
@@ -14,3 +22,304 @@ func TestCmdRun(t *testing.T) {
 		t.Errorf("got exit code '%d' want '%d'", exitCode, wantExitCode)
 	}
 }
+
+// runCmd runs gomodguard with the arguments in a subprocess, since the
+// command line flags can only be defined once, and returns its exit code.
+func runCmd(t *testing.T, args string) int {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCmdRunArgs$")
+	cmd.Env = append(os.Environ(), "GOMODGUARD_TEST_ARGS="+args)
+	// TestMain changes to the example directory relative to the package.
+	cmd.Dir = filepath.Dir(cwd)
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return 0
+}
+
+func TestCmdRunArgs(t *testing.T) {
+	if args := os.Getenv("GOMODGUARD_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"gomodguard"}, strings.Fields(args)...)
+		os.Exit(gomodguard.Run())
+	}
+}
+
+func TestCmdRunUsageError(t *testing.T) {
+	var tests = []struct {
+		testName     string
+		args         string
+		wantExitCode int
+	}{
+		{"default", "-unknown-flag", 2},
+		{"matrix", "-exit-code-mode matrix -unknown-flag", gomodguard.ExitUsage},
+		{"malformed value", "-exit-code-mode matrix -workers x", gomodguard.ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if exitCode := runCmd(t, tt.args); exitCode != tt.wantExitCode {
+				t.Errorf("got exit code '%d' want '%d'", exitCode, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestCmdRunCommandShadowedByPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTree(t, dir, map[string]string{
+		"go.mod":           "module example.com/app\n\nrequire github.com/foo/blocked v1.0.0\n",
+		".gomodguard.yaml": "resolution: requires\nblocked:\n  modules:\n    - github.com/foo/blocked: {}\n",
+		"config/config.go": "package config\n\nimport _ \"github.com/foo/blocked\"\n",
+	})
+
+	// The config directory is linted instead of running the config command,
+	// which fails without a sub command.
+	if exitCode := runCmd(t, "-C "+dir+" config"); exitCode != 2 {
+		t.Errorf("got exit code '%d' want '%d'", exitCode, 2)
+	}
+}
+
+func TestResultsExitCode(t *testing.T) {
+	var tests = []struct {
+		testName     string
+		mode         string
+		errors       int
+		warnings     int
+		wantExitCode int
+	}{
+		{"default clean", gomodguard.ExitCodeModeDefault, 0, 0, 0},
+		{"default errors", gomodguard.ExitCodeModeDefault, 1, 1, 5},
+		{"default warnings only", gomodguard.ExitCodeModeDefault, 0, 1, 0},
+		{"matrix clean", gomodguard.ExitCodeModeMatrix, 0, 0, gomodguard.ExitClean},
+		{"matrix errors", gomodguard.ExitCodeModeMatrix, 1, 1, gomodguard.ExitErrors},
+		{"matrix warnings only", gomodguard.ExitCodeModeMatrix, 0, 1, gomodguard.ExitWarnings},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			exitCode := gomodguard.ResultsExitCode(tt.mode, 5, tt.errors, tt.warnings)
+			if exitCode != tt.wantExitCode {
+				t.Errorf("got exit code '%d' want '%d'", exitCode, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestFailureExitCode(t *testing.T) {
+	_, configErr := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{Mode: "unknown"}, "example.com/app", nil)
+	if configErr == nil {
+		t.Fatal("want error for unknown mode")
+	}
+
+	var tests = []struct {
+		testName     string
+		mode         string
+		err          error
+		wantExitCode int
+	}{
+		{"default config invalid", gomodguard.ExitCodeModeDefault, configErr, 1},
+		{"default tool failure", gomodguard.ExitCodeModeDefault, errors.New("failed"), 1},
+		{"matrix config invalid", gomodguard.ExitCodeModeMatrix, configErr, gomodguard.ExitConfigInvalid},
+		{"matrix tool failure", gomodguard.ExitCodeModeMatrix, errors.New("failed"), gomodguard.ExitToolFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			exitCode := gomodguard.FailureExitCode(tt.mode, tt.err)
+			if exitCode != tt.wantExitCode {
+				t.Errorf("got exit code '%d' want '%d'", exitCode, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestModuleConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"own/.gomodguard.yaml":     "blocked:\n  modules:\n    - github.com/foo/own: {}\n",
+		"invalid/.gomodguard.yaml": "blocked: [\n",
+	}
+
+	writeTree(t, dir, files)
+
+	root := &gomodguard.Configuration{
+		Blocked:   gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/root": {}}}},
+		GoEnv:     map[string]string{"GOPRIVATE": "example.com"},
+		GoModPath: "go.mod",
+	}
+
+	var tests = []struct {
+		testName    string
+		dir         string
+		wantBlocked []string
+		wantGoEnv   map[string]string
+		wantErr     bool
+	}{
+		{
+			"module configuration file",
+			"own",
+			[]string{"github.com/foo/own"},
+			map[string]string{"GOMOD": filepath.Join(dir, "own", "go.mod")},
+			false,
+		},
+		{
+			"root configuration fallback",
+			"fallback",
+			[]string{"github.com/foo/root"},
+			map[string]string{"GOPRIVATE": "example.com", "GOMOD": filepath.Join(dir, "fallback", "go.mod")},
+			false,
+		},
+		{
+			"invalid module configuration file",
+			"invalid",
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := gomodguard.ModuleConfiguration(root, filepath.Join(dir, tt.dir))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got '%+v' want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if blocked := got.Blocked.Modules.Get(); !reflect.DeepEqual(blocked, tt.wantBlocked) {
+				t.Errorf("got '%v' want '%v'", blocked, tt.wantBlocked)
+			}
+
+			if !reflect.DeepEqual(got.GoEnv, tt.wantGoEnv) {
+				t.Errorf("got '%v' want '%v'", got.GoEnv, tt.wantGoEnv)
+			}
+
+			if got.GoModPath != "" {
+				t.Errorf("got '%s' want the module go.mod file from the go environment", got.GoModPath)
+			}
+		})
+	}
+
+	if wantGoEnv := map[string]string{"GOPRIVATE": "example.com"}; !reflect.DeepEqual(root.GoEnv, wantGoEnv) {
+		t.Errorf("got '%v' want the root configuration unchanged '%v'", root.GoEnv, wantGoEnv)
+	}
+}
+
+func TestLintModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	requires := "require (\n\tgithub.com/foo/own v1.0.0\n\tgithub.com/foo/root v1.0.0\n)\n"
+	imports := "package app\n\nimport (\n\t_ \"github.com/foo/own\"\n\t_ \"github.com/foo/root\" //nolint:gomodguard\n)\n"
+
+	files := map[string]string{
+		"own/go.mod":               "module example.com/own\n\n" + requires,
+		"own/.gomodguard.yaml":     "blocked:\n  modules:\n    - github.com/foo/own: {}\n    - github.com/foo/root: {}\n",
+		"own/app.go":               imports,
+		"fallback/go.mod":          "module example.com/fallback\n\n" + requires,
+		"fallback/app.go":          imports,
+		"fallback/internal/lib.go": "package lib\n\nimport _ \"github.com/foo/root\"\n",
+	}
+
+	writeTree(t, dir, files)
+
+	root := &gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/root": {}}}},
+		GoEnv:   map[string]string{"GOFLAGS": "", "GONOSUMDB": ""},
+	}
+
+	var tests = []struct {
+		testName       string
+		dir            string
+		files          []string
+		wantResults    []string
+		wantSuppressed int
+	}{
+		{
+			"module configuration file",
+			"own",
+			[]string{"app.go"},
+			[]string{"app.go github.com/foo/own"},
+			1,
+		},
+		{
+			"root configuration fallback",
+			"fallback",
+			[]string{"app.go", "internal/lib.go"},
+			[]string{"lib.go github.com/foo/root"},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config, err := gomodguard.ModuleConfiguration(root, filepath.Join(dir, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filenames := []string{}
+			for _, name := range tt.files {
+				filenames = append(filenames, filepath.Join(dir, tt.dir, name))
+			}
+
+			results := gomodguard.NewResultStream(0)
+			defer results.Close()
+
+			suppressed, _, err := gomodguard.LintModule(config, func() []string { return filenames }, nil, results)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+
+			err = results.Each(func(r gomodguard.Result) error {
+				got = append(got, filepath.Base(r.FileName)+" "+r.Module)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.wantResults) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantResults)
+			}
+
+			if len(suppressed) != tt.wantSuppressed {
+				t.Errorf("got '%d' suppressed results want '%d'", len(suppressed), tt.wantSuppressed)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package gomodguard
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	errReadingCodeOwners = "unable to read code owners file %s: %w"
+	unownedCodeOwner     = "unowned"
+)
+
+// codeOwnersDirs are the directories of a repository GitHub and GitLab look
+// for a CODEOWNERS file in besides the repository root.
+var codeOwnersDirs = []string{".github", ".gitlab", "docs"}
+
+// codeOwnersRule is a CODEOWNERS line, the owners of the paths matching the
+// pattern.
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeOwners are the rules of a CODEOWNERS file. Paths are matched relative
+// to the root of the repository.
+type codeOwners struct {
+	root  string
+	rules []codeOwnersRule
+}
+
+// readCodeOwners reads a CODEOWNERS file. The repository root is the directory
+// of the file, or its parent if the file is in one of the CODEOWNERS
+// directories.
+func readCodeOwners(filename string) (*codeOwners, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingCodeOwners, filename, err)
+	}
+	defer f.Close()
+
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingCodeOwners, filename, err)
+	}
+
+	c := &codeOwners{root: filepath.Dir(absFilename)}
+
+	for _, dir := range codeOwnersDirs {
+		if filepath.Base(c.root) == dir {
+			c.root = filepath.Dir(c.root)
+			break
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[") {
+			continue
+		}
+
+		owners := []string{}
+
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+
+			owners = append(owners, owner)
+		}
+
+		c.rules = append(c.rules, codeOwnersRule{pattern: codeOwnersPattern(fields[0]), owners: owners})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(errReadingCodeOwners, filename, err)
+	}
+
+	return c, nil
+}
+
+// codeOwnersPattern returns the regular expression of a gitignore style
+// CODEOWNERS pattern. Patterns containing a slash are anchored at the root,
+// other patterns match at any depth. A pattern matches a path or anything
+// under it, a trailing slash only matches directories. Like on GitHub, a
+// last element with a single asterisk, such as docs/*, only matches the
+// files directly in the directory and not nested files.
+func codeOwnersPattern(pattern string) *regexp.Regexp {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	last := pattern[strings.LastIndex(pattern, "/")+1:]
+	filesOnly := !dirOnly && strings.Contains(last, "*") && !strings.Contains(last, "**")
+
+	var expr strings.Builder
+
+	expr.WriteString("^")
+
+	if !anchored {
+		expr.WriteString("(.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case filesOnly:
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(/.*)?$")
+	}
+
+	return regexp.MustCompile(expr.String())
+}
+
+// Owners returns the owners of the file, the owners of the last matching
+// rule like GitHub does.
+func (c *codeOwners) Owners(filename string) []string {
+	if c == nil {
+		return nil
+	}
+
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(c.root, absFilename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(rel) {
+			return c.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// CodeOwnerCounts is the number of results of each code owner.
+type CodeOwnerCounts map[string]int
+
+// CodeOwnerSummary is the number of results of a code owner.
+type CodeOwnerSummary struct {
+	Owner   string
+	Results int
+}
+
+// Add counts the result for each of its code owners, a result of a file
+// without code owners is counted as unowned.
+func (c CodeOwnerCounts) Add(result Result) {
+	if len(result.CodeOwners) == 0 {
+		c[unownedCodeOwner]++
+	}
+
+	for _, owner := range result.CodeOwners {
+		c[owner]++
+	}
+}
+
+// Summary returns the counts sorted by the number of results, most first.
+func (c CodeOwnerCounts) Summary() []CodeOwnerSummary {
+	summary := make([]CodeOwnerSummary, 0, len(c))
+	for owner, count := range c {
+		summary = append(summary, CodeOwnerSummary{Owner: owner, Results: count})
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Results != summary[j].Results {
+			return summary[i].Results > summary[j].Results
+		}
+
+		return summary[i].Owner < summary[j].Owner
+	})
+
+	return summary
+}
@@ -0,0 +1,96 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorCodeOwners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	imports := "package example\n\nimport \"github.com/foo/bar\"\n"
+
+	files := map[string]string{
+		"go.mod":                "module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n",
+		".github/CODEOWNERS":    "# Code owners\n* @org/default\n/pkg/ @org/pkg\n*.pb.go @org/api @org/pkg # generated\n/cmd/**/main.go @org/cli\n/docs/* @org/docs\n",
+		"main.go":               imports,
+		"pkg/api/api.go":        imports,
+		"pkg/api/api.pb.go":     imports,
+		"cmd/tool/main.go":      imports,
+		"cmd/tool/helpers.go":   imports,
+		"docs/docs.go":          imports,
+		"docs/nested/nested.go": imports,
+		"pkg/broken.go":         "package broken\n\nimport (\n",
+	}
+
+	writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Blocked:    gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+		CodeOwners: filepath.Join(dir, ".github", "CODEOWNERS"),
+		GoEnv:      map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName       string
+		file           string
+		wantCodeOwners []string
+	}{
+		{"default owner", "main.go", []string{"@org/default"}},
+		{"directory owner", "pkg/api/api.go", []string{"@org/pkg"}},
+		{"last matching rule", "pkg/api/api.pb.go", []string{"@org/api", "@org/pkg"}},
+		{"double star", "cmd/tool/main.go", []string{"@org/cli"}},
+		{"double star other file", "cmd/tool/helpers.go", []string{"@org/default"}},
+		{"single star", "docs/docs.go", []string{"@org/docs"}},
+		{"single star nested file", "docs/nested/nested.go", []string{"@org/default"}},
+		{"syntax error", "pkg/broken.go", []string{"@org/pkg"}},
+	}
+
+	codeOwnerCounts := gomodguard.CodeOwnerCounts{}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor.Result = []gomodguard.Result{}
+
+			results := processor.ProcessFiles([]string{filepath.Join(dir, tt.file)})
+			if len(results) != 1 {
+				t.Fatalf("got '%+v' want 1 result", results)
+			}
+
+			if !reflect.DeepEqual(results[0].CodeOwners, tt.wantCodeOwners) {
+				t.Errorf("got '%+v' want '%+v'", results[0].CodeOwners, tt.wantCodeOwners)
+			}
+
+			if want := "(code owners: " + strings.Join(tt.wantCodeOwners, " ") + ")"; !strings.HasSuffix(results[0].String(), want) {
+				t.Errorf("got '%s' want it to end with '%s'", results[0].String(), want)
+			}
+
+			codeOwnerCounts.Add(results[0])
+		})
+	}
+
+	wantSummary := []gomodguard.CodeOwnerSummary{
+		{Owner: "@org/default", Results: 3},
+		{Owner: "@org/pkg", Results: 3},
+		{Owner: "@org/api", Results: 1},
+		{Owner: "@org/cli", Results: 1},
+		{Owner: "@org/docs", Results: 1},
+	}
+
+	if summary := codeOwnerCounts.Summary(); !reflect.DeepEqual(summary, wantSummary) {
+		t.Errorf("got '%+v' want '%+v'", summary, wantSummary)
+	}
+}
@@ -0,0 +1,139 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const errUnknownConfigFormat = "unknown config format %s, must be one of yaml, json"
+
+// Resolve returns the effective configuration. Blocked modules from the
+// modules URL are merged into the blocked modules, the messages file is merged
+// into the messages and module names are trimmed of surrounding whitespace.
+// The modules URL is fetched at most once every few minutes and skipped
+// offline, an error fetching it is returned, and not cached, so central
+// enforcement is not silently disabled.
+// The configuration itself is not modified.
+func (c *Configuration) Resolve() (*Configuration, error) {
+	resolved, warnings, err := c.resolve(readGoEnv(c.GoEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, warning := range warnings {
+		logger.Printf("warning: %s", warning)
+	}
+
+	return resolved, nil
+}
+
+// resolve returns the effective configuration in the go environment and the
+// warnings of the skipped modules URL.
+func (c *Configuration) resolve(env goEnv) (*Configuration, []string, error) {
+	resolved := *c
+	warnings := []string{}
+
+	resolved.Allowed.Modules = trimAll(c.Allowed.Modules)
+	resolved.Allowed.Domains = trimAll(c.Allowed.Domains)
+	resolved.Allowed.Checksums = trimAll(c.Allowed.Checksums)
+	resolved.Allowed.Rules = make([]AllowedRule, 0, len(c.Allowed.Rules))
+	resolved.Blocked.Modules = make(BlockedModules, 0, len(c.Blocked.Modules))
+	resolved.Blocked.Versions = make(BlockedVersions, 0, len(c.Blocked.Versions))
+
+	for _, rule := range c.Allowed.Rules {
+		rule.Path = strings.TrimSpace(rule.Path)
+		rule.Licenses = trimAll(rule.Licenses)
+		resolved.Allowed.Rules = append(resolved.Allowed.Rules, rule)
+	}
+
+	for n := range c.Blocked.Modules {
+		for moduleName, blockedModule := range c.Blocked.Modules[n] {
+			blockedModule.Recommendations = trimAll(blockedModule.Recommendations)
+			resolved.Blocked.Modules = append(resolved.Blocked.Modules, map[string]BlockedModule{strings.TrimSpace(moduleName): blockedModule})
+		}
+	}
+
+	resolved.Generated.Files = trimAll(c.Generated.Files)
+	resolved.Generated.Modules = make(BlockedModules, 0, len(c.Generated.Modules))
+
+	for n := range c.Generated.Modules {
+		for moduleName, blockedModule := range c.Generated.Modules[n] {
+			blockedModule.Recommendations = trimAll(blockedModule.Recommendations)
+			resolved.Generated.Modules = append(resolved.Generated.Modules, map[string]BlockedModule{strings.TrimSpace(moduleName): blockedModule})
+		}
+	}
+
+	for n := range c.Blocked.Versions {
+		for moduleName, blockedVersion := range c.Blocked.Versions[n] {
+			resolved.Blocked.Versions = append(resolved.Blocked.Versions, map[string]BlockedVersion{strings.TrimSpace(moduleName): blockedVersion})
+		}
+	}
+
+	if c.Blocked.ModulesURL != "" {
+		if c.Offline || env.isOffline() {
+			warnings = append(warnings, fmt.Sprintf(warnOfflineRemoteModules, c.Blocked.ModulesURL))
+		} else {
+			remoteModules, err := fetchBlockedModulesOnce(c.Blocked.ModulesURL)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			resolved.Blocked.Modules = resolved.Blocked.Modules.Merge(remoteModules)
+		}
+	}
+
+	if c.MessagesFile != "" {
+		messages, err := readMessagesFile(c.MessagesFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for rule, message := range c.Messages {
+			messages[rule] = message
+		}
+
+		resolved.Messages = messages
+	}
+
+	return &resolved, warnings, nil
+}
+
+// Marshal returns the configuration in the yaml or json format.
+func (c *Configuration) Marshal(format string) ([]byte, error) {
+	switch strings.TrimSpace(strings.ToLower(format)) {
+	case "", "yaml":
+		return yaml.Marshal(c)
+	case "json":
+		return json.MarshalIndent(c, "", "  ")
+	default:
+		return nil, fmt.Errorf(errUnknownConfigFormat, format)
+	}
+}
+
+// trimAll returns the values trimmed of surrounding whitespace.
+func trimAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		trimmed = append(trimmed, strings.TrimSpace(value))
+	}
+
+	return trimmed
+}
+
+// trimAllKeys returns the map with its keys and values trimmed of
+// surrounding whitespace.
+func trimAllKeys(values map[string]string) map[string]string {
+	trimmed := make(map[string]string, len(values))
+	for key, value := range values {
+		trimmed[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return trimmed
+}
@@ -0,0 +1,128 @@
+package gomodguard_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestConfigurationResolve(t *testing.T) {
+	unresolved := gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{Modules: []string{" github.com/someallowed/module "}},
+		Blocked: gomodguard.Blocked{
+			Modules: gomodguard.BlockedModules{{" github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module "}}}},
+		},
+	}
+
+	resolved, err := unresolved.Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantAllowedModules := []string{"github.com/someallowed/module"}
+	if !reflect.DeepEqual(resolved.Allowed.Modules, wantAllowedModules) {
+		t.Errorf("got '%+v' want '%+v'", resolved.Allowed.Modules, wantAllowedModules)
+	}
+
+	wantBlockedModules := gomodguard.BlockedModules{{"github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module"}}}}
+	if !reflect.DeepEqual(resolved.Blocked.Modules, wantBlockedModules) {
+		t.Errorf("got '%+v' want '%+v'", resolved.Blocked.Modules, wantBlockedModules)
+	}
+
+	if unresolved.Allowed.Modules[0] != " github.com/someallowed/module " {
+		t.Error("resolve should not modify the configuration")
+	}
+}
+
+func TestConfigurationMarshal(t *testing.T) {
+	var tests = []struct {
+		testName     string
+		format       string
+		wantErr      bool
+		wantContains string
+	}{
+		{"yaml", "yaml", false, "local_replace_directives: true"},
+		{"json", "json", false, `"local_replace_directives": true`},
+		{"unknown format", "toml", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			out, err := config.Marshal(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if !strings.Contains(string(out), tt.wantContains) {
+				t.Errorf("got '%s' want it to contain '%s'", out, tt.wantContains)
+			}
+		})
+	}
+
+	out, err := config.Marshal("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unmarshalled gomodguard.Configuration
+
+	err = json.Unmarshal(out, &unmarshalled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(unmarshalled.Blocked.Modules, config.Blocked.Modules) {
+		t.Errorf("got '%+v' want '%+v'", unmarshalled.Blocked.Modules, config.Blocked.Modules)
+	}
+}
+
+func TestConfigurationSchema(t *testing.T) {
+	out, err := gomodguard.ConfigurationSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string]interface{}{}
+
+	err = json.Unmarshal(out, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName string
+		path     []string
+		wantType string
+	}{
+		{"allowed modules", []string{"allowed", "modules"}, "array"},
+		{"blocked scorecard threshold", []string{"blocked", "scorecard", "threshold"}, "number"},
+		{"blocked deps.dev max dependencies", []string{"blocked", "deps_dev", "max_dependencies"}, "integer"},
+		{"blocked local replace directives", []string{"blocked", "local_replace_directives"}, "boolean"},
+		{"messages", []string{"messages"}, "object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			property := schema
+
+			for _, name := range tt.path {
+				properties, ok := property["properties"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("got no properties for '%s'", name)
+				}
+
+				property, ok = properties[name].(map[string]interface{})
+				if !ok {
+					t.Fatalf("got no property '%s'", name)
+				}
+			}
+
+			if property["type"] != tt.wantType {
+				t.Errorf("got type '%v' want '%s'", property["type"], tt.wantType)
+			}
+		})
+	}
+}
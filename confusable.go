@@ -0,0 +1,97 @@
+package gomodguard
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+var blockReasonConfusableImport = "import of package `%%s` is blocked because it contains characters confusable with the allowed module `%s`."
+
+// confusables maps characters that look like ASCII characters of module
+// paths to the character they resemble. Characters mapped to -1 are
+// invisible and dropped.
+var confusables = map[rune]rune{
+	// Cyrillic.
+	'а': 'a', 'в': 'b', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k', 'м': 'm', 'о': 'o',
+	'р': 'p', 'с': 'c', 'ѕ': 's', 'т': 't', 'у': 'y', 'х': 'x', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l',
+	'А': 'a', 'В': 'b', 'Е': 'e', 'І': 'i', 'Ј': 'j', 'К': 'k', 'М': 'm', 'Н': 'h', 'О': 'o', 'Р': 'p',
+	'С': 'c', 'Ѕ': 's', 'Т': 't', 'Х': 'x', 'Ү': 'y',
+	// Greek.
+	'α': 'a', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	'Α': 'a', 'Β': 'b', 'Ε': 'e', 'Ζ': 'z', 'Η': 'h', 'Ι': 'i', 'Κ': 'k', 'Μ': 'm', 'Ν': 'n', 'Ο': 'o',
+	'Ρ': 'p', 'Τ': 't', 'Υ': 'y', 'Χ': 'x',
+	// Latin.
+	'ı': 'i', 'ɡ': 'g', 'ℓ': 'l', 'ⅰ': 'i', 'ⅼ': 'l',
+	// Punctuation.
+	'‐': '-', '‑': '-', '‒': '-', '–': '-', '−': '-', '․': '.', '∕': '/', '⁄': '/',
+	// Invisible.
+	'\u200b': -1, '\u200c': -1, '\u200d': -1, '\u2060': -1, '\ufeff': -1,
+}
+
+// skeleton returns the lowercase path with confusable characters replaced
+// by the ASCII characters they resemble, so paths that look the same have
+// the same skeleton.
+func skeleton(path string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '\uff01' && r <= '\uff5e' {
+			r -= '\uff01' - '!' // Fullwidth forms.
+		}
+
+		if c, ok := confusables[r]; ok {
+			r = c
+		}
+
+		return unicode.ToLower(r)
+	}, strings.TrimSpace(path))
+}
+
+// confusableBlockReason returns a block reason if the imported package is
+// not allowed but looks like an allowed module or domain, because it
+// contains confusable characters or differs only in case.
+func (p *Processor) confusableBlockReason(importedPkg string) (blockReason, bool) {
+	allowed := make([]string, 0, len(p.Config.Allowed.Modules)+len(p.Config.Allowed.Domains)+len(p.Config.Allowed.Rules))
+
+	for _, module := range p.Config.Allowed.Modules {
+		if match.GlobModule(module, importedPkg) {
+			return blockReason{}, false
+		}
+
+		allowed = append(allowed, module)
+	}
+
+	for _, domain := range p.Config.Allowed.Domains {
+		if match.GlobDomain(domain, importedPkg) {
+			return blockReason{}, false
+		}
+
+		allowed = append(allowed, domain)
+	}
+
+	for i := range p.Config.Allowed.Rules {
+		if match.Domain(p.Config.Allowed.Rules[i].Path, importedPkg) {
+			return blockReason{}, false
+		}
+
+		allowed = append(allowed, p.Config.Allowed.Rules[i].Path)
+	}
+
+	importSkeleton := skeleton(importedPkg)
+
+	for _, path := range allowed {
+		if strings.TrimSpace(path) == "" || match.IsGlob(path) || !match.Prefix(skeleton(path), importSkeleton) {
+			continue
+		}
+
+		return blockReason{
+			rule:     RuleConfusableImport,
+			reason:   fmt.Sprintf(blockReasonConfusableImport, escapeReason(strings.TrimSpace(path))),
+			severity: SeverityError,
+			data:     MessageData{Module: strings.TrimSpace(path)},
+		}, true
+	}
+
+	return blockReason{}, false
+}
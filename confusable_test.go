@@ -0,0 +1,87 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestConfusableImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	imports := []string{
+		"github.com/sirupsen/logrus",
+		"github.com/Sirupsen/logrus/hooks",
+		"github.com/s\u0456rupsen/logrus",
+		"github.com/sirupsen/logrus\u200b",
+		"\uff47olang.org/x/mod",
+		"golang.org/x/mod",
+		"github.com/foo/bar",
+	}
+
+	src := "package example\n\nimport (\n"
+	for _, path := range imports {
+		src += "\t_ \"" + path + "\"\n"
+	}
+
+	err = ioutil.WriteFile(filename, []byte(src+")\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName     string
+		blocked      gomodguard.Blocked
+		wantPackages []string
+	}{
+		{
+			"disabled",
+			gomodguard.Blocked{},
+			[]string{},
+		},
+		{
+			"enabled",
+			gomodguard.Blocked{ConfusableImports: true},
+			[]string{imports[1], imports[2], imports[3], imports[4]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := gomodguard.Configuration{
+				Allowed: gomodguard.Allowed{Modules: []string{"github.com/sirupsen/logrus"}, Domains: []string{"golang.org"}},
+				Blocked: tt.blocked,
+			}
+
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			if len(results) != len(tt.wantPackages) {
+				t.Fatalf("got '%v' want '%v'", results, tt.wantPackages)
+			}
+
+			for i := range results {
+				if results[i].Package != tt.wantPackages[i] || results[i].Rule != gomodguard.RuleConfusableImport {
+					t.Errorf("got '%v' want '%v'", results[i], tt.wantPackages[i])
+				}
+			}
+		})
+	}
+}
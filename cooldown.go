@@ -0,0 +1,206 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ryancurrah/gomodguard/match"
+	"golang.org/x/mod/module"
+)
+
+const (
+	defaultProxyURL        = "https://proxy.golang.org"
+	proxyTimeout           = 10 * time.Second
+	errFetchingPublishTime = "unable to fetch publish time of %s@%s: %w"
+	errProxyStatus         = "unexpected proxy status code %d for %s@%s"
+)
+
+var (
+	blockReasonCooldown           = "import of package `%%s` is blocked because version `%s` of the module was published %d days ago, versions must be at least %d days old."
+	blockReasonUnknownPublishTime = "import of package `%%s` is blocked because the publish time of version `%s` of the module could not be determined, versions must be at least %d days old."
+)
+
+// Cooldown blocks direct module versions published less than a minimum
+// number of days ago, the window in which compromised releases are usually
+// detected and retracted. Days applies to all modules and Modules overrides
+// it per module path or domain, the longest match wins and 0 exempts a
+// module. Publish times are read from the module proxy, or the module cache
+// when offline or for private modules, pseudo-versions included since the
+// commit time they carry can be backdated. Versions whose publish time cannot
+// be determined are allowed with a warning, or blocked if
+// UnknownPublishTime is set.
+type Cooldown struct {
+	Days               int            `yaml:"days" json:"days"`
+	Modules            map[string]int `yaml:"modules" json:"modules"`
+	ProxyURL           string         `yaml:"proxy_url" json:"proxy_url"`
+	UnknownPublishTime bool           `yaml:"unknown_publish_time" json:"unknown_publish_time"`
+	transport          http.RoundTripper
+}
+
+// IsEnabled returns true if a minimum age is configured.
+func (c *Cooldown) IsEnabled() bool {
+	if c.Days > 0 {
+		return true
+	}
+
+	for _, days := range c.Modules {
+		if days > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MinimumDays returns the minimum age in days of versions of the module.
+func (c *Cooldown) MinimumDays(moduleName string) int {
+	days, longest := c.Days, ""
+
+	for path, pathDays := range c.Modules {
+		if match.Domain(path, moduleName) && len(strings.TrimSpace(path)) > len(longest) {
+			days, longest = pathDays, strings.TrimSpace(path)
+		}
+	}
+
+	return days
+}
+
+// PublishTime returns when the module version was published according to the
+// module proxy. The configured proxy is used, otherwise the first proxy of
+// GOPROXY or proxy.golang.org.
+func (c *Cooldown) PublishTime(moduleName, moduleVersion, goProxy string) (time.Time, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(errFetchingPublishTime, moduleName, moduleVersion, err)
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(errFetchingPublishTime, moduleName, moduleVersion, err)
+	}
+
+	proxyURL := c.ProxyURL
+	if proxyURL == "" {
+		proxyURL = goProxyURL(goProxy)
+	}
+
+	client := &http.Client{Timeout: proxyTimeout, Transport: c.transport}
+
+	resp, err := client.Get(fmt.Sprintf("%s/%s/@v/%s.info", strings.TrimRight(proxyURL, "/"), escapedPath, escapedVersion))
+	if err != nil {
+		return time.Time{}, fmt.Errorf(errFetchingPublishTime, moduleName, moduleVersion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf(errProxyStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	info := struct {
+		Time time.Time
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(errFetchingPublishTime, moduleName, moduleVersion, err)
+	}
+
+	return info.Time, nil
+}
+
+// goProxyURL returns the first proxy url of GOPROXY, or proxy.golang.org if
+// it has none.
+func goProxyURL(goProxy string) string {
+	for _, proxy := range strings.FieldsFunc(goProxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		proxy = strings.TrimSpace(proxy)
+		if strings.HasPrefix(proxy, "https://") || strings.HasPrefix(proxy, "http://") {
+			return proxy
+		}
+	}
+
+	return defaultProxyURL
+}
+
+// PublishTime returns when the module version was published according to the
+// info file the proxy served when it was downloaded to the module cache.
+func (c modCache) PublishTime(moduleName, moduleVersion string) (time.Time, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, "cache", "download", filepath.FromSlash(escapedPath), "@v", escapedVersion+".info"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s@%s", errModCacheNotFound, moduleName, moduleVersion)
+	}
+
+	info := struct {
+		Time time.Time
+	}{}
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.Time, nil
+}
+
+// cooldownBlockReason returns a block reason if the module version was
+// published less than the minimum number of days ago.
+func (p *Processor) cooldownBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion string, now time.Time) (blockReason, bool) {
+	cooldown := &p.network().cooldown
+
+	minimumDays := cooldown.MinimumDays(canonicalModuleName)
+	if minimumDays <= 0 {
+		return blockReason{}, false
+	}
+
+	var (
+		published time.Time
+		err       error
+	)
+
+	if p.isOffline() || p.goEnv.isPrivateModule(lintedModuleName) {
+		published, err = p.modCache().PublishTime(lintedModuleName, lintedModuleVersion)
+	} else {
+		published, err = cooldown.PublishTime(lintedModuleName, lintedModuleVersion, p.goEnv["GOPROXY"])
+	}
+
+	if err != nil {
+		p.warnf("%s", err)
+
+		if !cooldown.UnknownPublishTime {
+			return blockReason{}, false
+		}
+
+		return blockReason{
+			rule:     RuleCooldown,
+			reason:   fmt.Sprintf(blockReasonUnknownPublishTime, escapeReason(lintedModuleVersion), minimumDays),
+			severity: SeverityError,
+			data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+		}, true
+	}
+
+	age := int(now.Sub(published).Hours() / 24)
+	if age >= minimumDays {
+		return blockReason{}, false
+	}
+
+	return blockReason{
+		rule:     RuleCooldown,
+		reason:   fmt.Sprintf(blockReasonCooldown, escapeReason(lintedModuleVersion), age, minimumDays),
+		severity: SeverityError,
+		data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+	}, true
+}
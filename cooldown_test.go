@@ -0,0 +1,150 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestCooldown(t *testing.T) {
+	now := time.Now().UTC()
+
+	published := map[string]time.Time{
+		"/github.com/example/fresh/@v/v1.1.0.info":  now.AddDate(0, 0, -2),
+		"/github.com/example/old/@v/v1.0.0.info":    now.AddDate(-1, 0, 0),
+		"/github.com/example/exempt/@v/v1.1.0.info": now.AddDate(0, 0, -2),
+		"/github.com/example/slow/@v/v1.0.0.info":   now.AddDate(0, 0, -20),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := published[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprintf(w, `{"Version":"v1.0.0","Time":%q}`, t.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The commit time of the pseudo-version is backdated, the proxy knows
+	// it was published a day ago.
+	pseudoVersion := "v0.0.0-" + now.AddDate(-1, 0, 0).Format("20060102150405") + "-0123456789ab"
+	published["/github.com/example/pseudo/@v/"+pseudoVersion+".info"] = now.AddDate(0, 0, -1)
+
+	modules := map[string]string{
+		"github.com/example/fresh":   "v1.1.0",
+		"github.com/example/old":     "v1.0.0",
+		"github.com/example/exempt":  "v1.1.0",
+		"github.com/example/slow":    "v1.0.0",
+		"github.com/example/pseudo":  pseudoVersion,
+		"github.com/example/unknown": "v1.0.0",
+	}
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n"
+	src := "package example\n\nimport (\n"
+
+	for module, version := range modules {
+		goMod += "\t" + module + " " + version + "\n"
+		src += "\t_ \"" + module + "\"\n"
+	}
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod+")\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte(src+")\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		cooldown    gomodguard.Cooldown
+		wantModules []string
+	}{
+		{
+			"disabled",
+			gomodguard.Cooldown{},
+			[]string{},
+		},
+		{
+			"thirty days",
+			gomodguard.Cooldown{
+				Days:     30,
+				Modules:  map[string]int{"github.com/example/exempt": 0},
+				ProxyURL: server.URL,
+			},
+			[]string{"github.com/example/fresh", "github.com/example/pseudo", "github.com/example/slow"},
+		},
+		{
+			"per module",
+			gomodguard.Cooldown{
+				Modules:  map[string]int{"github.com/example": 7, "github.com/example/slow": 30},
+				ProxyURL: server.URL,
+			},
+			[]string{"github.com/example/exempt", "github.com/example/fresh", "github.com/example/pseudo", "github.com/example/slow"},
+		},
+		{
+			"unknown publish time",
+			gomodguard.Cooldown{
+				Days:               30,
+				Modules:            map[string]int{"github.com/example/exempt": 0},
+				ProxyURL:           server.URL,
+				UnknownPublishTime: true,
+			},
+			[]string{"github.com/example/fresh", "github.com/example/pseudo", "github.com/example/slow", "github.com/example/unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := gomodguard.Configuration{
+				Mode:    gomodguard.ModeBlock,
+				Blocked: gomodguard.Blocked{Cooldown: tt.cooldown},
+			}
+
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			got := []string{}
+			for i := range results {
+				if results[i].Rule == gomodguard.RuleCooldown {
+					got = append(got, results[i].Module)
+				}
+			}
+
+			sort.Strings(got)
+
+			if len(got) != len(tt.wantModules) {
+				t.Fatalf("got '%v' want '%v'", got, tt.wantModules)
+			}
+
+			for i := range got {
+				if got[i] != tt.wantModules[i] {
+					t.Errorf("got '%v' want '%v'", got, tt.wantModules)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+// Kinds of allowed list entries.
+const (
+	AllowedKindModule = "module"
+	AllowedKindDomain = "domain"
+)
+
+// AllowedCoverage is the usage of an allowed modules or domains entry.
+type AllowedCoverage struct {
+	Entry   string
+	Kind    string
+	Modules []string
+	Imports int
+}
+
+// AllowedCoverage returns, for each allowed modules and domains entry, the
+// direct module dependencies it matched and the number of imports of those
+// modules in the processed files. Entries with the most imports come first.
+func (p *Processor) AllowedCoverage() []AllowedCoverage {
+	coverage := []AllowedCoverage{}
+
+	for _, allowedModule := range p.Config.Allowed.Modules {
+		coverage = append(coverage, p.allowedCoverage(allowedModule, AllowedKindModule, match.Glob))
+	}
+
+	for _, allowedDomain := range p.Config.Allowed.Domains {
+		coverage = append(coverage, p.allowedCoverage(allowedDomain, AllowedKindDomain, match.GlobDomain))
+	}
+
+	sort.SliceStable(coverage, func(i, j int) bool {
+		if coverage[i].Imports != coverage[j].Imports {
+			return coverage[i].Imports > coverage[j].Imports
+		}
+
+		return coverage[i].Entry < coverage[j].Entry
+	})
+
+	return coverage
+}
+
+// allowedCoverage returns the usage of a single allowed list entry.
+func (p *Processor) allowedCoverage(entry, kind string, matches func(string, string) bool) AllowedCoverage {
+	coverage := AllowedCoverage{Entry: strings.TrimSpace(entry), Kind: kind, Modules: []string{}}
+
+	if p.Modfile == nil {
+		return coverage
+	}
+
+	for _, require := range p.Modfile.Require {
+		if require == nil || require.Indirect || !matches(entry, require.Mod.Path) {
+			continue
+		}
+
+		moduleName := strings.TrimSpace(require.Mod.Path)
+
+		coverage.Modules = append(coverage.Modules, moduleName)
+		coverage.Imports += p.importCounts[moduleName]
+	}
+
+	sort.Strings(coverage.Modules)
+
+	return coverage
+}
+
+// countImport counts an import of the required module providing the package.
+func (p *Processor) countImport(packageName string) {
+	requiredModule, ok := p.resolveModule(packageName)
+	if !ok {
+		return
+	}
+
+	if p.importCounts == nil {
+		p.importCounts = map[string]int{}
+	}
+
+	p.importCounts[strings.TrimSpace(requiredModule.Path)]++
+}
+
+// WriteAllowedCoverage writes the allowed list coverage as a table.
+func WriteAllowedCoverage(w io.Writer, coverage []AllowedCoverage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, err := fmt.Fprintln(tw, "ENTRY\tKIND\tIMPORTS\tMODULES")
+	if err != nil {
+		return err
+	}
+
+	for i := range coverage {
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", coverage[i].Entry, coverage[i].Kind, coverage[i].Imports, strings.Join(coverage[i].Modules, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,45 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorAllowedCoverage(t *testing.T) {
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor.ProcessFiles(gomodguard.GetFilteredFiles(cwd, false, []string{"./..."}))
+
+	coverage := processor.AllowedCoverage()
+	if len(coverage) != len(config.Allowed.Modules)+len(config.Allowed.Domains) {
+		t.Fatalf("got '%d' entries want '%d'", len(coverage), len(config.Allowed.Modules)+len(config.Allowed.Domains))
+	}
+
+	want := gomodguard.AllowedCoverage{
+		Entry:   "github.com/ryancurrah/gomodguard",
+		Kind:    gomodguard.AllowedKindModule,
+		Modules: []string{"github.com/ryancurrah/gomodguard"},
+		Imports: 1,
+	}
+
+	if !reflect.DeepEqual(coverage[0], want) {
+		t.Errorf("got '%+v' want '%+v'", coverage[0], want)
+	}
+
+	buf := &bytes.Buffer{}
+
+	err = gomodguard.WriteAllowedCoverage(buf, coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("ENTRY")) {
+		t.Errorf("got '%s' want a table header", buf.String())
+	}
+}
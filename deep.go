@@ -0,0 +1,162 @@
+package gomodguard
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+const (
+	errFetchingGoMod = "unable to fetch go.mod of %s@%s: %w"
+	errParsingDepMod = "unable to parse go.mod of %s@%s: %s"
+)
+
+var (
+	blockReasonRequiresBlockedModule  = "import of package `%%s` is blocked because the module requires `%s`, which is in the blocked modules list. %s"
+	blockReasonRequiresBlockedVersion = "import of package `%%s` is blocked because the module requires `%s %s`, which is a blocked version. %s"
+)
+
+// requirementBlockReasons returns a block reason for each requirement of the
+// go.mod file of the module version that is blocked or of a blocked version.
+// The go.mod file is read from the module cache when offline or for private
+// modules, otherwise from the module proxy.
+func (p *Processor) requirementBlockReasons(canonicalModuleName, lintedModuleName, lintedModuleVersion string, now time.Time) []blockReason {
+	var (
+		data []byte
+		err  error
+	)
+
+	if p.isOffline() || p.goEnv.isPrivateModule(lintedModuleName) {
+		data, err = p.modCache().GoMod(lintedModuleName, lintedModuleVersion)
+	} else {
+		data, err = proxyGoMod(lintedModuleName, lintedModuleVersion, goProxyURL(p.goEnv["GOPROXY"]), p.network().transport)
+	}
+
+	if err != nil {
+		if !errors.Is(err, errModCacheNotFound) {
+			p.warnf("%s", err)
+		}
+
+		return nil
+	}
+
+	depModFile, err := modfile.ParseLax(goModFilename, data, nil)
+	if err != nil {
+		p.warnf(errParsingDepMod, lintedModuleName, lintedModuleVersion, err)
+		return nil
+	}
+
+	blockReasons := []blockReason{}
+
+	for _, require := range depModFile.Require {
+		if require == nil {
+			continue
+		}
+
+		requiredName := strings.TrimSpace(require.Mod.Path)
+		requiredVersion := strings.TrimSpace(require.Mod.Version)
+		canonicalRequiredName := p.Config.CanonicalModulePath(requiredName)
+
+		blockModuleName, blockModuleReason := p.lookupBlockedModule(canonicalRequiredName)
+		blockModuleSource := []string{"blocked", "modules", blockModuleName}
+
+		if blockModuleReason == nil {
+			blockModuleName, blockModuleReason = p.blockedRegexModule(canonicalRequiredName)
+			blockModuleSource = []string{"blocked", "regex", blockModuleName}
+		}
+
+		// Recommended modules may require the module they replace.
+		if blockModuleReason != nil && !blockModuleReason.IsCurrentModuleARecommendation(canonicalModuleName) {
+			blockReasons = append(blockReasons, blockReason{
+				rule:     RuleBlockedRequirement,
+				reason:   fmt.Sprintf(blockReasonRequiresBlockedModule, escapeReason(requiredName), escapeReason(blockModuleReason.Message())),
+				severity: severity(blockModuleReason.IsEnforced(now)),
+				data: MessageData{
+					Module:          lintedModuleName,
+					Version:         lintedModuleVersion,
+					Reason:          blockModuleReason.Reason,
+					Recommendations: blockModuleReason.Recommendations,
+					Owner:           blockModuleReason.Owner,
+					Docs:            blockModuleReason.Docs,
+					PolicyURL:       blockModuleReason.PolicyURL,
+				},
+				source: blockModuleSource,
+			})
+
+			continue
+		}
+
+		blockVersionName, blockVersionReason := p.lookupBlockedVersion(canonicalRequiredName)
+		if blockVersionReason != nil && blockVersionReason.IsLintedModuleVersionBlocked(requiredVersion) {
+			blockReasons = append(blockReasons, blockReason{
+				rule:     RuleBlockedRequirement,
+				reason:   fmt.Sprintf(blockReasonRequiresBlockedVersion, escapeReason(requiredName), escapeReason(requiredVersion), escapeReason(blockVersionReason.Message(requiredVersion))),
+				severity: severity(blockVersionReason.IsEnforced(now)),
+				data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion, Reason: blockVersionReason.Reason, Owner: blockVersionReason.Owner, PolicyURL: blockVersionReason.PolicyURL},
+				source:   []string{"blocked", "versions", blockVersionName},
+			})
+		}
+	}
+
+	return blockReasons
+}
+
+// proxyGoMod returns the go.mod file of the module version from the module
+// proxy.
+func proxyGoMod(moduleName, moduleVersion, proxyURL string, transport http.RoundTripper) ([]byte, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingGoMod, moduleName, moduleVersion, err)
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingGoMod, moduleName, moduleVersion, err)
+	}
+
+	client := &http.Client{Timeout: proxyTimeout, Transport: transport}
+
+	resp, err := client.Get(fmt.Sprintf("%s/%s/@v/%s.mod", strings.TrimRight(proxyURL, "/"), escapedPath, escapedVersion))
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingGoMod, moduleName, moduleVersion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(errProxyStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingGoMod, moduleName, moduleVersion, err)
+	}
+
+	return data, nil
+}
+
+// GoMod returns the go.mod file of the module version in the module cache.
+func (c modCache) GoMod(moduleName, moduleVersion string) ([]byte, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, "cache", "download", filepath.FromSlash(escapedPath), "@v", escapedVersion+".mod"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s@%s", errModCacheNotFound, moduleName, moduleVersion)
+	}
+
+	return data, nil
+}
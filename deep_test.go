@@ -0,0 +1,125 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestDeep(t *testing.T) {
+	goMods := map[string]string{
+		"/github.com/example/clean/@v/v1.0.0.mod": "module github.com/example/clean\n\nrequire github.com/example/fine v1.0.0\n",
+		"/github.com/example/dirty/@v/v1.0.0.mod": "module github.com/example/dirty\n\nrequire (\n\tgithub.com/bad/mod v1.0.0\n\tgithub.com/old/ver v1.1.0 // indirect\n)\n",
+		"/github.com/example/reco/@v/v1.0.0.mod":  "module github.com/example/reco\n\nrequire github.com/bad/mod v1.0.0\n",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		goMod, ok := goMods[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(goMod))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Only the go.mod of the dirty module is in the module cache, with a
+	// single blocked requirement.
+	cachedGoMod := filepath.Join(dir, "cache", "download", "github.com", "example", "dirty", "@v", "v1.0.0.mod")
+
+	err = os.MkdirAll(filepath.Dir(cachedGoMod), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(cachedGoMod, []byte("module github.com/example/dirty\n\nrequire github.com/bad/mod v1.0.0\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goMod := []byte(`module github.com/ryancurrah/example
+
+require (
+	github.com/bad/mod v1.0.0
+	github.com/example/clean v1.0.0
+	github.com/example/dirty v1.0.0
+	github.com/example/reco v1.0.0
+)
+`)
+
+	blocked := gomodguard.Blocked{
+		Modules:  gomodguard.BlockedModules{{"github.com/bad/mod": gomodguard.BlockedModule{Recommendations: []string{"github.com/example/reco"}, Reason: "unmaintained"}}},
+		Versions: gomodguard.BlockedVersions{{"github.com/old/ver": gomodguard.BlockedVersion{Version: "< 2.0.0", Reason: "vulnerable"}}},
+	}
+
+	var tests = []struct {
+		testName    string
+		config      gomodguard.Configuration
+		wantReasons []string
+	}{
+		{
+			"disabled",
+			gomodguard.Configuration{Blocked: blocked},
+			[]string{},
+		},
+		{
+			"proxy",
+			gomodguard.Configuration{Deep: true, Blocked: blocked},
+			[]string{
+				"github.com/example/dirty: import of package `github.com/example/dirty` is blocked because the module requires `github.com/bad/mod`, which is in the blocked modules list. `github.com/example/reco` is a recommended module. unmaintained.",
+				"github.com/example/dirty: import of package `github.com/example/dirty` is blocked because the module requires `github.com/old/ver v1.1.0`, which is a blocked version. version `v1.1.0` is blocked because it does not meet the version constraint `< 2.0.0`. vulnerable.",
+			},
+		},
+		{
+			"module cache when offline",
+			gomodguard.Configuration{Deep: true, Offline: true, Blocked: blocked},
+			[]string{
+				"github.com/example/dirty: import of package `github.com/example/dirty` is blocked because the module requires `github.com/bad/mod`, which is in the blocked modules list. `github.com/example/reco` is a recommended module. unmaintained.",
+			},
+		},
+		{
+			"rule family disabled",
+			gomodguard.Configuration{Deep: true, Blocked: blocked, Rules: map[string]bool{gomodguard.RuleFamilyModule: false}},
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			config.Mode = gomodguard.ModeBlock
+			config.LintGoMod = true
+			config.GoEnv = map[string]string{"GOPROXY": server.URL, "GOMODCACHE": dir, "GOPRIVATE": "", "GONOSUMDB": "", "GOFLAGS": ""}
+
+			processor, err := gomodguard.NewProcessorFromModBytes(&config, goMod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, result := range processor.ProcessFiles([]string{}) {
+				if result.Rule == gomodguard.RuleBlockedRequirement {
+					got = append(got, result.Module+": "+result.Reason)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantReasons) {
+				t.Errorf("got '%v' want '%v'", strings.Join(got, "\n"), strings.Join(tt.wantReasons, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDepsDevAPIURL = "https://api.deps.dev"
+	depsDevTimeout       = 10 * time.Second
+	errFetchingDepsDev   = "unable to fetch deps.dev metadata for %s@%s: %w"
+	errDepsDevStatus     = "unexpected deps.dev api status code %d for %s@%s"
+	depsDevNonStandard   = "non-standard"
+	depsDevDeprecated    = "deprecated"
+)
+
+var (
+	blockReasonLicenseInBlockedList  = "import of package `%%s` is blocked because the module license `%s` is in the blocked licenses list."
+	blockReasonUnresolvableLicense   = "import of package `%s` is blocked because the module license could not be resolved."
+	blockReasonHasAdvisories         = "import of package `%%s` is blocked because the module version has known security advisories: %s."
+	blockReasonTooManyDependencies   = "import of package `%%s` is blocked because the module has %d dependencies which exceeds the maximum of %d."
+	blockReasonDeprecated            = "import of package `%s` is blocked because the module is deprecated."
+	errDepsDevNotFound               = fmt.Errorf("deps.dev metadata not found")
+	depsDevUnresolvableLicenseValues = []string{"", depsDevNonStandard, "unknown"}
+)
+
+// ModuleMetadata is metadata about a module version.
+type ModuleMetadata struct {
+	Licenses        []string
+	Advisories      []string
+	DependencyCount int
+	Deprecated      string
+}
+
+// HasUnresolvableLicense returns true if no license was found
+// or a license could not be mapped to a known license.
+func (m *ModuleMetadata) HasUnresolvableLicense() bool {
+	if len(m.Licenses) == 0 {
+		return true
+	}
+
+	for _, license := range m.Licenses {
+		for _, unresolvable := range depsDevUnresolvableLicenseValues {
+			if strings.EqualFold(strings.TrimSpace(license), unresolvable) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// BlockedLicense returns the first license that is in the given list.
+func (m *ModuleMetadata) BlockedLicense(blockedLicenses []string) (string, bool) {
+	for _, license := range m.Licenses {
+		for _, blockedLicense := range blockedLicenses {
+			if strings.EqualFold(strings.TrimSpace(license), strings.TrimSpace(blockedLicense)) {
+				return license, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// DepsDev blocks direct modules using license, advisory and
+// dependency metadata from the deps.dev API.
+type DepsDev struct {
+	APIURL               string   `yaml:"api_url" json:"api_url"`
+	Licenses             []string `yaml:"licenses" json:"licenses"`
+	UnresolvableLicenses bool     `yaml:"unresolvable_licenses" json:"unresolvable_licenses"`
+	Advisories           bool     `yaml:"advisories" json:"advisories"`
+	MaxDependencies      int      `yaml:"max_dependencies" json:"max_dependencies"`
+	Deprecated           bool     `yaml:"deprecated" json:"deprecated"`
+	EnforceAfter         string   `yaml:"enforce_after" json:"enforce_after"`
+	transport            http.RoundTripper
+}
+
+// IsEnabled returns true if any deps.dev rule is configured.
+func (d *DepsDev) IsEnabled() bool {
+	return len(d.Licenses) > 0 || d.UnresolvableLicenses || d.Advisories || d.MaxDependencies > 0 || d.Deprecated
+}
+
+// IsEnforced returns true if the deps.dev rules are enforced at the given time.
+func (d *DepsDev) IsEnforced(now time.Time) bool {
+	return isEnforced(d.EnforceAfter, now)
+}
+
+// Lookup returns the deps.dev metadata of a module version. The dependency
+// count is only fetched when a maximum number of dependencies is configured.
+func (d *DepsDev) Lookup(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	version := struct {
+		Licenses     []string `json:"licenses"`
+		AdvisoryKeys []struct {
+			ID string `json:"id"`
+		} `json:"advisoryKeys"`
+		IsDeprecated     bool   `json:"isDeprecated"`
+		DeprecatedReason string `json:"deprecatedReason"`
+	}{}
+
+	err := d.get(moduleName, moduleVersion, "", &version)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &ModuleMetadata{
+		Licenses:   append([]string{}, version.Licenses...),
+		Advisories: make([]string, 0, len(version.AdvisoryKeys)),
+	}
+
+	for i := range version.AdvisoryKeys {
+		metadata.Advisories = append(metadata.Advisories, version.AdvisoryKeys[i].ID)
+	}
+
+	if version.IsDeprecated {
+		metadata.Deprecated = version.DeprecatedReason
+		if metadata.Deprecated == "" {
+			metadata.Deprecated = depsDevDeprecated
+		}
+	}
+
+	if d.MaxDependencies <= 0 {
+		return metadata, nil
+	}
+
+	dependencies := struct {
+		Nodes []struct {
+			Relation string `json:"relation"`
+		} `json:"nodes"`
+	}{}
+
+	err = d.get(moduleName, moduleVersion, ":dependencies", &dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range dependencies.Nodes {
+		if dependencies.Nodes[i].Relation != "SELF" {
+			metadata.DependencyCount++
+		}
+	}
+
+	return metadata, nil
+}
+
+// get decodes the deps.dev version endpoint with the given suffix into v.
+func (d *DepsDev) get(moduleName, moduleVersion, suffix string, v interface{}) error {
+	apiURL := d.APIURL
+	if apiURL == "" {
+		apiURL = defaultDepsDevAPIURL
+	}
+
+	client := &http.Client{Timeout: depsDevTimeout, Transport: d.transport}
+
+	resp, err := client.Get(fmt.Sprintf("%s/v3/systems/go/packages/%s/versions/%s%s",
+		strings.TrimRight(apiURL, "/"), url.PathEscape(moduleName), url.PathEscape(moduleVersion), suffix))
+	if err != nil {
+		return fmt.Errorf(errFetchingDepsDev, moduleName, moduleVersion, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s@%s", errDepsDevNotFound, moduleName, moduleVersion)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf(errDepsDevStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf(errFetchingDepsDev, moduleName, moduleVersion, err)
+	}
+
+	return nil
+}
+
+// Reasons returns the reasons the module is blocked by the deps.dev rules.
+func (d *DepsDev) Reasons(metadata *ModuleMetadata) []string {
+	reasons := d.LicenseReasons(metadata)
+
+	if d.Advisories && len(metadata.Advisories) > 0 {
+		reasons = append(reasons, fmt.Sprintf(blockReasonHasAdvisories, escapeReason(strings.Join(metadata.Advisories, ", "))))
+	}
+
+	if d.MaxDependencies > 0 && metadata.DependencyCount > d.MaxDependencies {
+		reasons = append(reasons, fmt.Sprintf(blockReasonTooManyDependencies, metadata.DependencyCount, d.MaxDependencies))
+	}
+
+	if d.Deprecated && metadata.Deprecated != "" {
+		reason := blockReasonDeprecated
+		if metadata.Deprecated != depsDevDeprecated {
+			reason += " " + escapeReason(metadata.Deprecated)
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return reasons
+}
+
+// LicenseReasons returns the reasons the module is blocked by the deps.dev license rules.
+// Metadata without licenses, from providers not knowing licenses, is never
+// blocked by them.
+func (d *DepsDev) LicenseReasons(metadata *ModuleMetadata) []string {
+	reasons := []string{}
+
+	if metadata.Licenses == nil {
+		return reasons
+	}
+
+	if license, ok := metadata.BlockedLicense(d.Licenses); ok {
+		reasons = append(reasons, fmt.Sprintf(blockReasonLicenseInBlockedList, escapeReason(license)))
+	}
+
+	if d.UnresolvableLicenses && metadata.HasUnresolvableLicense() {
+		reasons = append(reasons, blockReasonUnresolvableLicense)
+	}
+
+	return reasons
+}
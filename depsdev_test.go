@@ -0,0 +1,94 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestDepsDevLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/go/packages/github.com%2Fsomeorg%2Fsomemodule/versions/v1.0.0":
+			fmt.Fprint(w, `{"licenses": ["non-standard"], "advisoryKeys": [{"id": "GHSA-xxxx-xxxx-xxxx"}]}`)
+		case "/v3/systems/go/packages/github.com%2Fsomeorg%2Fsomemodule/versions/v1.0.0:dependencies":
+			fmt.Fprint(w, `{"nodes": [{"relation": "SELF"}, {"relation": "DIRECT"}, {"relation": "INDIRECT"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	depsDev := gomodguard.DepsDev{
+		APIURL:               server.URL,
+		Licenses:             []string{"AGPL-3.0"},
+		UnresolvableLicenses: true,
+		Advisories:           true,
+		MaxDependencies:      1,
+	}
+
+	metadata, err := depsDev.Lookup("github.com/someorg/somemodule", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantMetadata := &gomodguard.ModuleMetadata{
+		Licenses:        []string{"non-standard"},
+		Advisories:      []string{"GHSA-xxxx-xxxx-xxxx"},
+		DependencyCount: 2,
+	}
+
+	if !reflect.DeepEqual(metadata, wantMetadata) {
+		t.Errorf("got '%+v' want '%+v'", metadata, wantMetadata)
+	}
+
+	wantReasons := []string{
+		"import of package `%s` is blocked because the module license could not be resolved.",
+		"import of package `%s` is blocked because the module version has known security advisories: GHSA-xxxx-xxxx-xxxx.",
+		"import of package `%s` is blocked because the module has 2 dependencies which exceeds the maximum of 1.",
+	}
+
+	if reasons := depsDev.Reasons(metadata); !reflect.DeepEqual(reasons, wantReasons) {
+		t.Errorf("got '%+v' want '%+v'", reasons, wantReasons)
+	}
+
+	_, err = depsDev.Lookup("github.com/someorg/othermodule", "v1.0.0")
+	if err == nil {
+		t.Error("want error for module without metadata")
+	}
+}
+
+func TestModuleMetadataBlockedLicense(t *testing.T) {
+	var tests = []struct {
+		testName        string
+		metadata        gomodguard.ModuleMetadata
+		blockedLicenses []string
+		wantBlocked     bool
+	}{
+		{
+			"license is blocked",
+			gomodguard.ModuleMetadata{Licenses: []string{"MIT", "AGPL-3.0"}},
+			[]string{"agpl-3.0"},
+			true,
+		},
+		{
+			"license is not blocked",
+			gomodguard.ModuleMetadata{Licenses: []string{"MIT"}},
+			[]string{"AGPL-3.0"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, blocked := tt.metadata.BlockedLicense(tt.blockedLicenses)
+			if blocked != tt.wantBlocked {
+				t.Errorf("got '%v' want '%v'", blocked, tt.wantBlocked)
+			}
+		})
+	}
+}
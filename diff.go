@@ -0,0 +1,155 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffLine is a line of a diff, kind is ' ', '-' or '+'.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// WriteDiff writes the unified diff of the old and new contents of the file.
+// Nothing is written if the contents are the same. The diff is computed with
+// the longest common subsequence of the lines, which is fine for small files
+// such as go.mod files.
+func WriteDiff(w io.Writer, filename string, oldData, newData []byte) error {
+	lines := diffLines(splitLines(string(oldData)), splitLines(string(newData)))
+
+	hunks := diffHunks(lines)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", filename, filename)
+	if err != nil {
+		return err
+	}
+
+	for _, hunk := range hunks {
+		oldStart, oldCount, newStart, newCount := 1, 0, 1, 0
+
+		for _, line := range lines[:hunk[0]] {
+			if line.kind != '+' {
+				oldStart++
+			}
+
+			if line.kind != '-' {
+				newStart++
+			}
+		}
+
+		for _, line := range lines[hunk[0]:hunk[1]] {
+			if line.kind != '+' {
+				oldCount++
+			}
+
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines[hunk[0]:hunk[1]] {
+			_, err = fmt.Fprintf(w, "%c%s\n", line.kind, line.text)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitLines returns the lines of the text without line endings.
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the lines of a and b as unchanged, removed and added
+// lines.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+// diffHunks returns the start and end indexes of the hunks of the lines,
+// changes with their surrounding context merged into one hunk when the
+// contexts overlap.
+func diffHunks(lines []diffLine) [][2]int {
+	hunks := [][2]int{}
+
+	for i, line := range lines {
+		if line.kind == ' ' {
+			continue
+		}
+
+		start, end := i-diffContext, i+diffContext+1
+		if start < 0 {
+			start = 0
+		}
+
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+			continue
+		}
+
+		hunks = append(hunks, [2]int{start, end})
+	}
+
+	return hunks
+}
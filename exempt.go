@@ -0,0 +1,302 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	exemptDirective    = "//gomodguard:exempt"
+	ignoreDirective    = "//gomodguard:ignore"
+	nolintDirective    = "nolint"
+	linterName         = "gomodguard"
+	packageDocFilename = "doc.go"
+)
+
+const errUnknownNonSuppressibleRule = "unknown non-suppressible rule %s, must be a rule or a rule family"
+
+// Exemption exempts a file, or all files of a package when declared in its
+// doc.go file, from rules. Exemptions of a line only exempt the results of
+// that line. An exemption without rules exempts from all rules. Exempted is
+// the number of results suppressed by the exemption.
+type Exemption struct {
+	FileName string
+	Line     int
+	Package  bool
+	Rules    []string
+	Reason   string
+	Exempted int
+
+	// directiveLine is the line of the directive declaring the exemption.
+	directiveLine int
+}
+
+// Location returns the file, or file and line, of the exemption.
+func (e *Exemption) Location() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d", e.FileName, e.Line)
+	}
+
+	return e.FileName
+}
+
+// appliesTo returns true if the exemption suppresses the result.
+func (e *Exemption) appliesTo(result *Result) bool {
+	if !isRule(result.Rule) {
+		return false
+	}
+
+	if e.Package {
+		if !samePath(filepath.Dir(result.FileName), filepath.Dir(e.FileName)) {
+			return false
+		}
+	} else if !samePath(result.FileName, e.FileName) {
+		return false
+	}
+
+	if e.Line > 0 && result.LineNumber != e.Line {
+		return false
+	}
+
+	if len(e.Rules) == 0 {
+		return true
+	}
+
+	for _, rule := range e.Rules {
+		if rule == result.Rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectExemptions records the exempt directives in the comments before the
+// package clause of a file, and the nolint and ignore directives. Nolint and
+// ignore directives before or on the line of the package clause exempt the
+// file, elsewhere they exempt their line. Like golangci-lint, a directive on
+// its own line directly above an import exempts the line of that import.
+func (p *Processor) collectExemptions(fileSet *token.FileSet, file *ast.File) {
+	packageLine := fileSet.Position(file.Package).Line
+
+	for _, commentGroup := range file.Comments {
+		for _, comment := range commentGroup.List {
+			position := fileSet.Position(comment.Pos())
+
+			if isDirective(comment.Text, exemptDirective) {
+				if comment.Pos() >= file.Package {
+					continue
+				}
+
+				exemption := parseExemptDirective(strings.TrimPrefix(comment.Text, exemptDirective))
+				exemption.FileName = position.Filename
+				exemption.Package = filepath.Base(position.Filename) == packageDocFilename
+				exemption.directiveLine = position.Line
+
+				p.Exemptions = append(p.Exemptions, exemption)
+
+				continue
+			}
+
+			exemption, ok := parseSuppressDirective(comment.Text)
+			if !ok {
+				continue
+			}
+
+			exemption.FileName = position.Filename
+			exemption.directiveLine = position.Line
+
+			if comment.Pos() >= file.Package && position.Line != packageLine {
+				exemption.Line = position.Line
+
+				if line, ok := importBelowLine(fileSet, file, commentGroup, position.Line); ok {
+					exemption.Line = line
+				}
+			}
+
+			p.Exemptions = append(p.Exemptions, exemption)
+		}
+	}
+}
+
+// importBelowLine returns the line of the import directly below the comment
+// group if the comment on the given line is not on the line of an import.
+func importBelowLine(fileSet *token.FileSet, file *ast.File, commentGroup *ast.CommentGroup, line int) (int, bool) {
+	below := fileSet.Position(commentGroup.End()).Line + 1
+	found := false
+
+	for _, spec := range file.Imports {
+		if fileSet.Position(spec.Pos()).Line <= line && fileSet.Position(spec.End()).Line >= line {
+			return 0, false
+		}
+
+		if fileSet.Position(spec.Pos()).Line == below {
+			found = true
+		}
+	}
+
+	return below, found
+}
+
+// isDirective returns true if the comment is the directive, optionally
+// followed by arguments.
+func isDirective(text, directive string) bool {
+	return text == directive || strings.HasPrefix(text, directive+" ")
+}
+
+// parseSuppressDirective parses a //gomodguard:ignore directive, with the
+// same arguments as the exempt directive, or a //nolint directive for all
+// linters or for gomodguard. The explanation of a nolint directive after a
+// second // is the reason.
+func parseSuppressDirective(text string) (Exemption, bool) {
+	if isDirective(text, ignoreDirective) {
+		return parseExemptDirective(strings.TrimPrefix(text, ignoreDirective)), true
+	}
+
+	directive := strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if !strings.HasPrefix(text, "//") || !strings.HasPrefix(directive, nolintDirective) {
+		return Exemption{}, false
+	}
+
+	directive, reason := strings.TrimPrefix(directive, nolintDirective), ""
+	if i := strings.Index(directive, "//"); i >= 0 {
+		directive, reason = directive[:i], strings.TrimSpace(directive[i+2:])
+	}
+
+	directive = strings.TrimSpace(directive)
+
+	switch {
+	case directive == "":
+		return Exemption{Reason: reason}, true
+	case !strings.HasPrefix(directive, ":"):
+		return Exemption{}, false
+	}
+
+	for _, linter := range strings.Split(directive[1:], ",") {
+		if strings.TrimSpace(linter) == linterName {
+			return Exemption{Reason: reason}, true
+		}
+	}
+
+	return Exemption{}, false
+}
+
+// parseExemptDirective parses the rules=a,b and reason="..." arguments of
+// an exempt directive.
+func parseExemptDirective(args string) Exemption {
+	exemption := Exemption{}
+
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		i := strings.Index(args, "=")
+		if i < 0 {
+			break
+		}
+
+		key := strings.TrimSpace(args[:i])
+		args = args[i+1:]
+
+		var value string
+
+		if strings.HasPrefix(args, `"`) {
+			end := strings.Index(args[1:], `"`)
+			if end < 0 {
+				value, args = args[1:], ""
+			} else {
+				value, args = args[1:end+1], args[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(args, " \t")
+			if end < 0 {
+				end = len(args)
+			}
+
+			value, args = args[:end], args[end:]
+		}
+
+		switch key {
+		case "rules":
+			for _, rule := range strings.Split(value, ",") {
+				if rule = strings.TrimSpace(rule); rule != "" {
+					exemption.Rules = append(exemption.Rules, rule)
+				}
+			}
+		case "reason":
+			exemption.Reason = value
+		}
+	}
+
+	return exemption
+}
+
+// IsRuleSuppressible returns true if results of the rule can be suppressed
+// by exempt and nolint directives and baselines, that is if neither the rule
+// nor its rule family is non-suppressible.
+func (c *Configuration) IsRuleSuppressible(rule string) bool {
+	for _, name := range c.NonSuppressible {
+		name = strings.TrimSpace(name)
+		if name == rule || name == ruleFamilies[rule] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateNonSuppressible returns an error if a non-suppressible rule is
+// neither a rule nor a rule family.
+func validateNonSuppressible(config *Configuration) error {
+	families := RuleFamilies()
+
+	for _, name := range config.NonSuppressible {
+		name = strings.TrimSpace(name)
+		if _, ok := ruleFamilies[name]; !ok && !containsString(families, name) {
+			return fmt.Errorf(errUnknownNonSuppressibleRule, name)
+		}
+	}
+
+	return nil
+}
+
+// applyExemptions removes the results from the given index on that are
+// suppressed by an exemption, they are moved to the suppressed results.
+// Results of non-suppressible rules are marked and never exempted.
+func (p *Processor) applyExemptions(from int) {
+	if p.Config != nil && len(p.Config.NonSuppressible) > 0 {
+		for i := from; i < len(p.Result); i++ {
+			p.Result[i].NonSuppressible = !p.Config.IsRuleSuppressible(p.Result[i].Rule)
+		}
+	}
+
+	if len(p.Exemptions) == 0 {
+		return
+	}
+
+	kept := p.Result[:from]
+
+	for i := from; i < len(p.Result); i++ {
+		exempted := false
+
+		for n := range p.Exemptions {
+			if p.Result[i].NonSuppressible {
+				break
+			}
+
+			if p.Exemptions[n].appliesTo(&p.Result[i]) {
+				p.Exemptions[n].Exempted++
+				p.Suppressed = append(p.Suppressed, p.Result[i])
+				exempted = true
+
+				break
+			}
+		}
+
+		if !exempted {
+			kept = append(kept, p.Result[i])
+		}
+	}
+
+	p.Result = kept
+}
@@ -0,0 +1,250 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorExemptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"file/exempt.go":      "//gomodguard:exempt rules=in_blocked_list reason=\"vendored generated code\"\n\npackage file\n\nimport \"github.com/foo/bar\"\n",
+		"file/other.go":       "package file\n\nimport \"github.com/foo/bar\"\n",
+		"pkg/doc.go":          "//gomodguard:exempt reason=legacy\n\n// Package pkg is exempt.\npackage pkg\n",
+		"pkg/pkg.go":          "package pkg\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n",
+		"rules/not_exempt.go": "//gomodguard:exempt rules=blocked_version\n\npackage rules\n\nimport \"github.com/foo/bar\"\n",
+	}
+
+	filenames := writeTree(t, dir, files)
+
+	processor := gomodguard.Processor{
+		Config: &gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{
+			{"github.com/foo/bar": gomodguard.BlockedModule{}},
+			{"github.com/foo/baz": gomodguard.BlockedModule{}},
+		}}},
+		Modfile: modFile,
+	}
+	processor.SetBlockedModules()
+
+	results := processor.ProcessFiles(filenames)
+
+	gotFiles := map[string]bool{}
+	for _, result := range results {
+		gotFiles[filepath.Base(result.FileName)] = true
+	}
+
+	wantFiles := map[string]bool{"other.go": true, "not_exempt.go": true}
+	if !reflect.DeepEqual(gotFiles, wantFiles) {
+		t.Errorf("got results for '%+v' want '%+v'", gotFiles, wantFiles)
+	}
+
+	exempted := map[string]gomodguard.Exemption{}
+	for _, exemption := range processor.Exemptions {
+		exempted[filepath.Base(exemption.FileName)] = exemption
+	}
+
+	if e := exempted["exempt.go"]; e.Package || e.Reason != "vendored generated code" || e.Exempted != 1 || !reflect.DeepEqual(e.Rules, []string{gomodguard.RuleInBlockedList}) {
+		t.Errorf("got '%+v' want a file exemption of one in_blocked_list result", e)
+	}
+
+	if e := exempted["doc.go"]; !e.Package || e.Reason != "legacy" || e.Exempted != 2 {
+		t.Errorf("got '%+v' want a package exemption of two results", e)
+	}
+}
+
+func TestProcessorSuppressDirectives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		src         string
+		wantLines   []int
+		wantReasons []string
+	}{
+		{
+			"nolint on an import line",
+			"package example\n\nimport (\n\t\"github.com/foo/bar\" //nolint:gomodguard // migrating in #42\n\t\"github.com/foo/baz\"\n)\n",
+			[]int{5},
+			[]string{"migrating in #42"},
+		},
+		{
+			"nolint above an import",
+			"package example\n\nimport (\n\t// Migrating in #42.\n\t//nolint:gomodguard // migrating in #42\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n",
+			[]int{7},
+			[]string{"migrating in #42"},
+		},
+		{
+			"nolint above a blank line",
+			"package example\n\nimport (\n\t//nolint:gomodguard\n\n\t\"github.com/foo/bar\"\n)\n",
+			[]int{6},
+			[]string{""},
+		},
+		{
+			"nolint for all linters",
+			"package example\n\nimport \"github.com/foo/bar\" //nolint\n",
+			[]int{},
+			[]string{""},
+		},
+		{
+			"nolint for other linters",
+			"package example\n\nimport \"github.com/foo/bar\" //nolint:depguard,revive\n",
+			[]int{3},
+			[]string{},
+		},
+		{
+			"nolint among other linters",
+			"package example\n\nimport \"github.com/foo/bar\" //nolint:depguard,gomodguard\n",
+			[]int{},
+			[]string{""},
+		},
+		{
+			"ignore with rules and reason",
+			"package example\n\nimport (\n\t\"github.com/foo/bar\" //gomodguard:ignore rules=in_blocked_list reason=\"approved by security\"\n\t\"github.com/foo/baz\" //gomodguard:ignore rules=blocked_version\n)\n",
+			[]int{5},
+			[]string{"approved by security", ""},
+		},
+		{
+			"nolint on the package clause",
+			"package example //nolint:gomodguard\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n",
+			[]int{},
+			[]string{""},
+		},
+		{
+			"ignore before the package clause",
+			"//gomodguard:ignore reason=legacy\n\npackage example\n\nimport \"github.com/foo/bar\"\n",
+			[]int{},
+			[]string{"legacy"},
+		},
+	}
+
+	for _, fastImports := range []bool{false, true} {
+		for i, tt := range tests {
+			t.Run(fmt.Sprintf("%s fast imports %t", tt.testName, fastImports), func(t *testing.T) {
+				filename := filepath.Join(dir, fmt.Sprintf("example%d.go", i))
+
+				err := ioutil.WriteFile(filename, []byte(tt.src), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				processor := gomodguard.Processor{
+					Config: &gomodguard.Configuration{
+						FastImports: fastImports,
+						Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{
+							{"github.com/foo/bar": gomodguard.BlockedModule{}},
+							{"github.com/foo/baz": gomodguard.BlockedModule{}},
+						}},
+					},
+					Modfile: modFile,
+				}
+				processor.SetBlockedModules()
+
+				results := processor.ProcessFiles([]string{filename})
+
+				gotLines := []int{}
+				for _, result := range results {
+					gotLines = append(gotLines, result.LineNumber)
+				}
+
+				if !reflect.DeepEqual(gotLines, tt.wantLines) {
+					t.Errorf("got '%v' want '%v'", gotLines, tt.wantLines)
+				}
+
+				gotReasons := []string{}
+				for _, exemption := range processor.Exemptions {
+					gotReasons = append(gotReasons, exemption.Reason)
+				}
+
+				if !reflect.DeepEqual(gotReasons, tt.wantReasons) {
+					t.Errorf("got '%v' want '%v'", gotReasons, tt.wantReasons)
+				}
+			})
+		}
+	}
+}
+
+func TestProcessorNonSuppressible(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport \"github.com/foo/bar\" //nolint:gomodguard\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName        string
+		nonSuppressible []string
+		wantResults     int
+	}{
+		{"suppressible", nil, 0},
+		{"non-suppressible rule", []string{"in_blocked_list"}, 1},
+		{"non-suppressible rule family", []string{"module-check"}, 1},
+		{"other rule family", []string{"version-check"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor := gomodguard.Processor{
+				Config: &gomodguard.Configuration{
+					NonSuppressible: tt.nonSuppressible,
+					Blocked:         gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+				},
+				Modfile: modFile,
+			}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+			if len(results) != tt.wantResults {
+				t.Fatalf("got '%v' want %d results", results, tt.wantResults)
+			}
+
+			for _, result := range results {
+				if !result.NonSuppressible {
+					t.Errorf("got '%v' want a non-suppressible result", result)
+				}
+			}
+		})
+	}
+
+	_, err = gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{NonSuppressible: []string{"unknown"}}, "github.com/ryancurrah/example", nil)
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
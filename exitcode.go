@@ -0,0 +1,143 @@
+package gomodguard
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const errUnknownExitCodeMode = "unknown exit code mode %s, must be one of %s"
+
+// Exit code modes. The default mode exits with the issues exit code when
+// errors were found and 1 when gomodguard failed. The matrix mode exits with
+// a distinct code for each outcome.
+const (
+	ExitCodeModeDefault = "default"
+	ExitCodeModeMatrix  = "matrix"
+)
+
+// Exit codes of the matrix exit code mode.
+const (
+	ExitClean         = 0
+	ExitErrors        = 1
+	ExitWarnings      = 2
+	ExitToolFailure   = 3
+	ExitConfigInvalid = 4
+)
+
+// ExitUsage is the exit code of unknown flags and malformed flag values in
+// the matrix exit code mode. It is the EX_USAGE code of sysexits.h, so it
+// does not collide with other outcomes.
+const ExitUsage = 64
+
+// exitDefaultUsage is the exit code of invalid flags in the default exit
+// code mode, the exit code of the flag package.
+const exitDefaultUsage = 2
+
+// ExitCodeModes is the list of all exit code modes.
+var ExitCodeModes = []string{ExitCodeModeDefault, ExitCodeModeMatrix}
+
+// exitCodeMode is the exit code mode of the run.
+var exitCodeMode = ExitCodeModeDefault
+
+// configError is an error caused by an invalid configuration or invalid
+// command line arguments.
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// invalidConfig marks the error as caused by an invalid configuration.
+func invalidConfig(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &configError{err: err}
+}
+
+// ResultsExitCode returns the exit code of a run that found the number of
+// error and warning results. In the default mode errors exit with the issues
+// exit code and warnings do not affect the exit code.
+func ResultsExitCode(mode string, issuesExitCode, errorCount, warningCount int) int {
+	switch {
+	case errorCount > 0 && mode == ExitCodeModeMatrix:
+		return ExitErrors
+	case errorCount > 0:
+		return issuesExitCode
+	case warningCount > 0 && mode == ExitCodeModeMatrix:
+		return ExitWarnings
+	}
+
+	return ExitClean
+}
+
+// FailureExitCode returns the exit code of a run that failed with the error.
+// In the matrix mode errors of an invalid configuration exit with
+// ExitConfigInvalid and all other errors with ExitToolFailure.
+func FailureExitCode(mode string, err error) int {
+	if mode != ExitCodeModeMatrix {
+		return 1
+	}
+
+	var cerr *configError
+	if errors.As(err, &cerr) {
+		return ExitConfigInvalid
+	}
+
+	return ExitToolFailure
+}
+
+// usageError is an error of unknown flags or malformed flag values.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// UsageExitCode returns the exit code of a run with invalid flags. The
+// default mode exits with 2 like the flag package, the matrix mode with
+// ExitUsage.
+func UsageExitCode(mode string) int {
+	if mode == ExitCodeModeMatrix {
+		return ExitUsage
+	}
+
+	return exitDefaultUsage
+}
+
+// parseFlags parses the flags of the arguments. Invalid flags are returned as
+// a usage error, flag.ErrHelp is returned as is if help was requested.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return &usageError{err: err}
+	}
+
+	return err
+}
+
+// validateExitCodeMode returns an error if the exit code mode is unknown.
+func validateExitCodeMode(mode string) error {
+	for _, m := range ExitCodeModes {
+		if m == mode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(errUnknownExitCodeMode, mode, strings.Join(ExitCodeModes, ", "))
+}
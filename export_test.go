@@ -0,0 +1,11 @@
+package gomodguard
+
+// The command helpers linting the modules of a repository, exported for the
+// tests of the gomodguard_test package.
+var (
+	LintModule          = lintModule
+	ModuleConfiguration = moduleConfiguration
+)
+
+// GoGenerateTool is exported for the tests of go:generate directives.
+var GoGenerateTool = goGenerateTool
@@ -0,0 +1,29 @@
+package gomodguard
+
+import (
+	"io"
+	"os"
+)
+
+// fileSystem is the file system the go.mod, go.sum and source files of the
+// linted module are read from.
+type fileSystem interface {
+	open(name string) (io.ReadCloser, error)
+}
+
+// osFileSystem reads files from disk.
+type osFileSystem struct{}
+
+func (osFileSystem) open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// fileSystem returns the file system of the processor, the disk unless the
+// processor was created with a file system.
+func (p *Processor) fileSystem() fileSystem {
+	if p.files == nil {
+		return osFileSystem{}
+	}
+
+	return p.files
+}
@@ -0,0 +1,252 @@
+package gomodguard
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const errFixingFile = "unable to fix imports of %s: %w"
+
+// FixImports rewrites the imports of blocked packages with a mapped
+// replacement package in the files of the results. Where the name of the
+// package changes, the identifiers qualified with the old name are renamed
+// as well. Files are formatted with gofmt. The results that were fixed are
+// returned.
+func FixImports(results []Result) ([]Result, error) {
+	files, byFile := fixableFiles(results)
+
+	fixed := []Result{}
+
+	for _, filename := range files {
+		rewritten, err := fixFile(filename, fileReplacements(byFile[filename]))
+		if err != nil {
+			return fixed, fmt.Errorf(errFixingFile, filename, err)
+		}
+
+		for _, result := range byFile[filename] {
+			if rewritten[result.Package] {
+				fixed = append(fixed, result)
+			}
+		}
+	}
+
+	return fixed, nil
+}
+
+// fixableFiles returns the files of the results with a replacement package,
+// in order, and their results.
+func fixableFiles(results []Result) ([]string, map[string][]Result) {
+	files := []string{}
+	byFile := map[string][]Result{}
+
+	for i := range results {
+		if _, ok := results[i].Replacement.PackagePath(results[i].Package); !ok || results[i].Package == "" {
+			continue
+		}
+
+		if _, ok := byFile[results[i].FileName]; !ok {
+			files = append(files, results[i].FileName)
+		}
+
+		byFile[results[i].FileName] = append(byFile[results[i].FileName], results[i])
+	}
+
+	return files, byFile
+}
+
+// fileReplacements returns the replacement packages of the results of a
+// file, old to new import path.
+func fileReplacements(results []Result) map[string]string {
+	replacements := map[string]string{}
+
+	for _, result := range results {
+		replacements[result.Package], _ = result.Replacement.PackagePath(result.Package)
+	}
+
+	return replacements
+}
+
+// fileRewrite is the rewritten source of a file and its rewritten import
+// paths.
+type fileRewrite struct {
+	filename  string
+	out       []byte
+	perm      os.FileMode
+	rewritten map[string]bool
+}
+
+// write writes the rewritten source if imports were rewritten, atomically
+// so an interrupted fix never leaves a truncated file, keeping its mode.
+func (r fileRewrite) write() error {
+	if len(r.rewritten) == 0 {
+		return nil
+	}
+
+	err := writeFileAtomic(r.filename, func(w io.Writer) error {
+		_, err := w.Write(r.out)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(r.filename, r.perm)
+}
+
+// fixFile rewrites the imports of the file, writes it if it changed and
+// returns the rewritten import paths.
+func fixFile(filename string, replacements map[string]string) (map[string]bool, error) {
+	rewrite, err := rewriteFile(filename, replacements)
+	if err != nil {
+		return nil, err
+	}
+
+	return rewrite.rewritten, rewrite.write()
+}
+
+// rewriteFile returns the file with its imports rewritten without writing
+// it.
+func rewriteFile(filename string, replacements map[string]string) (fileRewrite, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileRewrite{}, err
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fileRewrite{}, err
+	}
+
+	out, rewritten, err := fixImports(filename, src, replacements)
+	if err != nil {
+		return fileRewrite{}, err
+	}
+
+	return fileRewrite{filename: filename, out: out, perm: info.Mode().Perm(), rewritten: rewritten}, nil
+}
+
+// fixImports returns the source with the imports of the replacements, old
+// to new import path, rewritten and the rewritten import paths. Results of
+// go:generate directives have no import and are not rewritten.
+func fixImports(filename string, src []byte, replacements map[string]string) ([]byte, map[string]bool, error) {
+	fileSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fileSet, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rewritten := map[string]bool{}
+
+	for oldPath, newPath := range replacements {
+		spec := importSpec(file, oldPath)
+		if spec == nil {
+			continue
+		}
+
+		rewritten[oldPath] = true
+
+		if spec.Name == nil {
+			renamePackageIdents(file, assumedPackageName(oldPath), assumedPackageName(newPath))
+		}
+
+		if importSpec(file, newPath) != nil {
+			astutil.DeleteNamedImport(fileSet, file, importName(spec), oldPath)
+			continue
+		}
+
+		astutil.RewriteImport(fileSet, file, oldPath, newPath)
+	}
+
+	if len(rewritten) == 0 {
+		return src, rewritten, nil
+	}
+
+	var buf bytes.Buffer
+
+	err = format.Node(&buf, fileSet, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), rewritten, nil
+}
+
+// importSpec returns the import of the path in the file, if any.
+func importSpec(file *ast.File, importPath string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == importPath {
+			return spec
+		}
+	}
+
+	return nil
+}
+
+// importName returns the name of the import or an empty string.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return ""
+	}
+
+	return spec.Name.Name
+}
+
+// renamePackageIdents renames the package qualifier of the identifiers
+// qualified with the old package name. Identifiers declared in the file,
+// such as variables shadowing the package name, are resolved by the parser
+// and not renamed.
+func renamePackageIdents(file *ast.File, oldName, newName string) {
+	if oldName == newName || oldName == "" || newName == "" {
+		return
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == oldName && ident.Obj == nil {
+			ident.Name = newName
+		}
+
+		return true
+	})
+}
+
+// assumedPackageName returns the package name the import path most likely
+// declares, the last path element without a major version suffix, go-
+// prefix or characters that cannot be part of an identifier.
+func assumedPackageName(importPath string) string {
+	base := path.Base(importPath)
+
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			if dir := path.Dir(importPath); dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+
+	base = strings.TrimPrefix(base, "go-")
+
+	if i := strings.IndexFunc(base, func(r rune) bool { return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) }); i >= 0 {
+		base = base[:i]
+	}
+
+	return base
+}
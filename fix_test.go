@@ -0,0 +1,120 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestFixImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	replacement := &gomodguard.Replacement{
+		Mapping: map[string]string{
+			"github.com/uudashr/go-module": "golang.org/x/mod/modfile",
+			"github.com/foo/bar":           "github.com/foo/baz",
+		},
+	}
+
+	var tests = []struct {
+		testName  string
+		src       string
+		packages  []string
+		wantSrc   string
+		wantFixed int
+	}{
+		{
+			"renamed package",
+			"package example\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/uudashr/go-module\"\n)\n\nfunc f() {\n\tfmt.Println(module.Parse)\n}\n",
+			[]string{"github.com/uudashr/go-module"},
+			"package example\n\nimport (\n\t\"fmt\"\n\n\t\"golang.org/x/mod/modfile\"\n)\n\nfunc f() {\n\tfmt.Println(modfile.Parse)\n}\n",
+			1,
+		},
+		{
+			"sub package keeps its name",
+			"package example\n\nimport \"github.com/foo/bar/qux\" // comment\n\nvar _ = qux.X\n",
+			[]string{"github.com/foo/bar/qux"},
+			"package example\n\nimport \"github.com/foo/baz/qux\" // comment\n\nvar _ = qux.X\n",
+			1,
+		},
+		{
+			"aliased import keeps its alias",
+			"package example\n\nimport mod \"github.com/uudashr/go-module\"\n\nvar _ = mod.Parse\n",
+			[]string{"github.com/uudashr/go-module"},
+			"package example\n\nimport mod \"golang.org/x/mod/modfile\"\n\nvar _ = mod.Parse\n",
+			1,
+		},
+		{
+			"shadowed name is not renamed",
+			"package example\n\nimport \"github.com/uudashr/go-module\"\n\nvar _ = module.Parse\n\nfunc f(module struct{ Parse int }) int {\n\treturn module.Parse\n}\n",
+			[]string{"github.com/uudashr/go-module"},
+			"package example\n\nimport \"golang.org/x/mod/modfile\"\n\nvar _ = modfile.Parse\n\nfunc f(module struct{ Parse int }) int {\n\treturn module.Parse\n}\n",
+			1,
+		},
+		{
+			"replacement already imported",
+			"package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n\nvar _, _ = bar.X, baz.Y\n",
+			[]string{"github.com/foo/bar"},
+			"package example\n\nimport (\n\t\"github.com/foo/baz\"\n)\n\nvar _, _ = baz.X, baz.Y\n",
+			1,
+		},
+		{
+			"package without mapping",
+			"package example\n\nimport \"github.com/other/pkg\"\n\nvar _ = pkg.X\n",
+			[]string{"github.com/other/pkg"},
+			"package example\n\nimport \"github.com/other/pkg\"\n\nvar _ = pkg.X\n",
+			0,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			filename := filepath.Join(dir, fmt.Sprintf("example%d.go", i))
+
+			err := ioutil.WriteFile(filename, []byte(tt.src), 0640)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := []gomodguard.Result{}
+			for _, pkg := range tt.packages {
+				results = append(results, gomodguard.Result{FileName: filename, LineNumber: 3, Package: pkg, Replacement: replacement})
+			}
+
+			fixed, err := gomodguard.FixImports(results)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(fixed) != tt.wantFixed {
+				t.Errorf("got '%d' want '%d' fixed results", len(fixed), tt.wantFixed)
+			}
+
+			got, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.wantSrc {
+				t.Errorf("got '%s' want '%s'", got, tt.wantSrc)
+			}
+
+			info, err := os.Stat(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Mode().Perm() != 0640 {
+				t.Errorf("got '%v' want '%v'", info.Mode().Perm(), os.FileMode(0640))
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package gomodguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const errCheckingFix = "unable to check fixed imports of %s: %w"
+
+// BrokenFix is the rewrite of an import that breaks the compilation of its
+// package, with the errors of the compiler.
+type BrokenFix struct {
+	Result Result
+	Errors []string
+}
+
+// FixImportsChecked rewrites the imports like FixImports, but first builds
+// the packages of the rewritten files with go build, or go test -c for test
+// files, and then the whole module with go build ./..., reading the
+// rewritten files from an overlay so the tree is not modified. Packages are
+// checked one at a time, a package whose rewrites break its own compilation
+// or the compilation of another package of the module is not written and its
+// results are returned as broken, a single failing file rejects all rewrites
+// of its package. Packages that do not compile before the fix are not
+// checked, errors of the module build that also occur without the rewrites
+// are ignored. Overlays need Go 1.16 or newer.
+func FixImportsChecked(results []Result) ([]Result, []BrokenFix, error) {
+	files, byFile := fixableFiles(results)
+
+	rewrites := make([]fileRewrite, 0, len(files))
+
+	for _, filename := range files {
+		rewrite, err := rewriteFile(filename, fileReplacements(byFile[filename]))
+		if err != nil {
+			return nil, nil, fmt.Errorf(errFixingFile, filename, err)
+		}
+
+		if len(rewrite.rewritten) > 0 {
+			rewrites = append(rewrites, rewrite)
+		}
+	}
+
+	buildErrors, err := checkRewrites(rewrites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fixed := []Result{}
+	broken := []BrokenFix{}
+
+	for _, rewrite := range rewrites {
+		errs, isBroken := buildErrors[filepath.Dir(rewrite.filename)]
+
+		if !isBroken {
+			if err := rewrite.write(); err != nil {
+				return fixed, broken, fmt.Errorf(errFixingFile, rewrite.filename, err)
+			}
+		}
+
+		for _, result := range byFile[rewrite.filename] {
+			switch {
+			case !rewrite.rewritten[result.Package]:
+				continue
+			case isBroken:
+				broken = append(broken, BrokenFix{Result: result, Errors: errs})
+			default:
+				fixed = append(fixed, result)
+			}
+		}
+	}
+
+	return fixed, broken, nil
+}
+
+// checkRewrites builds the package of the rewritten files and then the
+// module with the rewrites of each package as an overlay, and returns the
+// build errors by package directory of the packages whose rewrites break
+// the build.
+func checkRewrites(rewrites []fileRewrite) (map[string][]string, error) {
+	if len(rewrites) == 0 {
+		return map[string][]string{}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "gomodguard-fix")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Overlay files are named after their index, errors in them are
+	// reported with the name of the rewritten file.
+	renames := map[string]string{}
+	dirs := []string{}
+	overlays := map[string]map[string]string{}
+	tests := map[string]bool{}
+
+	for i, rewrite := range rewrites {
+		filename, err := filepath.Abs(rewrite.filename)
+		if err != nil {
+			return nil, err
+		}
+
+		overlayFile := filepath.Join(dir, strconv.Itoa(i)+".go")
+
+		err = ioutil.WriteFile(overlayFile, rewrite.out, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		renames[overlayFile] = rewrite.filename
+
+		packageDir := filepath.Dir(rewrite.filename)
+		if _, ok := overlays[packageDir]; !ok {
+			dirs = append(dirs, packageDir)
+			overlays[packageDir] = map[string]string{}
+		}
+
+		overlays[packageDir][filename] = overlayFile
+		tests[packageDir] = tests[packageDir] || strings.HasSuffix(rewrite.filename, "_test.go")
+	}
+
+	buildErrors := map[string][]string{}
+	pristineModules := map[string]map[string]bool{}
+
+	for i, packageDir := range dirs {
+		overlayJSON, err := writeOverlay(filepath.Join(dir, strconv.Itoa(i)+".json"), overlays[packageDir])
+		if err != nil {
+			return nil, err
+		}
+
+		errs, err := buildPackage(packageDir, overlayJSON, tests[packageDir], renames)
+		if err != nil {
+			return nil, fmt.Errorf(errCheckingFix, packageDir, err)
+		}
+
+		if len(errs) > 0 {
+			pristineErrs, err := buildPackage(packageDir, "", tests[packageDir], nil)
+			if err != nil {
+				return nil, fmt.Errorf(errCheckingFix, packageDir, err)
+			}
+
+			if len(pristineErrs) == 0 {
+				buildErrors[packageDir] = errs
+			}
+
+			continue
+		}
+
+		moduleDir, err := goModuleDir(packageDir)
+		if err != nil {
+			return nil, fmt.Errorf(errCheckingFix, packageDir, err)
+		}
+
+		if _, ok := pristineModules[moduleDir]; !ok {
+			pristineErrs, err := buildModule(moduleDir, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf(errCheckingFix, packageDir, err)
+			}
+
+			pristineModules[moduleDir] = map[string]bool{}
+			for _, e := range pristineErrs {
+				pristineModules[moduleDir][e] = true
+			}
+		}
+
+		moduleErrs, err := buildModule(moduleDir, overlayJSON, renames)
+		if err != nil {
+			return nil, fmt.Errorf(errCheckingFix, packageDir, err)
+		}
+
+		for _, e := range moduleErrs {
+			if !pristineModules[moduleDir][e] {
+				buildErrors[packageDir] = append(buildErrors[packageDir], e)
+			}
+		}
+	}
+
+	return buildErrors, nil
+}
+
+// writeOverlay writes a build overlay replacing the files and returns its
+// file name.
+func writeOverlay(filename string, replace map[string]string) (string, error) {
+	overlay := struct {
+		Replace map[string]string
+	}{Replace: replace}
+
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return "", err
+	}
+
+	return filename, ioutil.WriteFile(filename, data, 0600)
+}
+
+// goModuleDir returns the root directory of the module of the package
+// directory.
+func goModuleDir(dir string) (string, error) {
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	goMod := strings.TrimSpace(string(out))
+	if goMod == "" || goMod == os.DevNull {
+		return dir, nil
+	}
+
+	return filepath.Dir(goMod), nil
+}
+
+// buildModule builds all packages of the module in the directory and
+// returns the errors of the compiler.
+func buildModule(dir, overlayJSON string, renames map[string]string) ([]string, error) {
+	return goBuild(dir, []string{"build"}, "./...", overlayJSON, renames)
+}
+
+// buildPackage builds the package in the directory, with its tests if set,
+// and returns the errors of the compiler.
+func buildPackage(dir, overlayJSON string, tests bool, renames map[string]string) ([]string, error) {
+	errs, err := goBuild(dir, []string{"build"}, ".", overlayJSON, renames)
+	if err != nil || !tests {
+		return errs, err
+	}
+
+	testErrs, err := goBuild(dir, []string{"test", "-c"}, ".", overlayJSON, renames)
+
+	return append(errs, testErrs...), err
+}
+
+// goBuild runs the go build command on the packages of the pattern in the
+// directory and returns the errors of the compiler. Errors in overlay files
+// are reported with the name of the file they replace.
+func goBuild(dir string, args []string, pattern, overlayJSON string, renames map[string]string) ([]string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if overlayJSON != "" {
+		args = append(args, "-overlay", overlayJSON)
+	}
+
+	cmd := exec.Command("go", append(args, "-o", os.DevNull, pattern)...)
+	cmd.Dir = dir
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	errs := []string{}
+
+	if err == nil {
+		return errs, nil
+	}
+
+	for _, line := range strings.Split(output.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		errs = append(errs, renameOverlayFiles(absDir, line, renames))
+	}
+
+	if len(errs) == 0 {
+		errs = append(errs, err.Error())
+	}
+
+	return errs, nil
+}
+
+// renameOverlayFiles replaces the overlay files in the line of the compiler
+// output with the name of the file they replace.
+func renameOverlayFiles(dir, line string, renames map[string]string) string {
+	for overlayFile, filename := range renames {
+		line = strings.ReplaceAll(line, overlayFile, filename)
+
+		if rel, err := filepath.Rel(dir, overlayFile); err == nil {
+			line = strings.ReplaceAll(line, rel, filename)
+		}
+	}
+
+	return line
+}
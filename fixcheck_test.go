@@ -0,0 +1,126 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestFixImportsChecked(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// example.com/fresh is a drop-in replacement of example.com/old, while
+	// example.com/bad lacks its function and the function of example.com/count
+	// returns another type, breaking the importers of lib.
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.16\n\nrequire (\n\texample.com/bad v0.0.0\n\texample.com/count v0.0.0\n\texample.com/fresh v0.0.0\n\texample.com/old v0.0.0\n)\n\nreplace (\n\texample.com/bad => ./bad\n\texample.com/count => ./count\n\texample.com/fresh => ./fresh\n\texample.com/old => ./old\n)\n",
+		"old/go.mod":       "module example.com/old\n\ngo 1.16\n",
+		"old/old.go":       "package old\n\nfunc Hello() string { return \"hello\" }\n",
+		"fresh/go.mod":     "module example.com/fresh\n\ngo 1.16\n",
+		"fresh/fresh.go":   "package fresh\n\nfunc Hello() string { return \"hello\" }\n",
+		"bad/go.mod":       "module example.com/bad\n\ngo 1.16\n",
+		"bad/bad.go":       "package bad\n",
+		"count/go.mod":     "module example.com/count\n\ngo 1.16\n",
+		"count/count.go":   "package count\n\nfunc Hello() int { return 1 }\n",
+		"lib/lib.go":       "package lib\n\nimport \"example.com/old\"\n\nvar Hello = old.Hello\n",
+		"user/user.go":     "package user\n\nimport \"example.com/app/lib\"\n\nvar _ string = lib.Hello()\n",
+		"a/a.go":           "package a\n\nimport \"example.com/old\"\n\nvar _ = old.Hello()\n",
+		"b/b.go":           "package b\n\nimport \"example.com/old\"\n\nvar _ = old.Hello()\n",
+		"c/c.go":           "package c\n",
+		"c/c_test.go":      "package c\n\nimport (\n\t\"testing\"\n\n\t\"example.com/old\"\n)\n\nfunc TestHello(t *testing.T) { _ = old.Hello() }\n",
+		"broken/broken.go": "package broken\n\nimport \"example.com/old\"\n\nvar _ = old.Hello() + undefined\n",
+	}
+
+	writeTree(t, dir, files)
+
+	fresh := &gomodguard.Replacement{Mapping: map[string]string{"example.com/old": "example.com/fresh"}}
+	bad := &gomodguard.Replacement{Mapping: map[string]string{"example.com/old": "example.com/bad"}}
+	count := &gomodguard.Replacement{Mapping: map[string]string{"example.com/old": "example.com/count"}}
+
+	results := []gomodguard.Result{
+		{FileName: filepath.Join(dir, "a", "a.go"), LineNumber: 3, Package: "example.com/old", Replacement: fresh},
+		{FileName: filepath.Join(dir, "b", "b.go"), LineNumber: 3, Package: "example.com/old", Replacement: bad},
+		{FileName: filepath.Join(dir, "c", "c_test.go"), LineNumber: 6, Package: "example.com/old", Replacement: bad},
+		{FileName: filepath.Join(dir, "broken", "broken.go"), LineNumber: 3, Package: "example.com/old", Replacement: bad},
+		{FileName: filepath.Join(dir, "lib", "lib.go"), LineNumber: 3, Package: "example.com/old", Replacement: count},
+	}
+
+	fixed, broken, err := gomodguard.FixImportsChecked(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFixed := []string{}
+	for i := range fixed {
+		gotFixed = append(gotFixed, filepath.Base(fixed[i].FileName))
+	}
+
+	// Packages not compiling before the fix are not checked.
+	wantFixed := []string{"a.go", "broken.go"}
+
+	if !reflect.DeepEqual(gotFixed, wantFixed) {
+		t.Errorf("got '%v' want '%v'", gotFixed, wantFixed)
+	}
+
+	// Rewrites are rejected for the errors of their package, or of the
+	// packages importing it.
+	wantErrors := map[string][]string{
+		"b.go":      {"b/b.go", "bad.Hello"},
+		"c_test.go": {"c/c_test.go", "bad.Hello"},
+		"lib.go":    {"user/user.go", "lib.Hello()"},
+	}
+
+	gotBroken := []string{}
+	for i := range broken {
+		name := filepath.Base(broken[i].Result.FileName)
+		gotBroken = append(gotBroken, name)
+
+		errs := strings.Join(broken[i].Errors, "\n")
+		for _, want := range wantErrors[name] {
+			if !strings.Contains(errs, want) {
+				t.Errorf("got errors '%s' want errors of '%s' mentioning %s", errs, name, want)
+			}
+		}
+	}
+
+	wantBroken := []string{"b.go", "c_test.go", "lib.go"}
+
+	if !reflect.DeepEqual(gotBroken, wantBroken) {
+		t.Errorf("got '%v' want '%v'", gotBroken, wantBroken)
+	}
+
+	var wantSrc = []struct {
+		name string
+		src  string
+	}{
+		{"a/a.go", "package a\n\nimport \"example.com/fresh\"\n\nvar _ = fresh.Hello()\n"},
+		{"b/b.go", files["b/b.go"]},
+		{"c/c_test.go", files["c/c_test.go"]},
+		{"lib/lib.go", files["lib/lib.go"]},
+	}
+
+	for _, want := range wantSrc {
+		got, err := ioutil.ReadFile(filepath.Join(dir, want.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want.src {
+			t.Errorf("got '%s' want '%s'", got, want.src)
+		}
+	}
+}
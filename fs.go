@@ -0,0 +1,71 @@
+//go:build go1.16
+// +build go1.16
+
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ioFileSystem reads files from an io/fs file system. Names are cleaned
+// slash separated paths relative to the root of the file system.
+type ioFileSystem struct {
+	fsys fs.FS
+}
+
+func (f ioFileSystem) open(name string) (io.ReadCloser, error) {
+	return f.fsys.Open(strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/"))
+}
+
+// NewProcessorFS creates a Processor reading the go.mod, go.sum and linted
+// files from the file system instead of the disk, such as editor buffers,
+// testing/fstest file systems or embedded fixtures. The go.mod file is read
+// from the go_mod_path of the configuration, by default go.mod at the root,
+// and file names passed to ProcessFiles are paths in the file system. Files
+// are not classified as outside of the module. Files are read by several
+// workers, the file system must be safe for concurrent use.
+func NewProcessorFS(config *Configuration, fsys fs.FS) (*Processor, error) {
+	files := ioFileSystem{fsys: fsys}
+
+	fsConfig := *config
+	if strings.TrimSpace(fsConfig.GoModPath) == "" {
+		fsConfig.GoModPath = goModFilename
+	}
+
+	env := configGoEnv(&fsConfig)
+
+	f, err := files.open(env.modFile())
+	if err != nil {
+		return nil, fmt.Errorf(errReadingGoModFile, env.modFile(), err)
+	}
+	defer f.Close()
+
+	goModFileBytes, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingGoModFile, env.modFile(), err)
+	}
+
+	modFile, err := modfile.Parse(goModFilename, goModFileBytes, nil)
+	if err != nil {
+		return nil, fmt.Errorf(errParsingGoModFile, goModFilename, err)
+	}
+
+	p, err := newProcessor(config, env, modFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.files = files
+	p.goListLoaded = true // Packages resolve to the requires of the go.mod file.
+	p.SetBlockedModules()
+
+	return p, nil
+}
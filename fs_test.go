@@ -0,0 +1,119 @@
+//go:build go1.16
+// +build go1.16
+
+package gomodguard_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestNewProcessorFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":       {Data: []byte("module example.com/m\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n\tgithub.com/foo/pinned v1.0.0\n)\n")},
+		"go.sum":       {Data: []byte("github.com/foo/pinned v1.0.0 h1:pinnedzip=\n")},
+		"pkg/a.go":     {Data: []byte("package pkg\n\nimport (\n\t_ \"github.com/foo/bar\"\n\t_ \"github.com/foo/baz\"\n\t_ \"github.com/foo/pinned\"\n)\n")},
+		"sub/go.mod":   {Data: []byte("module example.com/sub\n\nrequire github.com/foo/bar v1.0.0\n")},
+		"unrelated.go": {Data: []byte("package m\n")},
+	}
+
+	config := &gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/baz": {}}}},
+		Allowed: gomodguard.Allowed{
+			Modules:   []string{"github.com/foo/bar", "github.com/foo/baz"},
+			Checksums: []string{"h1:pinnedzip="},
+		},
+		Offline: true,
+	}
+
+	processor, err := gomodguard.NewProcessorFS(config, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{"pkg/a.go", "./unrelated.go", "missing.go"})
+
+	want := []string{"pkg/a.go github.com/foo/baz", "missing.go "}
+
+	if len(results) != len(want) {
+		t.Fatalf("got '%+v' want '%v'", results, want)
+	}
+
+	for i, result := range results {
+		if result.FileName+" "+result.Package != want[i] {
+			t.Errorf("got '%s %s' want '%s'", result.FileName, result.Package, want[i])
+		}
+	}
+
+	config.GoModPath = "sub/go.mod"
+
+	processor, err = gomodguard.NewProcessorFS(config, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := processor.Modfile.Module.Mod.Path; got != "example.com/sub" {
+		t.Errorf("got '%v' want '%v'", got, "example.com/sub")
+	}
+
+	_, err = gomodguard.NewProcessorFS(config, fstest.MapFS{})
+	if err == nil {
+		t.Error("got no error want a missing go.mod error")
+	}
+}
+
+// blockingFS blocks opening go files until unblocked.
+type blockingFS struct {
+	fstest.MapFS
+	unblock chan struct{}
+}
+
+func (f blockingFS) Open(name string) (fs.File, error) {
+	if strings.HasSuffix(name, ".go") {
+		<-f.unblock
+	}
+
+	return f.MapFS.Open(name)
+}
+
+func TestProcessFilesContextCancelledWhileParsing(t *testing.T) {
+	fsys := blockingFS{
+		MapFS: fstest.MapFS{
+			"go.mod": {Data: []byte("module example.com/m\n")},
+			"a.go":   {Data: []byte("package m\n")},
+		},
+		unblock: make(chan struct{}),
+	}
+	defer close(fsys.unblock)
+
+	processor, err := gomodguard.NewProcessorFS(&gomodguard.Configuration{Offline: true}, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := processor.ProcessFilesContext(ctx, []string{"a.go"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got '%v' want '%v'", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("processing did not return after the context was done")
+	}
+}
@@ -0,0 +1,157 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"time"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+const goGenerateDirective = "//go:generate "
+
+var (
+	goGenerateReasonInBlockedList    = "go:generate invocation of `%s` is blocked because the module is in the blocked modules list."
+	goGenerateReasonNotInAllowedList = "go:generate invocation of `%s` is blocked because the module is not in the allowed modules list."
+)
+
+// processGoGenerate adds lint errors for go:generate directives that run
+// tools from blocked modules. Reasons of disabled rule families are skipped.
+func (p *Processor) processGoGenerate(fileSet *token.FileSet, file *ast.File) {
+	for _, commentGroup := range file.Comments {
+		for _, comment := range commentGroup.List {
+			if !strings.HasPrefix(comment.Text, goGenerateDirective) {
+				continue
+			}
+
+			tool, ok := goGenerateTool(strings.TrimPrefix(comment.Text, goGenerateDirective))
+			if !ok {
+				continue
+			}
+
+			for _, r := range p.goGenerateBlockReasons(tool) {
+				if p.Config.IsRuleEnabled(r.rule) {
+					p.addError(fileSet, comment.Pos(), r)
+				}
+			}
+		}
+	}
+}
+
+// goGenerateBlockReasons returns the block reasons for a go:generate tool.
+// Tools required in the go.mod file are blocked the same way imports are,
+// tools run at a specific version are checked against the configuration.
+func (p *Processor) goGenerateBlockReasons(tool string) []blockReason {
+	if blockReasons := p.isBlockedPackageFromModFile(tool); blockReasons != nil {
+		for i := range blockReasons {
+			blockReasons[i].reason = strings.Replace(blockReasons[i].reason,
+				fmt.Sprintf("import of package `%s`", tool), fmt.Sprintf("go:generate invocation of `%s`", tool), 1)
+		}
+
+		return blockReasons
+	}
+
+	for _, require := range p.Modfile.Require {
+		if match.Module(require.Mod.Path, tool) {
+			return nil
+		}
+	}
+
+	canonicalTool := p.Config.CanonicalModulePath(tool)
+
+	for _, blockedModuleName := range p.Config.Blocked.Modules.Get() {
+		if !match.Module(blockedModuleName, canonicalTool) {
+			continue
+		}
+
+		blockedModule := p.Config.Blocked.Modules.GetBlockReason(blockedModuleName)
+		reason := goGenerateReasonInBlockedList
+
+		if msg := blockedModule.Message(); msg != "" {
+			reason += " " + escapeReason(msg)
+		}
+
+		r := blockReason{
+			rule:     RuleInBlockedList,
+			reason:   reason,
+			message:  entryMessage(blockedModuleName, blockedModule.CustomMessage),
+			severity: severity(blockedModule.IsEnforced(time.Now())),
+			data: MessageData{
+				Module:          blockedModuleName,
+				Reason:          blockedModule.Reason,
+				Recommendations: blockedModule.Recommendations,
+				Owner:           blockedModule.Owner,
+				Docs:            blockedModule.Docs,
+				PolicyURL:       blockedModule.PolicyURL,
+			},
+			replacement: blockedModule.Replacement(),
+			source:      []string{"blocked", "modules", blockedModuleName},
+		}
+		r.reason = p.renderReason(r, tool)
+
+		return []blockReason{r}
+	}
+
+	if !p.Config.IsBlockListMode() && !p.isAllowedPackage(canonicalTool) {
+		r := blockReason{
+			rule:     RuleNotInAllowedList,
+			reason:   goGenerateReasonNotInAllowedList,
+			severity: SeverityError,
+		}
+		r.reason = p.renderReason(r, tool)
+
+		return []blockReason{r}
+	}
+
+	return nil
+}
+
+// goRunValueFlags are the flags of `go run` taking a value, which is the next
+// argument unless it is given as -flag=value.
+var goRunValueFlags = map[string]bool{
+	"C": true, "asmflags": true, "buildmode": true, "compiler": true, "covermode": true, "coverpkg": true,
+	"exec": true, "gccgoflags": true, "gcflags": true, "installsuffix": true, "ldflags": true, "mod": true,
+	"modfile": true, "overlay": true, "p": true, "pgo": true, "pkgdir": true, "tags": true, "toolexec": true,
+}
+
+// goGenerateTool returns the package run by a go:generate directive
+// using `go run`, without its version.
+func goGenerateTool(directive string) (string, bool) {
+	fields := strings.Fields(directive)
+
+	if len(fields) < 3 || fields[0] != "go" || fields[1] != "run" {
+		return "", false
+	}
+
+	args := fields[2:]
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		flag := args[0]
+		args = args[1:]
+
+		if flag == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(flag, "-")
+		if !strings.Contains(name, "=") && goRunValueFlags[name] && len(args) > 0 {
+			args = args[1:]
+		}
+	}
+
+	if len(args) == 0 {
+		return "", false
+	}
+
+	tool := strings.SplitN(args[0], "@", 2)[0]
+
+	// Only packages with a domain are from modules, skip local paths, files
+	// and standard library packages.
+	if !strings.Contains(strings.SplitN(tool, "/", 2)[0], ".") || strings.HasSuffix(tool, ".go") {
+		return "", false
+	}
+
+	return tool, true
+}
@@ -0,0 +1,37 @@
+package gomodguard_test
+
+import (
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestGoGenerateTool(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		directive string
+		wantTool  string
+		wantFound bool
+	}{
+		{"tool", "go run github.com/x/tool", "github.com/x/tool", true},
+		{"tool with version", "go run github.com/x/tool@v1.0.0 -out x.go", "github.com/x/tool", true},
+		{"bool flag", "go run -mod=mod -trimpath github.com/x/tool", "github.com/x/tool", true},
+		{"flag with value", "go run -tags foo github.com/x/tool", "github.com/x/tool", true},
+		{"flag with equals value", "go run -tags=foo github.com/x/tool", "github.com/x/tool", true},
+		{"double dash flag with value", "go run --ldflags '-s' github.com/x/tool", "github.com/x/tool", true},
+		{"flags terminated", "go run -tags foo -- github.com/x/tool", "github.com/x/tool", true},
+		{"local file", "go run -tags foo gen.go", "", false},
+		{"standard library", "go run cmd/cover", "", false},
+		{"only flags", "go run -tags foo", "", false},
+		{"not go run", "stringer -type=Pill", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tool, found := gomodguard.GoGenerateTool(tt.directive)
+			if tool != tt.wantTool || found != tt.wantFound {
+				t.Errorf("got '%v' '%v' want '%v' '%v'", tool, found, tt.wantTool, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package gomodguard
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+var (
+	blockReasonGeneratedInBlockedList = "import of package `%s` in a generated file is blocked because the module is in the generated blocked modules list."
+
+	// defaultGeneratedFiles match the files generated by protoc-gen-go and
+	// protoc-gen-go-grpc, *_grpc.pb.go files included.
+	defaultGeneratedFiles = []string{"*.pb.go"}
+)
+
+// Generated is the rule profile of generated API trees such as protobuf and
+// gRPC code. Generated files are only checked against the blocked runtime
+// modules of the profile, all other rules are ignored in them.
+type Generated struct {
+	Files   []string       `yaml:"files" json:"files"`
+	Modules BlockedModules `yaml:"modules" json:"modules"`
+}
+
+// IsEnabled returns true if generated files are linted with the profile.
+func (g *Generated) IsEnabled() bool {
+	return len(g.Files) > 0 || len(g.Modules) > 0
+}
+
+// IsGeneratedFile returns true if the base name of the file matches one of
+// the generated file patterns, *.pb.go if no patterns are configured.
+func (g *Generated) IsGeneratedFile(filename string) bool {
+	if !g.IsEnabled() {
+		return false
+	}
+
+	patterns := g.Files
+	if len(patterns) == 0 {
+		patterns = defaultGeneratedFiles
+	}
+
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.TrimSpace(pattern), filepath.Base(filename)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generatedBlockReasons returns the block reasons of an import of a generated
+// file, the import is blocked if it is provided by a module in the blocked
+// modules of the generated profile.
+func (p *Processor) generatedBlockReasons(importedPkg string) []blockReason {
+	if !p.Config.IsRuleEnabled(RuleInBlockedList) {
+		return nil
+	}
+
+	canonicalPkg := p.Config.CanonicalModulePath(importedPkg)
+
+	for _, blockedModuleName := range p.Config.Generated.Modules.Get() {
+		if !match.Module(blockedModuleName, canonicalPkg) {
+			continue
+		}
+
+		blockedModule := p.Config.Generated.Modules.GetBlockReason(blockedModuleName)
+
+		r := blockReason{
+			rule:     RuleInBlockedList,
+			reason:   fmt.Sprintf("%s %s", blockReasonGeneratedInBlockedList, escapeReason(blockedModule.Message())),
+			message:  entryMessage(blockedModuleName, blockedModule.CustomMessage),
+			severity: severity(blockedModule.IsEnforced(time.Now())),
+			data: MessageData{
+				Module:          blockedModuleName,
+				Reason:          blockedModule.Reason,
+				Recommendations: blockedModule.Recommendations,
+				Owner:           blockedModule.Owner,
+				Docs:            blockedModule.Docs,
+				PolicyURL:       blockedModule.PolicyURL,
+			},
+			replacement: blockedModule.Replacement(),
+			source:      []string{"generated", "modules", blockedModuleName},
+		}
+
+		if requiredModule, ok := p.resolveModule(importedPkg); ok {
+			r.data.Module = strings.TrimSpace(requiredModule.Path)
+			r.data.Version = strings.TrimSpace(requiredModule.Version)
+		}
+
+		r.reason = p.renderReason(r, importedPkg)
+
+		return []blockReason{r}
+	}
+
+	return nil
+}
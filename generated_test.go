@@ -0,0 +1,107 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorGenerated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/golang/protobuf v1.5.0\n\tgithub.com/foo/bar v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imports := "package api\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/golang/protobuf/proto\"\n)\n"
+
+	files := []string{"api.pb.go", "api_grpc.pb.go", "main.go"}
+	for _, name := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(imports), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tests = []struct {
+		testName  string
+		generated gomodguard.Generated
+		want      []string
+	}{
+		{
+			"profile disabled",
+			gomodguard.Generated{},
+			[]string{
+				"api.pb.go:4:1 github.com/foo/bar",
+				"api_grpc.pb.go:4:1 github.com/foo/bar",
+				"main.go:4:1 github.com/foo/bar",
+			},
+		},
+		{
+			"default generated files",
+			gomodguard.Generated{
+				Modules: gomodguard.BlockedModules{{"github.com/golang/protobuf": gomodguard.BlockedModule{Recommendations: []string{"google.golang.org/protobuf"}}}},
+			},
+			[]string{
+				"api.pb.go:5:1 github.com/golang/protobuf",
+				"api_grpc.pb.go:5:1 github.com/golang/protobuf",
+				"main.go:4:1 github.com/foo/bar",
+			},
+		},
+		{
+			"configured generated files",
+			gomodguard.Generated{
+				Files:   []string{"*_grpc.pb.go"},
+				Modules: gomodguard.BlockedModules{{"github.com/golang/protobuf": gomodguard.BlockedModule{}}},
+			},
+			[]string{
+				"api.pb.go:4:1 github.com/foo/bar",
+				"api_grpc.pb.go:5:1 github.com/golang/protobuf",
+				"main.go:4:1 github.com/foo/bar",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor := gomodguard.Processor{
+				Config: &gomodguard.Configuration{
+					Blocked:   gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+					Generated: tt.generated,
+				},
+				Modfile: modFile,
+			}
+			processor.SetBlockedModules()
+
+			filenames := make([]string, 0, len(files))
+			for _, name := range files {
+				filenames = append(filenames, filepath.Join(dir, name))
+			}
+
+			results := processor.ProcessFiles(filenames)
+
+			if len(results) != len(tt.want) {
+				t.Fatalf("got '%+v' want '%+v'", results, tt.want)
+			}
+
+			for i := range results {
+				got := fmt.Sprintf("%s:%d:1 %s", filepath.Base(results[i].FileName), results[i].LineNumber, results[i].Module)
+				if got != tt.want[i] {
+					t.Errorf("got '%s' want '%s'", got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// githubDataEscaper and githubPropertyEscaper escape the message and the
+// properties of GitHub Actions workflow commands.
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// githubReporter writes the results as GitHub Actions workflow commands, so
+// they are shown as annotations of the pull request. The policy page of a
+// result is appended to its message since GitHub turns urls of annotations
+// into links.
+type githubReporter struct {
+	w   io.Writer
+	err error
+}
+
+func newGitHubReporter(w io.Writer) Reporter {
+	return &githubReporter{w: w}
+}
+
+func (r *githubReporter) Report(result Result) {
+	if r.err != nil {
+		return
+	}
+
+	command := "error"
+	if result.IsWarning() {
+		command = "warning"
+	}
+
+	properties := []string{"file=" + githubPropertyEscaper.Replace(result.FileName)}
+
+	if result.LineNumber > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", result.LineNumber))
+	}
+
+	if result.Position.Column > 0 {
+		properties = append(properties, fmt.Sprintf("col=%d", result.Position.Column))
+	}
+
+	title := "gomodguard"
+	if result.Rule != "" {
+		title += " " + result.Rule
+	}
+
+	properties = append(properties, "title="+githubPropertyEscaper.Replace(title))
+
+	message := result.Reason
+	if result.PolicyURL != "" {
+		message += " See " + result.PolicyURL
+	}
+
+	_, r.err = fmt.Fprintf(r.w, "::%s %s::%s\n", command, strings.Join(properties, ","), githubDataEscaper.Replace(message))
+}
+
+func (r *githubReporter) Flush() error {
+	return r.err
+}
@@ -0,0 +1,90 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// goEnvKeys are the go environment variables used by gomodguard.
+var goEnvKeys = []string{"GOMOD", "GOFLAGS", "GOPROXY", "GOPRIVATE", "GONOSUMDB", "GOVCS", "GOMODCACHE"}
+
+// goEnv is the go environment as reported by `go env`.
+type goEnv map[string]string
+
+// readGoEnv returns the go environment reported by `go env`. Values in
+// overrides take precedence, a missing go command results in an
+// environment containing only the overrides.
+func readGoEnv(overrides map[string]string) goEnv {
+	env := goEnv{}
+
+	out, err := exec.Command("go", append([]string{"env", "-json"}, goEnvKeys...)...).Output()
+	if err == nil {
+		_ = json.Unmarshal(out, &env)
+	}
+
+	for key, value := range overrides {
+		env[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return env
+}
+
+// configGoEnv returns the go environment of the configuration. The go.mod
+// path of the configuration takes precedence over a -modfile flag in GOFLAGS
+// and GOMOD.
+func configGoEnv(config *Configuration) goEnv {
+	env := readGoEnv(config.GoEnv)
+
+	goModPath := strings.TrimSpace(config.GoModPath)
+	if goModPath == "" {
+		return env
+	}
+
+	flags := []string{}
+
+	for _, flag := range strings.Fields(env["GOFLAGS"]) {
+		if !strings.HasPrefix(strings.TrimLeft(flag, "-"), "modfile=") {
+			flags = append(flags, flag)
+		}
+	}
+
+	env["GOFLAGS"] = strings.Join(flags, " ")
+	env["GOMOD"] = goModPath
+
+	return env
+}
+
+// modFile returns the go.mod file of the main module. A -modfile flag in
+// GOFLAGS takes precedence over GOMOD.
+func (e goEnv) modFile() string {
+	for _, flag := range strings.Fields(e["GOFLAGS"]) {
+		flag = strings.TrimPrefix(flag, "-")
+		flag = strings.TrimPrefix(flag, "-")
+
+		if strings.HasPrefix(flag, "modfile=") {
+			return strings.TrimPrefix(flag, "modfile=")
+		}
+	}
+
+	if gomod := e["GOMOD"]; gomod != "" && gomod != os.DevNull {
+		return gomod
+	}
+
+	return goModFilename
+}
+
+// isPrivateModule returns true if the module matches GOPRIVATE or GONOSUMDB.
+// Private modules are not looked up in public metadata services.
+func (e goEnv) isPrivateModule(moduleName string) bool {
+	return module.MatchPrefixPatterns(e["GOPRIVATE"], moduleName) ||
+		module.MatchPrefixPatterns(e["GONOSUMDB"], moduleName)
+}
+
+// isOffline returns true if GOPROXY is off.
+func (e goEnv) isOffline() bool {
+	return strings.TrimSpace(e["GOPROXY"]) == "off"
+}
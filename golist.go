@@ -0,0 +1,138 @@
+package gomodguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Resolutions of imported packages to the modules providing them.
+const (
+	// ResolutionRequires resolves packages to the required module with the
+	// longest matching path.
+	ResolutionRequires = "requires"
+	// ResolutionGoList resolves packages with go list, which handles nested
+	// modules, vanity import paths and replace directives like the build.
+	ResolutionGoList = "go_list"
+)
+
+const (
+	errUnknownResolution = "unknown resolution %s, must be one of %s"
+	errGoList            = "unable to resolve packages with go list, falling back to go.mod requires: %w"
+)
+
+// Resolutions are the valid package resolutions.
+var Resolutions = []string{ResolutionRequires, ResolutionGoList}
+
+// goListPackage is a package as reported by go list -json.
+type goListPackage struct {
+	ImportPath string
+	Standard   bool
+	Module     *struct {
+		Path    string
+		Version string
+		Main    bool
+	}
+}
+
+// validateResolution returns an error if the resolution is unknown.
+func validateResolution(config *Configuration) error {
+	resolution := strings.TrimSpace(config.Resolution)
+	if resolution == "" || containsString(Resolutions, resolution) {
+		return nil
+	}
+
+	return fmt.Errorf(errUnknownResolution, resolution, strings.Join(Resolutions, ", "))
+}
+
+// goListModules returns the modules providing the packages of the main module
+// in the directory and the packages they import, including test imports.
+// Packages provided by the main module map to a version with an empty path,
+// standard library packages are not included.
+func goListModules(dir string, env goEnv) (map[string]module.Version, error) {
+	cmd := exec.Command("go", "list", "-e", "-deps", "-test", "-json", "./...")
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+
+	for _, key := range []string{"GOFLAGS", "GOPROXY"} {
+		if value := env[key]; value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return nil, err
+	}
+
+	modules := map[string]module.Version{}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+
+	for {
+		var pkg goListPackage
+
+		err = decoder.Decode(&pkg)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.Standard || pkg.Module == nil {
+			continue
+		}
+
+		// Test variants are listed as "path [path.test]".
+		importPath := strings.Fields(pkg.ImportPath)[0]
+
+		if pkg.Module.Main {
+			modules[importPath] = module.Version{}
+			continue
+		}
+
+		modules[importPath] = module.Version{Path: pkg.Module.Path, Version: pkg.Module.Version}
+	}
+
+	return modules, nil
+}
+
+// goListModule returns the module providing the package according to go
+// list. False is returned if go list does not know the package, such as
+// packages of files outside of the main module. Packages of the main module
+// resolve to a version with an empty path.
+func (p *Processor) goListModule(packageName string) (module.Version, bool) {
+	if !p.goListLoaded {
+		p.goListLoaded = true
+
+		dir := p.moduleDir
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+
+		modules, err := goListModules(dir, p.goEnv)
+		if err != nil {
+			p.warnf("%s", fmt.Errorf(errGoList, err))
+		}
+
+		p.goListPackages = modules
+	}
+
+	mod, ok := p.goListPackages[packageName]
+
+	return mod, ok
+}
@@ -0,0 +1,79 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestResolutionGoList(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// example.com/lib/sub is a nested module only required by example.com/lib,
+	// so go.mod requires resolve its packages to example.com/lib.
+	files := map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.14\n\nrequire example.com/lib v0.0.0\n\nreplace (\n\texample.com/lib => ./lib\n\texample.com/lib/sub => ./lib/sub\n)\n",
+		"app/app.go":     "package app\n\nimport (\n\t_ \"example.com/lib\"\n\t_ \"example.com/lib/sub/x\"\n)\n",
+		"lib/go.mod":     "module example.com/lib\n\ngo 1.14\n\nrequire example.com/lib/sub v0.0.0\n",
+		"lib/lib.go":     "package lib\n",
+		"lib/sub/go.mod": "module example.com/lib/sub\n\ngo 1.14\n",
+		"lib/sub/x/x.go": "package x\n",
+	}
+
+	writeTree(t, dir, files)
+
+	var tests = []struct {
+		testName     string
+		resolution   string
+		wantPackages []string
+	}{
+		{"requires", gomodguard.ResolutionRequires, []string{"example.com/lib", "example.com/lib/sub/x"}},
+		{"go list", gomodguard.ResolutionGoList, []string{"example.com/lib"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+				Mode:       gomodguard.ModeBlock,
+				Blocked:    gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"example.com/lib": {}}}},
+				Resolution: tt.resolution,
+				GoEnv:      map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod"), "GOPROXY": "off"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := processor.ProcessFiles([]string{filepath.Join(dir, "app", "app.go")})
+
+			got := []string{}
+			for i := range results {
+				got = append(got, results[i].Package)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantPackages) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantPackages)
+			}
+		})
+	}
+
+	_, err = gomodguard.NewProcessor(&gomodguard.Configuration{
+		Resolution: "guess",
+		GoEnv:      map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
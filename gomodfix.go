@@ -0,0 +1,206 @@
+package gomodguard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	errInvalidGoModReplace = "invalid go_mod replace of %s: %s, must be a local path or a module path and version"
+	errInvalidGoModMinimum = "invalid go_mod minimum version of %s: %s"
+	errFixingGoMod         = "unable to fix %s: %w"
+)
+
+// Actions of go.mod edits.
+const (
+	GoModDropRequire = "drop_require"
+	GoModAddReplace  = "add_replace"
+	GoModPinVersion  = "pin_version"
+)
+
+// GoModPolicy are the go.mod edits the fix-gomod command applies besides
+// dropping blocked requires. Replace maps module paths to the mandated
+// replacement, a local path or a module path and version as written on the
+// right side of a replace directive. MinimumVersions maps module paths to the
+// lowest version they may be required at.
+type GoModPolicy struct {
+	Replace         map[string]string `yaml:"replace" json:"replace"`
+	MinimumVersions map[string]string `yaml:"minimum_versions" json:"minimum_versions"`
+}
+
+// GoModEdit is an edit of the go.mod file.
+type GoModEdit struct {
+	Action string
+	Module string
+	From   string
+	To     string
+}
+
+// String returns a description of the edit.
+func (e GoModEdit) String() string {
+	switch e.Action {
+	case GoModDropRequire:
+		return fmt.Sprintf("dropped unused blocked require %s %s", e.Module, e.From)
+	case GoModAddReplace:
+		return fmt.Sprintf("replaced %s with %s", e.Module, e.To)
+	default:
+		return fmt.Sprintf("pinned %s from %s to %s", e.Module, e.From, e.To)
+	}
+}
+
+// validateGoModPolicy returns an error if a replacement or minimum version is
+// malformed.
+func validateGoModPolicy(config *Configuration) error {
+	for modulePath, replacement := range config.GoMod.Replace {
+		if _, _, ok := parseGoModReplacement(replacement); !ok {
+			return fmt.Errorf(errInvalidGoModReplace, strings.TrimSpace(modulePath), replacement)
+		}
+	}
+
+	for modulePath, version := range config.GoMod.MinimumVersions {
+		if !semver.IsValid(strings.TrimSpace(version)) {
+			return fmt.Errorf(errInvalidGoModMinimum, strings.TrimSpace(modulePath), version)
+		}
+	}
+
+	return nil
+}
+
+// parseGoModReplacement returns the path and version of a replacement. Local
+// paths have no version.
+func parseGoModReplacement(replacement string) (string, string, bool) {
+	fields := strings.Fields(replacement)
+
+	switch {
+	case len(fields) == 1 && modfile.IsDirectoryPath(fields[0]):
+		return fields[0], "", true
+	case len(fields) == 2 && semver.IsValid(fields[1]) && module.CheckPath(fields[0]) == nil:
+		return fields[0], fields[1], true
+	default:
+		return "", "", false
+	}
+}
+
+// FixGoMod returns the go.mod file with the edits mandated by the policy
+// applied and the edits. Direct requires of blocked modules that are not
+// imported by the processed files are dropped, so files must be processed
+// first. Mandated replacements are added for required modules and required
+// versions are raised to their minimum version or pinned to the version of the
+// bill of materials.
+func (p *Processor) FixGoMod(filename string, data []byte) ([]byte, []GoModEdit, error) {
+	modFile, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errFixingGoMod, filename, err)
+	}
+
+	edits := []GoModEdit{}
+	required := map[string]string{}
+	minimumVersions := trimAllKeys(p.Config.GoMod.MinimumVersions)
+	replacements := trimAllKeys(p.Config.GoMod.Replace)
+
+	for _, require := range append([]*modfile.Require{}, modFile.Require...) {
+		modulePath, version := require.Mod.Path, require.Mod.Version
+
+		if !require.Indirect && p.importCounts[modulePath] == 0 && p.isBlockedRequire(modulePath) {
+			err = modFile.DropRequire(modulePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf(errFixingGoMod, filename, err)
+			}
+
+			edits = append(edits, GoModEdit{Action: GoModDropRequire, Module: modulePath, From: version})
+
+			continue
+		}
+
+		pinned := version
+
+		if minimum, ok := minimumVersions[modulePath]; ok && semver.Compare(version, minimum) < 0 {
+			pinned = minimum
+		}
+
+		if mandated, ok := p.bom.Version(p.Config.CanonicalModulePath(modulePath)); ok && !require.Indirect {
+			pinned = mandated
+		}
+
+		if pinned != version {
+			err = modFile.AddRequire(modulePath, pinned)
+			if err != nil {
+				return nil, nil, fmt.Errorf(errFixingGoMod, filename, err)
+			}
+
+			edits = append(edits, GoModEdit{Action: GoModPinVersion, Module: modulePath, From: version, To: pinned})
+		}
+
+		required[modulePath] = pinned
+	}
+
+	replaced := make([]string, 0, len(replacements))
+	for modulePath := range replacements {
+		replaced = append(replaced, modulePath)
+	}
+
+	sort.Strings(replaced)
+
+	for _, modulePath := range replaced {
+		replacement := replacements[modulePath]
+
+		if _, ok := required[modulePath]; !ok {
+			continue
+		}
+
+		newPath, newVersion, ok := parseGoModReplacement(replacement)
+		if !ok || hasReplace(modFile, modulePath, newPath, newVersion) {
+			continue
+		}
+
+		err = modFile.AddReplace(modulePath, "", newPath, newVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf(errFixingGoMod, filename, err)
+		}
+
+		edits = append(edits, GoModEdit{Action: GoModAddReplace, Module: modulePath, To: strings.TrimSpace(newPath + " " + newVersion)})
+	}
+
+	if len(edits) == 0 {
+		return data, edits, nil
+	}
+
+	modFile.Cleanup()
+
+	out, err := modFile.Format()
+	if err != nil {
+		return nil, nil, fmt.Errorf(errFixingGoMod, filename, err)
+	}
+
+	return out, edits, nil
+}
+
+// isBlockedRequire returns true if the required module is blocked with an
+// error.
+func (p *Processor) isBlockedRequire(modulePath string) bool {
+	for _, reason := range p.blockedModulesFromModFile[modulePath] {
+		if reason.severity != SeverityWarning {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasReplace returns true if all versions of the module are already replaced
+// by the replacement.
+func hasReplace(modFile *modfile.File, modulePath, newPath, newVersion string) bool {
+	for _, replace := range modFile.Replace {
+		if replace.Old.Path == modulePath && replace.Old.Version == "" &&
+			replace.New.Path == newPath && replace.New.Version == newVersion {
+			return true
+		}
+	}
+
+	return false
+}
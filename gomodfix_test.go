@@ -0,0 +1,126 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/module"
+)
+
+func TestProcessorFixGoMod(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/used\"\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goMod := `module github.com/ryancurrah/example
+
+go 1.14
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/baz v1.5.0
+	github.com/foo/unused v1.0.0
+	github.com/foo/used v1.0.0
+)
+`
+
+	requires := []module.Version{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/foo/baz", Version: "v1.5.0"},
+		{Path: "github.com/foo/unused", Version: "v1.0.0"},
+		{Path: "github.com/foo/used", Version: "v1.0.0"},
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{
+			Modules: gomodguard.BlockedModules{
+				{"github.com/foo/unused": gomodguard.BlockedModule{}},
+				{"github.com/foo/used": gomodguard.BlockedModule{}},
+			},
+		},
+		GoMod: gomodguard.GoModPolicy{
+			Replace:         map[string]string{"github.com/foo/bar": "github.com/myorg/bar v1.0.1", "github.com/foo/other": "../other"},
+			MinimumVersions: map[string]string{" github.com/foo/bar ": "v1.2.0", "github.com/foo/baz": "v1.2.0"},
+		},
+	}, "github.com/ryancurrah/example", requires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor.ProcessFiles([]string{filename})
+
+	out, edits, err := processor.FixGoMod("go.mod", []byte(goMod))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantEdits := []gomodguard.GoModEdit{
+		{Action: gomodguard.GoModPinVersion, Module: "github.com/foo/bar", From: "v1.0.0", To: "v1.2.0"},
+		{Action: gomodguard.GoModDropRequire, Module: "github.com/foo/unused", From: "v1.0.0"},
+		{Action: gomodguard.GoModAddReplace, Module: "github.com/foo/bar", To: "github.com/myorg/bar v1.0.1"},
+	}
+
+	if !reflect.DeepEqual(edits, wantEdits) {
+		t.Errorf("got '%+v' want '%+v'", edits, wantEdits)
+	}
+
+	for _, want := range []string{"github.com/foo/bar v1.2.0\n", "github.com/foo/baz v1.5.0\n", "github.com/foo/used v1.0.0\n", "replace github.com/foo/bar => github.com/myorg/bar v1.0.1\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("got '%s' want it to contain '%s'", out, want)
+		}
+	}
+
+	if strings.Contains(string(out), "github.com/foo/unused") {
+		t.Errorf("got '%s' want github.com/foo/unused dropped", out)
+	}
+
+	var diff bytes.Buffer
+
+	err = gomodguard.WriteDiff(&diff, "go.mod", []byte(goMod), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"--- go.mod\n+++ go.mod\n", "-\tgithub.com/foo/bar v1.0.0\n", "+\tgithub.com/foo/bar v1.2.0\n", "-\tgithub.com/foo/unused v1.0.0\n"} {
+		if !strings.Contains(diff.String(), want) {
+			t.Errorf("got '%s' want it to contain '%s'", diff.String(), want)
+		}
+	}
+}
+
+func TestValidateGoModPolicy(t *testing.T) {
+	var tests = []struct {
+		testName string
+		policy   gomodguard.GoModPolicy
+		wantErr  bool
+	}{
+		{"local replacement", gomodguard.GoModPolicy{Replace: map[string]string{"github.com/foo/bar": "./bar"}}, false},
+		{"module replacement", gomodguard.GoModPolicy{Replace: map[string]string{"github.com/foo/bar": "github.com/myorg/bar v1.0.0"}}, false},
+		{"replacement without version", gomodguard.GoModPolicy{Replace: map[string]string{"github.com/foo/bar": "github.com/myorg/bar"}}, true},
+		{"invalid minimum version", gomodguard.GoModPolicy{MinimumVersions: map[string]string{"github.com/foo/bar": "1.0"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{GoMod: tt.policy}, "github.com/ryancurrah/example", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got '%v' want error '%v'", err, tt.wantErr)
+			}
+		})
+	}
+}
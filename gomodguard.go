@@ -1,37 +1,141 @@
 package gomodguard
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"go/parser"
+	"go/ast"
 	"go/token"
+	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/Masterminds/semver"
+	"github.com/ryancurrah/gomodguard/match"
 
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 const (
-	goModFilename       = "go.mod"
-	errReadingGoModFile = "unable to read go mod file %s: %w"
-	errParsingGoModFile = "unable to parsing go mod file %s: %w"
+	goModFilename            = "go.mod"
+	errReadingGoModFile      = "unable to read go mod file %s: %w"
+	errParsingGoModFile      = "unable to parsing go mod file %s: %w"
+	errInvalidAllowedPattern = "invalid allowed pattern %s: %w"
+	errInvalidEnforceAfter   = "invalid enforce_after %s of %s, must be a date in the format YYYY-MM-DD"
+	enforceAfterLayout       = "2006-01-02"
 )
 
 var (
 	blockReasonNotInAllowedList         = "import of package `%s` is blocked because the module is not in the allowed modules list."
 	blockReasonInBlockedList            = "import of package `%s` is blocked because the module is in the blocked modules list."
 	blockReasonHasLocalReplaceDirective = "import of package `%s` is blocked because the module has a local replace directive."
+	blockReasonDisallowedReplace        = "import of package `%s` is blocked because the module is replaced by `%s`, which is not allowed."
 )
 
+// Severity of a lint result.
+type Severity string
+
+const (
+	// SeverityError is used for results that fail the lint.
+	SeverityError Severity = "error"
+	// SeverityWarning is used for results that are reported but do not fail the lint.
+	SeverityWarning Severity = "warning"
+)
+
+// isEnforced returns true if the enforce after date is empty or is on or
+// before now. Processors reject invalid dates with validateEnforceAfter,
+// entries checked without a processor treat them as enforced.
+func isEnforced(enforceAfter string, now time.Time) bool {
+	if strings.TrimSpace(enforceAfter) == "" {
+		return true
+	}
+
+	date, err := time.Parse(enforceAfterLayout, strings.TrimSpace(enforceAfter))
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(date)
+}
+
+// validateEnforceAfter returns an error if an enforce after date of a blocked
+// entry or rule is not a date in the enforceAfterLayout.
+func validateEnforceAfter(config *Configuration) error {
+	for _, modules := range []BlockedModules{config.Blocked.Modules, config.Blocked.Regex, config.Generated.Modules} {
+		for i := range modules {
+			for name, blockedModule := range modules[i] {
+				err := validateEnforceAfterDate(blockedModule.EnforceAfter, name)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := range config.Blocked.Versions {
+		for name, blockedVersion := range config.Blocked.Versions[i] {
+			err := validateEnforceAfterDate(blockedVersion.EnforceAfter, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := validateEnforceAfterDate(config.Blocked.Scorecard.EnforceAfter, "blocked.scorecard")
+	if err != nil {
+		return err
+	}
+
+	return validateEnforceAfterDate(config.Blocked.DepsDev.EnforceAfter, "blocked.deps_dev")
+}
+
+// validateEnforceAfterDate returns an error if the enforce after date is set
+// and not a date in the enforceAfterLayout.
+func validateEnforceAfterDate(enforceAfter, name string) error {
+	if strings.TrimSpace(enforceAfter) == "" {
+		return nil
+	}
+
+	_, err := time.Parse(enforceAfterLayout, strings.TrimSpace(enforceAfter))
+	if err != nil {
+		return fmt.Errorf(errInvalidEnforceAfter, enforceAfter, name)
+	}
+
+	return nil
+}
+
+// severity returns SeverityError when enforced otherwise SeverityWarning.
+func severity(enforced bool) Severity {
+	if enforced {
+		return SeverityError
+	}
+
+	return SeverityWarning
+}
+
 // BlockedVersion has a version constraint a reason why the the module version is blocked.
 type BlockedVersion struct {
-	Version string `yaml:"version"`
-	Reason  string `yaml:"reason"`
+	Version       string `yaml:"version" json:"version"`
+	Reason        string `yaml:"reason" json:"reason"`
+	CustomMessage string `yaml:"message" json:"message"`
+	EnforceAfter  string `yaml:"enforce_after" json:"enforce_after"`
+	Owner         string `yaml:"owner" json:"owner"`
+	PolicyURL     string `yaml:"policy_url" json:"policy_url"`
+}
+
+// IsEnforced returns true if the blocked version is enforced at the given time.
+// Before the enforce after date (YYYY-MM-DD) matches are reported as warnings.
+func (r *BlockedVersion) IsEnforced(now time.Time) bool {
+	return isEnforced(r.EnforceAfter, now)
 }
 
 // IsLintedModuleVersionBlocked returns true if a version constraint is specified and the
@@ -75,8 +179,30 @@ func (r *BlockedVersion) Message(lintedModuleVersion string) string {
 
 // BlockedModule has alternative modules to use and a reason why the module is blocked.
 type BlockedModule struct {
-	Recommendations []string `yaml:"recommendations"`
-	Reason          string   `yaml:"reason"`
+	Recommendations []string          `yaml:"recommendations" json:"recommendations"`
+	Reason          string            `yaml:"reason" json:"reason"`
+	CustomMessage   string            `yaml:"message" json:"message"`
+	EnforceAfter    string            `yaml:"enforce_after" json:"enforce_after"`
+	Owner           string            `yaml:"owner" json:"owner"`
+	Docs            string            `yaml:"docs" json:"docs"`
+	PolicyURL       string            `yaml:"policy_url" json:"policy_url"`
+	Automatable     bool              `yaml:"automatable" json:"automatable"`
+	Mapping         map[string]string `yaml:"mapping" json:"mapping"`
+}
+
+// Replacement describes how to migrate away from a blocked module. Mapping
+// maps package paths of the blocked module to their replacement package paths.
+type Replacement struct {
+	Modules     []string          `json:"modules"`
+	Docs        string            `json:"docs,omitempty"`
+	Automatable bool              `json:"automatable"`
+	Mapping     map[string]string `json:"mapping,omitempty"`
+}
+
+// IsEnforced returns true if the blocked module is enforced at the given time.
+// Before the enforce after date (YYYY-MM-DD) matches are reported as warnings.
+func (r *BlockedModule) IsEnforced(now time.Time) bool {
+	return isEnforced(r.EnforceAfter, now)
 }
 
 // IsCurrentModuleARecommendation returns true if the current module is in the Recommendations list.
@@ -90,7 +216,7 @@ func (r *BlockedModule) IsCurrentModuleARecommendation(currentModuleName string)
 	}
 
 	for n := range r.Recommendations {
-		if strings.TrimSpace(currentModuleName) == strings.TrimSpace(r.Recommendations[n]) {
+		if match.AnyMajorVersion(r.Recommendations[n], currentModuleName, match.Exact) {
 			return true
 		}
 	}
@@ -116,20 +242,59 @@ func (r *BlockedModule) Message() string {
 		}
 	}
 
-	if r.Reason == "" {
-		return msg
+	// Add reason to message
+	if r.Reason != "" {
+		msg = strings.TrimSpace(fmt.Sprintf("%s %s.", msg, strings.TrimRight(r.Reason, ".")))
 	}
 
-	// Add reason to message
-	if msg == "" {
-		msg = fmt.Sprintf("%s.", strings.TrimRight(r.Reason, "."))
-	} else {
-		msg += fmt.Sprintf(" %s.", strings.TrimRight(r.Reason, "."))
+	// Add migration guide to message
+	if r.Docs != "" {
+		msg = strings.TrimSpace(fmt.Sprintf("%s See %s for a migration guide.", msg, r.Docs))
 	}
 
 	return msg
 }
 
+// Replacement returns how to migrate away from the blocked module,
+// nil if no recommendations, docs or mapping are configured.
+func (r *BlockedModule) Replacement() *Replacement {
+	if r == nil || (len(r.Recommendations) == 0 && r.Docs == "" && len(r.Mapping) == 0) {
+		return nil
+	}
+
+	return &Replacement{
+		Modules:     r.Recommendations,
+		Docs:        r.Docs,
+		Automatable: r.Automatable,
+		Mapping:     r.Mapping,
+	}
+}
+
+// PackagePath returns the replacement of the package path from the mapping.
+// The longest mapped path the package is under is replaced, so mapping a
+// package also maps its sub packages.
+func (r *Replacement) PackagePath(packagePath string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+
+	longest := ""
+
+	for from := range r.Mapping {
+		if match.Prefix(from, packagePath) && len(strings.TrimSpace(from)) > len(strings.TrimSpace(longest)) {
+			longest = from
+		}
+	}
+
+	if longest == "" {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(packagePath), strings.TrimSpace(longest))
+
+	return strings.TrimSpace(r.Mapping[longest]) + rest, true
+}
+
 // HasRecommendations returns true if the blocked package has
 // recommended modules.
 func (r *BlockedModule) HasRecommendations() bool {
@@ -158,16 +323,24 @@ func (b BlockedVersions) Get() []string {
 }
 
 // GetBlockReason returns a block version if one is set for the provided linted module name.
+// Entries only apply to their own major version.
 func (b BlockedVersions) GetBlockReason(lintedModuleName string) *BlockedVersion {
+	_, blockedVersion := b.lookup(lintedModuleName)
+	return blockedVersion
+}
+
+// lookup returns the name and the blocked version of the entry matching the
+// linted module.
+func (b BlockedVersions) lookup(lintedModuleName string) (string, *BlockedVersion) {
 	for _, blockedModule := range b {
 		for blockedModuleName, blockedVersion := range blockedModule {
-			if strings.TrimSpace(lintedModuleName) == strings.TrimSpace(blockedModuleName) {
-				return &blockedVersion
+			if match.Exact(blockedModuleName, lintedModuleName) {
+				return blockedModuleName, &blockedVersion
 			}
 		}
 	}
 
-	return nil
+	return "", nil
 }
 
 // BlockedModules a list of blocked modules.
@@ -188,32 +361,103 @@ func (b BlockedModules) Get() []string {
 }
 
 // GetBlockReason returns a block module if one is set for the provided linted module name.
+// Entries only apply to their own major version.
 func (b BlockedModules) GetBlockReason(lintedModuleName string) *BlockedModule {
+	_, blockedModule := b.lookup(lintedModuleName)
+	return blockedModule
+}
+
+// lookup returns the name and the blocked module of the entry matching the
+// linted module.
+func (b BlockedModules) lookup(lintedModuleName string) (string, *BlockedModule) {
 	for _, blockedModule := range b {
 		for blockedModuleName, blockedModule := range blockedModule {
-			if strings.TrimSpace(lintedModuleName) == strings.TrimSpace(blockedModuleName) {
-				return &blockedModule
+			if match.Exact(blockedModuleName, lintedModuleName) {
+				return blockedModuleName, &blockedModule
 			}
 		}
 	}
 
-	return nil
+	return "", nil
+}
+
+// blockedEntry is the position of an entry in a blocked list.
+type blockedEntry struct {
+	index int
+	name  string
+}
+
+// blockedIndex maps the normalized module paths of the entries of a blocked
+// list to their position, of entries listed more than once the first.
+type blockedIndex map[string]blockedEntry
+
+// newBlockedIndex returns the index of the entries of a blocked list, given
+// the names of the entries at each position.
+func newBlockedIndex(names [][]string) blockedIndex {
+	index := blockedIndex{}
+
+	for i := range names {
+		sort.Strings(names[i])
+
+		for _, name := range names[i] {
+			if _, ok := index[match.Normalize(name)]; !ok {
+				index[match.Normalize(name)] = blockedEntry{index: i, name: name}
+			}
+		}
+	}
+
+	return index
+}
+
+// lookup returns the position of the entry matching the linted module, like
+// the lookup of the blocked lists.
+func (b blockedIndex) lookup(lintedModuleName string) (blockedEntry, bool) {
+	entry, ok := b[match.Normalize(lintedModuleName)]
+	return entry, ok
+}
+
+// index returns the index of the blocked modules.
+func (b BlockedModules) index() blockedIndex {
+	names := make([][]string, len(b))
+	for i := range b {
+		for name := range b[i] {
+			names[i] = append(names[i], name)
+		}
+	}
+
+	return newBlockedIndex(names)
+}
+
+// index returns the index of the blocked versions.
+func (b BlockedVersions) index() blockedIndex {
+	names := make([][]string, len(b))
+	for i := range b {
+		for name := range b[i] {
+			names[i] = append(names[i], name)
+		}
+	}
+
+	return newBlockedIndex(names)
 }
 
 // Allowed is a list of modules and module
 // domains that are allowed to be used.
 type Allowed struct {
-	Modules []string `yaml:"modules"`
-	Domains []string `yaml:"domains"`
+	Modules   []string      `yaml:"modules" json:"modules"`
+	Domains   []string      `yaml:"domains" json:"domains"`
+	Checksums []string      `yaml:"checksums" json:"checksums"`
+	Rules     []AllowedRule `yaml:"rules" json:"rules"`
+	Regex     []string      `yaml:"regex" json:"regex"`
 }
 
 // IsAllowedModule returns true if the given module
-// name is in the allowed modules list.
+// name is in the allowed modules list or matches a glob pattern of it.
+// Allowed modules without a major version suffix allow all major versions.
 func (a *Allowed) IsAllowedModule(moduleName string) bool {
 	allowedModules := a.Modules
 
 	for i := range allowedModules {
-		if strings.TrimSpace(moduleName) == strings.TrimSpace(allowedModules[i]) {
+		if match.AnyMajorVersion(allowedModules[i], moduleName, match.Glob) {
 			return true
 		}
 	}
@@ -222,12 +466,40 @@ func (a *Allowed) IsAllowedModule(moduleName string) bool {
 }
 
 // IsAllowedModuleDomain returns true if the given modules domain is
-// in the allowed module domains list.
+// in the allowed module domains list or matches a glob pattern of it.
 func (a *Allowed) IsAllowedModuleDomain(moduleName string) bool {
 	allowedDomains := a.Domains
 
 	for i := range allowedDomains {
-		if strings.HasPrefix(strings.TrimSpace(strings.ToLower(moduleName)), strings.TrimSpace(strings.ToLower(allowedDomains[i]))) {
+		if match.GlobDomain(allowedDomains[i], moduleName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAllowedPackage returns true if no allowed modules or domains are
+// configured or the package is from an allowed module or domain.
+func (a *Allowed) isAllowedPackage(packageName string) bool {
+	if a.isEmpty() {
+		return true
+	}
+
+	if a.IsAllowedModuleDomain(packageName) {
+		return true
+	}
+
+	for i := range a.Modules {
+		if match.AnyMajorVersion(a.Modules[i], packageName, match.GlobModule) {
+			return true
+		}
+	}
+
+	// Packages are not versioned, only rules without predicates on the
+	// version or metadata can allow them.
+	for i := range a.Rules {
+		if a.Rules[i].Version == "" && !a.Rules[i].needsMetadata() && match.Domain(a.Rules[i].Path, packageName) {
 			return true
 		}
 	}
@@ -235,47 +507,196 @@ func (a *Allowed) IsAllowedModuleDomain(moduleName string) bool {
 	return false
 }
 
+// isEmpty returns true if nothing is allowed explicitly.
+func (a *Allowed) isEmpty() bool {
+	return len(a.Modules) == 0 && len(a.Domains) == 0 && len(a.Checksums) == 0 && len(a.Rules) == 0 && len(a.Regex) == 0
+}
+
 // Blocked is a list of modules that are
 // blocked and not to be used.
 type Blocked struct {
-	Modules                BlockedModules  `yaml:"modules"`
-	Versions               BlockedVersions `yaml:"versions"`
-	LocalReplaceDirectives bool            `yaml:"local_replace_directives"`
+	Modules                BlockedModules  `yaml:"modules" json:"modules"`
+	ModulesURL             string          `yaml:"modules_url" json:"modules_url"`
+	Regex                  BlockedModules  `yaml:"regex" json:"regex"`
+	Versions               BlockedVersions `yaml:"versions" json:"versions"`
+	LocalReplaceDirectives bool            `yaml:"local_replace_directives" json:"local_replace_directives"`
+	Replaces               Replaces        `yaml:"replaces" json:"replaces"`
+	GoGenerate             bool            `yaml:"go_generate" json:"go_generate"`
+	MajorVersionMismatch   bool            `yaml:"major_version_mismatch" json:"major_version_mismatch"`
+	ConfusableImports      bool            `yaml:"confusable_imports" json:"confusable_imports"`
+	Typosquatting          Typosquatting   `yaml:"typosquatting" json:"typosquatting"`
+	Cooldown               Cooldown        `yaml:"cooldown" json:"cooldown"`
+	SizeBudget             SizeBudget      `yaml:"size_budget" json:"size_budget"`
+	VCS                    string          `yaml:"vcs" json:"vcs"`
+	Scorecard              Scorecard       `yaml:"scorecard" json:"scorecard"`
+	DepsDev                DepsDev         `yaml:"deps_dev" json:"deps_dev"`
+	Popularity             Popularity      `yaml:"popularity" json:"popularity"`
 }
 
 // Configuration of gomodguard allow and block lists.
 type Configuration struct {
-	Allowed Allowed `yaml:"allowed"`
-	Blocked Blocked `yaml:"blocked"`
+	Mode            string              `yaml:"mode" json:"mode"`
+	Allowed         Allowed             `yaml:"allowed" json:"allowed"`
+	Blocked         Blocked             `yaml:"blocked" json:"blocked"`
+	Internal        Internal            `yaml:"internal" json:"internal"`
+	Categories      map[string][]string `yaml:"categories" json:"categories"`
+	Resolution      string              `yaml:"resolution" json:"resolution"`
+	Generated       Generated           `yaml:"generated" json:"generated"`
+	Messages        map[string]string   `yaml:"messages" json:"messages"`
+	Rules           map[string]bool     `yaml:"rules" json:"rules"`
+	GoEnv           map[string]string   `yaml:"go_env" json:"go_env"`
+	Reports         []Report            `yaml:"reports" json:"reports"`
+	Offline         bool                `yaml:"offline" json:"offline"`
+	Owners          map[string]string   `yaml:"owners" json:"owners"`
+	PolicyURLs      map[string]string   `yaml:"policy_urls" json:"policy_urls"`
+	HostAliases     []HostAlias         `yaml:"host_aliases" json:"host_aliases"`
+	FastImports     bool                `yaml:"fast_imports" json:"fast_imports"`
+	FullParse       bool                `yaml:"full_parse" json:"full_parse"`
+	Ratchet         string              `yaml:"ratchet" json:"ratchet"`
+	Baseline        string              `yaml:"baseline" json:"baseline"`
+	BOM             string              `yaml:"bom" json:"bom"`
+	GoMod           GoModPolicy         `yaml:"go_mod" json:"go_mod"`
+	CodeOwners      string              `yaml:"code_owners" json:"code_owners"`
+	SuppressionAges string              `yaml:"suppression_ages" json:"suppression_ages"`
+	AuditFile       string              `yaml:"audit_file" json:"audit_file"`
+	MessagesFile    string              `yaml:"messages_file" json:"messages_file"`
+	Severities      map[string]string   `yaml:"severities" json:"severities"`
+	Profile         string              `yaml:"profile" json:"profile"`
+	Profiles        map[string]Profile  `yaml:"profiles" json:"profiles"`
+	Blame           bool                `yaml:"blame" json:"blame"`
+	DiscoverModules bool                `yaml:"discover_modules" json:"discover_modules"`
+	ModulesOnly     bool                `yaml:"modules_only" json:"modules_only"`
+	GoModPath       string              `yaml:"go_mod_path" json:"go_mod_path"`
+	LintGoMod       bool                `yaml:"lint_go_mod" json:"lint_go_mod"`
+	Indirect        bool                `yaml:"indirect" json:"indirect"`
+	Deep            bool                `yaml:"deep" json:"deep"`
+	OutsideModule   string              `yaml:"outside_module" json:"outside_module"`
+	Symlinks        string              `yaml:"symlinks" json:"symlinks"`
+	Workers         int                 `yaml:"workers" json:"workers"`
+	Metadata        Metadata            `yaml:"metadata" json:"metadata"`
+	Network         Network             `yaml:"network" json:"network"`
+	NonSuppressible []string            `yaml:"non_suppressible" json:"non_suppressible"`
+
+	source *configSource
 }
 
 // Result represents the result of one error.
 type Result struct {
-	FileName   string
-	LineNumber int
-	Position   token.Position
-	Reason     string
+	FileName        string
+	LineNumber      int
+	Position        token.Position
+	Reason          string
+	Severity        Severity
+	Rule            string
+	Package         string
+	Module          string
+	Version         string
+	RequirePath     []string
+	Owner           string
+	CodeOwners      []string
+	PolicyURL       string
+	Provenance      *Provenance
+	Replacement     *Replacement
+	Blame           *Blame
+	NonSuppressible bool
 }
 
 // String returns the filename, line
 // number and reason of a Result.
 func (r *Result) String() string {
-	return fmt.Sprintf("%s:%d:1 %s", r.FileName, r.LineNumber, r.Reason)
+	reason := r.Reason
+	if r.Owner != "" {
+		reason += fmt.Sprintf(" (owner: %s)", r.Owner)
+	}
+
+	if len(r.CodeOwners) > 0 {
+		reason += fmt.Sprintf(" (code owners: %s)", strings.Join(r.CodeOwners, " "))
+	}
+
+	if r.Blame != nil {
+		reason += fmt.Sprintf(" (introduced by %s)", r.Blame)
+	}
+
+	if r.PolicyURL != "" {
+		reason += fmt.Sprintf(" (policy: %s)", r.PolicyURL)
+	}
+
+	if r.Severity == SeverityWarning {
+		return fmt.Sprintf("%s:%d:1 %s: %s", r.FileName, r.LineNumber, SeverityWarning, reason)
+	}
+
+	return fmt.Sprintf("%s:%d:1 %s", r.FileName, r.LineNumber, reason)
+}
+
+// IsWarning returns true if the result should not fail the lint.
+func (r *Result) IsWarning() bool {
+	return r.Severity == SeverityWarning
+}
+
+// blockReason is the reason a module is blocked and the severity it is reported with.
+type blockReason struct {
+	rule        string
+	reason      string
+	message     *template.Template
+	severity    Severity
+	data        MessageData
+	requirePath []string
+	replacement *Replacement
+	source      []string
 }
 
 // Processor processes Go files.
 type Processor struct {
 	Config                    *Configuration
 	Modfile                   *modfile.File
-	blockedModulesFromModFile map[string][]string
+	blockedModulesFromModFile map[string][]blockReason
+	messages                  map[string]*template.Template
+	goEnv                     goEnv
+	importCounts              map[string]int
+	moduleDir                 string
+	codeOwners                *codeOwners
+	allowedModules            match.Matcher
+	allowedDomains            match.Matcher
+	requiredModules           match.Matcher
+	blockedModules            blockedIndex
+	blockedVersions           blockedIndex
+	requiredVersions          map[string]module.Version
+	allowedRegex              []*regexp.Regexp
+	blockedRegex              []blockedRegex
+	bom                       BOM
+	fileSet                   *token.FileSet
+	packageImports            map[string]map[string]token.Position
+	mainPackages              map[string]bool
+	goListPackages            map[string]module.Version
+	goListLoaded              bool
+	categoryImports           map[string][]categoryImport
+	fileModuleDirs            map[string]string
+	otherModules              map[string]*Processor
+	networkState              *processorNetwork
+	importGraph               map[string]map[string]token.Position
+	files                     fileSystem
+	collectWarnings           bool
+	warnings                  []string
 	Result                    []Result
+	Exemptions                []Exemption
+	Suppressed                []Result
+	Progress                  io.Writer
+	// MetadataLookup, if set, provides the module metadata instead of
+	// deps.dev or the module cache. It must be safe for concurrent use if
+	// the network concurrency is above 1.
+	MetadataLookup func(moduleName, moduleVersion string) (*ModuleMetadata, error)
+	// ModuleGraph, if set, is the module graph attributing indirect modules
+	// to direct requires instead of go mod graph.
+	ModuleGraph ModuleGraph
 }
 
 // NewProcessor will create a Processor to lint blocked packages.
 func NewProcessor(config *Configuration) (*Processor, error) {
-	goModFileBytes, err := loadGoModFile()
+	env := configGoEnv(config)
+
+	goModFileBytes, err := loadGoModFile(env, config.GoModPath != "")
 	if err != nil {
-		return nil, fmt.Errorf(errReadingGoModFile, goModFilename, err)
+		return nil, fmt.Errorf(errReadingGoModFile, env.modFile(), err)
 	}
 
 	modFile, err := modfile.Parse(goModFilename, goModFileBytes, nil)
@@ -283,10 +704,13 @@ func NewProcessor(config *Configuration) (*Processor, error) {
 		return nil, fmt.Errorf(errParsingGoModFile, goModFilename, err)
 	}
 
-	p := &Processor{
-		Config:  config,
-		Modfile: modFile,
-		Result:  []Result{},
+	p, err := newProcessor(config, env, modFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if modFilename, err := filepath.Abs(env.modFile()); err == nil {
+		p.moduleDir = filepath.Dir(modFilename)
 	}
 
 	p.SetBlockedModules()
@@ -294,178 +718,1210 @@ func NewProcessor(config *Configuration) (*Processor, error) {
 	return p, nil
 }
 
-// ProcessFiles takes a string slice with file names (full paths)
-// and lints them.
-func (p *Processor) ProcessFiles(filenames []string) []Result {
-	for _, filename := range filenames {
-		data, err := ioutil.ReadFile(filename)
-		if err != nil {
-			p.Result = append(p.Result, Result{
-				FileName:   filename,
-				LineNumber: 0,
-				Reason:     fmt.Sprintf("unable to read file, file cannot be linted (%s)", err.Error()),
-			})
-		}
+// NewProcessorFromRequires creates a Processor linting against the given
+// direct module requirements of the module path instead of a go.mod file,
+// for tools computing the dependencies themselves such as build systems.
+func NewProcessorFromRequires(config *Configuration, modulePath string, requires []module.Version) (*Processor, error) {
+	modFile := &modfile.File{Require: make([]*modfile.Require, 0, len(requires))}
 
-		p.process(filename, data)
+	if modulePath != "" {
+		modFile.Module = &modfile.Module{Mod: module.Version{Path: modulePath}}
 	}
 
-	return p.Result
-}
+	for _, require := range requires {
+		modFile.Require = append(modFile.Require, &modfile.Require{Mod: require})
+	}
 
-// process file imports and add lint error if blocked package is imported.
-func (p *Processor) process(filename string, data []byte) {
-	fileSet := token.NewFileSet()
+	return NewProcessorFromModFile(config, modFile)
+}
 
-	file, err := parser.ParseFile(fileSet, filename, data, parser.ParseComments)
+// NewProcessorFromModBytes creates a Processor linting against the contents
+// of a go.mod file instead of reading it from disk, for library users and
+// tests building a Processor in memory.
+func NewProcessorFromModBytes(config *Configuration, goMod []byte) (*Processor, error) {
+	modFile, err := modfile.Parse(goModFilename, goMod, nil)
 	if err != nil {
-		p.Result = append(p.Result, Result{
-			FileName:   filename,
-			LineNumber: 0,
-			Reason:     fmt.Sprintf("invalid syntax, file cannot be linted (%s)", err.Error()),
-		})
-
-		return
+		return nil, fmt.Errorf(errParsingGoModFile, goModFilename, err)
 	}
 
-	imports := file.Imports
-	for n := range imports {
-		importedPkg := strings.TrimSpace(strings.Trim(imports[n].Path.Value, "\""))
+	return NewProcessorFromModFile(config, modFile)
+}
 
-		blockReasons := p.isBlockedPackageFromModFile(importedPkg)
-		if blockReasons == nil {
-			continue
-		}
+// NewProcessorFromModFile creates a Processor linting against an already
+// parsed go.mod file. Files are not classified as outside of the module since
+// the module has no directory.
+func NewProcessorFromModFile(config *Configuration, modFile *modfile.File) (*Processor, error) {
+	if modFile == nil {
+		modFile = &modfile.File{}
+	}
 
-		for _, blockReason := range blockReasons {
-			p.addError(fileSet, imports[n].Pos(), blockReason)
-		}
+	p, err := newProcessor(config, configGoEnv(config), modFile, false)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// addError adds an error for the file and line number for the current token.Pos
-// with the given reason.
-func (p *Processor) addError(fileset *token.FileSet, pos token.Pos, reason string) {
-	position := fileset.Position(pos)
+	p.SetBlockedModules()
 
-	p.Result = append(p.Result, Result{
-		FileName:   position.Filename,
-		LineNumber: position.Line,
-		Position:   position,
-		Reason:     reason,
-	})
+	return p, nil
 }
 
-// SetBlockedModules determines and sets which modules are blocked by reading
-// the go.mod file of the module that is being linted.
-//
-// It works by iterating over the dependant modules specified in the require
-// directive, checking if the module domain or full name is in the allowed list.
-func (p *Processor) SetBlockedModules() { //nolint:gocognit
-	blockedModules := make(map[string][]string, len(p.Modfile.Require))
-	currentModuleName := p.Modfile.Module.Mod.Path
-	lintedModules := p.Modfile.Require
-	replacedModules := p.Modfile.Replace
+// newProcessor returns a processor for the parsed go.mod file without
+// reading the go.mod file or setting the blocked modules. The configuration
+// is resolved in the go environment and its warnings are collected by the
+// processor if collectWarnings is set, otherwise logged. Errors of the
+// configuration are marked as invalid configuration.
+func newProcessor(config *Configuration, env goEnv, modFile *modfile.File, collectWarnings bool) (*Processor, error) {
+	config, warnings, err := config.resolve(env)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-	for i := range lintedModules {
-		if lintedModules[i].Indirect {
-			continue // Do not lint indirect modules.
-		}
+	messages, err := compileMessages(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		lintedModuleName := strings.TrimSpace(lintedModules[i].Mod.Path)
-		lintedModuleVersion := strings.TrimSpace(lintedModules[i].Mod.Version)
+	err = validateEntryMessages(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		var isAllowed bool
+	err = validateRuleFamilies(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		switch {
-		case len(p.Config.Allowed.Modules) == 0 && len(p.Config.Allowed.Domains) == 0:
-			isAllowed = true
-		case p.Config.Allowed.IsAllowedModuleDomain(lintedModuleName):
-			isAllowed = true
-		case p.Config.Allowed.IsAllowedModule(lintedModuleName):
-			isAllowed = true
-		default:
-			isAllowed = false
-		}
+	err = validateSeverities(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		blockModuleReason := p.Config.Blocked.Modules.GetBlockReason(lintedModuleName)
-		blockVersionReason := p.Config.Blocked.Versions.GetBlockReason(lintedModuleName)
+	err = validateMode(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		if !isAllowed && blockModuleReason == nil && blockVersionReason == nil {
-			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], blockReasonNotInAllowedList)
-			continue
-		}
+	err = validateAllowedGlobs(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		if blockModuleReason != nil && !blockModuleReason.IsCurrentModuleARecommendation(currentModuleName) {
-			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], fmt.Sprintf("%s %s", blockReasonInBlockedList, blockModuleReason.Message()))
-		}
+	err = validateGoModPolicy(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-		if blockVersionReason != nil && blockVersionReason.IsLintedModuleVersionBlocked(lintedModuleVersion) {
-			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], fmt.Sprintf("%s %s", blockReasonInBlockedList, blockVersionReason.Message(lintedModuleVersion)))
-		}
+	err = validateCategories(config)
+	if err != nil {
+		return nil, invalidConfig(err)
 	}
 
-	// Replace directives with local paths are blocked.
-	// Filesystem paths found in "replace" directives are represented by a path with an empty version.
-	// https://github.com/golang/mod/blob/bc388b264a244501debfb9caea700c6dcaff10e2/module/module.go#L122-L124
-	if p.Config.Blocked.LocalReplaceDirectives {
-		for i := range replacedModules {
-			replacedModuleOldName := strings.TrimSpace(replacedModules[i].Old.Path)
-			replacedModuleNewName := strings.TrimSpace(replacedModules[i].New.Path)
-			replacedModuleNewVersion := strings.TrimSpace(replacedModules[i].New.Version)
+	err = validateSizeBudget(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-			if replacedModuleNewName != "" && replacedModuleNewVersion == "" {
-				blockedModules[replacedModuleOldName] = append(blockedModules[replacedModuleOldName], blockReasonHasLocalReplaceDirective)
-			}
-		}
+	err = validateResolution(config)
+	if err != nil {
+		return nil, invalidConfig(err)
 	}
 
-	p.blockedModulesFromModFile = blockedModules
-}
+	err = validateOutsideModule(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
+
+	err = validatePolicyURLs(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-// isBlockedPackageFromModFile returns the block reason if the package is blocked.
-func (p *Processor) isBlockedPackageFromModFile(packageName string) []string {
-	for blockedModuleName, blockReasons := range p.blockedModulesFromModFile {
-		if strings.HasPrefix(strings.TrimSpace(packageName), strings.TrimSpace(blockedModuleName)) {
-			formattedReasons := make([]string, 0, len(blockReasons))
+	err = validateEnforceAfter(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-			for _, blockReason := range blockReasons {
-				formattedReasons = append(formattedReasons, fmt.Sprintf(blockReason, packageName))
-			}
+	err = validateSymlinks(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-			return formattedReasons
-		}
+	err = validateWorkers(config)
+	if err != nil {
+		return nil, invalidConfig(err)
 	}
 
-	return nil
-}
+	err = validateNonSuppressible(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-func loadGoModFile() ([]byte, error) {
-	cmd := exec.Command("go", "env", "-json")
-	stdout, _ := cmd.StdoutPipe()
-	_ = cmd.Start()
+	err = validateMetadataProviders(config)
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
 
-	if stdout == nil {
-		return ioutil.ReadFile(goModFilename)
+	err = validateNetwork(config)
+	if err != nil {
+		return nil, invalidConfig(err)
 	}
 
-	buf := new(bytes.Buffer)
-	_, _ = buf.ReadFrom(stdout)
+	p := &Processor{
+		Config:          config,
+		Modfile:         modFile,
+		messages:        messages,
+		goEnv:           env,
+		collectWarnings: collectWarnings,
+		Result:          []Result{},
+	}
 
-	goEnv := make(map[string]string)
+	for _, warning := range warnings {
+		p.warnf("%s", warning)
+	}
 
-	err := json.Unmarshal(buf.Bytes(), &goEnv)
+	_, err = p.vcsRules()
 	if err != nil {
-		return ioutil.ReadFile(goModFilename)
+		return nil, invalidConfig(err)
 	}
 
-	if _, ok := goEnv["GOMOD"]; !ok {
-		return ioutil.ReadFile(goModFilename)
+	p.allowedModules, p.allowedDomains = p.allowedMatchers()
+	p.requiredModules = p.requireMatcher()
+	p.blockedModules, p.blockedVersions = p.blockedIndexes()
+
+	err = p.compileRegexes()
+	if err != nil {
+		return nil, invalidConfig(err)
+	}
+
+	if config.CodeOwners != "" {
+		p.codeOwners, err = readCodeOwners(config.CodeOwners)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.BOM != "" {
+		p.bom, err = ReadBOM(config.BOM)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if _, err := os.Stat(goEnv["GOMOD"]); os.IsNotExist(err) {
+	return p, nil
+}
+
+// ProcessFiles takes a string slice with file names (full paths)
+// and lints them.
+func (p *Processor) ProcessFiles(filenames []string) []Result {
+	results, _ := p.ProcessFilesContext(context.Background(), filenames)
+
+	return results
+}
+
+// ProcessFilesContext lints the files like ProcessFiles until the context is
+// cancelled or its deadline passes. On cancellation the results of the files
+// linted so far are returned with the error of the context, rules spanning
+// packages such as import cycles are not checked for partial runs.
+func (p *Processor) ProcessFilesContext(ctx context.Context, filenames []string) ([]Result, error) {
+	from := len(p.Result)
+
+	err := p.processFiles(ctx, filenames, nil)
+	if err == nil {
+		p.processPackageRules()
+	}
+
+	p.applyExemptions(from)
+	p.emitFindings(p.Result[from:])
+
+	return p.Result, err
+}
+
+// processFiles reads and lints the files. Files are read and parsed by a
+// pool of workers and linted in order, so results do not depend on the
+// number of workers. Files of a run share a file set, positions stay valid
+// for the results. If set, processed is called after each file with its
+// index and processing stops on its error. The error of the context is
+// returned if it is done before all files were linted.
+func (p *Processor) processFiles(ctx context.Context, filenames []string, processed func(i int) error) error {
+	fileSet := token.NewFileSet()
+
+	p.fileSet = fileSet
+	defer func() { p.fileSet = nil }()
+
+	done := make(chan struct{})
+	slots := make(chan struct{}, p.workers())
+	parsed := p.parseFiles(fileSet, filenames, slots, done)
+
+	// Files parsed ahead of a cancelled or failed run are not processed,
+	// their buffers are released here or by the workers still parsing.
+	defer func() {
+		close(done)
+
+		for i := range parsed {
+			releaseParsed(parsed[i])
+		}
+	}()
+
+	for i, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var file parsedFile
+
+		select {
+		case file = <-parsed[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		<-slots
+
+		p.processParsed(ctx, fileSet, filename, file)
+
+		if processed == nil {
+			continue
+		}
+
+		if err := processed(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processParsed lints a file read and parsed by a worker, unless it is
+// outside of the module.
+func (p *Processor) processParsed(ctx context.Context, fileSet *token.FileSet, filename string, file parsedFile) {
+	if file.data != nil {
+		defer releaseBuffer(file.data)
+	}
+
+	if p.processOutsideModule(ctx, filename) {
+		return
+	}
+
+	p.emitProgress(progressStarted, filename, nil)
+	defer p.emitProgress(progressFinished, filename, nil)
+
+	if file.readErr != nil {
+		p.Result = append(p.Result, Result{
+			FileName:   filename,
+			LineNumber: 0,
+			Reason:     fmt.Sprintf("unable to read file, file cannot be linted (%s)", file.readErr.Error()),
+			Severity:   SeverityError,
+			Rule:       ResultReadError,
+			CodeOwners: p.codeOwners.Owners(filename),
+		})
+
+		return
+	}
+
+	p.processSafely(filename, func() {
+		if file.panicked != nil {
+			panic(file.panicked)
+		}
+
+		p.processParsedFile(fileSet, filename, file.file, file.parseErr)
+	})
+}
+
+// processSafely processes the file and converts a panic into a result so
+// that one malformed file never stops the remaining files from being linted.
+func (p *Processor) processSafely(filename string, process func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.Result = append(p.Result, Result{
+				FileName:   filename,
+				LineNumber: 0,
+				Reason:     fmt.Sprintf("internal error, file cannot be linted (%v)", r),
+				Severity:   SeverityError,
+				Rule:       ResultInternalError,
+				CodeOwners: p.codeOwners.Owners(filename),
+			})
+		}
+	}()
+
+	process()
+}
+
+// process file imports and add lint error if blocked package is imported.
+func (p *Processor) process(filename string, data []byte) {
+	fileSet := p.fileSet
+	if fileSet == nil {
+		fileSet = token.NewFileSet()
+	}
+
+	file, err := p.parseFile(fileSet, filename, data)
+	p.processParsedFile(fileSet, filename, file, err)
+}
+
+// processParsedFile lints a parsed file, files that could not be parsed are
+// reported with their syntax error.
+func (p *Processor) processParsedFile(fileSet *token.FileSet, filename string, file *ast.File, err error) {
+	if err != nil {
+		p.Result = append(p.Result, Result{
+			FileName:   filename,
+			LineNumber: 0,
+			Reason:     fmt.Sprintf("invalid syntax, file cannot be linted (%s)", err.Error()),
+			Severity:   SeverityError,
+			Rule:       ResultSyntaxError,
+			CodeOwners: p.codeOwners.Owners(filename),
+		})
+
+		return
+	}
+
+	p.processFile(fileSet, filename, file)
+}
+
+// processFile lints the imports and go:generate directives of a parsed file.
+func (p *Processor) processFile(fileSet *token.FileSet, filename string, file *ast.File) {
+	p.collectExemptions(fileSet, file)
+
+	// Generated files are only checked against the generated profile.
+	generated := p.Config != nil && p.Config.Generated.IsGeneratedFile(filename)
+
+	packagePath, isModulePackage := "", false
+	if p.Config != nil && (p.Config.Internal.IsEnabled() || p.isCategoriesEnabled()) && !generated {
+		packagePath, isModulePackage = p.filePackagePath(filename)
+	}
+
+	importingPackage := externalTestPackagePath(packagePath, filename, file)
+
+	graphPackage, isGraphPackage := "", false
+	if p.importGraph != nil {
+		graphPackage, isGraphPackage = p.filePackagePath(filename)
+		graphPackage = externalTestPackagePath(graphPackage, filename, file)
+	}
+
+	// Test files are not part of binaries.
+	categories := isModulePackage && p.isCategoriesEnabled() && !strings.HasSuffix(filename, "_test.go")
+	if categories {
+		p.recordMainPackage(packagePath, file)
+	}
+
+	imports := file.Imports
+	for n := range imports {
+		importedPkg, err := strconv.Unquote(imports[n].Path.Value)
+		if err != nil {
+			position := fileSet.Position(imports[n].Pos())
+
+			p.Result = append(p.Result, Result{
+				FileName:   position.Filename,
+				LineNumber: position.Line,
+				Position:   position,
+				Reason:     fmt.Sprintf("invalid import path %s, import cannot be linted (%s)", imports[n].Path.Value, err.Error()),
+				Severity:   SeverityError,
+				Rule:       ResultInvalidImport,
+				CodeOwners: p.codeOwners.Owners(position.Filename),
+			})
+
+			continue
+		}
+
+		importedPkg = strings.TrimSpace(importedPkg)
+
+		p.countImport(importedPkg)
+
+		if isGraphPackage {
+			p.recordGraphImport(graphPackage, importedPkg, fileSet.Position(imports[n].Path.Pos()))
+		}
+
+		if generated {
+			for _, r := range p.generatedBlockReasons(importedPkg) {
+				r.data.Package = importedPkg
+				p.addError(fileSet, imports[n].Path.Pos(), r)
+			}
+
+			continue
+		}
+
+		blockReasons := p.isBlockedPackageFromModFile(importedPkg)
+
+		if p.Config != nil && p.Modfile != nil && p.Config.Blocked.MajorVersionMismatch && p.Config.IsRuleEnabled(RuleMajorVersionMismatch) {
+			if r, ok := p.majorVersionBlockReason(importedPkg); ok {
+				r.reason = p.renderReason(r, importedPkg)
+				blockReasons = append(blockReasons, r)
+			}
+		}
+
+		if p.Config != nil && p.Config.Blocked.ConfusableImports && p.Config.IsRuleEnabled(RuleConfusableImport) {
+			if r, ok := p.confusableBlockReason(importedPkg); ok {
+				r.reason = p.renderReason(r, importedPkg)
+				blockReasons = append(blockReasons, r)
+			}
+		}
+
+		if categories {
+			p.recordCategoryImport(packagePath, importedPkg, fileSet.Position(imports[n].Path.Pos()))
+		}
+
+		if isModulePackage {
+			for _, r := range p.internalBlockReasons(packagePath, importingPackage, importedPkg, fileSet.Position(imports[n].Pos())) {
+				r.reason = p.renderReason(r, importedPkg)
+				blockReasons = append(blockReasons, r)
+			}
+		}
+
+		for _, r := range blockReasons {
+			r.data.Package = importedPkg
+			p.addError(fileSet, imports[n].Path.Pos(), r)
+		}
+	}
+
+	if p.Config != nil && p.Config.Blocked.GoGenerate && p.Config.IsRuleFamilyEnabled(RuleFamilyGenerate) && !generated {
+		p.processGoGenerate(fileSet, file)
+	}
+}
+
+// addError adds an error for the file and line number for the current token.Pos
+// with the given block reason. Results of imports are positioned at the
+// import path.
+func (p *Processor) addError(fileset *token.FileSet, pos token.Pos, r blockReason) {
+	position := fileset.Position(pos)
+
+	severity, ok := p.ruleSeverity(r)
+	if !ok {
+		return
+	}
+
+	reason := r.reason
+	if len(r.requirePath) > 0 {
+		reason += fmt.Sprintf(requirePathReason, formatRequirePath(r.requirePath))
+	}
+
+	p.Result = append(p.Result, Result{
+		FileName:    position.Filename,
+		LineNumber:  position.Line,
+		Position:    position,
+		Reason:      reason,
+		Severity:    severity,
+		Rule:        r.rule,
+		Package:     r.data.Package,
+		Module:      r.data.Module,
+		Version:     r.data.Version,
+		RequirePath: r.requirePath,
+		Owner:       p.owner(r),
+		CodeOwners:  p.codeOwners.Owners(position.Filename),
+		PolicyURL:   p.policyURL(r),
+		Provenance:  p.provenance(r),
+		Replacement: r.replacement,
+	})
+}
+
+// ruleSeverity returns the severity of the block reason, as configured for
+// its rule. False is returned if the rule is off.
+func (p *Processor) ruleSeverity(r blockReason) (Severity, bool) {
+	if p.Config == nil {
+		return r.severity, true
+	}
+
+	return p.Config.RuleSeverity(r.rule, r.severity)
+}
+
+// matches returns true if the path matches a pattern of the matcher.
+func matches(m match.Matcher, path string) bool {
+	_, ok := m.Match(path)
+	return ok
+}
+
+// matchesModule returns true if the matcher matches the module path or the
+// path of its logical module, so allowing a module allows all its major
+// versions.
+func matchesModule(m match.Matcher, modulePath string) bool {
+	if matches(m, modulePath) {
+		return true
+	}
+
+	logical := match.TrimMajorVersion(modulePath)
+
+	return logical != strings.TrimSpace(modulePath) && matches(m, logical)
+}
+
+// HasPolicyWork returns true if linting files can produce results. When the
+// go.mod file requires no blocked modules and no file level rules are
+// configured there is nothing to lint, so drivers can skip collecting and
+// reading files entirely.
+func (p *Processor) HasPolicyWork() bool {
+	if len(p.blockedModulesFromModFile) > 0 {
+		return true
+	}
+
+	if p.Config == nil {
+		return false
+	}
+
+	switch {
+	case p.Modfile != nil && p.Config.Blocked.MajorVersionMismatch && p.Config.IsRuleEnabled(RuleMajorVersionMismatch):
+		return true
+	case p.Config.Blocked.GoGenerate && p.Config.IsRuleFamilyEnabled(RuleFamilyGenerate):
+		return true
+	case p.Config.Blocked.ConfusableImports && p.Config.IsRuleEnabled(RuleConfusableImport):
+		return true
+	case p.Config.Internal.IsEnabled() && p.Config.IsRuleFamilyEnabled(RuleFamilyInternal):
+		return true
+	case p.isCategoriesEnabled():
+		return true
+	case p.Config.Generated.IsEnabled() && p.Config.IsRuleEnabled(RuleInBlockedList):
+		return true
+	}
+
+	return false
+}
+
+// SetBlockedModules determines and sets which modules are blocked by reading
+// the go.mod file of the module that is being linted.
+//
+// It works by iterating over the dependant modules specified in the require
+// directive, checking if the module domain or full name is in the allowed list.
+func (p *Processor) SetBlockedModules() { //nolint:gocognit
+	if p.Config == nil || p.Modfile == nil {
+		p.blockedModulesFromModFile = map[string][]blockReason{}
+		return
+	}
+
+	p.warnIgnoredAllowedList()
+
+	blockedModules := make(map[string][]blockReason, len(p.Modfile.Require))
+	now := time.Now()
+	currentModuleName := ""
+
+	if p.Modfile.Module != nil {
+		currentModuleName = p.Config.CanonicalModulePath(p.Modfile.Module.Mod.Path)
+	}
+
+	lintedModules := p.Modfile.Require
+	replacedModules := p.Modfile.Replace
+	vcsRules, _ := p.vcsRules()
+
+	allowedModules, allowedDomains := p.allowedMatchers()
+
+	if p.allowedRegex == nil {
+		_ = p.compileRegexes() // Invalid patterns are reported by NewProcessor.
+	}
+
+	var goSum map[module.Version][]string
+	if len(p.Config.Allowed.Checksums) > 0 {
+		goSum = readGoSum(p.fileSystem(), p.goEnv.goSumFilename())
+	}
+
+	// The metadata rules read from the network are checked after the
+	// loop, in parallel.
+	metadataChecks := []metadataCheck{}
+
+	for i := range lintedModules {
+		if lintedModules[i] == nil || (lintedModules[i].Indirect && !p.Config.Indirect) {
+			continue // Do not lint indirect modules unless configured.
+		}
+
+		lintedModuleName := strings.TrimSpace(lintedModules[i].Mod.Path)
+		lintedModuleVersion := strings.TrimSpace(lintedModules[i].Mod.Version)
+
+		// Policies are written against canonical hosts, mirror hosts are
+		// matched as their canonical host.
+		canonicalModuleName := p.Config.CanonicalModulePath(lintedModuleName)
+
+		isAllowed := p.isAllowedModule(canonicalModuleName, module.Version{Path: lintedModuleName, Version: lintedModuleVersion}, goSum)
+
+		blockModuleName, blockModuleReason := p.lookupBlockedModule(canonicalModuleName)
+		blockModuleSource := []string{"blocked", "modules", blockModuleName}
+
+		if blockModuleReason == nil {
+			blockModuleName, blockModuleReason = p.blockedRegexModule(canonicalModuleName)
+			blockModuleSource = []string{"blocked", "regex", blockModuleName}
+		}
+
+		blockVersionName, blockVersionReason := p.lookupBlockedVersion(canonicalModuleName)
+
+		if target, ok := p.moduleReplacement(lintedModules[i].Mod); ok {
+			allowedTarget := p.isAllowedReplaceTarget(target, goSum)
+
+			// The code of the replacement is built instead of the module.
+			if allowedTarget && p.Config.Blocked.Replaces.SatisfiedByAllowed {
+				isAllowed, blockModuleReason, blockVersionReason = true, nil, nil
+			}
+
+			if !allowedTarget && target.Version != "" && p.Config.Blocked.Replaces.DisallowedTargets {
+				blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], blockReason{
+					rule:     RuleDisallowedReplace,
+					reason:   fmt.Sprintf(blockReasonDisallowedReplace, "%s", escapeReason(target.Path+" "+target.Version)),
+					severity: SeverityError,
+					data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+				})
+			}
+		}
+
+		if p.Config.Blocked.Typosquatting.IsEnabled() && p.Config.IsRuleEnabled(RuleTyposquat) &&
+			!matches(allowedDomains, canonicalModuleName) && !matchesModule(allowedModules, canonicalModuleName) {
+			if reason, ok := p.typosquatBlockReason(canonicalModuleName, lintedModuleVersion); ok {
+				blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], reason)
+			}
+		}
+
+		if !isAllowed && blockModuleReason == nil && blockVersionReason == nil {
+			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], blockReason{
+				rule:     RuleNotInAllowedList,
+				reason:   blockReasonNotInAllowedList,
+				severity: SeverityError,
+				data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+			})
+
+			continue
+		}
+
+		if blockModuleReason != nil && !blockModuleReason.IsCurrentModuleARecommendation(currentModuleName) {
+			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], blockReason{
+				rule:     RuleInBlockedList,
+				reason:   fmt.Sprintf("%s %s", blockReasonInBlockedList, escapeReason(blockModuleReason.Message())),
+				message:  entryMessage(lintedModuleName, blockModuleReason.CustomMessage),
+				severity: severity(blockModuleReason.IsEnforced(now)),
+				data: MessageData{
+					Module:          lintedModuleName,
+					Version:         lintedModuleVersion,
+					Reason:          blockModuleReason.Reason,
+					Recommendations: blockModuleReason.Recommendations,
+					Owner:           blockModuleReason.Owner,
+					Docs:            blockModuleReason.Docs,
+					PolicyURL:       blockModuleReason.PolicyURL,
+				},
+				replacement: p.Config.preferredReplacement(blockModuleReason.Replacement(), canonicalModuleName, lintedModuleName),
+				source:      blockModuleSource,
+			})
+		}
+
+		if blockVersionReason != nil && blockVersionReason.IsLintedModuleVersionBlocked(lintedModuleVersion) {
+			blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], blockReason{
+				rule:     RuleBlockedVersion,
+				reason:   fmt.Sprintf("%s %s", blockReasonInBlockedList, escapeReason(blockVersionReason.Message(lintedModuleVersion))),
+				message:  entryMessage(lintedModuleName, blockVersionReason.CustomMessage),
+				severity: severity(blockVersionReason.IsEnforced(now)),
+				data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion, Reason: blockVersionReason.Reason, Owner: blockVersionReason.Owner, PolicyURL: blockVersionReason.PolicyURL},
+				source:   []string{"blocked", "versions", blockVersionName},
+			})
+		}
+
+		if vcsRules != nil {
+			private := module.MatchPrefixPatterns(p.goEnv["GOPRIVATE"], lintedModuleName)
+			if reason, ok := vcsBlockReason(vcsRules, lintedModuleName, lintedModuleVersion, private); ok {
+				blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], reason)
+			}
+		}
+
+		if p.bom != nil && p.Config.IsRuleEnabled(RuleBOMDrift) {
+			if reason, ok := p.bomBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion); ok {
+				blockedModules[lintedModuleName] = append(blockedModules[lintedModuleName], reason)
+			}
+		}
+
+		// Only the requirements of modules not reported themselves are
+		// checked in deep mode.
+		deep := p.Config.Deep && p.Config.IsRuleEnabled(RuleBlockedRequirement) && len(blockedModules[lintedModuleName]) == 0
+
+		metadataChecks = append(metadataChecks, metadataCheck{
+			moduleName: lintedModuleName,
+			check: func() []blockReason {
+				blockReasons := p.metadataBlockReasons(canonicalModuleName, lintedModuleName, lintedModuleVersion, now)
+
+				if deep {
+					blockReasons = append(blockReasons, p.requirementBlockReasons(canonicalModuleName, lintedModuleName, lintedModuleVersion, now)...)
+				}
+
+				return blockReasons
+			},
+		})
+	}
+
+	for _, check := range p.runMetadataChecks(metadataChecks) {
+		blockedModules[check.moduleName] = append(blockedModules[check.moduleName], check.reasons...)
+	}
+
+	if p.Config.Indirect {
+		p.setRequirePaths(blockedModules)
+	}
+
+	// Replace directives with local paths, outside of the allowed local paths, are blocked.
+	// Filesystem paths found in "replace" directives are represented by a path with an empty version.
+	// https://github.com/golang/mod/blob/bc388b264a244501debfb9caea700c6dcaff10e2/module/module.go#L122-L124
+	if p.Config.Blocked.LocalReplaceDirectives {
+		for i := range replacedModules {
+			if replacedModules[i] == nil {
+				continue
+			}
+
+			replacedModuleOldName := strings.TrimSpace(replacedModules[i].Old.Path)
+			replacedModuleNewName := strings.TrimSpace(replacedModules[i].New.Path)
+			replacedModuleNewVersion := strings.TrimSpace(replacedModules[i].New.Version)
+
+			if replacedModuleNewName != "" && replacedModuleNewVersion == "" && !p.isAllowedLocalReplacePath(replacedModuleNewName) {
+				blockedModules[replacedModuleOldName] = append(blockedModules[replacedModuleOldName], blockReason{
+					rule:     RuleLocalReplaceDirective,
+					reason:   blockReasonHasLocalReplaceDirective,
+					severity: SeverityError,
+					data:     MessageData{Module: replacedModuleOldName, Version: strings.TrimSpace(replacedModules[i].Old.Version)},
+				})
+			}
+		}
+	}
+
+	for moduleName, blockReasons := range blockedModules {
+		enabledReasons := make([]blockReason, 0, len(blockReasons))
+
+		for _, r := range blockReasons {
+			if p.Config.IsRuleEnabled(r.rule) {
+				enabledReasons = append(enabledReasons, r)
+			}
+		}
+
+		if len(enabledReasons) == 0 {
+			delete(blockedModules, moduleName)
+			continue
+		}
+
+		blockedModules[moduleName] = enabledReasons
+	}
+
+	p.blockedModulesFromModFile = blockedModules
+}
+
+// isAllowedModule returns true if the module is allowed by the allowed lists,
+// or if no allowed lists apply in the block list mode.
+func (p *Processor) isAllowedModule(canonicalModuleName string, mod module.Version, goSum map[module.Version][]string) bool {
+	allowedModules, allowedDomains := p.allowedMatchers()
+
+	switch {
+	case p.Config.IsBlockListMode():
+		return true
+	case matches(allowedDomains, canonicalModuleName):
+		return true
+	case matchesModule(allowedModules, canonicalModuleName):
+		return true
+	case goSum != nil && p.Config.Allowed.IsAllowedChecksum(p.moduleChecksums(goSum, mod)):
+		return true
+	case p.isAllowedByRule(mod):
+		return true
+	default:
+		return p.isAllowedByRegex(canonicalModuleName)
+	}
+}
+
+// metadataBlockReasons returns the block reasons of the metadata rules
+// reading from the module proxy, the module cache and metadata services.
+func (p *Processor) metadataBlockReasons(canonicalModuleName, lintedModuleName, lintedModuleVersion string, now time.Time) []blockReason {
+	blockReasons := []blockReason{}
+
+	if p.Config.Blocked.Cooldown.IsEnabled() && p.Config.IsRuleEnabled(RuleCooldown) {
+		if reason, ok := p.cooldownBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion, now); ok {
+			blockReasons = append(blockReasons, reason)
+		}
+	}
+
+	if p.Config.Blocked.SizeBudget.IsEnabled() && p.Config.IsRuleEnabled(RuleSizeBudget) {
+		if reason, ok := p.sizeBudgetBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion); ok {
+			blockReasons = append(blockReasons, reason)
+		}
+	}
+
+	// Offline the deps.dev rules fall back to the module cache, other
+	// metadata rules are skipped.
+	if p.isOffline() {
+		if p.Config.Blocked.DepsDev.IsEnabled() && (p.Config.IsRuleEnabled(RuleLicense) || p.Config.IsRuleEnabled(RuleDepsDev)) {
+			blockReasons = append(blockReasons, p.depsDevBlockReasons(lintedModuleName, lintedModuleVersion, now)...)
+		}
+
+		return blockReasons
+	}
+
+	// Private modules are not sent to public metadata services.
+	if p.goEnv.isPrivateModule(lintedModuleName) {
+		return blockReasons
+	}
+
+	if p.Config.Blocked.Scorecard.IsEnabled() && p.Config.IsRuleEnabled(RuleScorecard) {
+		if reason, ok := p.scorecardBlockReason(lintedModuleName, now); ok {
+			blockReasons = append(blockReasons, reason)
+		}
+	}
+
+	if p.Config.Blocked.DepsDev.IsEnabled() && (p.Config.IsRuleEnabled(RuleLicense) || p.Config.IsRuleEnabled(RuleDepsDev)) {
+		blockReasons = append(blockReasons, p.depsDevBlockReasons(lintedModuleName, lintedModuleVersion, now)...)
+	}
+
+	if p.Config.Blocked.Popularity.IsEnabled() && p.Config.IsRuleEnabled(RulePopularity) {
+		blockReasons = append(blockReasons, p.popularityBlockReasons(lintedModuleName, lintedModuleVersion)...)
+	}
+
+	return blockReasons
+}
+
+// scorecardBlockReason returns a block reason if the module has a scorecard
+// score below the configured threshold.
+func (p *Processor) scorecardBlockReason(lintedModuleName string, now time.Time) (blockReason, bool) {
+	scorecard := &p.network().scorecard
+
+	result, err := scorecard.Lookup(lintedModuleName)
+	if err != nil {
+		if !errors.Is(err, errScorecardNotFound) {
+			p.warnf("%s", err)
+		}
+
+		return blockReason{}, false
+	}
+
+	if !result.IsBelowThreshold(scorecard.Threshold) {
+		return blockReason{}, false
+	}
+
+	reason := fmt.Sprintf(blockReasonBelowScorecardThreshold, result.Score, scorecard.Threshold)
+	if msg := result.Message(scorecard.Threshold); msg != "" {
+		reason += " " + escapeReason(msg)
+	}
+
+	return blockReason{
+		rule:     RuleScorecard,
+		reason:   reason,
+		severity: severity(scorecard.IsEnforced(now)),
+		data:     MessageData{Module: lintedModuleName},
+	}, true
+}
+
+// popularityBlockReasons returns the block reasons of the popularity rules for the module version.
+func (p *Processor) popularityBlockReasons(lintedModuleName, lintedModuleVersion string) []blockReason {
+	popularity := &p.network().popularity
+
+	metrics, err := popularity.Lookup(lintedModuleName, lintedModuleVersion)
+	if err != nil {
+		p.warnf("%s", err)
+		return nil
+	}
+
+	reasons := popularity.Reasons(metrics)
+	blockReasons := make([]blockReason, 0, len(reasons))
+
+	for i := range reasons {
+		blockReasons = append(blockReasons, blockReason{
+			rule:     RulePopularity,
+			reason:   reasons[i],
+			severity: severity(popularity.Enforce),
+			data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+		})
+	}
+
+	return blockReasons
+}
+
+// allowedMatchers returns the matchers of the allowed modules and domains,
+// compiled once per processor.
+func (p *Processor) allowedMatchers() (match.Matcher, match.Matcher) {
+	if p.allowedModules == nil {
+		p.allowedModules = match.NewExactMatcher(p.Config.Allowed.Modules, match.Auto)
+	}
+
+	if p.allowedDomains == nil {
+		p.allowedDomains = match.NewDomainMatcher(p.Config.Allowed.Domains, match.Auto)
+	}
+
+	return p.allowedModules, p.allowedDomains
+}
+
+// blockedIndexes returns the indexes of the blocked modules and versions,
+// built once per processor.
+func (p *Processor) blockedIndexes() (blockedIndex, blockedIndex) {
+	if p.blockedModules == nil {
+		p.blockedModules = p.Config.Blocked.Modules.index()
+	}
+
+	if p.blockedVersions == nil {
+		p.blockedVersions = p.Config.Blocked.Versions.index()
+	}
+
+	return p.blockedModules, p.blockedVersions
+}
+
+// lookupBlockedModule returns the name and the blocked module of the entry
+// matching the linted module.
+func (p *Processor) lookupBlockedModule(lintedModuleName string) (string, *BlockedModule) {
+	blockedModules, _ := p.blockedIndexes()
+
+	entry, ok := blockedModules.lookup(lintedModuleName)
+	if !ok {
+		return "", nil
+	}
+
+	blockedModule := p.Config.Blocked.Modules[entry.index][entry.name]
+
+	return entry.name, &blockedModule
+}
+
+// lookupBlockedVersion returns the name and the blocked version of the entry
+// matching the linted module.
+func (p *Processor) lookupBlockedVersion(lintedModuleName string) (string, *BlockedVersion) {
+	_, blockedVersions := p.blockedIndexes()
+
+	entry, ok := blockedVersions.lookup(lintedModuleName)
+	if !ok {
+		return "", nil
+	}
+
+	blockedVersion := p.Config.Blocked.Versions[entry.index][entry.name]
+
+	return entry.name, &blockedVersion
+}
+
+// requireMatcher returns the matcher of the modules required by the go.mod
+// file, built once per processor since every import is resolved with it.
+func (p *Processor) requireMatcher() match.Matcher {
+	if p.requiredModules != nil {
+		return p.requiredModules
+	}
+
+	modulePaths := make([]string, 0, len(p.Modfile.Require))
+	p.requiredVersions = make(map[string]module.Version, len(p.Modfile.Require))
+
+	for _, require := range p.Modfile.Require {
+		if require == nil {
+			continue
+		}
+
+		if _, ok := p.requiredVersions[require.Mod.Path]; !ok {
+			p.requiredVersions[require.Mod.Path] = require.Mod
+		}
+
+		modulePaths = append(modulePaths, require.Mod.Path)
+	}
+
+	p.requiredModules = match.NewModuleMatcher(modulePaths, match.Auto)
+
+	return p.requiredModules
+}
+
+// validateAllowedGlobs returns an error if an allowed module or domain is a
+// malformed glob pattern.
+func validateAllowedGlobs(config *Configuration) error {
+	for _, pattern := range append(append([]string{}, config.Allowed.Modules...), config.Allowed.Domains...) {
+		if err := match.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf(errInvalidAllowedPattern, strings.TrimSpace(pattern), err)
+		}
+	}
+
+	return nil
+}
+
+// isOffline returns true if offline mode is configured or GOPROXY is off.
+func (p *Processor) isOffline() bool {
+	return p.Config.Offline || p.goEnv.isOffline()
+}
+
+// modCache returns the module cache of the go environment.
+func (p *Processor) modCache() modCache {
+	if dir := p.goEnv["GOMODCACHE"]; dir != "" {
+		return modCache{dir: dir}
+	}
+
+	return modCache{dir: goModCacheDir()}
+}
+
+// metadataLookup returns the module metadata lookup, the injected lookup,
+// the module cache when offline, the configured providers or deps.dev.
+func (p *Processor) metadataLookup() func(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	if p.MetadataLookup != nil {
+		return p.MetadataLookup
+	}
+
+	if p.isOffline() {
+		return p.modCache().Lookup
+	}
+
+	if len(p.Config.Metadata.Providers) > 0 {
+		return p.providersLookup()
+	}
+
+	return p.network().depsDev.Lookup
+}
+
+// vcsRules returns the GOVCS style rules of the blocked vcs setting, falling
+// back to GOVCS. Nil is returned if neither is set.
+func (p *Processor) vcsRules() ([]vcsRule, error) {
+	govcs := strings.TrimSpace(p.Config.Blocked.VCS)
+	if govcs == "" {
+		govcs = strings.TrimSpace(p.goEnv["GOVCS"])
+	}
+
+	if govcs == "" {
+		return nil, nil
+	}
+
+	return parseVCSRules(govcs)
+}
+
+// depsDevBlockReasons returns the block reasons of the deps.dev rules for the module version.
+func (p *Processor) depsDevBlockReasons(lintedModuleName, lintedModuleVersion string, now time.Time) []blockReason {
+	depsDev := &p.network().depsDev
+
+	metadata, err := p.metadataLookup()(lintedModuleName, lintedModuleVersion)
+	if err != nil {
+		if !isMetadataNotFound(err) {
+			p.warnf("%s", err)
+		}
+
+		return nil
+	}
+
+	licenseReasons := depsDev.LicenseReasons(metadata)
+	reasons := depsDev.Reasons(metadata)
+	blockReasons := make([]blockReason, 0, len(reasons))
+
+	for i := range reasons {
+		rule := RuleDepsDev
+		if i < len(licenseReasons) {
+			rule = RuleLicense
+		}
+
+		blockReasons = append(blockReasons, blockReason{
+			rule:     rule,
+			reason:   reasons[i],
+			severity: severity(depsDev.IsEnforced(now)),
+			data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+		})
+	}
+
+	return blockReasons
+}
+
+// renderReason returns the reason for the imported package. The message of the
+// matched entry takes precedence over the message template of the rule.
+func (p *Processor) renderReason(r blockReason, packageName string) string {
+	data := r.data
+	data.Package = packageName
+	data.Rule = r.rule
+	data.Owner = p.owner(r)
+	data.PolicyURL = p.policyURL(r)
+	data.Default = fmt.Sprintf(r.reason, packageName)
+
+	tmpl, ok := p.messages[r.rule]
+	if r.message != nil {
+		tmpl, ok = r.message, true
+	}
+
+	if !ok {
+		return data.Default
+	}
+
+	var msg strings.Builder
+
+	err := tmpl.Execute(&msg, data)
+	if err != nil {
+		return data.Default
+	}
+
+	return msg.String()
+}
+
+// owner returns the owner of the blocked module or version entry, falling
+// back to the owner of the rule.
+func (p *Processor) owner(r blockReason) string {
+	if r.data.Owner != "" || p.Config == nil {
+		return r.data.Owner
+	}
+
+	return p.Config.Owners[r.rule]
+}
+
+// warnMu serializes the warnings of the metadata rules checked in parallel.
+var warnMu sync.Mutex
+
+// warnf logs a warning, or records it if warnings are collected.
+func (p *Processor) warnf(format string, args ...interface{}) {
+	warnMu.Lock()
+	defer warnMu.Unlock()
+
+	if p.collectWarnings {
+		p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+		return
+	}
+
+	logger.Printf("warning: "+format, args...)
+}
+
+// policyURL returns the policy page of the blocked entry, otherwise of the
+// rule.
+func (p *Processor) policyURL(r blockReason) string {
+	if r.data.PolicyURL != "" || p.Config == nil {
+		return r.data.PolicyURL
+	}
+
+	return p.Config.PolicyURLs[r.rule]
+}
+
+// escapeReason escapes formatting verbs in text added to a block reason
+// since the package name is formatted into the reason later.
+func escapeReason(text string) string {
+	return strings.ReplaceAll(text, "%", "%%")
+}
+
+// isBlockedPackageFromModFile returns the block reasons if the package is blocked.
+func (p *Processor) isBlockedPackageFromModFile(packageName string) []blockReason {
+	requiredModule, ok := p.resolveModule(packageName)
+	if !ok {
+		return nil
+	}
+
+	blockReasons, ok := p.blockedModulesFromModFile[strings.TrimSpace(requiredModule.Path)]
+	if !ok {
+		return nil
+	}
+
+	formattedReasons := make([]blockReason, 0, len(blockReasons))
+
+	for _, r := range blockReasons {
+		r.reason = p.renderReason(r, packageName)
+		r.data.Module = strings.TrimSpace(requiredModule.Path)
+		r.data.Version = strings.TrimSpace(requiredModule.Version)
+		formattedReasons = append(formattedReasons, r)
+	}
+
+	return formattedReasons
+}
+
+// resolveModule returns the required module that provides the package. Like the
+// go command the module with the longest path matching the package is used, a
+// package provided by the current module is not resolved to a required module.
+// With the go list resolution the module reported by go list is used for the
+// packages it knows.
+func (p *Processor) resolveModule(packageName string) (module.Version, bool) {
+	if p.Modfile == nil {
+		return module.Version{}, false
+	}
+
+	if p.Config != nil && strings.TrimSpace(p.Config.Resolution) == ResolutionGoList {
+		if mod, ok := p.goListModule(packageName); ok {
+			return mod, mod.Path != ""
+		}
+	}
+
+	requiredPath, found := p.requireMatcher().Match(packageName)
+	if !found {
+		return module.Version{}, false
+	}
+
+	resolved := p.requiredVersions[requiredPath]
+
+	if p.Modfile.Module != nil && match.Module(p.Modfile.Module.Mod.Path, packageName) &&
+		len(strings.TrimSpace(p.Modfile.Module.Mod.Path)) > len(strings.TrimSpace(resolved.Path)) {
+		return module.Version{}, false
+	}
+
+	return resolved, true
+}
+
+// loadGoModFile returns the contents of the go.mod file of the main module,
+// falling back to the go.mod file in the current directory unless the go.mod
+// file was explicitly configured.
+func loadGoModFile(env goEnv, explicit bool) ([]byte, error) {
+	if _, err := os.Stat(env.modFile()); err != nil && !explicit {
 		return ioutil.ReadFile(goModFilename)
 	}
 
-	return ioutil.ReadFile(goEnv["GOMOD"])
+	return ioutil.ReadFile(env.modFile())
 }
@@ -2,13 +2,23 @@
 package gomodguard_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 var (
@@ -98,6 +108,12 @@ func TestBlockedModuleMessage(t *testing.T) {
 			"github.com/ryancurrah/gomodguard",
 			blockedWithRecommendations,
 		},
+		{
+			"blocked with migration guide",
+			gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module"}, Reason: "Some reason.", Docs: "https://wiki.example.com/migrate"},
+			"github.com/ryancurrah/gomodguard",
+			blockedWithRecommendation + " See https://wiki.example.com/migrate for a migration guide.",
+		},
 	}
 
 	for _, tt := range tests {
@@ -138,6 +154,44 @@ func TestBlockedModuleHasRecommendations(t *testing.T) {
 	}
 }
 
+func TestBlockedModuleReplacement(t *testing.T) {
+	var tests = []struct {
+		testName        string
+		blockedModule   *gomodguard.BlockedModule
+		wantReplacement *gomodguard.Replacement
+	}{
+		{
+			"no replacement",
+			&gomodguard.BlockedModule{Reason: "Some reason."},
+			nil,
+		},
+		{
+			"structured replacement",
+			&gomodguard.BlockedModule{
+				Recommendations: []string{"github.com/google/uuid"},
+				Docs:            "https://wiki.example.com/uuid",
+				Automatable:     true,
+				Mapping:         map[string]string{"github.com/satori/go.uuid": "github.com/google/uuid"},
+			},
+			&gomodguard.Replacement{
+				Modules:     []string{"github.com/google/uuid"},
+				Docs:        "https://wiki.example.com/uuid",
+				Automatable: true,
+				Mapping:     map[string]string{"github.com/satori/go.uuid": "github.com/google/uuid"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			replacement := tt.blockedModule.Replacement()
+			if !reflect.DeepEqual(replacement, tt.wantReplacement) {
+				t.Errorf("got '%+v' want '%+v'", replacement, tt.wantReplacement)
+			}
+		})
+	}
+}
+
 func TestBlockedModulesGet(t *testing.T) {
 	var tests = []struct {
 		testName           string
@@ -195,6 +249,112 @@ func TestBlockedVersionMessage(t *testing.T) {
 	}
 }
 
+func TestBlockedModuleIsEnforced(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		testName      string
+		blockedModule gomodguard.BlockedModule
+		wantEnforced  bool
+	}{
+		{
+			"no enforce after date",
+			gomodguard.BlockedModule{},
+			true,
+		},
+		{
+			"enforce after date in the future",
+			gomodguard.BlockedModule{EnforceAfter: "2021-07-01"},
+			false,
+		},
+		{
+			"enforce after date in the past",
+			gomodguard.BlockedModule{EnforceAfter: "2021-05-01"},
+			true,
+		},
+		{
+			"enforce after date is today",
+			gomodguard.BlockedModule{EnforceAfter: "2021-06-01"},
+			true,
+		},
+		{
+			"invalid enforce after date",
+			gomodguard.BlockedModule{EnforceAfter: "next tuesday"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			enforced := tt.blockedModule.IsEnforced(now)
+			if enforced != tt.wantEnforced {
+				t.Errorf("got '%v' want '%v'", enforced, tt.wantEnforced)
+			}
+		})
+	}
+}
+
+func TestInvalidEnforceAfter(t *testing.T) {
+	goMod := []byte("module example.com/m\n")
+
+	var tests = []struct {
+		testName string
+		config   gomodguard.Configuration
+	}{
+		{
+			"blocked module",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{EnforceAfter: "next tuesday"}}}}},
+		},
+		{
+			"blocked version",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Versions: gomodguard.BlockedVersions{{"github.com/foo/bar": gomodguard.BlockedVersion{Version: "< 1.0.0", EnforceAfter: "07/01/2021"}}}}},
+		},
+		{
+			"scorecard",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Scorecard: gomodguard.Scorecard{Threshold: 5, EnforceAfter: "2021-13-01"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := gomodguard.NewProcessorFromModBytes(&tt.config, goMod)
+			if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+				t.Errorf("got '%v' want an invalid configuration error", err)
+			}
+		})
+	}
+}
+
+func TestBlockedVersionIsEnforced(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		testName       string
+		blockedVersion gomodguard.BlockedVersion
+		wantEnforced   bool
+	}{
+		{
+			"no enforce after date",
+			gomodguard.BlockedVersion{Version: "<= 1.0.0"},
+			true,
+		},
+		{
+			"enforce after date in the future",
+			gomodguard.BlockedVersion{Version: "<= 1.0.0", EnforceAfter: "2021-07-01"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			enforced := tt.blockedVersion.IsEnforced(now)
+			if enforced != tt.wantEnforced {
+				t.Errorf("got '%v' want '%v'", enforced, tt.wantEnforced)
+			}
+		})
+	}
+}
+
 func TestBlockedModulesGetBlockedModule(t *testing.T) {
 	var tests = []struct {
 		testName          string
@@ -302,6 +462,16 @@ func TestResultString(t *testing.T) {
 			gomodguard.Result{FileName: "test.go", LineNumber: 1, Reason: "Some reason."},
 			"test.go:1:1 Some reason.",
 		},
+		{
+			"reason lint warning",
+			gomodguard.Result{FileName: "test.go", LineNumber: 1, Reason: "Some reason.", Severity: gomodguard.SeverityWarning},
+			"test.go:1:1 warning: Some reason.",
+		},
+		{
+			"reason with owner",
+			gomodguard.Result{FileName: "test.go", LineNumber: 1, Reason: "Some reason.", Owner: "team-platform"},
+			"test.go:1:1 Some reason. (owner: team-platform)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -321,6 +491,337 @@ func TestProcessorNewProcessor(t *testing.T) {
 	}
 }
 
+func TestNewProcessorFromRequires(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar/pkg\"\n\t\"github.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{
+			Versions: gomodguard.BlockedVersions{{"github.com/foo/bar": gomodguard.BlockedVersion{Version: "< 1.2.0"}}},
+		},
+	}, "github.com/ryancurrah/example", []module.Version{
+		{Path: "github.com/foo/bar", Version: "v1.1.0"},
+		{Path: "github.com/foo/baz", Version: "v1.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	if len(results) != 1 || results[0].Module != "github.com/foo/bar" || results[0].LineNumber != 4 {
+		t.Errorf("got '%+v' want github.com/foo/bar blocked on line 4", results)
+	}
+}
+
+func TestNewProcessorFromModBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar/pkg\"\n\t\"github.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/baz": {}}}},
+	}
+	goMod := []byte("module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.1.0\n\tgithub.com/foo/baz v1.0.0\n)\n")
+
+	processor, err := gomodguard.NewProcessorFromModBytes(config, goMod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	if len(results) != 1 || results[0].Module != "github.com/foo/baz" || results[0].LineNumber != 5 {
+		t.Errorf("got '%+v' want github.com/foo/baz blocked on line 5", results)
+	}
+
+	modFile, err := modfile.Parse("go.mod", goMod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor, err = gomodguard.NewProcessorFromModFile(config, modFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if processor.Modfile != modFile {
+		t.Errorf("got '%p' want the parsed go.mod file '%p'", processor.Modfile, modFile)
+	}
+
+	_, err = gomodguard.NewProcessorFromModBytes(config, []byte("module"))
+	if err == nil {
+		t.Errorf("got no error want an error parsing the go.mod file")
+	}
+}
+
+// cancelWriter cancels the context once a progress event containing the
+// text is written.
+type cancelWriter struct {
+	text   string
+	cancel context.CancelFunc
+}
+
+func (w cancelWriter) Write(b []byte) (int, error) {
+	if strings.Contains(string(b), w.text) {
+		w.cancel()
+	}
+
+	return len(b), nil
+}
+
+func TestProcessFilesWorkers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filenames := []string{}
+
+	for i := 0; i < 40; i++ {
+		source := fmt.Sprintf("package example\n\nimport (\n\t_ \"github.com/foo/bar\"\n\t_ \"github.com/foo/baz/v%d\"\n)\n", i%3+2)
+		if i%7 == 0 {
+			source = "package example\n\nimport (\n"
+		}
+
+		filename := filepath.Join(dir, fmt.Sprintf("file%d.go", i))
+		filenames = append(filenames, filename)
+
+		err = ioutil.WriteFile(filename, []byte(source), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filenames = append(filenames, filepath.Join(dir, "missing.go"))
+
+	goMod := []byte("module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz/v2 v2.0.0\n\tgithub.com/foo/baz/v3 v3.0.0\n)\n")
+
+	var want []gomodguard.Result
+
+	for _, workers := range []int{1, 4, 64, 0} {
+		processor, err := gomodguard.NewProcessorFromModBytes(&gomodguard.Configuration{
+			Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": {}}}},
+			Workers: workers,
+		}, goMod)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := processor.ProcessFiles(filenames)
+
+		if want == nil {
+			want = got
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got '%+v' with %d workers want '%+v'", got, workers, want)
+		}
+	}
+
+	// 34 blocked imports, 6 syntax errors and the missing file.
+	if len(want) != 41 || want[0].Rule != gomodguard.ResultSyntaxError || want[40].Rule != gomodguard.ResultReadError {
+		t.Errorf("got '%+v' want the results of every file in order", want)
+	}
+
+	_, err = gomodguard.NewProcessorFromModBytes(&gomodguard.Configuration{Workers: -1}, goMod)
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
+
+func TestProcessFilesContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filenames := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}
+
+	for _, filename := range filenames {
+		err = ioutil.WriteFile(filename, []byte("package example\n\nimport \"github.com/foo/baz\"\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/baz": {}}}},
+	}
+	goMod := []byte("module github.com/ryancurrah/example\n\nrequire github.com/foo/baz v1.0.0\n")
+
+	tests := []struct {
+		name    string
+		cancel  string
+		want    int
+		wantErr error
+	}{
+		{name: "not cancelled", want: 2},
+		{name: "cancelled after the first file", cancel: `"finished"`, want: 1, wantErr: context.Canceled},
+		{name: "cancelled while linting the first file", cancel: `"started"`, want: 1, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := gomodguard.NewProcessorFromModBytes(config, goMod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tt.cancel != "" {
+				processor.Progress = cancelWriter{text: tt.cancel, cancel: cancel}
+			}
+
+			results, err := processor.ProcessFilesContext(ctx, filenames)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("got '%v' want '%v'", err, tt.wantErr)
+			}
+
+			if len(results) != tt.want {
+				t.Errorf("got '%+v' want %d results", results, tt.want)
+			}
+		})
+	}
+
+	processor, err := gomodguard.NewProcessorFromModBytes(config, goMod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	results, err := processor.ProcessFilesContext(ctx, filenames)
+	if !errors.Is(err, context.DeadlineExceeded) || len(results) != 0 {
+		t.Errorf("got '%+v' '%v' want no results and '%v'", results, err, context.DeadlineExceeded)
+	}
+}
+
+func TestAllowedGlobPatterns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/myorg/tool/pkg\"\n\t\"git.internal.corp/team/lib\"\n\t\"github.com/other/tool\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{
+			Modules: []string{"github.com/myorg/*"},
+			Domains: []string{"*.internal.corp"},
+		},
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(config, "github.com/ryancurrah/example", []module.Version{
+		{Path: "github.com/myorg/tool", Version: "v1.0.0"},
+		{Path: "git.internal.corp/team/lib", Version: "v1.0.0"},
+		{Path: "github.com/other/tool", Version: "v1.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	if len(results) != 1 || results[0].Module != "github.com/other/tool" {
+		t.Errorf("got '%+v' want only github.com/other/tool blocked", results)
+	}
+
+	config.Allowed.Modules = []string{"github.com/myorg/[a-"}
+
+	_, err = gomodguard.NewProcessorFromRequires(config, "github.com/ryancurrah/example", nil)
+	if err == nil {
+		t.Errorf("got no error want an error for a malformed pattern")
+	}
+}
+
+func TestMajorVersionLogicalModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar/v3\"\n\t\"github.com/foo/baz\"\n\t\"github.com/foo/baz/v2\"\n\t\"github.com/foo/old\"\n\t\"github.com/foo/old/v2\"\n\t\"github.com/foo/qux\"\n\t\"github.com/foo/qux/v2\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{
+			Modules: []string{"github.com/foo/bar", "github.com/foo/baz", "github.com/foo/old", "github.com/foo/qux/v2"},
+		},
+		Blocked: gomodguard.Blocked{
+			// Blocked entries only apply to their own major version.
+			Modules:  gomodguard.BlockedModules{{"github.com/foo/old": {}}},
+			Versions: gomodguard.BlockedVersions{{"github.com/foo/baz": {Version: "< 2.0.0"}}},
+		},
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(config, "github.com/ryancurrah/example", []module.Version{
+		{Path: "github.com/foo/bar/v3", Version: "v3.0.0"},
+		{Path: "github.com/foo/baz", Version: "v1.5.0"},
+		{Path: "github.com/foo/baz/v2", Version: "v2.1.0"},
+		{Path: "github.com/foo/old", Version: "v1.0.0"},
+		{Path: "github.com/foo/old/v2", Version: "v2.0.0"},
+		{Path: "github.com/foo/qux", Version: "v1.0.0"},
+		{Path: "github.com/foo/qux/v2", Version: "v2.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	got := make([]string, 0, len(results))
+	for _, r := range results {
+		got = append(got, r.Rule+" "+r.Module)
+	}
+
+	want := []string{
+		gomodguard.RuleBlockedVersion + " github.com/foo/baz",
+		gomodguard.RuleInBlockedList + " github.com/foo/old",
+		gomodguard.RuleNotInAllowedList + " github.com/foo/qux",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+}
+
 func TestProcessorProcessFiles(t *testing.T) {
 	processor, err := gomodguard.NewProcessor(config)
 	if err != nil {
@@ -349,6 +850,21 @@ func TestProcessorProcessFiles(t *testing.T) {
 			gomodguard.Processor{Config: config, Modfile: processor.Modfile, Result: []gomodguard.Result{}},
 			"blocked_example.go:8:1 import of package `github.com/ryancurrah/gomodguard` is blocked because the module has a local replace directive.",
 		},
+		{
+			"module blocked because of major version mismatch",
+			gomodguard.Processor{Config: config, Modfile: processor.Modfile, Result: []gomodguard.Result{}},
+			"major_version_example.go:4:1 import of package `github.com/gofrs/uuid/v4` does not match the major version of the required module `github.com/gofrs/uuid` v3.3.0+incompatible.",
+		},
+		{
+			"go:generate tool blocked because module is in go.mod",
+			gomodguard.Processor{Config: config, Modfile: processor.Modfile, Result: []gomodguard.Result{}},
+			"blocked_example.go:33:1 go:generate invocation of `github.com/uudashr/go-module/cmd/gomod` is blocked because the module is in the blocked modules list. `golang.org/x/mod` is a recommended module. `mod` is the official go.mod parser library.",
+		},
+		{
+			"go:generate tool blocked because of configuration",
+			gomodguard.Processor{Config: config, Modfile: processor.Modfile, Result: []gomodguard.Result{}},
+			"blocked_example.go:34:1 go:generate invocation of `github.com/golang/mock/mockgen` is blocked because the module is in the blocked modules list. testing if go:generate tools are blocked.",
+		},
 	}
 
 	for _, tt := range tests {
@@ -375,3 +891,401 @@ func TestProcessorProcessFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessorHasPolicyWork(t *testing.T) {
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName string
+		config   gomodguard.Configuration
+		want     bool
+	}{
+		{
+			"nothing blocked",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/baz": gomodguard.BlockedModule{}}}}},
+			false,
+		},
+		{
+			"required module blocked",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}}},
+			true,
+		},
+		{
+			"required module not allowed",
+			gomodguard.Configuration{Allowed: gomodguard.Allowed{Domains: []string{"golang.org"}}},
+			true,
+		},
+		{
+			"file rule configured",
+			gomodguard.Configuration{Internal: gomodguard.Internal{ImportCycles: true}},
+			true,
+		},
+		{
+			"file rule disabled",
+			gomodguard.Configuration{Internal: gomodguard.Internal{ImportCycles: true}, Rules: map[string]bool{gomodguard.RuleFamilyInternal: false}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			if got := processor.HasPolicyWork(); got != tt.want {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteNotice(t *testing.T) {
+	attributions := []gomodguard.Attribution{
+		{
+			Module:    "github.com/someallowed/module",
+			Version:   "v1.0.0",
+			Licenses:  []string{"MIT"},
+			Copyright: []string{"Copyright (c) 2020 Some Author"},
+		},
+	}
+
+	wantNotice := "This project includes the following third party modules.\n\ngithub.com/someallowed/module v1.0.0\nLicense: MIT\nCopyright (c) 2020 Some Author\n"
+
+	var notice strings.Builder
+
+	err := gomodguard.WriteNotice(&notice, attributions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notice.String() != wantNotice {
+		t.Errorf("got '%s' want '%s'", notice.String(), wantNotice)
+	}
+}
+
+func TestProcessorMessages(t *testing.T) {
+	var tests = []struct {
+		testName   string
+		messages   map[string]string
+		wantErr    bool
+		wantReason string
+	}{
+		{
+			"custom message template",
+			map[string]string{"in_blocked_list": "{{.Module}} n'est pas autorisé, utilisez {{index .Recommendations 0}}."},
+			false,
+			"blocked_example.go:9:1 github.com/uudashr/go-module n'est pas autorisé, utilisez golang.org/x/mod.",
+		},
+		{
+			"default message in template",
+			map[string]string{"local_replace_directive": "{{.Default}} See the wiki."},
+			false,
+			"blocked_example.go:8:1 import of package `github.com/ryancurrah/gomodguard` is blocked because the module has a local replace directive. See the wiki.",
+		},
+		{
+			"entry message takes precedence",
+			map[string]string{"blocked_version": "{{.Module}} is blocked."},
+			false,
+			"blocked_example.go:7:1 Upgrade {{.Module}}, see https://wiki.example.com/go-homedir or contact #platform.",
+		},
+		{
+			"unknown message",
+			map[string]string{"unknown": "Some message."},
+			true,
+			"",
+		},
+		{
+			"invalid message template",
+			map[string]string{"in_blocked_list": "{{.Module"},
+			true,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			messagesConfig := *config
+			messagesConfig.Messages = tt.messages
+			messagesConfig.Blocked.Versions = gomodguard.BlockedVersions{
+				{"github.com/mitchellh/go-homedir": gomodguard.BlockedVersion{
+					Version:       "<= 1.1.0",
+					CustomMessage: "Upgrade {{`{{.Module}}`}}, see https://wiki.example.com/go-homedir or contact #platform.",
+				}},
+			}
+
+			processor, err := gomodguard.NewProcessor(&messagesConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			results := processor.ProcessFiles(gomodguard.GetFilteredFiles(cwd, false, []string{"./..."}))
+
+			allReasons := make([]string, 0, len(results))
+			for _, result := range results {
+				allReasons = append(allReasons, result.String())
+
+				if result.String() == tt.wantReason {
+					return
+				}
+			}
+
+			t.Errorf("got '%+v' want '%s'", allReasons, tt.wantReason)
+		})
+	}
+}
+
+func TestProcessorResolvesLongestModulePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/bar/baz v1.2.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar/baz/pkg\"\n\t\"github.com/foo/bar/qux\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor := gomodguard.Processor{
+		Config:  &gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}}},
+		Modfile: modFile,
+	}
+	processor.SetBlockedModules()
+
+	results := processor.ProcessFiles([]string{filename})
+	if len(results) != 1 {
+		t.Fatalf("got '%d' results want '%d'", len(results), 1)
+	}
+
+	if results[0].LineNumber != 5 || results[0].Module != "github.com/foo/bar" || results[0].Version != "v1.0.0" {
+		t.Errorf("got '%+v' want import of github.com/foo/bar v1.0.0 on line 5", results[0])
+	}
+}
+
+func TestProcessorRuleFamilies(t *testing.T) {
+	var tests = []struct {
+		testName    string
+		rules       map[string]bool
+		wantErr     bool
+		wantRule    string
+		wantPresent bool
+	}{
+		{
+			"enabled by default",
+			nil,
+			false,
+			gomodguard.RuleInBlockedList,
+			true,
+		},
+		{
+			"module check disabled",
+			map[string]bool{gomodguard.RuleFamilyModule: false},
+			false,
+			gomodguard.RuleInBlockedList,
+			false,
+		},
+		{
+			"replace check disabled",
+			map[string]bool{gomodguard.RuleFamilyReplace: false},
+			false,
+			gomodguard.RuleLocalReplaceDirective,
+			false,
+		},
+		{
+			"other families unaffected",
+			map[string]bool{gomodguard.RuleFamilyReplace: false},
+			false,
+			gomodguard.RuleBlockedVersion,
+			true,
+		},
+		{
+			"unknown family",
+			map[string]bool{"stdlib-check": false},
+			true,
+			"",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			rulesConfig := *config
+			rulesConfig.Rules = tt.rules
+
+			processor, err := gomodguard.NewProcessor(&rulesConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			results := processor.ProcessFiles(gomodguard.GetFilteredFiles(cwd, false, []string{"./..."}))
+
+			present := false
+			for _, result := range results {
+				if result.Rule == tt.wantRule {
+					present = true
+				}
+			}
+
+			if present != tt.wantPresent {
+				t.Errorf("got rule '%s' present '%v' want '%v'", tt.wantRule, present, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestProcessorGoEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goModFilename := filepath.Join(dir, "alt.mod")
+
+	err = ioutil.WriteFile(goModFilename, []byte("module github.com/ryancurrah/alt\n\nrequire github.com/someorg/private v1.0.0\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		testName    string
+		goEnv       map[string]string
+		wantLookups int
+	}{
+		{
+			"public module",
+			map[string]string{"GOFLAGS": "-modfile=" + goModFilename, "GOPROXY": "https://proxy.golang.org", "GOPRIVATE": "", "GONOSUMDB": ""},
+			1,
+		},
+		{
+			"private module",
+			map[string]string{"GOFLAGS": "-modfile=" + goModFilename, "GOPROXY": "https://proxy.golang.org", "GOPRIVATE": "github.com/someorg", "GONOSUMDB": ""},
+			0,
+		},
+		{
+			"offline",
+			map[string]string{"GOFLAGS": "-modfile=" + goModFilename, "GOPROXY": "off", "GOPRIVATE": "", "GONOSUMDB": ""},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lookups = 0
+
+			processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+				Blocked: gomodguard.Blocked{DepsDev: gomodguard.DepsDev{APIURL: server.URL, Advisories: true}},
+				GoEnv:   tt.goEnv,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if processor.Modfile.Module.Mod.Path != "github.com/ryancurrah/alt" {
+				t.Errorf("got module '%s' want '%s'", processor.Modfile.Module.Mod.Path, "github.com/ryancurrah/alt")
+			}
+
+			if lookups != tt.wantLookups {
+				t.Errorf("got '%d' lookups want '%d'", lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestProcessorGoModPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"project/go.mod": "module example.com/project\n\nrequire github.com/foo/bar v1.0.0\n",
+		"project/a/a.go": "package a\n\nimport _ \"github.com/foo/bar\"\n",
+		"other.mod":      "module example.com/other\n",
+	}
+
+	writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Blocked:   gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": {}}}},
+		GoEnv:     map[string]string{"GOFLAGS": "-mod=mod -modfile=" + filepath.Join(dir, "other.mod")},
+		GoModPath: filepath.Join(dir, "project", "go.mod"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if processor.Modfile.Module.Mod.Path != "example.com/project" {
+		t.Errorf("got module '%s' want '%s'", processor.Modfile.Module.Mod.Path, "example.com/project")
+	}
+
+	results := processor.ProcessFiles([]string{filepath.Join(dir, "project", "a", "a.go")})
+	if len(results) != 1 || results[0].Module != "github.com/foo/bar" {
+		t.Errorf("got '%+v' want a result of github.com/foo/bar", results)
+	}
+
+	// A missing go.mod path does not fall back to the current directory.
+	_, err = gomodguard.NewProcessor(&gomodguard.Configuration{GoModPath: filepath.Join(dir, "missing", "go.mod")})
+	if err == nil {
+		t.Errorf("got no error want an error reading the go.mod file")
+	}
+}
+
+// writeTree writes the files, by path relative to the directory, creating
+// their parent directories, and returns the written go files in order.
+func writeTree(t *testing.T, dir string, files map[string]string) []string {
+	t.Helper()
+
+	filenames := []string{}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+
+		err := os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filename, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if filepath.Ext(name) == ".go" {
+			filenames = append(filenames, filename)
+		}
+	}
+
+	sort.Strings(filenames)
+
+	return filenames
+}
@@ -0,0 +1,93 @@
+package gomodguard
+
+import (
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+// HostAlias is an alternative host, such as a corporate mirror, for modules
+// of a canonical host. The alias and canonical host can include a path, i.e.
+// mirror.example.com/github for github.com. Policies are written against the
+// canonical host, PreferAlias makes the alias the host fixes rewrite to.
+type HostAlias struct {
+	Canonical   string `yaml:"canonical" json:"canonical"`
+	Alias       string `yaml:"alias" json:"alias"`
+	PreferAlias bool   `yaml:"prefer_alias" json:"prefer_alias"`
+}
+
+// CanonicalModulePath returns the module path with an aliased host replaced
+// by its canonical host.
+func (c *Configuration) CanonicalModulePath(modulePath string) string {
+	modulePath = strings.TrimSpace(modulePath)
+
+	if alias, ok := longestHostAlias(c.HostAliases, modulePath, func(h HostAlias) string { return h.Alias }); ok {
+		return rewriteHost(modulePath, alias.Alias, alias.Canonical)
+	}
+
+	return modulePath
+}
+
+// PreferredModulePath returns the module path on its preferred host. Paths
+// are canonicalized and then rewritten to the alias of hosts preferring it.
+func (c *Configuration) PreferredModulePath(modulePath string) string {
+	modulePath = c.CanonicalModulePath(modulePath)
+
+	if alias, ok := longestHostAlias(c.HostAliases, modulePath, func(h HostAlias) string { return h.Canonical }); ok && alias.PreferAlias {
+		return rewriteHost(modulePath, alias.Canonical, alias.Alias)
+	}
+
+	return modulePath
+}
+
+// preferredReplacement returns the replacement of a blocked module with its
+// mapped package paths on their preferred host, so fixes rewrite imports to
+// the preferred host. Mapped paths of the canonical module are also mapped
+// from the path the module is required with, so imports of mirror paths are
+// fixed too.
+func (c *Configuration) preferredReplacement(r *Replacement, canonicalModuleName, lintedModuleName string) *Replacement {
+	if r == nil || len(r.Mapping) == 0 {
+		return r
+	}
+
+	preferred := *r
+	preferred.Mapping = make(map[string]string, len(r.Mapping))
+
+	for from, to := range r.Mapping {
+		to = c.PreferredModulePath(to)
+		preferred.Mapping[from] = to
+
+		if lintedModuleName != canonicalModuleName && match.Prefix(canonicalModuleName, from) {
+			preferred.Mapping[rewriteHost(strings.TrimSpace(from), canonicalModuleName, lintedModuleName)] = to
+		}
+	}
+
+	return &preferred
+}
+
+// longestHostAlias returns the host alias whose host, as returned by the host
+// func, is the longest prefix of the module path.
+func longestHostAlias(aliases []HostAlias, modulePath string, host func(HostAlias) string) (HostAlias, bool) {
+	var (
+		longest HostAlias
+		found   bool
+	)
+
+	for _, alias := range aliases {
+		prefix := strings.TrimSpace(host(alias))
+		if prefix == "" || !match.Prefix(prefix, modulePath) {
+			continue
+		}
+
+		if !found || len(prefix) > len(strings.TrimSpace(host(longest))) {
+			longest, found = alias, true
+		}
+	}
+
+	return longest, found
+}
+
+// rewriteHost replaces the from prefix of the module path with the to prefix.
+func rewriteHost(modulePath, from, to string) string {
+	return strings.TrimSpace(to) + strings.TrimPrefix(modulePath, strings.TrimSpace(from))
+}
@@ -0,0 +1,132 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestConfigurationHostAliases(t *testing.T) {
+	config := gomodguard.Configuration{
+		HostAliases: []gomodguard.HostAlias{
+			{Canonical: "github.com", Alias: "github.corp-mirror.example.com", PreferAlias: true},
+			{Canonical: "golang.org", Alias: "mirror.example.com/golang"},
+		},
+	}
+
+	var tests = []struct {
+		testName      string
+		modulePath    string
+		wantCanonical string
+		wantPreferred string
+	}{
+		{
+			"mirror path",
+			"github.corp-mirror.example.com/foo/bar",
+			"github.com/foo/bar",
+			"github.corp-mirror.example.com/foo/bar",
+		},
+		{
+			"canonical path preferring alias",
+			"github.com/foo/bar",
+			"github.com/foo/bar",
+			"github.corp-mirror.example.com/foo/bar",
+		},
+		{
+			"mirror path with path prefix",
+			"mirror.example.com/golang/x/mod",
+			"golang.org/x/mod",
+			"golang.org/x/mod",
+		},
+		{
+			"host sharing a prefix",
+			"github.company.com/foo/bar",
+			"github.company.com/foo/bar",
+			"github.company.com/foo/bar",
+		},
+		{
+			"unaliased host",
+			"gopkg.in/yaml.v2",
+			"gopkg.in/yaml.v2",
+			"gopkg.in/yaml.v2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := config.CanonicalModulePath(tt.modulePath); got != tt.wantCanonical {
+				t.Errorf("got '%v' want '%v'", got, tt.wantCanonical)
+			}
+
+			if got := config.PreferredModulePath(tt.modulePath); got != tt.wantPreferred {
+				t.Errorf("got '%v' want '%v'", got, tt.wantPreferred)
+			}
+		})
+	}
+}
+
+func TestProcessorHostAliases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.corp-mirror.example.com/foo/bar v1.0.0\n\tgithub.corp-mirror.example.com/foo/baz v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.corp-mirror.example.com/foo/bar\"\n\t\"github.corp-mirror.example.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor := gomodguard.Processor{
+		Config: &gomodguard.Configuration{
+			Blocked: gomodguard.Blocked{
+				Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{
+					Mapping: map[string]string{"github.com/foo/bar": "github.com/foo/qux"},
+				}}},
+			},
+			HostAliases: []gomodguard.HostAlias{{Canonical: "github.com", Alias: "github.corp-mirror.example.com", PreferAlias: true}},
+		},
+		Modfile: modFile,
+	}
+	processor.SetBlockedModules()
+
+	results := processor.ProcessFiles([]string{filename})
+
+	if len(results) != 1 {
+		t.Fatalf("got '%+v' want 1 result", results)
+	}
+
+	want := "github.corp-mirror.example.com/foo/bar"
+	if results[0].Module != want {
+		t.Errorf("got '%v' want '%v'", results[0].Module, want)
+	}
+
+	// Fixes rewrite the mirror path to the replacement on the preferred host.
+	_, err = gomodguard.FixImports(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSrc := "package example\n\nimport (\n\t\"github.corp-mirror.example.com/foo/baz\"\n\t\"github.corp-mirror.example.com/foo/qux\"\n)\n"
+	if string(got) != wantSrc {
+		t.Errorf("got '%s' want '%s'", got, wantSrc)
+	}
+}
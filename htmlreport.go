@@ -0,0 +1,101 @@
+package gomodguard
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlTemplate is the page of HTML reports, the policy page of a result
+// links its reason. The page is written in parts, the head, the table head
+// before the first result, a row per result and the end with the count.
+var htmlTemplate = template.Must(template.New("report").Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gomodguard report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.warning { color: #9a6700; }
+.error { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>gomodguard report</h1>
+{{- end}}
+{{- define "table" }}
+<table>
+<tr><th>Location</th><th>Severity</th><th>Rule</th><th>Module</th><th>Reason</th></tr>
+{{- end}}
+{{- define "row" }}
+<tr>
+<td>{{.FileName}}:{{.LineNumber}}</td>
+<td class="{{.Severity}}">{{.Severity}}</td>
+<td>{{.Rule}}</td>
+<td>{{.Module}}{{if .Version}}@{{.Version}}{{end}}</td>
+<td>{{.Reason}}{{if .PolicyURL}} <a href="{{.PolicyURL}}">Policy</a>{{end}}</td>
+</tr>
+{{- end}}
+{{- define "end" }}
+{{- if .}}
+</table>
+{{- end}}
+<p>{{.}} result(s)</p>
+</body>
+</html>
+{{end}}`))
+
+// htmlReporter writes the results as a standalone HTML page for sharing
+// outside of CI. Each result is written as it is reported, so the page is
+// not held in memory.
+type htmlReporter struct {
+	w       io.Writer
+	count   int
+	started bool
+	err     error
+}
+
+func newHTMLReporter(w io.Writer) Reporter {
+	return &htmlReporter{w: w}
+}
+
+func (r *htmlReporter) Report(result Result) {
+	r.start()
+
+	if r.err != nil {
+		return
+	}
+
+	if r.count == 0 {
+		r.err = htmlTemplate.ExecuteTemplate(r.w, "table", nil)
+		if r.err != nil {
+			return
+		}
+	}
+
+	r.count++
+	r.err = htmlTemplate.ExecuteTemplate(r.w, "row", result)
+}
+
+// start writes the head of the page once.
+func (r *htmlReporter) start() {
+	if r.started {
+		return
+	}
+
+	r.started = true
+	r.err = htmlTemplate.ExecuteTemplate(r.w, "head", nil)
+}
+
+func (r *htmlReporter) Flush() error {
+	r.start()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	return htmlTemplate.ExecuteTemplate(r.w, "end", r.count)
+}
@@ -0,0 +1,315 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+var (
+	blockReasonLayerViolation = "import of package `%%s` is blocked because package `%s` in layer `%s` may not import layer `%s`."
+	blockReasonImportCycle    = "import of package `%s` creates an import cycle: %s."
+)
+
+// Internal rules apply to the imports between the packages of the linted module.
+type Internal struct {
+	ImportCycles bool    `yaml:"import_cycles" json:"import_cycles"`
+	Layers       []Layer `yaml:"layers" json:"layers"`
+}
+
+// Layer is a stratum of packages. Layers are ordered from top to bottom and a
+// package may only import packages of its own layer or the layers below.
+type Layer struct {
+	Name     string   `yaml:"name" json:"name"`
+	Packages []string `yaml:"packages" json:"packages"`
+}
+
+// IsEnabled returns true if any internal rule is configured.
+func (i *Internal) IsEnabled() bool {
+	return i.ImportCycles || len(i.Layers) > 0
+}
+
+// layer returns the index of the layer of the package, -1 if the package is
+// in no layer. The layer with the longest matching package prefix is used.
+func (i *Internal) layer(packagePath string) int {
+	layer, longest := -1, -1
+
+	for n := range i.Layers {
+		for _, prefix := range i.Layers[n].Packages {
+			if match.Prefix(prefix, packagePath) && len(strings.TrimSpace(prefix)) > longest {
+				layer, longest = n, len(strings.TrimSpace(prefix))
+			}
+		}
+	}
+
+	return layer
+}
+
+// filePackagePath returns the import path of the package of a file of the
+// linted module.
+func (p *Processor) filePackagePath(filename string) (string, bool) {
+	if p.Modfile == nil || p.Modfile.Module == nil {
+		return "", false
+	}
+
+	moduleDir := p.moduleDir
+	if moduleDir == "" {
+		moduleDir, _ = os.Getwd()
+	}
+
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(moduleDir, filepath.Dir(absFilename))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	return path.Join(strings.TrimSpace(p.Modfile.Module.Mod.Path), filepath.ToSlash(rel)), true
+}
+
+// externalTestPackagePath returns the import path of the package of a file,
+// keyed apart from the package of its directory for external test packages,
+// like the go command does. External test packages may import the package
+// of their directory without creating an import cycle.
+func externalTestPackagePath(packagePath, filename string, file *ast.File) string {
+	if strings.HasSuffix(filename, "_test.go") && file.Name != nil && strings.HasSuffix(file.Name.Name, "_test") {
+		return packagePath + "_test"
+	}
+
+	return packagePath
+}
+
+// isFirstPartyPackage returns true if the package is provided by the linted module.
+func (p *Processor) isFirstPartyPackage(packagePath string) bool {
+	if p.Modfile == nil || p.Modfile.Module == nil || !match.Module(p.Modfile.Module.Mod.Path, packagePath) {
+		return false
+	}
+
+	_, required := p.resolveModule(packagePath)
+
+	return !required
+}
+
+// internalBlockReasons records an import between packages of the linted module
+// and returns the block reasons of the layers rule. Imports are recorded for
+// the importing package, which is the external test package for its files,
+// and layers apply to the package of the directory.
+func (p *Processor) internalBlockReasons(packagePath, importingPkg, importedPkg string, position token.Position) []blockReason {
+	if !p.isFirstPartyPackage(importedPkg) || importingPkg == importedPkg {
+		return nil
+	}
+
+	if p.packageImports == nil {
+		p.packageImports = map[string]map[string]token.Position{}
+	}
+
+	if p.packageImports[importingPkg] == nil {
+		p.packageImports[importingPkg] = map[string]token.Position{}
+	}
+
+	if _, ok := p.packageImports[importingPkg][importedPkg]; !ok {
+		p.packageImports[importingPkg][importedPkg] = position
+	}
+
+	internal := &p.Config.Internal
+
+	from, to := internal.layer(packagePath), internal.layer(importedPkg)
+	if from < 0 || to < 0 || to >= from || !p.Config.IsRuleEnabled(RuleLayerViolation) {
+		return nil
+	}
+
+	return []blockReason{{
+		rule:     RuleLayerViolation,
+		reason:   fmt.Sprintf(blockReasonLayerViolation, escapeReason(packagePath), escapeReason(internal.Layers[from].Name), escapeReason(internal.Layers[to].Name)),
+		severity: SeverityError,
+		data:     MessageData{Module: strings.TrimSpace(p.Modfile.Module.Mod.Path)},
+	}}
+}
+
+// processPackageRules adds lint errors of the rules applying to the imports
+// of all processed packages and forgets the recorded imports.
+func (p *Processor) processPackageRules() {
+	if p.Config != nil && p.Config.Internal.ImportCycles && p.Config.IsRuleEnabled(RuleImportCycle) {
+		p.processImportCycles()
+	}
+
+	if p.isCategoriesEnabled() {
+		p.processCategories()
+	}
+
+	if p.Config != nil && (p.Config.LintGoMod || p.Config.Indirect) {
+		p.processGoModRequires()
+	}
+
+	p.packageImports, p.mainPackages, p.categoryImports = nil, nil, nil
+}
+
+// processImportCycles adds lint errors for import cycles between the packages
+// of the linted module. Each cycle is reported once, at the import that
+// starts the cycle from its lexically smallest package.
+func (p *Processor) processImportCycles() {
+	for _, cycle := range importCycles(p.packageImports) {
+		position := p.packageImports[cycle[0]][cycle[1]]
+
+		r := blockReason{
+			rule:     RuleImportCycle,
+			reason:   fmt.Sprintf(blockReasonImportCycle, "%s", escapeReason(strings.Join(cycle, requirePathSeparator))),
+			severity: SeverityError,
+			data:     MessageData{Module: strings.TrimSpace(p.Modfile.Module.Mod.Path)},
+		}
+
+		severity, ok := p.ruleSeverity(r)
+		if !ok {
+			continue
+		}
+
+		p.Result = append(p.Result, Result{
+			FileName:   position.Filename,
+			LineNumber: position.Line,
+			Position:   position,
+			Reason:     p.renderReason(r, cycle[1]),
+			Severity:   severity,
+			Rule:       r.rule,
+			Module:     r.data.Module,
+			Owner:      p.owner(r),
+			PolicyURL:  p.policyURL(r),
+			Provenance: p.provenance(r),
+			CodeOwners: p.codeOwners.Owners(position.Filename),
+		})
+	}
+}
+
+// importCycles returns one cycle for each strongly connected component of
+// the import graph, starting and ending with its lexically smallest package.
+func importCycles(graph map[string]map[string]token.Position) [][]string {
+	var (
+		index    = map[string]int{}
+		lowlink  = map[string]int{}
+		onStack  = map[string]bool{}
+		stack    = []string{}
+		cycles   = [][]string{}
+		next     = 0
+		strongly func(string)
+	)
+
+	strongly = func(pkg string) {
+		index[pkg], lowlink[pkg] = next, next
+		next++
+
+		stack = append(stack, pkg)
+		onStack[pkg] = true
+
+		for _, imported := range sortedImports(graph[pkg]) {
+			if _, visited := index[imported]; !visited {
+				strongly(imported)
+
+				if lowlink[imported] < lowlink[pkg] {
+					lowlink[pkg] = lowlink[imported]
+				}
+			} else if onStack[imported] && index[imported] < lowlink[pkg] {
+				lowlink[pkg] = index[imported]
+			}
+		}
+
+		if lowlink[pkg] != index[pkg] {
+			return
+		}
+
+		component := map[string]bool{}
+
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component[top] = true
+
+			if top == pkg {
+				break
+			}
+		}
+
+		if len(component) > 1 {
+			cycles = append(cycles, componentCycle(graph, component))
+		}
+	}
+
+	packages := make([]string, 0, len(graph))
+	for pkg := range graph {
+		packages = append(packages, pkg)
+	}
+
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		if _, visited := index[pkg]; !visited {
+			strongly(pkg)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+
+	return cycles
+}
+
+// componentCycle returns the shortest cycle through the lexically smallest
+// package of a strongly connected component.
+func componentCycle(graph map[string]map[string]token.Position, component map[string]bool) []string {
+	start := ""
+	for pkg := range component {
+		if start == "" || pkg < start {
+			start = pkg
+		}
+	}
+
+	parents := map[string]string{}
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		for _, imported := range sortedImports(graph[pkg]) {
+			if !component[imported] {
+				continue
+			}
+
+			if imported == start {
+				cycle := []string{start}
+				for ; pkg != start; pkg = parents[pkg] {
+					cycle = append([]string{pkg}, cycle...)
+				}
+
+				return append([]string{start}, cycle...)
+			}
+
+			if _, seen := parents[imported]; !seen {
+				parents[imported] = pkg
+				queue = append(queue, imported)
+			}
+		}
+	}
+
+	return []string{start}
+}
+
+// sortedImports returns the imported packages in lexical order.
+func sortedImports(imports map[string]token.Position) []string {
+	sorted := make([]string, 0, len(imports))
+	for imported := range imports {
+		sorted = append(sorted, imported)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,65 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorInternal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"a/a.go":          "package a\n\nimport _ \"example.com/app/b\"\n",
+		"b/b.go":          "package b\n\nimport _ \"example.com/app/c\"\n",
+		"c/c.go":          "package c\n\nimport _ \"example.com/app/a\"\n",
+		"d/d.go":          "package d\n\nimport _ \"example.com/app/c\"\n",
+		"c/c2.go":         "package c\n\nimport _ \"example.com/app/c/internal\"\n",
+		"c/internal/i.go": "package internal\n",
+		"e/e.go":          "package e\n\nimport _ \"example.com/app/f\"\n",
+		"f/f.go":          "package f\n",
+		// External test packages importing their importers are not cycles.
+		"f/f_test.go": "package f_test\n\nimport _ \"example.com/app/e\"\n",
+	}
+
+	filenames := writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Internal: gomodguard.Internal{
+			ImportCycles: true,
+			Layers: []gomodguard.Layer{
+				{Name: "app", Packages: []string{"example.com/app/a", "example.com/app/d"}},
+				{Name: "core", Packages: []string{"example.com/app/c"}},
+			},
+		},
+		GoEnv: map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles(filenames)
+
+	wantReasons := map[string]string{
+		gomodguard.RuleLayerViolation: "import of package `example.com/app/a` is blocked because package `example.com/app/c` in layer `core` may not import layer `app`.",
+		gomodguard.RuleImportCycle:    "import of package `example.com/app/b` creates an import cycle: example.com/app/a -> example.com/app/b -> example.com/app/c -> example.com/app/a.",
+	}
+
+	if len(results) != len(wantReasons) {
+		t.Fatalf("got '%+v' want '%+v'", results, wantReasons)
+	}
+
+	for _, result := range results {
+		if result.Reason != wantReasons[result.Rule] {
+			t.Errorf("got '%s' want '%s'", result.Reason, wantReasons[result.Rule])
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// IsolationPoint is a first-party package that, if wrapped, isolates the
+// rest of the module from a module. Every package of the module importing
+// the module through Package is only reached through Package, Importers are
+// the packages behind it importing the module directly.
+type IsolationPoint struct {
+	Package   string
+	Importers []string
+}
+
+// IsolationPoints lints the files and suggests the smallest set of packages
+// of the linted module that, if wrapped behind an abstraction layer, isolate
+// the module from the rest of the linted module. Packages not imported by
+// other packages, such as main packages, are only suggested if they import
+// the module directly. Points are sorted by package.
+func (p *Processor) IsolationPoints(filenames []string, modulePath string) []IsolationPoint {
+	return isolationPoints(p.processImportGraph(filenames), strings.TrimSpace(modulePath))
+}
+
+// isolationPoints returns the isolation points of the module in the import
+// graph. The dominator tree of the packages reaching the module is computed
+// from their entry packages, a package dominates the direct importers of the
+// module in its subtree. Bottom up, a package is chosen over the packages
+// chosen in its subtree if that reduces their number, so the deepest smallest
+// set of packages dominating all direct importers is returned.
+func isolationPoints(graph map[string]map[string]token.Position, modulePath string) []IsolationPoint {
+	reaching := map[string]bool{}
+	direct := map[string]bool{}
+
+	for _, chain := range reachingChains(graph, modulePath) {
+		reaching[chain.Package] = true
+		direct[chain.Packages[len(chain.Packages)-2]] = true
+	}
+
+	successors := map[string][]string{}
+	hasImporter := map[string]bool{}
+
+	for pkg := range reaching {
+		for _, imported := range sortedImports(graph[pkg]) {
+			if reaching[imported] && imported != pkg {
+				successors[pkg] = append(successors[pkg], imported)
+				hasImporter[imported] = true
+			}
+		}
+	}
+
+	packages := make([]string, 0, len(reaching))
+	for pkg := range reaching {
+		packages = append(packages, pkg)
+	}
+
+	sort.Strings(packages)
+
+	entries := []string{}
+	for _, pkg := range packages {
+		if !hasImporter[pkg] {
+			entries = append(entries, pkg)
+		}
+	}
+
+	idom := dominators(packages, successors, &entries)
+
+	isEntry := map[string]bool{}
+	for _, entry := range entries {
+		isEntry[entry] = true
+	}
+
+	children := map[string][]string{}
+	for _, pkg := range packages {
+		children[idom[pkg]] = append(children[idom[pkg]], pkg)
+	}
+
+	// cover returns the chosen packages dominating the direct importers in
+	// the subtree of the package.
+	var cover func(pkg string) []string
+
+	cover = func(pkg string) []string {
+		chosen := []string{}
+		for _, child := range children[pkg] {
+			chosen = append(chosen, cover(child)...)
+		}
+
+		if direct[pkg] || len(chosen) > 1 {
+			return []string{pkg}
+		}
+
+		return chosen
+	}
+
+	points := []IsolationPoint{}
+
+	for _, entry := range entries {
+		chosen := []string{}
+
+		if direct[entry] {
+			points = append(points, IsolationPoint{Package: entry, Importers: []string{entry}})
+		}
+
+		for _, child := range children[entry] {
+			chosen = append(chosen, cover(child)...)
+		}
+
+		for _, pkg := range chosen {
+			points = append(points, IsolationPoint{Package: pkg, Importers: directImporters(pkg, children, direct)})
+		}
+	}
+
+	// Packages imported by several entries are dominated by no package.
+	for _, pkg := range children[""] {
+		if isEntry[pkg] {
+			continue
+		}
+
+		for _, chosen := range cover(pkg) {
+			points = append(points, IsolationPoint{Package: chosen, Importers: directImporters(chosen, children, direct)})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].Package < points[j].Package })
+
+	return points
+}
+
+// directImporters returns the direct importers in the dominator subtree of
+// the package, sorted.
+func directImporters(pkg string, children map[string][]string, direct map[string]bool) []string {
+	importers := []string{}
+
+	if direct[pkg] {
+		importers = append(importers, pkg)
+	}
+
+	for _, child := range children[pkg] {
+		importers = append(importers, directImporters(child, children, direct)...)
+	}
+
+	sort.Strings(importers)
+
+	return importers
+}
+
+// dominators returns the immediate dominator of each package of the graph
+// with a virtual root importing the entries, the empty package. Packages not
+// reachable from the entries, such as cycles imported by no other package,
+// add their lexically smallest package to the entries. The immediate
+// dominators are computed with the iterative algorithm of Cooper, Harvey and
+// Kennedy.
+func dominators(packages []string, successors map[string][]string, entries *[]string) map[string]string {
+	order := map[string]int{}
+	postorder := []string{}
+
+	var visit func(pkg string)
+
+	visit = func(pkg string) {
+		order[pkg] = -1
+
+		for _, successor := range successors[pkg] {
+			if _, ok := order[successor]; !ok {
+				visit(successor)
+			}
+		}
+
+		order[pkg] = len(postorder)
+		postorder = append(postorder, pkg)
+	}
+
+	for _, entry := range *entries {
+		visit(entry)
+	}
+
+	for _, pkg := range packages {
+		if _, ok := order[pkg]; !ok {
+			*entries = append(*entries, pkg)
+			visit(pkg)
+		}
+	}
+
+	sort.Strings(*entries)
+
+	predecessors := map[string][]string{}
+	for _, pkg := range packages {
+		for _, successor := range successors[pkg] {
+			predecessors[successor] = append(predecessors[successor], pkg)
+		}
+	}
+
+	const root = ""
+
+	order[root] = len(postorder)
+
+	idom := map[string]string{}
+	for _, entry := range *entries {
+		idom[entry] = root
+	}
+
+	intersect := func(a, b string) string {
+		for a != b {
+			for order[a] < order[b] {
+				a = idom[a]
+			}
+
+			for order[b] < order[a] {
+				b = idom[b]
+			}
+		}
+
+		return a
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for i := len(postorder) - 1; i >= 0; i-- {
+			pkg := postorder[i]
+			if idom[pkg] == root && containsString(*entries, pkg) {
+				continue
+			}
+
+			newIdom, found := "", false
+
+			for _, predecessor := range predecessors[pkg] {
+				if _, ok := idom[predecessor]; !ok {
+					continue
+				}
+
+				if !found {
+					newIdom, found = predecessor, true
+					continue
+				}
+
+				newIdom = intersect(predecessor, newIdom)
+			}
+
+			if current, ok := idom[pkg]; found && (!ok || current != newIdom) {
+				idom[pkg] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	return idom
+}
+
+// WriteIsolationPoints writes the isolation points as a table.
+func WriteIsolationPoints(w io.Writer, points []IsolationPoint) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, err := fmt.Fprintln(tw, "PACKAGE\tIMPORTERS")
+	if err != nil {
+		return err
+	}
+
+	for i := range points {
+		_, err = fmt.Fprintf(tw, "%s\t%s\n", points[i].Package, strings.Join(points[i].Importers, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,70 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorIsolationPoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":             "module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n",
+		"cmd/main.go":        "package main\n\nimport (\n\t\"github.com/ryancurrah/example/api\"\n\t\"github.com/ryancurrah/example/cli\"\n\t\"github.com/ryancurrah/example/shared\"\n)\n",
+		"tool/main.go":       "package main\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/ryancurrah/example/shared\"\n)\n",
+		"api/api.go":         "package api\n\nimport (\n\t\"github.com/ryancurrah/example/api/a\"\n\t\"github.com/ryancurrah/example/api/b\"\n)\n",
+		"api/a/a.go":         "package a\n\nimport \"github.com/foo/bar\"\n",
+		"api/b/b.go":         "package b\n\nimport \"github.com/foo/bar/client\"\n",
+		"cli/cli.go":         "package cli\n\nimport \"github.com/ryancurrah/example/cli/c\"\n",
+		"cli/c/c.go":         "package c\n\nimport \"github.com/foo/bar\"\n",
+		"shared/shared.go":   "package shared\n\nimport \"github.com/foo/bar\"\n",
+		"unrelated/unrel.go": "package unrelated\n\nimport \"fmt\"\n",
+		"cycle/x/x.go":       "package x\n\nimport \"github.com/ryancurrah/example/cycle/y\"\n",
+		"cycle/y/y.go":       "package y\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/ryancurrah/example/cycle/x\"\n)\n",
+	}
+
+	filenames := writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		GoEnv: map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := processor.IsolationPoints(filenames, "github.com/foo/bar")
+
+	want := []gomodguard.IsolationPoint{
+		{Package: "github.com/ryancurrah/example/api", Importers: []string{"github.com/ryancurrah/example/api/a", "github.com/ryancurrah/example/api/b"}},
+		{Package: "github.com/ryancurrah/example/cli/c", Importers: []string{"github.com/ryancurrah/example/cli/c"}},
+		{Package: "github.com/ryancurrah/example/cycle/y", Importers: []string{"github.com/ryancurrah/example/cycle/y"}},
+		{Package: "github.com/ryancurrah/example/shared", Importers: []string{"github.com/ryancurrah/example/shared"}},
+		{Package: "github.com/ryancurrah/example/tool", Importers: []string{"github.com/ryancurrah/example/tool"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+
+	var buf bytes.Buffer
+
+	err = gomodguard.WriteIsolationPoints(&buf, got[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTable := "PACKAGE                            IMPORTERS\ngithub.com/ryancurrah/example/api  github.com/ryancurrah/example/api/a, github.com/ryancurrah/example/api/b\n"
+	if buf.String() != wantTable {
+		t.Errorf("got '%v' want '%v'", buf.String(), wantTable)
+	}
+}
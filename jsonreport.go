@@ -0,0 +1,98 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonResult is the structured form of a result in json reports, webhook
+// payloads and progress events.
+type jsonResult struct {
+	File            string       `json:"file"`
+	Line            int          `json:"line"`
+	Column          int          `json:"column,omitempty"`
+	Rule            string       `json:"rule"`
+	Package         string       `json:"package,omitempty"`
+	Module          string       `json:"module"`
+	Version         string       `json:"version"`
+	Reason          string       `json:"reason"`
+	Severity        string       `json:"severity"`
+	Owner           string       `json:"owner,omitempty"`
+	CodeOwners      []string     `json:"code_owners,omitempty"`
+	PolicyURL       string       `json:"policy_url,omitempty"`
+	Provenance      *Provenance  `json:"provenance,omitempty"`
+	RequirePath     []string     `json:"require_path,omitempty"`
+	Replacement     *Replacement `json:"replacement,omitempty"`
+	Blame           *Blame       `json:"blame,omitempty"`
+	NonSuppressible bool         `json:"non_suppressible,omitempty"`
+}
+
+// newJSONResult returns the structured form of a result.
+func newJSONResult(result *Result) jsonResult {
+	return jsonResult{
+		File:            normalizePath(result.FileName),
+		Line:            result.LineNumber,
+		Column:          result.Position.Column,
+		Rule:            result.Rule,
+		Package:         result.Package,
+		Module:          result.Module,
+		Version:         result.Version,
+		Reason:          result.Reason,
+		Severity:        string(result.Severity),
+		Owner:           result.Owner,
+		CodeOwners:      result.CodeOwners,
+		PolicyURL:       result.PolicyURL,
+		Provenance:      result.Provenance,
+		RequirePath:     result.RequirePath,
+		Replacement:     result.Replacement,
+		Blame:           result.Blame,
+		NonSuppressible: result.NonSuppressible,
+	}
+}
+
+// jsonReporter writes the results as a json array, one result per line.
+type jsonReporter struct {
+	w     io.Writer
+	count int
+	err   error
+}
+
+func newJSONReporter(w io.Writer) Reporter {
+	return &jsonReporter{w: w}
+}
+
+func (r *jsonReporter) Report(result Result) {
+	if r.err != nil {
+		return
+	}
+
+	data, err := json.Marshal(newJSONResult(&result))
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	separator := "[\n  "
+	if r.count > 0 {
+		separator = ",\n  "
+	}
+
+	r.count++
+
+	_, r.err = io.WriteString(r.w, separator+string(data))
+}
+
+func (r *jsonReporter) Flush() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	end := "\n]\n"
+	if r.count == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(r.w, end)
+
+	return err
+}
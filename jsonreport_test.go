@@ -0,0 +1,96 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestWriteReportJSON(t *testing.T) {
+	var tests = []struct {
+		testName string
+		results  []gomodguard.Result
+		want     []map[string]interface{}
+	}{
+		{
+			"no results",
+			[]gomodguard.Result{},
+			[]map[string]interface{}{},
+		},
+		{
+			"results",
+			[]gomodguard.Result{
+				{
+					FileName:    "main.go",
+					LineNumber:  4,
+					Position:    token.Position{Filename: "main.go", Line: 4, Column: 2},
+					Reason:      "import of package `github.com/foo/bar/baz` is blocked.",
+					Severity:    gomodguard.SeverityError,
+					Rule:        gomodguard.RuleInBlockedList,
+					Package:     "github.com/foo/bar/baz",
+					Module:      "github.com/foo/bar",
+					Version:     "v1.0.0",
+					Replacement: &gomodguard.Replacement{Modules: []string{"github.com/foo/qux"}},
+				},
+				{
+					FileName:   "pkg/a.go",
+					LineNumber: 3,
+					Reason:     "import of package `github.com/foo/baz` is blocked.",
+					Severity:   gomodguard.SeverityWarning,
+					Rule:       gomodguard.RuleNotInAllowedList,
+					Module:     "github.com/foo/baz",
+					Version:    "v1.0.0",
+				},
+			},
+			[]map[string]interface{}{
+				{
+					"file":        "main.go",
+					"line":        float64(4),
+					"column":      float64(2),
+					"rule":        "in_blocked_list",
+					"package":     "github.com/foo/bar/baz",
+					"module":      "github.com/foo/bar",
+					"version":     "v1.0.0",
+					"reason":      "import of package `github.com/foo/bar/baz` is blocked.",
+					"severity":    "error",
+					"replacement": map[string]interface{}{"modules": []interface{}{"github.com/foo/qux"}, "automatable": false},
+				},
+				{
+					"file":     "pkg/a.go",
+					"line":     float64(3),
+					"rule":     "not_in_allowed_list",
+					"module":   "github.com/foo/baz",
+					"version":  "v1.0.0",
+					"reason":   "import of package `github.com/foo/baz` is blocked.",
+					"severity": "warning",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := gomodguard.WriteReport(&buf, gomodguard.ReportJSON, tt.results)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []map[string]interface{}{}
+
+			err = json.Unmarshal(buf.Bytes(), &got)
+			if err != nil {
+				t.Fatalf("got '%s' want a json array: %s", buf.String(), err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+}
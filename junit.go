@@ -0,0 +1,114 @@
+package gomodguard
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitReporter writes the results as JUnit XML for CI systems that only
+// understand test reports. Each file is a test suite and each result a test
+// case of it, errors are failed test cases and warnings skipped test cases
+// so they are shown without failing the build. The counts of the suites are
+// only known after the last result, so the report is written on Flush.
+type junitReporter struct {
+	w      io.Writer
+	suites junitTestSuites
+	index  map[string]int
+}
+
+func newJUnitReporter(w io.Writer) Reporter {
+	return &junitReporter{
+		w:      w,
+		suites: junitTestSuites{Name: "gomodguard", Suites: []junitTestSuite{}},
+		index:  map[string]int{},
+	}
+}
+
+func (r *junitReporter) Report(result Result) {
+	i, ok := r.index[result.FileName]
+	if !ok {
+		i = len(r.suites.Suites)
+		r.index[result.FileName] = i
+		r.suites.Suites = append(r.suites.Suites, junitTestSuite{Name: result.FileName})
+	}
+
+	suite := &r.suites.Suites[i]
+
+	name := result.Rule
+	if result.Package != "" {
+		name += " " + result.Package
+	}
+
+	testCase := junitTestCase{
+		Name:      name,
+		ClassName: fmt.Sprintf("%s:%d:%d", result.FileName, result.LineNumber, result.Position.Column),
+	}
+
+	if result.IsWarning() {
+		testCase.Skipped = &junitSkipped{Message: result.Reason}
+		suite.Skipped++
+		r.suites.Skipped++
+	} else {
+		testCase.Failure = &junitFailure{Message: result.Reason, Type: result.Rule, Contents: result.String()}
+		suite.Failures++
+		r.suites.Failures++
+	}
+
+	suite.Tests++
+	r.suites.Tests++
+	suite.Cases = append(suite.Cases, testCase)
+}
+
+func (r *junitReporter) Flush() error {
+	_, err := io.WriteString(r.w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(r.w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(r.suites)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(r.w, "\n")
+
+	return err
+}
@@ -0,0 +1,97 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"go/token"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestWriteReportJUnit(t *testing.T) {
+	results := []gomodguard.Result{
+		{
+			FileName:   "main.go",
+			LineNumber: 4,
+			Position:   token.Position{Filename: "main.go", Line: 4, Column: 2},
+			Reason:     "import of package `github.com/foo/bar` is blocked.",
+			Severity:   gomodguard.SeverityError,
+			Rule:       gomodguard.RuleInBlockedList,
+			Package:    "github.com/foo/bar",
+		},
+		{
+			FileName:   "pkg/a.go",
+			LineNumber: 3,
+			Position:   token.Position{Filename: "pkg/a.go", Line: 3, Column: 8},
+			Reason:     "import of package `github.com/foo/baz` is blocked.",
+			Severity:   gomodguard.SeverityWarning,
+			Rule:       gomodguard.RuleNotInAllowedList,
+			Package:    "github.com/foo/baz",
+		},
+		{
+			FileName:   "main.go",
+			LineNumber: 5,
+			Position:   token.Position{Filename: "main.go", Line: 5, Column: 2},
+			Reason:     "import of package `github.com/foo/qux` is blocked.",
+			Severity:   gomodguard.SeverityError,
+			Rule:       gomodguard.RuleNotInAllowedList,
+			Package:    "github.com/foo/qux",
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err := gomodguard.WriteReport(&buf, gomodguard.ReportJUnit, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name      string `xml:"name,attr"`
+				ClassName string `xml:"classname,attr"`
+				Failure   *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+				Skipped *struct{} `xml:"skipped"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+
+	err = xml.Unmarshal(buf.Bytes(), &report)
+	if err != nil {
+		t.Fatalf("got '%v' want valid xml: %s", err, buf.String())
+	}
+
+	if report.Tests != 3 || report.Failures != 2 || report.Skipped != 1 {
+		t.Errorf("got '%d' '%d' '%d' want '3' '2' '1' tests, failures and skipped", report.Tests, report.Failures, report.Skipped)
+	}
+
+	if len(report.Suites) != 2 || report.Suites[0].Name != "main.go" || len(report.Suites[0].Cases) != 2 {
+		t.Fatalf("got '%+v' want a suite per file", report.Suites)
+	}
+
+	first := report.Suites[0].Cases[0]
+
+	if got, want := first.Name, "in_blocked_list github.com/foo/bar"; got != want {
+		t.Errorf("got '%s' want '%s'", got, want)
+	}
+
+	if got, want := first.ClassName, "main.go:4:2"; got != want {
+		t.Errorf("got '%s' want '%s'", got, want)
+	}
+
+	if first.Failure == nil || first.Failure.Message != results[0].Reason {
+		t.Errorf("got '%+v' want a failure with the reason", first.Failure)
+	}
+
+	if warning := report.Suites[1].Cases[0]; warning.Failure != nil || warning.Skipped == nil {
+		t.Errorf("got '%+v' want warnings to be skipped", warning)
+	}
+}
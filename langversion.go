@@ -0,0 +1,108 @@
+package gomodguard
+
+import (
+	"bufio"
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// buildConstraintGoVersion matches the go1.N release tags of a build
+// constraint that are not negated.
+var buildConstraintGoVersion = regexp.MustCompile(`(^|[^!\w])go1\.(\d+)\b`)
+
+// parseFile parses a file with comments up to its imports, only imports are
+// linted. With full parse, or when go:generate directives need the comments
+// of the whole file, the whole file is parsed. Files requiring a newer
+// language version than the toolchain gomodguard was built with may use
+// syntax the parser does not know, those are parsed up to the imports
+// instead. With fast imports only the imports are tokenized.
+func (p *Processor) parseFile(fileSet *token.FileSet, filename string, data []byte) (*ast.File, error) {
+	fullParse := p.Config != nil && (p.Config.FullParse ||
+		p.Config.Blocked.GoGenerate && p.Config.IsRuleFamilyEnabled(RuleFamilyGenerate))
+
+	if p.Config != nil && p.Config.FastImports && !fullParse {
+		if file, ok := scanImports(fileSet, filename, data); ok {
+			return file, nil
+		}
+	}
+
+	if !fullParse {
+		return parser.ParseFile(fileSet, filename, data, parser.ImportsOnly|parser.ParseComments)
+	}
+
+	file, err := parser.ParseFile(fileSet, filename, data, parser.ParseComments)
+	if err == nil || p.fileGoVersion(data) <= toolchainGoVersion() {
+		return file, err
+	}
+
+	importsFile, importsErr := parser.ParseFile(fileSet, filename, data, parser.ImportsOnly|parser.ParseComments)
+	if importsErr != nil {
+		return nil, err
+	}
+
+	return importsFile, nil
+}
+
+// fileGoVersion returns the minor language version of a file, the newer of
+// the go directive of the module and the go1.N build constraints of the file.
+func (p *Processor) fileGoVersion(data []byte) int {
+	version := 0
+
+	if p.Modfile != nil && p.Modfile.Go != nil {
+		version, _ = goMinorVersion(p.Modfile.Go.Version)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+
+		if !strings.HasPrefix(line, "//go:build") && !strings.HasPrefix(line, "// +build") {
+			continue
+		}
+
+		for _, match := range buildConstraintGoVersion.FindAllStringSubmatch(line, -1) {
+			if minor, err := strconv.Atoi(match[2]); err == nil && minor > version {
+				version = minor
+			}
+		}
+	}
+
+	return version
+}
+
+// goMinorVersion returns the minor version of a go version like 1.16 or go1.16.3.
+func goMinorVersion(version string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "go"), ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, false
+	}
+
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return 0, false
+	}
+
+	return minor, true
+}
+
+// toolchainGoVersion returns the minor version of the toolchain gomodguard
+// was built with, development toolchains support every version.
+func toolchainGoVersion() int {
+	minor, ok := goMinorVersion(runtime.Version())
+	if !ok {
+		return math.MaxInt32
+	}
+
+	return minor
+}
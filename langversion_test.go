@@ -0,0 +1,81 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorLanguageVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\ngo 1.14\n\nrequire github.com/foo/bar v1.0.0\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName  string
+		content   string
+		fullParse bool
+		wantRule  string
+	}{
+		{
+			"newer language version",
+			"//go:build go1.999\n\npackage example\n\nimport \"github.com/foo/bar\"\n\nfunc f() { future syntax }\n",
+			true,
+			gomodguard.RuleInBlockedList,
+		},
+		{
+			"negated language version",
+			"//go:build !go1.999\n\npackage example\n\nimport \"github.com/foo/bar\"\n\nfunc f() { future syntax }\n",
+			true,
+			gomodguard.ResultSyntaxError,
+		},
+		{
+			"supported language version",
+			"package example\n\nimport \"github.com/foo/bar\"\n\nfunc f() { future syntax }\n",
+			true,
+			gomodguard.ResultSyntaxError,
+		},
+		{
+			"imports only",
+			"package example\n\nimport \"github.com/foo/bar\"\n\nfunc f() { future syntax }\n",
+			false,
+			gomodguard.RuleInBlockedList,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			filename := filepath.Join(dir, "example.go")
+
+			err := ioutil.WriteFile(filename, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			processor := gomodguard.Processor{
+				Config: &gomodguard.Configuration{
+					Blocked:   gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+					FullParse: tt.fullParse,
+				},
+				Modfile: modFile,
+			}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+			if len(results) != 1 || results[0].Rule != tt.wantRule {
+				t.Errorf("got '%+v' want a '%s' result", results, tt.wantRule)
+			}
+		})
+	}
+}
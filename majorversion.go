@@ -0,0 +1,61 @@
+package gomodguard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+	"golang.org/x/mod/module"
+)
+
+var blockReasonMajorVersionMismatch = "import of package `%%s` does not match the major version of the required module `%s` %s."
+
+// majorVersionBlockReason returns a block reason if the major version suffix of the
+// imported package does not match the major version of the required module.
+// Both major versions being required is not a mismatch.
+func (p *Processor) majorVersionBlockReason(importedPkg string) (blockReason, bool) {
+	required := make(map[string]bool, len(p.Modfile.Require))
+	for _, require := range p.Modfile.Require {
+		required[strings.TrimSpace(require.Mod.Path)] = true
+	}
+
+	for _, require := range p.Modfile.Require {
+		prefix, pathMajor, ok := module.SplitPathVersion(strings.TrimSpace(require.Mod.Path))
+		if !ok || strings.HasPrefix(pathMajor, ".") {
+			continue // gopkg.in style major versions are part of the module name.
+		}
+
+		if importedPkg != prefix && !strings.HasPrefix(importedPkg, prefix+"/") {
+			continue
+		}
+
+		importMajor := importPathMajor(importedPkg[len(prefix):])
+		if importMajor == pathMajor || required[prefix+importMajor] {
+			continue
+		}
+
+		return blockReason{
+			rule:     RuleMajorVersionMismatch,
+			reason:   fmt.Sprintf(blockReasonMajorVersionMismatch, escapeReason(require.Mod.Path), escapeReason(require.Mod.Version)),
+			severity: SeverityError,
+			data:     MessageData{Module: require.Mod.Path, Version: require.Mod.Version},
+		}, true
+	}
+
+	return blockReason{}, false
+}
+
+// importPathMajor returns the major version suffix, such as /v2, at the
+// start of the remaining import path of a module or an empty string.
+func importPathMajor(rest string) string {
+	if !strings.HasPrefix(rest, "/") {
+		return ""
+	}
+
+	element := strings.SplitN(rest[1:], "/", 2)[0]
+	if !match.IsMajorVersion(element) {
+		return ""
+	}
+
+	return "/" + element
+}
@@ -0,0 +1,128 @@
+package match
+
+import (
+	"path"
+	"strings"
+)
+
+// IsGlob returns true if the pattern contains the glob metacharacters *, ?
+// or [. As in path.Match, * and ? do not match the path separator, so each
+// metacharacter matches within a single path segment.
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// ValidateGlob returns path.ErrBadPattern if the pattern is malformed.
+func ValidateGlob(pattern string) error {
+	_, err := path.Match(clean(pattern), "")
+	return err
+}
+
+// Glob returns true if the path matches the pattern, like Exact for patterns
+// without metacharacters. The pattern github.com/myorg/* matches the path
+// github.com/myorg/foo but not github.com/myorg/foo/bar.
+func Glob(pattern, p string) bool {
+	if !IsGlob(pattern) {
+		return Exact(pattern, p)
+	}
+
+	rest, ok := globPrefix(clean(pattern), clean(p))
+
+	return ok && rest == ""
+}
+
+// GlobModule returns true if the package path is provided by a module
+// matching the pattern, like Module for patterns without metacharacters.
+func GlobModule(pattern, packagePath string) bool {
+	if !IsGlob(pattern) {
+		return Module(pattern, packagePath)
+	}
+
+	rest, ok := globPrefix(clean(pattern), clean(packagePath))
+
+	return ok && !IsMajorVersion(strings.SplitN(rest, "/", 2)[0])
+}
+
+// GlobDomain returns true if the path is in a domain matching the pattern,
+// like Domain for patterns without metacharacters. The pattern
+// *.internal.corp matches the path git.internal.corp/foo/bar.
+func GlobDomain(pattern, p string) bool {
+	if !IsGlob(pattern) {
+		return Domain(pattern, p)
+	}
+
+	_, ok := globPrefix(strings.ToLower(clean(pattern)), strings.ToLower(clean(p)))
+
+	return ok
+}
+
+// globPrefix matches the pattern against as many leading segments of the
+// path as the pattern has and returns the remaining segments.
+func globPrefix(pattern, p string) (string, bool) {
+	if pattern == "" {
+		return "", false
+	}
+
+	segments := strings.Count(pattern, "/") + 1
+	parts := strings.SplitN(p, "/", segments+1)
+
+	if len(parts) < segments {
+		return "", false
+	}
+
+	rest := ""
+	if len(parts) > segments {
+		rest = parts[segments]
+	}
+
+	ok, err := path.Match(pattern, strings.Join(parts[:segments], "/"))
+
+	return rest, ok && err == nil
+}
+
+// globMatcher matches the literal patterns with a matcher of the chosen
+// strategy and the glob patterns one by one.
+type globMatcher struct {
+	literal Matcher
+	globs   []string
+	domain  bool
+}
+
+func (m globMatcher) Match(p string) (string, bool) {
+	longest, found := m.literal.Match(p)
+	if found && !m.domain {
+		return longest, true
+	}
+
+	for _, pattern := range m.globs {
+		if !m.domain {
+			if Glob(pattern, p) {
+				return pattern, true
+			}
+
+			continue
+		}
+
+		if GlobDomain(pattern, p) && (!found || len(clean(pattern)) > len(clean(longest))) {
+			longest, found = pattern, true
+		}
+	}
+
+	return longest, found
+}
+
+// splitGlobs returns the literal and the glob patterns.
+func splitGlobs(patterns []string) ([]string, []string) {
+	literals := make([]string, 0, len(patterns))
+	globs := []string{}
+
+	for _, pattern := range patterns {
+		if IsGlob(pattern) {
+			globs = append(globs, pattern)
+		} else {
+			literals = append(literals, pattern)
+		}
+	}
+
+	return literals, globs
+}
@@ -0,0 +1,96 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+func TestGlob(t *testing.T) {
+	var tests = []struct {
+		testName   string
+		pattern    string
+		path       string
+		wantGlob   bool
+		wantModule bool
+		wantDomain bool
+	}{
+		{"module in organization", "github.com/myorg/*", "github.com/myorg/foo", true, true, true},
+		{"package of module in organization", "github.com/myorg/*", "github.com/myorg/foo/bar", false, true, true},
+		{"major version of module in organization", "github.com/myorg/*", "github.com/myorg/foo/v2", false, false, true},
+		{"other organization", "github.com/myorg/*", "github.com/other/foo", false, false, false},
+		{"organization itself", "github.com/myorg/*", "github.com/myorg", false, false, false},
+		{"subdomain", "*.internal.corp", "git.internal.corp/foo/bar", false, true, true},
+		{"subdomain different case", "*.internal.corp", "Git.Internal.Corp/foo", false, false, true},
+		{"domain itself", "*.internal.corp", "internal.corp/foo", false, false, false},
+		{"single character", "github.com/myorg/tool?", "github.com/myorg/tool2", true, true, true},
+		{"character class", "github.com/myorg/[ab]*", "github.com/myorg/bar", true, true, true},
+		{"literal pattern", "github.com/myorg/foo", "github.com/myorg/foo", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Glob(tt.pattern, tt.path); got != tt.wantGlob {
+				t.Errorf("Glob got '%v' want '%v'", got, tt.wantGlob)
+			}
+
+			if got := match.GlobModule(tt.pattern, tt.path); got != tt.wantModule {
+				t.Errorf("GlobModule got '%v' want '%v'", got, tt.wantModule)
+			}
+
+			if got := match.GlobDomain(tt.pattern, tt.path); got != tt.wantDomain {
+				t.Errorf("GlobDomain got '%v' want '%v'", got, tt.wantDomain)
+			}
+		})
+	}
+}
+
+func TestGlobMatcher(t *testing.T) {
+	modules := []string{"github.com/foo/bar", "github.com/myorg/*"}
+	domains := []string{"golang.org", "*.internal.corp", "git.internal.corp/team"}
+
+	var tests = []struct {
+		testName    string
+		domain      bool
+		path        string
+		wantPattern string
+		wantMatch   bool
+	}{
+		{"literal module", false, "github.com/foo/bar", "github.com/foo/bar", true},
+		{"glob module", false, "github.com/myorg/tool", "github.com/myorg/*", true},
+		{"glob module sub path", false, "github.com/myorg/tool/pkg", "", false},
+		{"literal domain", true, "golang.org/x/mod", "golang.org", true},
+		{"glob domain", true, "code.internal.corp/foo", "*.internal.corp", true},
+		{"longest domain", true, "git.internal.corp/team/foo", "git.internal.corp/team", true},
+		{"no domain", true, "github.com/foo/bar", "", false},
+	}
+
+	for _, s := range strategies {
+		moduleMatcher := match.NewExactMatcher(modules, s.strategy)
+		domainMatcher := match.NewDomainMatcher(domains, s.strategy)
+
+		for _, tt := range tests {
+			t.Run(s.name+" "+tt.testName, func(t *testing.T) {
+				matcher := moduleMatcher
+				if tt.domain {
+					matcher = domainMatcher
+				}
+
+				gotPattern, gotMatch := matcher.Match(tt.path)
+				if gotPattern != tt.wantPattern || gotMatch != tt.wantMatch {
+					t.Errorf("got '%v' '%v' want '%v' '%v'", gotPattern, gotMatch, tt.wantPattern, tt.wantMatch)
+				}
+			})
+		}
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	if err := match.ValidateGlob("github.com/myorg/*"); err != nil {
+		t.Errorf("got '%v' want no error", err)
+	}
+
+	if err := match.ValidateGlob("github.com/myorg/[a-"); err == nil {
+		t.Errorf("got no error want an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,219 @@
+// Package match implements the module path matching used by the allowed,
+// blocked and recommended module lists.
+//
+// Paths are trimmed of surrounding whitespace and trailing slashes before they
+// are compared. Paths in the escaped form of the module cache and proxies,
+// where an uppercase letter is written as ! followed by the lowercase letter,
+// are unescaped, so github.com/!burnt!sushi/toml is github.com/BurntSushi/toml.
+//
+// Module paths are case-sensitive, as they are for the go command, so
+// Exact, Prefix and Module compare them as is. Domains are compared
+// case-insensitively by Domain.
+//
+// Normalize and NormalizeDomain return paths in the form they are compared
+// in, so callers matching many paths against the same list can normalize the
+// list once and compare normalized paths directly.
+//
+// Prefixes only match at path segment boundaries, so the prefix
+// github.com/foo matches github.com/foo and github.com/foo/bar but not
+// github.com/foobar.
+//
+// Domains also match gopkg.in style major versions of their last segment, so
+// the domain gopkg.in/yaml matches gopkg.in/yaml.v2 and gopkg.in/yaml.v3 but
+// not gopkg.in/yamlv2. PathPrefix exposes this comparison for other callers.
+//
+// A major version suffix, such as /v2, starts a different module. The module
+// github.com/foo/bar owns the package github.com/foo/bar/baz but not the
+// package github.com/foo/bar/v2/baz, which is owned by github.com/foo/bar/v2.
+// Policies still treat all major versions of a module as the same logical
+// module, AnyMajorVersion matches a pattern without a major version suffix
+// against every major version of a path and TrimMajorVersion returns the path
+// of the logical module.
+//
+// Glob, GlobModule and GlobDomain additionally accept patterns with the
+// metacharacters of path.Match, such as github.com/myorg/* or *.internal.corp,
+// matching whole path segments.
+package match
+
+import (
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Normalize returns the module path in the form it is compared in, trimmed
+// of surrounding whitespace and trailing slashes and unescaped.
+func Normalize(path string) string {
+	return clean(path)
+}
+
+// NormalizeDomain returns the domain in the form it is compared in, like
+// Normalize but lowercased.
+func NormalizeDomain(domain string) string {
+	return strings.ToLower(clean(domain))
+}
+
+// Exact returns true if the path is the same as the pattern.
+func Exact(pattern, path string) bool {
+	return clean(pattern) == clean(path)
+}
+
+// Prefix returns true if the path is the prefix or starts with the
+// prefix followed by a path separator.
+func Prefix(prefix, path string) bool {
+	return hasPathPrefix(clean(path), clean(prefix))
+}
+
+// Module returns true if the package path is provided by the module path.
+// Packages under a major version suffix of the module are not provided
+// by the module.
+func Module(modulePath, packagePath string) bool {
+	modulePath, packagePath = clean(modulePath), clean(packagePath)
+
+	if !hasPathPrefix(packagePath, modulePath) {
+		return false
+	}
+
+	rest := strings.TrimPrefix(packagePath, modulePath)
+
+	return !IsMajorVersion(strings.SplitN(strings.TrimPrefix(rest, "/"), "/", 2)[0])
+}
+
+// Domain returns true if the path is in the domain. The domain can contain a
+// path, such as github.com/myorg, to match all modules under that path.
+func Domain(domain, path string) bool {
+	return PathPrefix(NormalizeDomain(domain), NormalizeDomain(path))
+}
+
+// PathPrefix returns true if the path is the prefix, is under the prefix or is
+// a gopkg.in style major version of it, such as gopkg.in/yaml.v2 of
+// gopkg.in/yaml. The paths are compared as is, they are not cleaned.
+func PathPrefix(prefix, path string) bool {
+	if hasPathPrefix(path, prefix) {
+		return true
+	}
+
+	if prefix == "" || !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	segment := strings.SplitN(path[len(prefix):], "/", 2)[0]
+
+	return isDotMajorVersion(segment)
+}
+
+// AnyMajorVersion returns true if the path matches the pattern with the
+// matching function, such as Exact, Glob or GlobModule. Patterns without a
+// major version suffix also match every major version of the path, so
+// github.com/foo/bar matches github.com/foo/bar/v3 while github.com/foo/bar/v2
+// only matches the v2 major version.
+func AnyMajorVersion(pattern, path string, matches func(pattern, path string) bool) bool {
+	if matches(pattern, path) {
+		return true
+	}
+
+	if TrimMajorVersion(pattern) != clean(pattern) {
+		return false
+	}
+
+	trimmed := TrimMajorVersion(path)
+
+	return trimmed != clean(path) && matches(pattern, trimmed)
+}
+
+// TrimMajorVersion returns the path without its major version suffix, the
+// first path element that is a major version such as /v2, or the .v2 suffix of
+// a gopkg.in path. The package github.com/foo/bar/v2/baz is trimmed to
+// github.com/foo/bar/baz and gopkg.in/yaml.v2 to gopkg.in/yaml.
+func TrimMajorVersion(path string) string {
+	path = clean(path)
+	elements := strings.Split(path, "/")
+
+	for i := 1; i < len(elements); i++ {
+		if IsMajorVersion(elements[i]) {
+			return strings.Join(append(elements[:i:i], elements[i+1:]...), "/")
+		}
+
+		if elements[0] != "gopkg.in" {
+			continue
+		}
+
+		if trimmed, ok := trimDotMajorVersion(elements[i]); ok {
+			elements[i] = trimmed
+			return strings.Join(elements, "/")
+		}
+	}
+
+	return path
+}
+
+// IsMajorVersion returns true if the path element is a
+// major version suffix such as v2, v1 and v0 are not.
+func IsMajorVersion(element string) bool {
+	if len(element) < 2 || element[0] != 'v' || element[1] == '0' || element == "v1" {
+		return false
+	}
+
+	for _, r := range element[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDotMajorVersion returns true if the text is a gopkg.in style major version
+// suffix such as .v0, .v1 or .v2.
+func isDotMajorVersion(text string) bool {
+	if len(text) < 3 || text[:2] != ".v" || (text[2] == '0' && len(text) > 3) {
+		return false
+	}
+
+	for _, r := range text[2:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trimDotMajorVersion returns the path segment without its gopkg.in style
+// major version suffix, if it has one.
+func trimDotMajorVersion(segment string) (string, bool) {
+	i := strings.LastIndex(segment, ".v")
+	if i <= 0 || !isDotMajorVersion(segment[i:]) {
+		return segment, false
+	}
+
+	return segment[:i], true
+}
+
+// hasPathPrefix returns true if the path is the
+// prefix or is under the prefix.
+func hasPathPrefix(path, prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+
+	if path == prefix {
+		return true
+	}
+
+	return strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// clean trims surrounding whitespace and trailing slashes from the path and
+// unescapes escaped paths. Paths that are not validly escaped are kept.
+func clean(path string) string {
+	path = strings.TrimRight(strings.TrimSpace(path), "/")
+
+	if strings.IndexByte(path, '!') >= 0 {
+		if unescaped, err := module.UnescapePath(path); err == nil {
+			return unescaped
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,204 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+func TestNormalize(t *testing.T) {
+	var tests = []struct {
+		testName   string
+		path       string
+		want       string
+		wantDomain string
+	}{
+		{"clean path", "github.com/Foo/bar", "github.com/Foo/bar", "github.com/foo/bar"},
+		{"whitespace and trailing slash", " github.com/foo/bar/ ", "github.com/foo/bar", "github.com/foo/bar"},
+		{"escaped path", "github.com/!burnt!sushi/toml", "github.com/BurntSushi/toml", "github.com/burntsushi/toml"},
+		{"invalid escape", "github.com/foo!/bar", "github.com/foo!/bar", "github.com/foo!/bar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Normalize(tt.path); got != tt.want {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+
+			if got := match.NormalizeDomain(tt.path); got != tt.wantDomain {
+				t.Errorf("got '%v' want '%v'", got, tt.wantDomain)
+			}
+		})
+	}
+}
+
+func TestExact(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		pattern   string
+		path      string
+		wantMatch bool
+	}{
+		{"same path", "github.com/foo/bar", " github.com/foo/bar ", true},
+		{"different case", "github.com/Foo/bar", "github.com/foo/bar", false},
+		{"sub package", "github.com/foo/bar", "github.com/foo/bar/baz", false},
+		{"trailing slash", "github.com/foo/bar", "github.com/foo/bar/", true},
+		{"escaped uppercase", "github.com/!burnt!sushi/toml", "github.com/BurntSushi/toml", true},
+		{"escaped lowercase", "github.com/!burnt!sushi/toml", "github.com/burntsushi/toml", false},
+		{"invalid escape", "github.com/!Foo/bar", "github.com/Foo/bar", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Exact(tt.pattern, tt.path); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		prefix    string
+		path      string
+		wantMatch bool
+	}{
+		{"same path", "github.com/foo", "github.com/foo", true},
+		{"sub path", "github.com/foo", "github.com/foo/bar", true},
+		{"prefix with trailing slash", "github.com/foo/", "github.com/foo/bar", true},
+		{"not at segment boundary", "github.com/foo", "github.com/foobar", false},
+		{"empty prefix", "", "github.com/foo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Prefix(tt.prefix, tt.path); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestModule(t *testing.T) {
+	var tests = []struct {
+		testName    string
+		modulePath  string
+		packagePath string
+		wantMatch   bool
+	}{
+		{"module root package", "github.com/foo/bar", "github.com/foo/bar", true},
+		{"module sub package", "github.com/foo/bar", "github.com/foo/bar/baz", true},
+		{"other module", "github.com/foo/bar", "github.com/foo/barbaz", false},
+		{"major version of module", "github.com/foo/bar", "github.com/foo/bar/v2/baz", false},
+		{"major version module", "github.com/foo/bar/v2", "github.com/foo/bar/v2/baz", true},
+		{"v1 directory", "github.com/foo/bar", "github.com/foo/bar/v1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Module(tt.modulePath, tt.packagePath); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestDomain(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		domain    string
+		path      string
+		wantMatch bool
+	}{
+		{"module in domain", "golang.org", "golang.org/x/mod", true},
+		{"different case", "GitHub.com", "github.com/foo/bar", true},
+		{"domain with path", "github.com/foo", "github.com/foo/bar", true},
+		{"not at segment boundary", "golang.org", "golang.org.example.com/x/mod", false},
+		{"gopkg.in style major version", "gopkg.in/yaml", "gopkg.in/yaml.v2", true},
+		{"not a gopkg.in style major version", "gopkg.in/yaml", "gopkg.in/yaml.vx", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.Domain(tt.domain, tt.path); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		prefix    string
+		path      string
+		wantMatch bool
+	}{
+		{"same path", "github.com/foo", "github.com/foo", true},
+		{"sub path", "github.com/foo", "github.com/foo/bar", true},
+		{"not at segment boundary", "github.com/foo", "github.com/foobar", false},
+		{"gopkg.in style major version", "gopkg.in/yaml", "gopkg.in/yaml.v3", true},
+		{"package of gopkg.in style major version", "gopkg.in/yaml", "gopkg.in/yaml.v3/sub", true},
+		{"v0 major version", "gopkg.in/yaml", "gopkg.in/yaml.v0", true},
+		{"leading zero major version", "gopkg.in/yaml", "gopkg.in/yaml.v01", false},
+		{"major version without dot", "gopkg.in/yaml", "gopkg.in/yamlv2", false},
+		{"empty prefix", "", "github.com/foo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.PathPrefix(tt.prefix, tt.path); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestAnyMajorVersion(t *testing.T) {
+	var tests = []struct {
+		testName  string
+		pattern   string
+		path      string
+		wantMatch bool
+	}{
+		{"same module", "github.com/foo/bar", "github.com/foo/bar", true},
+		{"major version of module", "github.com/foo/bar", "github.com/foo/bar/v3", true},
+		{"major version pattern", "github.com/foo/bar/v2", "github.com/foo/bar/v2", true},
+		{"other major version of pattern", "github.com/foo/bar/v2", "github.com/foo/bar/v3", false},
+		{"major version pattern of module", "github.com/foo/bar/v2", "github.com/foo/bar", false},
+		{"gopkg.in major version", "gopkg.in/yaml", "gopkg.in/yaml.v2", true},
+		{"other module", "github.com/foo/bar", "github.com/foo/baz/v2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.AnyMajorVersion(tt.pattern, tt.path, match.Exact); got != tt.wantMatch {
+				t.Errorf("got '%v' want '%v'", got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestTrimMajorVersion(t *testing.T) {
+	var tests = []struct {
+		testName string
+		path     string
+		want     string
+	}{
+		{"module without major version", "github.com/foo/bar", "github.com/foo/bar"},
+		{"module with major version", "github.com/foo/bar/v2", "github.com/foo/bar"},
+		{"package of major version", "github.com/foo/bar/v2/baz", "github.com/foo/bar/baz"},
+		{"v1 directory", "github.com/foo/bar/v1", "github.com/foo/bar/v1"},
+		{"gopkg.in major version", "gopkg.in/yaml.v3", "gopkg.in/yaml"},
+		{"dot major version outside gopkg.in", "github.com/foo/bar.v2", "github.com/foo/bar.v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := match.TrimMajorVersion(tt.path); got != tt.want {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package match
+
+import (
+	"strings"
+)
+
+// Strategy is the data structure a Matcher uses to match paths.
+type Strategy int
+
+const (
+	// Auto chooses the strategy by the number of patterns, module matchers
+	// always use a trie.
+	Auto Strategy = iota
+	// Linear compares the path with every pattern.
+	Linear
+	// Map looks up the path, and for domains every parent path and the path
+	// without a gopkg.in style major version, in a set.
+	Map
+	// Trie walks the path segments down a trie of the patterns.
+	Trie
+)
+
+// The thresholds up to which comparing the path with every pattern is faster
+// than building and hashing into a set, measured by the matcher benchmarks.
+// Domain comparisons are case-insensitive and cost more, so the set pays off
+// sooner. Module lookups walk the package path down a trie of the modules,
+// built once by the processor: the longest module prefix of a package path is
+// found in a single walk of its segments, without slicing and hashing every
+// parent path of the package as a set lookup does.
+const (
+	linearMaxPatterns = 16
+	linearMaxDomains  = 2
+)
+
+// Matcher matches paths against a list of patterns.
+type Matcher interface {
+	// Match returns the pattern matching the path. For domains the longest
+	// matching domain is returned.
+	Match(path string) (string, bool)
+}
+
+// NewExactMatcher returns a Matcher matching paths the same as a pattern,
+// like Exact, or matching a glob pattern, like Glob.
+func NewExactMatcher(patterns []string, strategy Strategy) Matcher {
+	patterns, globs := splitGlobs(patterns)
+
+	if strategy == Auto {
+		strategy = Map
+		if len(patterns) <= linearMaxPatterns {
+			strategy = Linear
+		}
+	}
+
+	return withGlobs(newMatcher(patterns, strategy, false), globs, false)
+}
+
+// NewDomainMatcher returns a Matcher matching paths in a domain, like Domain,
+// or in a domain matching a glob pattern, like GlobDomain.
+func NewDomainMatcher(domains []string, strategy Strategy) Matcher {
+	domains, globs := splitGlobs(domains)
+
+	if strategy == Auto {
+		strategy = Map
+		if len(domains) <= linearMaxDomains {
+			strategy = Linear
+		}
+	}
+
+	return withGlobs(newMatcher(domains, strategy, true), globs, true)
+}
+
+// NewModuleMatcher returns a Matcher matching packages provided by a module,
+// like Module. As for the go command the longest module path providing the
+// package is returned, of module paths listed more than once the first.
+func NewModuleMatcher(modulePaths []string, strategy Strategy) Matcher {
+	if strategy == Auto {
+		strategy = Trie
+	}
+
+	switch strategy {
+	case Map:
+		m := mapMatcher{patterns: make(map[string]string, len(modulePaths)), module: true}
+		for _, modulePath := range modulePaths {
+			if _, ok := m.patterns[clean(modulePath)]; !ok && clean(modulePath) != "" {
+				m.patterns[clean(modulePath)] = modulePath
+			}
+		}
+
+		return m
+	case Trie:
+		t := trieMatcher{root: &trieNode{}, module: true}
+		for _, modulePath := range modulePaths {
+			if clean(modulePath) != "" {
+				t.add(modulePath)
+			}
+		}
+
+		return t
+	default:
+		return moduleLinearMatcher{modulePaths: modulePaths}
+	}
+}
+
+// withGlobs returns the matcher of the literal patterns, extended by the glob
+// patterns if there are any.
+func withGlobs(literal Matcher, globs []string, domain bool) Matcher {
+	if len(globs) == 0 {
+		return literal
+	}
+
+	return globMatcher{literal: literal, globs: globs, domain: domain}
+}
+
+// newMatcher returns the matcher of the strategy. Domain matchers match paths
+// under a pattern and compare them case-insensitively.
+func newMatcher(patterns []string, strategy Strategy, domain bool) Matcher {
+	switch strategy {
+	case Map:
+		m := mapMatcher{patterns: make(map[string]string, len(patterns)), domain: domain}
+		for _, pattern := range patterns {
+			m.patterns[normalize(pattern, domain)] = pattern
+		}
+
+		return m
+	case Trie:
+		t := trieMatcher{root: &trieNode{}, domain: domain}
+		for _, pattern := range patterns {
+			t.add(pattern)
+		}
+
+		return t
+	default:
+		return linearMatcher{patterns: patterns, domain: domain}
+	}
+}
+
+// linearMatcher compares the path with every pattern.
+type linearMatcher struct {
+	patterns []string
+	domain   bool
+}
+
+func (m linearMatcher) Match(path string) (string, bool) {
+	longest, found := "", false
+
+	for _, pattern := range m.patterns {
+		if !m.domain {
+			if Exact(pattern, path) {
+				return pattern, true
+			}
+
+			continue
+		}
+
+		if Domain(pattern, path) && (!found || len(clean(pattern)) > len(clean(longest))) {
+			longest, found = pattern, true
+		}
+	}
+
+	return longest, found
+}
+
+// moduleLinearMatcher compares the package with every module path.
+type moduleLinearMatcher struct {
+	modulePaths []string
+}
+
+func (m moduleLinearMatcher) Match(path string) (string, bool) {
+	longest, found := "", false
+
+	for _, modulePath := range m.modulePaths {
+		if Module(modulePath, path) && (!found || len(clean(modulePath)) > len(clean(longest))) {
+			longest, found = modulePath, true
+		}
+	}
+
+	return longest, found
+}
+
+// mapMatcher looks up the path in a set of the normalized patterns. Domains
+// are found by looking up the path and its parent paths, longest first, each
+// also without a gopkg.in style major version of its last segment. Modules
+// are found by looking up the package path and its parent paths, longest
+// first, skipping parents followed by a major version suffix.
+type mapMatcher struct {
+	patterns map[string]string
+	domain   bool
+	module   bool
+}
+
+func (m mapMatcher) Match(path string) (string, bool) {
+	if m.module {
+		return m.matchModule(clean(path))
+	}
+
+	path = normalize(path, m.domain)
+
+	for path != "" {
+		if pattern, ok := m.patterns[path]; ok {
+			return pattern, true
+		}
+
+		if !m.domain {
+			break
+		}
+
+		i := strings.LastIndex(path, "/")
+
+		if trimmed, ok := trimDotMajorVersion(path[i+1:]); ok {
+			if pattern, ok := m.patterns[path[:i+1]+trimmed]; ok {
+				return pattern, true
+			}
+		}
+
+		if i < 0 {
+			break
+		}
+
+		path = path[:i]
+	}
+
+	return "", false
+}
+
+func (m mapMatcher) matchModule(path string) (string, bool) {
+	for parent := path; parent != ""; {
+		if modulePath, ok := m.patterns[parent]; ok && !IsMajorVersion(firstSegment(path[len(parent):])) {
+			return modulePath, true
+		}
+
+		i := strings.LastIndex(parent, "/")
+		if i < 0 {
+			break
+		}
+
+		parent = parent[:i]
+	}
+
+	return "", false
+}
+
+// trieMatcher walks the path segments down a trie of the normalized patterns.
+// Modules match where a module path ends unless a major version suffix
+// follows.
+type trieMatcher struct {
+	root   *trieNode
+	domain bool
+	module bool
+}
+
+// trieNode is a path segment, pattern is set if a pattern ends at the segment.
+type trieNode struct {
+	children map[string]*trieNode
+	pattern  string
+	terminal bool
+}
+
+func (t trieMatcher) add(pattern string) {
+	node := t.root
+
+	for _, segment := range strings.Split(normalize(pattern, t.domain), "/") {
+		if node.children == nil {
+			node.children = map[string]*trieNode{}
+		}
+
+		child, ok := node.children[segment]
+		if !ok {
+			child = &trieNode{}
+			node.children[segment] = child
+		}
+
+		node = child
+	}
+
+	if t.module && node.terminal {
+		return
+	}
+
+	node.pattern, node.terminal = pattern, true
+}
+
+func (t trieMatcher) Match(path string) (string, bool) {
+	node := t.root
+	longest, found := "", false
+	rest := normalize(path, t.domain)
+
+	for rest != "" {
+		segment := rest
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			segment, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+
+		if trimmed, ok := trimDotMajorVersion(segment); ok && t.domain {
+			if versioned := node.children[trimmed]; versioned != nil && versioned.terminal {
+				longest, found = versioned.pattern, true
+			}
+		}
+
+		node = node.children[segment]
+		if node == nil {
+			break
+		}
+
+		if node.terminal && (t.domain || rest == "" || t.module && !IsMajorVersion(firstSegment(rest))) {
+			longest, found = node.pattern, true
+		}
+	}
+
+	return longest, found
+}
+
+// firstSegment returns the first segment of the path, ignoring a leading
+// separator.
+func firstSegment(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+
+	return path
+}
+
+// normalize returns the path as it is compared, domains are compared
+// case-insensitively and without a trailing separator.
+func normalize(path string, domain bool) string {
+	if domain {
+		return NormalizeDomain(path)
+	}
+
+	return clean(path)
+}
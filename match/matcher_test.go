@@ -0,0 +1,218 @@
+package match_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+var strategies = []struct {
+	name     string
+	strategy match.Strategy
+}{
+	{"auto", match.Auto},
+	{"linear", match.Linear},
+	{"map", match.Map},
+	{"trie", match.Trie},
+}
+
+func TestExactMatcher(t *testing.T) {
+	patterns := []string{"github.com/foo/bar", " github.com/foo/baz ", "github.com/Foo/qux"}
+
+	var tests = []struct {
+		testName    string
+		path        string
+		wantPattern string
+		wantMatch   bool
+	}{
+		{"same path", "github.com/foo/bar", "github.com/foo/bar", true},
+		{"pattern with whitespace", "github.com/foo/baz", " github.com/foo/baz ", true},
+		{"different case", "github.com/foo/qux", "", false},
+		{"sub package", "github.com/foo/bar/baz", "", false},
+		{"parent path", "github.com/foo", "", false},
+	}
+
+	for _, s := range strategies {
+		matcher := match.NewExactMatcher(patterns, s.strategy)
+
+		for _, tt := range tests {
+			t.Run(s.name+" "+tt.testName, func(t *testing.T) {
+				gotPattern, gotMatch := matcher.Match(tt.path)
+				if gotPattern != tt.wantPattern || gotMatch != tt.wantMatch {
+					t.Errorf("got '%v' '%v' want '%v' '%v'", gotPattern, gotMatch, tt.wantPattern, tt.wantMatch)
+				}
+			})
+		}
+	}
+}
+
+func TestDomainMatcher(t *testing.T) {
+	domains := []string{"golang.org", "GitHub.com/foo", "github.com/foo/bar/", "gopkg.in", "github.com/foo/yaml"}
+
+	var tests = []struct {
+		testName    string
+		path        string
+		wantPattern string
+		wantMatch   bool
+	}{
+		{"module in domain", "golang.org/x/mod", "golang.org", true},
+		{"different case", "github.com/Foo/baz", "GitHub.com/foo", true},
+		{"longest domain", "github.com/foo/bar/baz", "github.com/foo/bar/", true},
+		{"domain itself", "gopkg.in", "gopkg.in", true},
+		{"not at segment boundary", "golang.org.example.com/x/mod", "", false},
+		{"parent of domain", "github.com", "", false},
+		{"gopkg.in style major version", "github.com/foo/yaml.v2/sub", "github.com/foo/yaml", true},
+		{"not a major version", "github.com/foo/yamlv2", "GitHub.com/foo", true},
+	}
+
+	for _, s := range strategies {
+		matcher := match.NewDomainMatcher(domains, s.strategy)
+
+		for _, tt := range tests {
+			t.Run(s.name+" "+tt.testName, func(t *testing.T) {
+				gotPattern, gotMatch := matcher.Match(tt.path)
+				if gotPattern != tt.wantPattern || gotMatch != tt.wantMatch {
+					t.Errorf("got '%v' '%v' want '%v' '%v'", gotPattern, gotMatch, tt.wantPattern, tt.wantMatch)
+				}
+			})
+		}
+	}
+}
+
+func TestModuleMatcher(t *testing.T) {
+	modulePaths := []string{"github.com/foo/bar", "github.com/foo/bar/sub", "github.com/foo/bar/v2", " github.com/foo/baz ", "github.com/foo/bar", ""}
+
+	var tests = []struct {
+		testName    string
+		path        string
+		wantPattern string
+		wantMatch   bool
+	}{
+		{"module itself", "github.com/foo/bar", "github.com/foo/bar", true},
+		{"package of module", "github.com/foo/bar/pkg", "github.com/foo/bar", true},
+		{"longest module", "github.com/foo/bar/sub/pkg", "github.com/foo/bar/sub", true},
+		{"major version module", "github.com/foo/bar/v2/pkg", "github.com/foo/bar/v2", true},
+		{"major version without module", "github.com/foo/bar/v3/pkg", "", false},
+		{"module with whitespace", "github.com/foo/baz/pkg", " github.com/foo/baz ", true},
+		{"different case", "github.com/Foo/bar", "", false},
+		{"not at segment boundary", "github.com/foo/barbaz", "", false},
+		{"parent of module", "github.com/foo", "", false},
+	}
+
+	for _, s := range strategies {
+		matcher := match.NewModuleMatcher(modulePaths, s.strategy)
+
+		for _, tt := range tests {
+			t.Run(s.name+" "+tt.testName, func(t *testing.T) {
+				gotPattern, gotMatch := matcher.Match(tt.path)
+				if gotPattern != tt.wantPattern || gotMatch != tt.wantMatch {
+					t.Errorf("got '%v' '%v' want '%v' '%v'", gotPattern, gotMatch, tt.wantPattern, tt.wantMatch)
+				}
+			})
+		}
+	}
+}
+
+// benchmarkListSizes are realistic allowed and blocked list sizes, from a
+// hand written configuration to a generated organization wide list.
+var benchmarkListSizes = []int{4, 16, 64, 512, 4096}
+
+// benchmarkLinearMaxSize is the largest list size the linear strategy is
+// benchmarked with, beyond it a single iteration takes seconds.
+const benchmarkLinearMaxSize = 512
+
+// benchmarkPaths returns module paths like those of a large configuration.
+func benchmarkPaths(n int) []string {
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("github.com/org%d/repo%d", i%97, i)
+	}
+
+	return paths
+}
+
+func BenchmarkExactMatcher(b *testing.B) {
+	for _, size := range benchmarkListSizes {
+		patterns := benchmarkPaths(size)
+		paths := append(benchmarkPaths(size/2), "golang.org/x/mod", "github.com/unknown/repo")
+
+		for _, s := range strategies {
+			if s.strategy == match.Linear && size > benchmarkLinearMaxSize {
+				continue
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", s.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					matcher := match.NewExactMatcher(patterns, s.strategy)
+					for _, path := range paths {
+						matcher.Match(path)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDomainMatcher(b *testing.B) {
+	for _, size := range benchmarkListSizes {
+		domains := benchmarkPaths(size)
+		paths := make([]string, 0, size/2+2)
+
+		for _, path := range benchmarkPaths(size / 2) {
+			paths = append(paths, path+"/pkg/sub")
+		}
+
+		paths = append(paths, "golang.org/x/mod", "github.com/unknown/repo/pkg")
+
+		for _, s := range strategies {
+			if s.strategy == match.Linear && size > benchmarkLinearMaxSize {
+				continue
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", s.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					matcher := match.NewDomainMatcher(domains, s.strategy)
+					for _, path := range paths {
+						matcher.Match(path)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkModuleMatcher(b *testing.B) {
+	for _, size := range benchmarkListSizes {
+		modulePaths := benchmarkPaths(size)
+		paths := make([]string, 0, size/2+2)
+
+		for _, path := range benchmarkPaths(size / 2) {
+			paths = append(paths, path+"/pkg/sub")
+		}
+
+		paths = append(paths, "golang.org/x/mod", "github.com/unknown/repo/pkg")
+
+		for _, s := range strategies {
+			if s.strategy == match.Linear && size > benchmarkLinearMaxSize {
+				continue
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", s.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+
+				matcher := match.NewModuleMatcher(modulePaths, s.strategy)
+
+				for i := 0; i < b.N; i++ {
+					for _, path := range paths {
+						matcher.Match(path)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	errReadingMessagesFile = "unable to read messages file %s: %w"
+	errParsingMessagesFile = "unable to parse messages file %s: %w"
+	errParsingMessage      = "unable to parse message template %s: %w"
+	errUnknownMessage      = "unknown message %s, must be one of %s"
+)
+
+// Rules a module can be blocked by. The rule names are also the
+// keys used to configure message templates.
+const (
+	RuleNotInAllowedList      = "not_in_allowed_list"
+	RuleInBlockedList         = "in_blocked_list"
+	RuleBlockedVersion        = "blocked_version"
+	RuleLocalReplaceDirective = "local_replace_directive"
+	RuleScorecard             = "scorecard"
+	RuleLicense               = "license"
+	RuleDepsDev               = "deps_dev"
+	RuleMajorVersionMismatch  = "major_version_mismatch"
+	RuleVCS                   = "vcs"
+	RulePopularity            = "popularity"
+	RuleLayerViolation        = "layer_violation"
+	RuleImportCycle           = "import_cycle"
+	RuleConfusableImport      = "confusable_import"
+	RuleTyposquat             = "typosquat"
+	RuleCooldown              = "cooldown"
+	RuleBOMDrift              = "bom_drift"
+	RuleDuplicateCategory     = "duplicate_category"
+	RuleSizeBudget            = "size_budget"
+	RuleDisallowedReplace     = "disallowed_replace"
+	RuleBlockedRequirement    = "blocked_requirement"
+)
+
+// Results that are not produced by a rule are classified by the
+// reason the file or import could not be linted.
+const (
+	ResultReadError     = "read_error"
+	ResultSyntaxError   = "syntax_error"
+	ResultInvalidImport = "invalid_import"
+	ResultInternalError = "internal_error"
+)
+
+// Rules is the list of all rules.
+var Rules = []string{
+	RuleNotInAllowedList,
+	RuleInBlockedList,
+	RuleBlockedVersion,
+	RuleLocalReplaceDirective,
+	RuleScorecard,
+	RuleLicense,
+	RuleDepsDev,
+	RuleMajorVersionMismatch,
+	RuleVCS,
+	RulePopularity,
+	RuleLayerViolation,
+	RuleImportCycle,
+	RuleConfusableImport,
+	RuleTyposquat,
+	RuleCooldown,
+	RuleBOMDrift,
+	RuleDuplicateCategory,
+	RuleSizeBudget,
+	RuleDisallowedReplace,
+	RuleBlockedRequirement,
+}
+
+// MessageData is available to message templates.
+type MessageData struct {
+	// Package is the imported package.
+	Package string
+	// Module is the module of the imported package.
+	Module string
+	// Version is the required version of the module.
+	Version string
+	// Rule is the rule the module is blocked by.
+	Rule string
+	// Reason is the reason from the configuration, if any.
+	Reason string
+	// Recommendations are the recommended modules, if any.
+	Recommendations []string
+	// Default is the default message.
+	Default string
+	// Owner is the owner of the rule or blocked entry, if any.
+	Owner string
+	// Docs is the migration guide of the blocked module, if any.
+	Docs string
+	// PolicyURL is the policy page of the rule or blocked entry, if any.
+	PolicyURL string
+}
+
+// compileMessages compiles the message templates of the messages file
+// and configuration. Messages in the configuration take precedence.
+func compileMessages(config *Configuration) (map[string]*template.Template, error) {
+	messages := map[string]string{}
+
+	if config.MessagesFile != "" {
+		var err error
+
+		messages, err = readMessagesFile(config.MessagesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for rule, message := range config.Messages {
+		messages[rule] = message
+	}
+
+	templates := make(map[string]*template.Template, len(messages))
+
+	for rule, message := range messages {
+		if !isRule(rule) {
+			return nil, fmt.Errorf(errUnknownMessage, rule, strings.Join(Rules, ", "))
+		}
+
+		tmpl, err := template.New(rule).Parse(message)
+		if err != nil {
+			return nil, fmt.Errorf(errParsingMessage, rule, err)
+		}
+
+		templates[rule] = tmpl
+	}
+
+	return templates, nil
+}
+
+// readMessagesFile reads a message catalog file.
+func readMessagesFile(messagesFile string) (map[string]string, error) {
+	messages := map[string]string{}
+
+	data, err := ioutil.ReadFile(messagesFile)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingMessagesFile, messagesFile, err)
+	}
+
+	err = yaml.Unmarshal(data, &messages)
+	if err != nil {
+		return nil, fmt.Errorf(errParsingMessagesFile, messagesFile, err)
+	}
+
+	return messages, nil
+}
+
+// validateEntryMessages returns an error if a message of a blocked module
+// or version entry is not a valid template.
+func validateEntryMessages(config *Configuration) error {
+	for n := range config.Blocked.Modules {
+		for moduleName, blockedModule := range config.Blocked.Modules[n] {
+			if _, err := template.New(moduleName).Parse(blockedModule.CustomMessage); err != nil {
+				return fmt.Errorf(errParsingMessage, moduleName, err)
+			}
+		}
+	}
+
+	for n := range config.Generated.Modules {
+		for moduleName, blockedModule := range config.Generated.Modules[n] {
+			if _, err := template.New(moduleName).Parse(blockedModule.CustomMessage); err != nil {
+				return fmt.Errorf(errParsingMessage, moduleName, err)
+			}
+		}
+	}
+
+	for n := range config.Blocked.Versions {
+		for moduleName, blockedVersion := range config.Blocked.Versions[n] {
+			if _, err := template.New(moduleName).Parse(blockedVersion.CustomMessage); err != nil {
+				return fmt.Errorf(errParsingMessage, moduleName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// entryMessage returns the template of a custom entry message or nil if
+// the entry does not have a valid message.
+func entryMessage(name, message string) *template.Template {
+	if strings.TrimSpace(message) == "" {
+		return nil
+	}
+
+	tmpl, err := template.New(name).Parse(message)
+	if err != nil {
+		return nil
+	}
+
+	return tmpl
+}
+
+// isRule returns true if the name is a known rule.
+func isRule(name string) bool {
+	for _, rule := range Rules {
+		if rule == name {
+			return true
+		}
+	}
+
+	return false
+}
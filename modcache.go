@@ -0,0 +1,133 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+const deprecatedPrefix = "Deprecated:"
+
+var errModCacheNotFound = fmt.Errorf("module not found in the module cache")
+
+// licenseSignatures identify common licenses by phrases of their text. More
+// specific licenses come first as their text may contain a more generic
+// license phrase.
+var licenseSignatures = []struct {
+	license string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+}
+
+// modCache reads module metadata from the module cache.
+type modCache struct {
+	dir string
+}
+
+// Lookup returns the metadata of a module version found in the module cache.
+// Licenses are detected from the license files of the module and deprecation
+// from the deprecation comment of its go.mod file.
+func (c modCache) Lookup(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	moduleDir, ok := moduleCacheDir(c.dir, module.Version{Path: moduleName, Version: moduleVersion})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s@%s", errModCacheNotFound, moduleName, moduleVersion)
+	}
+
+	metadata := &ModuleMetadata{Licenses: []string{}}
+
+	for _, licenseFileName := range licenseFileNames {
+		data, err := ioutil.ReadFile(filepath.Join(moduleDir, licenseFileName))
+		if err != nil {
+			continue
+		}
+
+		metadata.Licenses = append(metadata.Licenses, detectLicense(string(data)))
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(moduleDir, goModFilename))
+	if err == nil {
+		metadata.Deprecated = modFileDeprecation(data)
+	}
+
+	return metadata, nil
+}
+
+// moduleCacheDir returns the directory of a module version in the module cache.
+func moduleCacheDir(modCacheDir string, mod module.Version) (string, bool) {
+	escapedPath, err := module.EscapePath(mod.Path)
+	if err != nil {
+		return "", false
+	}
+
+	escapedVersion, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return "", false
+	}
+
+	moduleDir := filepath.Join(modCacheDir, fmt.Sprintf("%s@%s", escapedPath, escapedVersion))
+
+	if _, err := ioutil.ReadDir(moduleDir); err != nil {
+		return "", false
+	}
+
+	return moduleDir, true
+}
+
+// detectLicense returns the SPDX identifier of a license text or
+// non-standard if the license is not recognized.
+func detectLicense(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	for _, signature := range licenseSignatures {
+		matched := true
+
+		for _, phrase := range signature.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return signature.license
+		}
+	}
+
+	return depsDevNonStandard
+}
+
+// modFileDeprecation returns the deprecation message of a go.mod file, the
+// text of a "Deprecated:" paragraph in the comment of the module directive.
+func modFileDeprecation(data []byte) string {
+	file, err := modfile.ParseLax(goModFilename, data, nil)
+	if err != nil || file.Module == nil {
+		return ""
+	}
+
+	comments := append(file.Module.Syntax.Before, file.Module.Syntax.Suffix...)
+
+	for i := range comments {
+		text := strings.TrimSpace(strings.TrimPrefix(comments[i].Token, "//"))
+		if strings.HasPrefix(text, deprecatedPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(text, deprecatedPrefix))
+		}
+	}
+
+	return ""
+}
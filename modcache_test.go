@@ -0,0 +1,61 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":     "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/agpl v1.0.0\n\tgithub.com/foo/old v1.0.0\n\tgithub.com/foo/mit v1.0.0\n)\n",
+		"example.go": "package example\n\nimport (\n\t\"github.com/foo/agpl\"\n\t\"github.com/foo/old\"\n\t\"github.com/foo/mit\"\n)\n",
+		"modcache/github.com/foo/agpl@v1.0.0/LICENSE": "GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007\n",
+		"modcache/github.com/foo/old@v1.0.0/LICENSE":  "MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\n",
+		"modcache/github.com/foo/old@v1.0.0/go.mod":   "// Deprecated: use github.com/foo/new instead.\nmodule github.com/foo/old\n",
+		"modcache/github.com/foo/mit@v1.0.0/LICENSE":  "Permission is hereby granted, free of charge, to any person obtaining a copy\n",
+		"modcache/github.com/foo/mit@v1.0.0/NOTICE":   "This product includes software developed by Foo.\n",
+	}
+
+	writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		Blocked: gomodguard.Blocked{DepsDev: gomodguard.DepsDev{
+			APIURL:               "http://127.0.0.1:0",
+			Licenses:             []string{"AGPL-3.0"},
+			UnresolvableLicenses: true,
+			Deprecated:           true,
+		}},
+		GoEnv:   map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod"), "GOMODCACHE": filepath.Join(dir, "modcache")},
+		Offline: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filepath.Join(dir, "example.go")})
+
+	wantReasons := []string{
+		"import of package `github.com/foo/agpl` is blocked because the module license `AGPL-3.0` is in the blocked licenses list.",
+		"import of package `github.com/foo/old` is blocked because the module is deprecated. use github.com/foo/new instead.",
+	}
+
+	if len(results) != len(wantReasons) {
+		t.Fatalf("got '%+v' want '%+v'", results, wantReasons)
+	}
+
+	for i := range results {
+		if results[i].Reason != wantReasons[i] {
+			t.Errorf("got '%s' want '%s'", results[i].Reason, wantReasons[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gomodguard
+
+import (
+	"fmt"
+	"strings"
+)
+
+const errUnknownMode = "unknown mode %s, must be one of %s, %s"
+
+// Policy modes. In the allow list mode only modules in the allowed list may
+// be required, in the block list mode every module may be required except
+// the modules in the blocked list. Without a mode the block list mode is used
+// when the allowed list is empty.
+const (
+	ModeAllow = "allow"
+	ModeBlock = "block"
+)
+
+// IsBlockListMode returns true if modules not in the blocked list are
+// allowed, the allowed list is then ignored.
+func (c *Configuration) IsBlockListMode() bool {
+	switch strings.TrimSpace(strings.ToLower(c.Mode)) {
+	case ModeBlock:
+		return true
+	case ModeAllow:
+		return false
+	default:
+		return c.Allowed.isEmpty()
+	}
+}
+
+// validateMode returns an error if the mode is unknown.
+func validateMode(config *Configuration) error {
+	switch strings.TrimSpace(strings.ToLower(config.Mode)) {
+	case "", ModeAllow, ModeBlock:
+	default:
+		return fmt.Errorf(errUnknownMode, config.Mode, ModeAllow, ModeBlock)
+	}
+
+	return nil
+}
+
+// warnIgnoredAllowedList warns if the allowed list is ignored in the block
+// list mode.
+func (p *Processor) warnIgnoredAllowedList() {
+	if strings.TrimSpace(strings.ToLower(p.Config.Mode)) == ModeBlock && !p.Config.Allowed.isEmpty() {
+		p.warnf("the allowed list is ignored in the %s mode", ModeBlock)
+	}
+}
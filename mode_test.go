@@ -0,0 +1,101 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := gomodguard.Allowed{Modules: []string{"github.com/foo/bar"}}
+	blocked := gomodguard.Blocked{
+		Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}},
+	}
+
+	var tests = []struct {
+		testName  string
+		config    gomodguard.Configuration
+		wantRules map[string]string
+	}{
+		{
+			"implicit block list mode",
+			gomodguard.Configuration{Blocked: blocked},
+			map[string]string{"github.com/foo/bar": gomodguard.RuleInBlockedList},
+		},
+		{
+			"explicit block list mode ignores allowed list",
+			gomodguard.Configuration{Mode: gomodguard.ModeBlock, Allowed: allowed, Blocked: blocked},
+			map[string]string{"github.com/foo/bar": gomodguard.RuleInBlockedList},
+		},
+		{
+			"allow list mode",
+			gomodguard.Configuration{Mode: gomodguard.ModeAllow, Allowed: allowed},
+			map[string]string{"github.com/foo/baz": gomodguard.RuleNotInAllowedList},
+		},
+		{
+			"blocked list takes precedence over allowed list",
+			gomodguard.Configuration{Allowed: allowed, Blocked: blocked},
+			map[string]string{
+				"github.com/foo/bar": gomodguard.RuleInBlockedList,
+				"github.com/foo/baz": gomodguard.RuleNotInAllowedList,
+			},
+		},
+		{
+			"empty allow list mode",
+			gomodguard.Configuration{Mode: gomodguard.ModeAllow},
+			map[string]string{
+				"github.com/foo/bar": gomodguard.RuleNotInAllowedList,
+				"github.com/foo/baz": gomodguard.RuleNotInAllowedList,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			got := map[string]string{}
+			for i := range results {
+				got[results[i].Module] = results[i].Rule
+			}
+
+			if len(got) != len(tt.wantRules) {
+				t.Fatalf("got '%v' want '%v'", got, tt.wantRules)
+			}
+
+			for module, rule := range tt.wantRules {
+				if got[module] != rule {
+					t.Errorf("got '%v' want '%v'", got, tt.wantRules)
+				}
+			}
+		})
+	}
+}
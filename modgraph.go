@@ -0,0 +1,214 @@
+package gomodguard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	errParsingModuleGraph = "unable to parse module graph line %d: %q"
+	errGoModGraph         = "unable to read the module graph with go mod graph, indirect modules are reported without require chains: %w"
+	requirePathSeparator  = " -> "
+)
+
+var requirePathReason = " Required through %s."
+
+// ModuleGraph is a module requirement graph in the format printed by
+// `go mod graph`, each module maps to the modules it requires. Modules
+// are identified by path@version, the main module by its path only.
+type ModuleGraph map[string][]string
+
+// ParseModuleGraph parses the output of `go mod graph`.
+func ParseModuleGraph(r io.Reader) (ModuleGraph, error) {
+	graph := ModuleGraph{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf(errParsingModuleGraph, lineNumber, line)
+		}
+
+		graph[fields[0]] = append(graph[fields[0]], fields[1])
+	}
+
+	return graph, scanner.Err()
+}
+
+// RequirePath returns the shortest require chain from the module to the
+// module with the given path, at any version, including both ends. Nil is
+// returned if the module is not required.
+func (g ModuleGraph) RequirePath(from, toModulePath string) []string {
+	parents := map[string]string{from: ""}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if node != from && moduleGraphPath(node) == toModulePath {
+			path := []string{}
+			for ; node != ""; node = parents[node] {
+				path = append([]string{node}, path...)
+			}
+
+			return path
+		}
+
+		for _, required := range g[node] {
+			if _, seen := parents[required]; !seen {
+				parents[required] = node
+				queue = append(queue, required)
+			}
+		}
+	}
+
+	return nil
+}
+
+// directRequires returns the graph with the edges of the main module to
+// indirect requires removed. Since Go 1.17 go.mod lists every module of the
+// build, so the main module requires indirect modules directly in the graph
+// although they are pulled in by a direct require.
+func (g ModuleGraph) directRequires(mainModule string, indirect map[string]bool) ModuleGraph {
+	graph := make(ModuleGraph, len(g))
+	for node, required := range g {
+		graph[node] = required
+	}
+
+	direct := []string{}
+
+	for _, required := range g[mainModule] {
+		if !indirect[moduleGraphPath(required)] {
+			direct = append(direct, required)
+		}
+	}
+
+	graph[mainModule] = direct
+
+	return graph
+}
+
+// moduleGraphPath returns the module path of a module graph node.
+func moduleGraphPath(node string) string {
+	if i := strings.Index(node, "@"); i >= 0 {
+		return node[:i]
+	}
+
+	return node
+}
+
+// formatRequirePath returns the require chain as A -> B -> C.
+func formatRequirePath(path []string) string {
+	return strings.Join(path, requirePathSeparator)
+}
+
+// moduleGraph returns the module graph of the main module, the injected
+// graph or the graph of go mod graph. The graph is read once.
+func (p *Processor) moduleGraph() ModuleGraph {
+	if p.ModuleGraph != nil {
+		return p.ModuleGraph
+	}
+
+	dir := p.moduleDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	graph, err := goModGraph(dir, p.goEnv)
+	if err != nil {
+		p.warnf("%s", fmt.Errorf(errGoModGraph, err))
+
+		graph = ModuleGraph{}
+	}
+
+	p.ModuleGraph = graph
+
+	return graph
+}
+
+// goModGraph returns the module graph of the main module in the directory
+// printed by go mod graph.
+func goModGraph(dir string, env goEnv) (ModuleGraph, error) {
+	cmd := exec.Command("go", "mod", "graph")
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+
+	for _, key := range []string{"GOFLAGS", "GOPROXY"} {
+		if value := env[key]; value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return nil, err
+	}
+
+	return ParseModuleGraph(bytes.NewReader(out))
+}
+
+// setRequirePaths sets the require chain from the main module on the block
+// reasons of indirect requires, so their results tell which direct require
+// pulls them in. The module graph is only read if an indirect require is
+// blocked.
+func (p *Processor) setRequirePaths(blockedModules map[string][]blockReason) {
+	if p.Modfile.Module == nil {
+		return
+	}
+
+	mainModule := strings.TrimSpace(p.Modfile.Module.Mod.Path)
+
+	indirect := map[string]bool{}
+
+	for _, require := range p.Modfile.Require {
+		if require != nil && require.Indirect {
+			indirect[strings.TrimSpace(require.Mod.Path)] = true
+		}
+	}
+
+	var graph ModuleGraph
+
+	for _, require := range p.Modfile.Require {
+		if require == nil || !require.Indirect {
+			continue
+		}
+
+		modulePath := strings.TrimSpace(require.Mod.Path)
+
+		blockReasons, ok := blockedModules[modulePath]
+		if !ok {
+			continue
+		}
+
+		if graph == nil {
+			graph = p.moduleGraph().directRequires(mainModule, indirect)
+		}
+
+		requirePath := graph.RequirePath(mainModule, modulePath)
+
+		for i := range blockReasons {
+			blockReasons[i].requirePath = requirePath
+		}
+	}
+}
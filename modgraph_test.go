@@ -0,0 +1,139 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestModuleGraphRequirePath(t *testing.T) {
+	graph, err := gomodguard.ParseModuleGraph(strings.NewReader(`github.com/ryancurrah/example github.com/foo/a@v1.0.0
+github.com/ryancurrah/example github.com/foo/b@v1.0.0
+github.com/foo/a@v1.0.0 github.com/foo/c@v1.1.0
+github.com/foo/b@v1.0.0 github.com/foo/a@v1.0.0
+github.com/foo/c@v1.1.0 github.com/blocked/module@v0.1.0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName   string
+		modulePath string
+		wantPath   []string
+	}{
+		{
+			"transitive module",
+			"github.com/blocked/module",
+			[]string{"github.com/ryancurrah/example", "github.com/foo/a@v1.0.0", "github.com/foo/c@v1.1.0", "github.com/blocked/module@v0.1.0"},
+		},
+		{
+			"direct module",
+			"github.com/foo/b",
+			[]string{"github.com/ryancurrah/example", "github.com/foo/b@v1.0.0"},
+		},
+		{
+			"module not required",
+			"github.com/other/module",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			path := graph.RequirePath("github.com/ryancurrah/example", tt.modulePath)
+			if !reflect.DeepEqual(path, tt.wantPath) {
+				t.Errorf("got '%+v' want '%+v'", path, tt.wantPath)
+			}
+		})
+	}
+
+	_, err = gomodguard.ParseModuleGraph(strings.NewReader("invalid\n"))
+	if err == nil {
+		t.Error("want error for invalid module graph")
+	}
+}
+
+func TestProcessorIndirect(t *testing.T) {
+	goMod := `module github.com/ryancurrah/example
+
+require (
+	github.com/foo/a v1.0.0
+	github.com/blocked/module v0.1.0 // indirect
+	github.com/foo/allowed v1.0.0 // indirect
+)
+`
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := gomodguard.ParseModuleGraph(strings.NewReader(`github.com/ryancurrah/example github.com/foo/a@v1.0.0
+github.com/ryancurrah/example github.com/blocked/module@v0.1.0
+github.com/ryancurrah/example github.com/foo/allowed@v1.0.0
+github.com/foo/a@v1.0.0 github.com/foo/allowed@v1.0.0
+github.com/foo/allowed@v1.0.0 github.com/blocked/module@v0.1.0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := gomodguard.BlockedModules{{"github.com/blocked/module": gomodguard.BlockedModule{}}}
+
+	var tests = []struct {
+		testName    string
+		config      gomodguard.Configuration
+		wantResults []string
+	}{
+		{
+			"indirect requires ignored",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{Modules: blocked}},
+			[]string{},
+		},
+		{
+			"indirect requires",
+			gomodguard.Configuration{Indirect: true, Blocked: gomodguard.Blocked{Modules: blocked}},
+			[]string{"go.mod:5 github.com/blocked/module github.com/ryancurrah/example -> github.com/foo/a@v1.0.0 -> github.com/foo/allowed@v1.0.0 -> github.com/blocked/module@v0.1.0"},
+		},
+		{
+			"direct and indirect requires",
+			gomodguard.Configuration{
+				Indirect:  true,
+				LintGoMod: true,
+				Allowed:   gomodguard.Allowed{Modules: []string{"github.com/foo/allowed"}},
+			},
+			[]string{
+				"go.mod:4 github.com/foo/a ",
+				"go.mod:5 github.com/blocked/module github.com/ryancurrah/example -> github.com/foo/a@v1.0.0 -> github.com/foo/allowed@v1.0.0 -> github.com/blocked/module@v0.1.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile, ModuleGraph: graph}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{})
+
+			got := []string{}
+			for i := range results {
+				got = append(got, fmt.Sprintf("%s:%d %s %s", results[i].FileName, results[i].LineNumber, results[i].Module, strings.Join(results[i].RequirePath, " -> ")))
+
+				if len(results[i].RequirePath) > 0 && !strings.HasSuffix(results[i].Reason, " Required through "+strings.Join(results[i].RequirePath, " -> ")+".") {
+					t.Errorf("got reason '%s' want the require chain", results[i].Reason)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.wantResults) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantResults)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// processGoModRequires adds lint errors for the blocked modules required by
+// go.mod, positioned at their require, whether or not a file imports them.
+// Direct requires are only reported with lint_go_mod, indirect requires,
+// which files rarely import, with indirect.
+func (p *Processor) processGoModRequires() {
+	if p.Modfile == nil {
+		return
+	}
+
+	filename := p.goModResultFilename()
+
+	for _, require := range p.Modfile.Require {
+		if require == nil || (!require.Indirect && !p.Config.LintGoMod) {
+			continue
+		}
+
+		modulePath := strings.TrimSpace(require.Mod.Path)
+
+		blockReasons, ok := p.blockedModulesFromModFile[modulePath]
+		if !ok {
+			continue
+		}
+
+		position := token.Position{Filename: filename}
+		if require.Syntax != nil {
+			position.Line = require.Syntax.Start.Line
+			position.Column = require.Syntax.Start.LineRune
+		}
+
+		for _, r := range blockReasons {
+			severity, ok := p.ruleSeverity(r)
+			if !ok {
+				continue
+			}
+
+			r.data.Module = modulePath
+			r.data.Version = strings.TrimSpace(require.Mod.Version)
+
+			reason := p.renderReason(r, modulePath)
+			if len(r.requirePath) > 0 {
+				reason += fmt.Sprintf(requirePathReason, formatRequirePath(r.requirePath))
+			}
+
+			p.Result = append(p.Result, Result{
+				FileName:    position.Filename,
+				LineNumber:  position.Line,
+				Position:    position,
+				Reason:      reason,
+				Severity:    severity,
+				Rule:        r.rule,
+				Module:      r.data.Module,
+				Version:     r.data.Version,
+				RequirePath: r.requirePath,
+				Owner:       p.owner(r),
+				CodeOwners:  p.codeOwners.Owners(position.Filename),
+				PolicyURL:   p.policyURL(r),
+				Provenance:  p.provenance(r),
+				Replacement: r.replacement,
+			})
+		}
+	}
+}
+
+// goModResultFilename returns the go.mod file of the main module relative to
+// the current directory, like the filenames of the linted files.
+func (p *Processor) goModResultFilename() string {
+	filename := p.goEnv.modFile()
+	if !filepath.IsAbs(filename) {
+		return filename
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+
+	if rel, err := filepath.Rel(cwd, filename); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+
+	return filename
+}
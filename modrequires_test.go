@@ -0,0 +1,104 @@
+package gomodguard_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorLintGoMod(t *testing.T) {
+	goMod := `module github.com/ryancurrah/example
+
+require (
+	github.com/foo/allowed v1.0.0
+	github.com/foo/blocked v1.0.0
+	github.com/foo/old v1.0.0 // indirect
+)
+
+require github.com/foo/other v1.0.0
+`
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		line   int
+		rule   string
+		module string
+	}
+
+	var tests = []struct {
+		testName    string
+		config      gomodguard.Configuration
+		wantResults []result
+	}{
+		{
+			"disabled",
+			gomodguard.Configuration{
+				Allowed: gomodguard.Allowed{Modules: []string{"github.com/foo/allowed"}},
+			},
+			[]result{},
+		},
+		{
+			"not allowed requires",
+			gomodguard.Configuration{
+				LintGoMod: true,
+				Allowed:   gomodguard.Allowed{Modules: []string{"github.com/foo/allowed", "github.com/foo/old"}},
+			},
+			[]result{
+				{5, gomodguard.RuleNotInAllowedList, "github.com/foo/blocked"},
+				{9, gomodguard.RuleNotInAllowedList, "github.com/foo/other"},
+			},
+		},
+		{
+			"blocked modules and versions",
+			gomodguard.Configuration{
+				LintGoMod: true,
+				Blocked: gomodguard.Blocked{
+					Modules:  gomodguard.BlockedModules{{"github.com/foo/blocked": gomodguard.BlockedModule{}}, {"github.com/foo/old": gomodguard.BlockedModule{}}},
+					Versions: gomodguard.BlockedVersions{{"github.com/foo/other": gomodguard.BlockedVersion{Version: "< 2.0.0"}}},
+				},
+			},
+			[]result{
+				{5, gomodguard.RuleInBlockedList, "github.com/foo/blocked"},
+				{9, gomodguard.RuleBlockedVersion, "github.com/foo/other"},
+			},
+		},
+		{
+			"rule family disabled",
+			gomodguard.Configuration{
+				LintGoMod: true,
+				Blocked:   gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/blocked": gomodguard.BlockedModule{}}}},
+				Rules:     map[string]bool{gomodguard.RuleFamilyModule: false},
+			},
+			[]result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{})
+
+			got := []result{}
+			for i := range results {
+				if results[i].FileName != "go.mod" {
+					t.Errorf("got file '%s' want '%s'", results[i].FileName, "go.mod")
+				}
+
+				got = append(got, result{results[i].LineNumber, results[i].Rule, results[i].Module})
+			}
+
+			if !reflect.DeepEqual(got, tt.wantResults) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantResults)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package gomodguard
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// GroupFilesByModule groups files by the directory of the nearest go.mod
+// file enclosing them, so repositories with many nested modules can lint
+// each file against its own module. Files outside of any module are grouped
+// under the empty directory. Directories are absolute.
+func GroupFilesByModule(filenames []string) map[string][]string {
+	moduleDirs := map[string]string{}
+	groups := map[string][]string{}
+
+	for _, filename := range filenames {
+		dir, err := filepath.Abs(filepath.Dir(filename))
+		if err != nil {
+			groups[""] = append(groups[""], filename)
+			continue
+		}
+
+		moduleDir := findModuleDir(dir, moduleDirs)
+		groups[moduleDir] = append(groups[moduleDir], filename)
+	}
+
+	return groups
+}
+
+// FilterModuleFiles returns the files enclosed by a go.mod file, dropping
+// files outside of any module, such as the tooling of other languages in a
+// multi-language repository.
+func FilterModuleFiles(filenames []string) []string {
+	moduleDirs := map[string]string{}
+	moduleFiles := []string{}
+
+	for _, filename := range filenames {
+		dir, err := filepath.Abs(filepath.Dir(filename))
+		if err != nil || findModuleDir(dir, moduleDirs) == "" {
+			continue
+		}
+
+		moduleFiles = append(moduleFiles, filename)
+	}
+
+	return moduleFiles
+}
+
+// findModuleDir returns the directory of the nearest go.mod file in the
+// directory or its parents, the empty directory if there is none. The module
+// directories of the visited directories are cached in moduleDirs.
+func findModuleDir(dir string, moduleDirs map[string]string) string {
+	visited := []string{}
+	moduleDir := ""
+
+	for {
+		if cached, ok := moduleDirs[dir]; ok {
+			moduleDir = cached
+			break
+		}
+
+		visited = append(visited, dir)
+
+		if info, err := os.Stat(filepath.Join(dir, goModFilename)); err == nil && !info.IsDir() {
+			moduleDir = dir
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	for _, v := range visited {
+		moduleDirs[v] = moduleDir
+	}
+
+	return moduleDir
+}
+
+// sortedModuleDirs returns the module directories of the groups in order.
+func sortedModuleDirs(groups map[string][]string) []string {
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+
+	return dirs
+}
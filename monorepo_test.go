@@ -0,0 +1,116 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestGroupFilesByModule(t *testing.T) {
+	dir, filenames := writeMonorepo(t)
+	defer os.RemoveAll(dir)
+
+	got := gomodguard.GroupFilesByModule(filenames)
+
+	want := map[string][]string{
+		filepath.Join(dir, "repo"): {
+			filepath.Join(dir, "repo/internal/x/x.go"),
+			filepath.Join(dir, "repo/main.go"),
+		},
+		filepath.Join(dir, "repo/services/api"): {
+			filepath.Join(dir, "repo/services/api/api.go"),
+			filepath.Join(dir, "repo/services/api/handler/handler.go"),
+		},
+		filepath.Join(dir, "repo/services/worker"): {
+			filepath.Join(dir, "repo/services/worker/worker.go"),
+		},
+		"": {
+			filepath.Join(dir, "scratch/scratch.go"),
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+}
+
+func TestFilterModuleFiles(t *testing.T) {
+	dir, filenames := writeMonorepo(t)
+	defer os.RemoveAll(dir)
+
+	got := gomodguard.FilterModuleFiles(filenames)
+
+	want := []string{
+		filepath.Join(dir, "repo/internal/x/x.go"),
+		filepath.Join(dir, "repo/main.go"),
+		filepath.Join(dir, "repo/services/api/api.go"),
+		filepath.Join(dir, "repo/services/api/handler/handler.go"),
+		filepath.Join(dir, "repo/services/worker/worker.go"),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+}
+
+// writeMonorepo writes a repository with nested modules, a node_modules
+// directory and a directory outside of any module to a temporary directory
+// and returns the directory and the go files found walking it.
+func writeMonorepo(t *testing.T) (string, []string) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The temporary directory may be below a symlink, module directories are
+	// absolute paths of the resolved directory.
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The scratch directory is only outside of any module if no parent of
+	// the temporary directory has a go.mod file.
+	for parent := dir; parent != filepath.Dir(parent); parent = filepath.Dir(parent) {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(parent), "go.mod")); err == nil {
+			os.RemoveAll(dir)
+			t.Skipf("temporary directory %s is inside a module", dir)
+		}
+	}
+
+	files := []string{
+		"repo/go.mod",
+		"repo/main.go",
+		"repo/internal/x/x.go",
+		"repo/services/api/go.mod",
+		"repo/services/api/api.go",
+		"repo/services/api/handler/handler.go",
+		"repo/services/worker/go.mod",
+		"repo/services/worker/worker.go",
+		"scratch/scratch.go",
+		"repo/web/node_modules/tool/tool.go",
+	}
+
+	for _, name := range files {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("module example.com/x\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filenames := []string{}
+	for _, name := range gomodguard.GetFilteredFiles(dir, false, []string{dir + "/..."}) {
+		filenames = append(filenames, filepath.Join(dir, name))
+	}
+
+	return dir, filenames
+}
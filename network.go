@@ -0,0 +1,442 @@
+package gomodguard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	errNegativeNetworkConcurrency = "network concurrency must not be negative, got %d"
+	errNegativeNetworkRateLimit   = "network rate_limit must not be negative, got %v"
+	errInvalidNetworkCacheTTL     = "invalid network cache_ttl %s: %w"
+	defaultNetworkCacheTTL        = 24 * time.Hour
+	maxCachedResponseSize         = 4 << 20
+	maxRateLimitRetries           = 2
+	maxRetryAfter                 = time.Minute
+)
+
+// Network configures the requests of the rules reading module metadata from
+// the network, the module proxy, deps.dev, OSV, the OpenSSF Scorecard api and
+// GitHub. Concurrency is the number of modules looked up in parallel, 1 by
+// default. RateLimit is the maximum number of requests per second to a host,
+// 0 is unlimited. Responses are cached for CacheTTL, 24h by default, in
+// memory for the processor and, if CacheDir is set, on disk.
+type Network struct {
+	Concurrency int     `yaml:"concurrency" json:"concurrency"`
+	RateLimit   float64 `yaml:"rate_limit" json:"rate_limit"`
+	CacheDir    string  `yaml:"cache_dir" json:"cache_dir"`
+	CacheTTL    string  `yaml:"cache_ttl" json:"cache_ttl"`
+}
+
+// validateNetwork returns an error if the concurrency or rate limit is
+// negative or the cache ttl is not a duration.
+func validateNetwork(config *Configuration) error {
+	network := config.Network
+
+	if network.Concurrency < 0 {
+		return fmt.Errorf(errNegativeNetworkConcurrency, network.Concurrency)
+	}
+
+	if network.RateLimit < 0 {
+		return fmt.Errorf(errNegativeNetworkRateLimit, network.RateLimit)
+	}
+
+	if _, err := network.cacheTTL(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// concurrency returns the number of modules looked up in parallel.
+func (n Network) concurrency() int {
+	if n.Concurrency > 0 {
+		return n.Concurrency
+	}
+
+	return 1
+}
+
+// cacheTTL returns how long responses cached on disk are used.
+func (n Network) cacheTTL() (time.Duration, error) {
+	if n.CacheTTL == "" {
+		return defaultNetworkCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(n.CacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf(errInvalidNetworkCacheTTL, n.CacheTTL, err)
+	}
+
+	return ttl, nil
+}
+
+// newTransport returns a transport of the network settings.
+func (n Network) newTransport() *networkTransport {
+	ttl, _ := n.cacheTTL() // Invalid durations are reported by NewProcessor.
+
+	t := &networkTransport{
+		base:     http.DefaultTransport,
+		cacheDir: n.CacheDir,
+		ttl:      ttl,
+		memory:   map[string]*cachedResponse{},
+		next:     map[string]time.Time{},
+	}
+
+	if n.RateLimit > 0 {
+		t.interval = time.Duration(float64(time.Second) / n.RateLimit)
+	}
+
+	return t
+}
+
+// processorNetwork is the network state of a processor: its transport and
+// copies of the metadata rules using it, so the configuration shared by
+// processors is not modified.
+type processorNetwork struct {
+	transport  *networkTransport
+	scorecard  Scorecard
+	depsDev    DepsDev
+	popularity Popularity
+	cooldown   Cooldown
+	sizeBudget SizeBudget
+}
+
+// network returns the network state of the processor, created on first use.
+// It is created before modules are looked up in parallel.
+func (p *Processor) network() *processorNetwork {
+	if p.networkState != nil {
+		return p.networkState
+	}
+
+	t := p.Config.Network.newTransport()
+
+	n := &processorNetwork{
+		transport:  t,
+		scorecard:  p.Config.Blocked.Scorecard,
+		depsDev:    p.Config.Blocked.DepsDev,
+		popularity: p.Config.Blocked.Popularity,
+		cooldown:   p.Config.Blocked.Cooldown,
+		sizeBudget: p.Config.Blocked.SizeBudget,
+	}
+
+	n.scorecard.results = &sync.Map{}
+	n.scorecard.transport = t
+	n.depsDev.transport = t
+	n.popularity.transport = t
+	n.cooldown.transport = t
+	n.sizeBudget.transport = t
+
+	p.networkState = n
+
+	return n
+}
+
+// metadataCheck is the check of the metadata rules of a module, with the
+// block reasons it returned.
+type metadataCheck struct {
+	moduleName string
+	check      func() []blockReason
+	reasons    []blockReason
+}
+
+// runMetadataChecks runs the checks with the configured concurrency and
+// returns them with their block reasons, in order.
+func (p *Processor) runMetadataChecks(checks []metadataCheck) []metadataCheck {
+	if len(checks) == 0 {
+		return checks
+	}
+
+	p.network()
+
+	workers := p.Config.Network.concurrency()
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				checks[i].reasons = checks[i].check()
+			}
+		}()
+	}
+
+	for i := range checks {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return checks
+}
+
+// cachedResponse is a response of the cache.
+type cachedResponse struct {
+	Status        int         `json:"status"`
+	Header        http.Header `json:"header"`
+	ContentLength int64       `json:"content_length"`
+	Body          []byte      `json:"body"`
+	Time          time.Time   `json:"time"`
+}
+
+// networkTransport caches the responses of GET and HEAD requests, and of
+// POST requests by their body, and limits the rate of requests per host.
+// Successful and not found responses are cached, requests answered with too
+// many requests are retried after the delay the server asks for.
+type networkTransport struct {
+	base     http.RoundTripper
+	cacheDir string
+	ttl      time.Duration
+	interval time.Duration
+
+	mu     sync.Mutex
+	memory map[string]*cachedResponse
+	next   map[string]time.Time
+}
+
+// RoundTrip returns the cached response of the request or sends it.
+func (t *networkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, ok := cacheKey(req)
+	if !ok {
+		t.wait(req.URL.Host)
+		return t.base.RoundTrip(req)
+	}
+
+	if cached, ok := t.cached(key); ok {
+		return cached.response(req), nil
+	}
+
+	resp, err := t.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusGone {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCachedResponseSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Large responses, such as module zip files, are not cached.
+	if len(body) > maxCachedResponseSize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	cached := &cachedResponse{
+		Status:        resp.StatusCode,
+		Header:        resp.Header,
+		ContentLength: resp.ContentLength,
+		Body:          body,
+		Time:          time.Now(),
+	}
+
+	t.store(key, cached)
+
+	return cached.response(req), nil
+}
+
+// send sends the request once its host is below the rate limit, retrying
+// when the server answers with too many requests.
+func (t *networkTransport) send(req *http.Request) (*http.Response, error) {
+	for retries := 0; ; retries++ {
+		t.wait(req.URL.Host)
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || retries == maxRateLimitRetries {
+			return resp, err
+		}
+
+		delay, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		time.Sleep(delay)
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// cacheKey returns the cache key of the request, its method, url and, for
+// POST requests with a replayable body, the body.
+func cacheKey(req *http.Request) (string, bool) {
+	key := req.Method + " " + req.URL.String()
+
+	switch {
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		return key, true
+	case req.Method != http.MethodPost || req.GetBody == nil:
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", false
+	}
+
+	return key + " " + string(data), true
+}
+
+// wait blocks until the next request to the host is allowed by the rate
+// limit.
+func (t *networkTransport) wait(host string) {
+	if t.interval <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+
+	now := time.Now()
+
+	at := t.next[host]
+	if at.Before(now) {
+		at = now
+	}
+
+	t.next[host] = at.Add(t.interval)
+
+	t.mu.Unlock()
+
+	time.Sleep(at.Sub(now))
+}
+
+// cached returns the cached response of the request, from memory or from a
+// disk cache entry, younger than the ttl.
+func (t *networkTransport) cached(key string) (*cachedResponse, bool) {
+	t.mu.Lock()
+	cached, ok := t.memory[key]
+	t.mu.Unlock()
+
+	if ok && time.Since(cached.Time) <= t.ttl {
+		return cached, true
+	}
+
+	if t.cacheDir == "" {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(t.cacheFile(key))
+	if err != nil {
+		return nil, false
+	}
+
+	cached = &cachedResponse{}
+
+	err = json.Unmarshal(data, cached)
+	if err != nil || time.Since(cached.Time) > t.ttl {
+		return nil, false
+	}
+
+	t.mu.Lock()
+	t.memory[key] = cached
+	t.mu.Unlock()
+
+	return cached, true
+}
+
+// store caches the response in memory and, if configured, on disk. Failing
+// to write the disk cache only loses the entry.
+func (t *networkTransport) store(key string, cached *cachedResponse) {
+	t.mu.Lock()
+	t.memory[key] = cached
+	t.mu.Unlock()
+
+	if t.cacheDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(t.cacheDir, 0755); err != nil {
+		return
+	}
+
+	_ = writeFileAtomic(t.cacheFile(key), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// cacheFile returns the disk cache file of the request.
+func (t *networkTransport) cacheFile(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(t.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// response returns a response to the request with the cached contents.
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header,
+		ContentLength: c.ContentLength,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+		Request:       req,
+	}
+}
+
+// retryAfter returns the delay of a Retry-After header in seconds, capped.
+func retryAfter(value string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	delay := time.Duration(seconds) * time.Second
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+
+	return delay, true
+}
+
+// readCloser reads from a reader and closes a closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,200 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestNetwork(t *testing.T) {
+	published := time.Now().UTC().AddDate(0, 0, -1).Format(time.RFC3339)
+
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+		inFlight int
+		maxSeen  int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+
+		// The first request of the throttled module is rate limited.
+		if r.URL.Path == "/github.com/example/throttled/@v/v1.0.0.info" && count == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"Version":"v1.0.0","Time":%q}`, published)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modules := []string{
+		"github.com/example/a",
+		"github.com/example/b",
+		"github.com/example/c",
+		"github.com/example/d",
+		"github.com/example/e",
+		"github.com/example/f",
+		"github.com/example/g",
+		"github.com/example/throttled",
+	}
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n"
+	for _, module := range modules {
+		goMod += "\t" + module + " v1.0.0\n"
+	}
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod+")\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cooldown := func(network gomodguard.Network) []string {
+		config := gomodguard.Configuration{
+			Mode:      gomodguard.ModeBlock,
+			LintGoMod: true,
+			Blocked:   gomodguard.Blocked{Cooldown: gomodguard.Cooldown{Days: 7, ProxyURL: server.URL}},
+			Network:   network,
+		}
+
+		processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+		processor.SetBlockedModules()
+
+		got := []string{}
+		for _, result := range processor.ProcessFiles([]string{}) {
+			got = append(got, result.Module)
+		}
+
+		// The network state is kept on the processor, not the configuration.
+		if want := (gomodguard.Blocked{Cooldown: gomodguard.Cooldown{Days: 7, ProxyURL: server.URL}}); !reflect.DeepEqual(config.Blocked, want) {
+			t.Errorf("got '%+v' want '%+v'", config.Blocked, want)
+		}
+
+		return got
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	network := gomodguard.Network{Concurrency: 4, CacheDir: cacheDir}
+
+	for run := 0; run < 2; run++ {
+		if got := cooldown(network); !reflect.DeepEqual(got, modules) {
+			t.Errorf("got '%v' want '%v'", got, modules)
+		}
+	}
+
+	for _, module := range modules {
+		want := 1
+		if module == "github.com/example/throttled" {
+			want = 2
+		}
+
+		if got := requests["/"+module+"/@v/v1.0.0.info"]; got != want {
+			t.Errorf("got %d requests of %s want %d", got, module, want)
+		}
+	}
+
+	if maxSeen < 2 {
+		t.Errorf("got %d concurrent requests want more than 1", maxSeen)
+	}
+
+	cached, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cached) != len(modules) {
+		t.Errorf("got %d cached responses want %d", len(cached), len(modules))
+	}
+
+	// A new cache directory starts a new cache, 20 requests per second space
+	// the requests by 50ms.
+	start := time.Now()
+
+	if got := cooldown(gomodguard.Network{Concurrency: 4, RateLimit: 20, CacheDir: filepath.Join(dir, "limited")}); !reflect.DeepEqual(got, modules) {
+		t.Errorf("got '%v' want '%v'", got, modules)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Duration(len(modules)-1)*50*time.Millisecond {
+		t.Errorf("got %s for %d rate limited requests want at least %s", elapsed, len(modules), time.Duration(len(modules)-1)*50*time.Millisecond)
+	}
+
+	// Queries of the osv provider are cached by their body.
+	osvQueries := 0
+
+	osv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		osvQueries++
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"vulns":[{"id":"GO-2024-0001"}]}`))
+	}))
+	defer osv.Close()
+
+	for run := 0; run < 2; run++ {
+		config := gomodguard.Configuration{
+			Mode:      gomodguard.ModeBlock,
+			LintGoMod: true,
+			Blocked:   gomodguard.Blocked{DepsDev: gomodguard.DepsDev{Advisories: true}},
+			Metadata:  gomodguard.Metadata{Providers: []string{gomodguard.MetadataProviderOSV}, OSVURL: osv.URL},
+			Network:   network,
+		}
+
+		processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+		processor.SetBlockedModules()
+
+		if got := len(processor.ProcessFiles([]string{})); got != len(modules) {
+			t.Errorf("got %d results want %d", got, len(modules))
+		}
+	}
+
+	if osvQueries != len(modules) {
+		t.Errorf("got %d osv queries want %d", osvQueries, len(modules))
+	}
+
+	var invalid = []gomodguard.Network{
+		{Concurrency: -1},
+		{RateLimit: -1},
+		{CacheTTL: "soon"},
+	}
+
+	for _, network := range invalid {
+		_, err = gomodguard.NewProcessorFromModBytes(&gomodguard.Configuration{Network: network}, []byte(goMod+")\n"))
+		if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+			t.Errorf("got '%v' want an invalid configuration error for '%+v'", err, network)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gomodguard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	noticeHeader   = "This project includes the following third party modules.\n"
+	unknownLicense = "UNKNOWN"
+)
+
+var (
+	licenseFileNames     = []string{"LICENSE", "LICENSE.txt", "LICENSE.md", "LICENCE", "COPYING"}
+	attributionFileNames = append(append([]string{}, licenseFileNames...), "NOTICE")
+)
+
+// Attribution of a direct module dependency.
+type Attribution struct {
+	Module    string
+	Version   string
+	Licenses  []string
+	Copyright []string
+}
+
+// Attributions returns the attributions of all allowed direct module
+// dependencies sorted by module name. Licenses are detected using deps.dev
+// and copyright statements are read from the license files in the module cache.
+func (p *Processor) Attributions() []Attribution {
+	modCacheDir := p.modCache().dir
+	attributions := []Attribution{}
+
+	for _, require := range p.Modfile.Require {
+		if require.Indirect {
+			continue
+		}
+
+		moduleName := strings.TrimSpace(require.Mod.Path)
+		moduleVersion := strings.TrimSpace(require.Mod.Version)
+
+		if _, ok := p.blockedModulesFromModFile[moduleName]; ok {
+			continue
+		}
+
+		attribution := Attribution{
+			Module:    moduleName,
+			Version:   moduleVersion,
+			Licenses:  []string{unknownLicense},
+			Copyright: readCopyright(modCacheDir, require.Mod),
+		}
+
+		metadata, err := p.network().depsDev.Lookup(moduleName, moduleVersion)
+		if err == nil && !metadata.HasUnresolvableLicense() {
+			attribution.Licenses = metadata.Licenses
+		}
+
+		attributions = append(attributions, attribution)
+	}
+
+	sort.Slice(attributions, func(i, j int) bool {
+		return attributions[i].Module < attributions[j].Module
+	})
+
+	return attributions
+}
+
+// WriteNotice writes the attributions in a NOTICE file format.
+func WriteNotice(w io.Writer, attributions []Attribution) error {
+	_, err := fmt.Fprint(w, noticeHeader)
+	if err != nil {
+		return err
+	}
+
+	for i := range attributions {
+		_, err = fmt.Fprintf(w, "\n%s %s\nLicense: %s\n", attributions[i].Module, attributions[i].Version, strings.Join(attributions[i].Licenses, ", "))
+		if err != nil {
+			return err
+		}
+
+		for _, copyright := range attributions[i].Copyright {
+			_, err = fmt.Fprintln(w, copyright)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readCopyright returns the copyright statements found in the
+// license and notice files of a module in the module cache.
+func readCopyright(modCacheDir string, mod module.Version) []string {
+	copyright := []string{}
+
+	moduleDir, ok := moduleCacheDir(modCacheDir, mod)
+	if !ok {
+		return copyright
+	}
+
+	for _, attributionFileName := range attributionFileNames {
+		f, err := os.Open(filepath.Join(moduleDir, attributionFileName))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(strings.ToLower(line), "copyright") {
+				copyright = append(copyright, line)
+			}
+		}
+
+		f.Close()
+	}
+
+	return copyright
+}
+
+// goModCacheDir returns the module cache directory.
+func goModCacheDir() string {
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err == nil && strings.TrimSpace(string(out)) != "" {
+		return strings.TrimSpace(string(out))
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, _ := homedir.Dir()
+		gopath = filepath.Join(home, "go")
+	}
+
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
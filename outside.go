@@ -0,0 +1,132 @@
+package gomodguard
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Handling of linted files not belonging to the module linted against.
+const (
+	// OutsideModuleSkip skips files of other modules and files outside of
+	// any module with a warning.
+	OutsideModuleSkip = "skip"
+	// OutsideModuleLint lints files outside of the module against the module
+	// anyway.
+	OutsideModuleLint = "lint"
+	// OutsideModuleNearest lints files of other modules against the go.mod
+	// file of their module and skips files outside of any module.
+	OutsideModuleNearest = "nearest"
+)
+
+const (
+	errUnknownOutsideModule = "unknown outside_module %s, must be one of %s"
+	warnOtherModule         = "skipping %s, it belongs to the module in %s instead of %s"
+	warnOutsideModule       = "skipping %s, it is outside of the module in %s and of any other module"
+)
+
+// OutsideModules are the valid handlings of files outside of the module.
+var OutsideModules = []string{OutsideModuleSkip, OutsideModuleLint, OutsideModuleNearest}
+
+// validateOutsideModule returns an error if the handling of files outside of
+// the module is unknown.
+func validateOutsideModule(config *Configuration) error {
+	outsideModule := strings.TrimSpace(config.OutsideModule)
+	if outsideModule == "" || containsString(OutsideModules, outsideModule) {
+		return nil
+	}
+
+	return fmt.Errorf(errUnknownOutsideModule, outsideModule, strings.Join(OutsideModules, ", "))
+}
+
+// fileModuleDir returns the directory of the module the file belongs to, the
+// directory of the nearest go.mod file enclosing it or the module directory
+// of the processor. The empty directory is returned for files outside of any
+// module, such as GOPATH code or temporary files. Files of processors without
+// a module directory always belong to the module.
+func (p *Processor) fileModuleDir(filename string) string {
+	if p.moduleDir == "" {
+		return ""
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return p.moduleDir
+	}
+
+	if p.fileModuleDirs == nil {
+		// The go.mod file of the processor may have another name, such as
+		// with -modfile, so the walk stops at the module directory.
+		p.fileModuleDirs = map[string]string{p.moduleDir: p.moduleDir}
+	}
+
+	return findModuleDir(dir, p.fileModuleDirs)
+}
+
+// processOutsideModule handles a file not belonging to the module of the
+// processor. True is returned if the file was handled and must not be
+// linted against the module.
+func (p *Processor) processOutsideModule(ctx context.Context, filename string) bool {
+	if p.moduleDir == "" || p.Config == nil {
+		return false
+	}
+
+	outsideModule := strings.TrimSpace(p.Config.OutsideModule)
+	if outsideModule == OutsideModuleLint {
+		return false
+	}
+
+	moduleDir := p.fileModuleDir(filename)
+
+	switch {
+	case samePath(moduleDir, p.moduleDir):
+		return false
+	case moduleDir == "":
+		p.warnf(warnOutsideModule, filename, p.moduleDir)
+	case outsideModule == OutsideModuleNearest:
+		p.processOtherModule(ctx, moduleDir, filename)
+	default:
+		p.warnf(warnOtherModule, filename, moduleDir, p.moduleDir)
+	}
+
+	return true
+}
+
+// processOtherModule lints the file against the go.mod file of the module in
+// the directory. The processors of other modules are kept for the run, the
+// exemptions of the file move to the processor so they apply to its results.
+func (p *Processor) processOtherModule(ctx context.Context, moduleDir, filename string) {
+	other, ok := p.otherModules[moduleDir]
+	if !ok {
+		config := *p.Config
+		config.GoModPath = filepath.Join(moduleDir, goModFilename)
+
+		var err error
+
+		other, err = NewProcessor(&config)
+		if err != nil {
+			p.warnf("%s", err)
+		}
+
+		if p.otherModules == nil {
+			p.otherModules = map[string]*Processor{}
+		}
+
+		p.otherModules[moduleDir] = other
+	}
+
+	if other == nil {
+		p.warnf(warnOtherModule, filename, moduleDir, p.moduleDir)
+		return
+	}
+
+	other.collectWarnings = p.collectWarnings
+	other.networkState = p.network()
+	_ = other.processFiles(ctx, []string{filename}, nil)
+
+	p.Result = append(p.Result, other.Result...)
+	p.warnings = append(p.warnings, other.warnings...)
+	p.Exemptions = append(p.Exemptions, other.Exemptions...)
+	other.Result, other.warnings, other.Exemptions = []Result{}, nil, nil
+}
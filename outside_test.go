@@ -0,0 +1,81 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorOutsideModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app/go.mod":          "module example.com/app\n\nrequire github.com/foo/bar v1.0.0\n",
+		"app/app.go":          "package app\n\nimport _ \"github.com/foo/bar\"\n",
+		"app/tools/go.mod":    "module example.com/app/tools\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/bar/v2 v2.0.0\n)\n",
+		"app/tools/tools.go":  "package tools\n\nimport _ \"github.com/foo/bar/v2\"\n",
+		"app/tools/nolint.go": "package tools\n\nimport _ \"github.com/foo/bar\" //nolint:gomodguard\n",
+		"scratch/scratch.go":  "package scratch\n\nimport _ \"github.com/foo/bar\"\n",
+	}
+
+	writeTree(t, dir, files)
+
+	filenames := []string{
+		filepath.Join(dir, "app", "app.go"),
+		filepath.Join(dir, "app", "tools", "tools.go"),
+		filepath.Join(dir, "app", "tools", "nolint.go"),
+		filepath.Join(dir, "scratch", "scratch.go"),
+	}
+
+	var tests = []struct {
+		testName      string
+		outsideModule string
+		want          []string
+	}{
+		{"default", "", []string{"app.go github.com/foo/bar"}},
+		{"skip", gomodguard.OutsideModuleSkip, []string{"app.go github.com/foo/bar"}},
+		{"lint", gomodguard.OutsideModuleLint, []string{"app.go github.com/foo/bar", "scratch.go github.com/foo/bar"}},
+		{"nearest", gomodguard.OutsideModuleNearest, []string{"app.go github.com/foo/bar", "tools.go github.com/foo/bar/v2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+				Blocked:       gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": {}}, {"github.com/foo/bar/v2": {}}}},
+				GoModPath:     filepath.Join(dir, "app", "go.mod"),
+				OutsideModule: tt.outsideModule,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, result := range processor.ProcessFiles(filenames) {
+				got = append(got, filepath.Base(result.FileName)+" "+result.Module)
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got '%v' want '%v'", got, tt.want)
+			}
+		})
+	}
+
+	_, err = gomodguard.NewProcessor(&gomodguard.Configuration{
+		GoModPath:     filepath.Join(dir, "app", "go.mod"),
+		OutsideModule: "ignore",
+	})
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
@@ -0,0 +1,43 @@
+package gomodguard
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// normalizePath returns the file name with slash separators, cleaned of ./
+// elements and with an upper case volume letter, so file names recorded on
+// Windows, macOS and Linux agents compare equal.
+func normalizePath(filename string) string {
+	if filename == "" {
+		return ""
+	}
+
+	filename = path.Clean(filepath.ToSlash(filename))
+
+	if len(filename) >= 2 && filename[1] == ':' && isASCIILetter(filename[0]) {
+		filename = strings.ToUpper(filename[:1]) + filename[1:]
+	}
+
+	return filename
+}
+
+// isASCIILetter returns true if the byte is an ASCII letter.
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// samePath returns true if the file names refer to the same file. Case is
+// ignored on Windows and macOS, whose file systems are case-insensitive by
+// default.
+func samePath(a, b string) bool {
+	a, b = normalizePath(a), normalizePath(b)
+
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+
+	return a == b
+}
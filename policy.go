@@ -0,0 +1,64 @@
+package gomodguard
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	errUnknownPolicyURLRule = "unknown rule %s in policy_urls, must be one of %s"
+	errInvalidPolicyURL     = "invalid policy url %s of %s, must be an absolute http or https url"
+)
+
+// validatePolicyURLs returns an error if a policy url of a rule or blocked
+// entry is not an absolute http or https url, or a rule is unknown.
+func validatePolicyURLs(config *Configuration) error {
+	for rule, policyURL := range config.PolicyURLs {
+		if !isRule(rule) {
+			return fmt.Errorf(errUnknownPolicyURLRule, rule, strings.Join(Rules, ", "))
+		}
+
+		err := validatePolicyURL(policyURL, rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, modules := range []BlockedModules{config.Blocked.Modules, config.Blocked.Regex, config.Generated.Modules} {
+		for i := range modules {
+			for name, blockedModule := range modules[i] {
+				err := validatePolicyURL(blockedModule.PolicyURL, name)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := range config.Blocked.Versions {
+		for name, blockedVersion := range config.Blocked.Versions[i] {
+			err := validatePolicyURL(blockedVersion.PolicyURL, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePolicyURL returns an error if the policy url is set and not an
+// absolute http or https url.
+func validatePolicyURL(policyURL, name string) error {
+	if policyURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(policyURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf(errInvalidPolicyURL, policyURL, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestPolicyURLs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/example/blocked v1.0.0\n\tgithub.com/example/unlisted v1.0.0\n)\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t_ \"github.com/example/blocked\"\n\t_ \"github.com/example/unlisted\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{Modules: []string{"github.com/example/blocked"}},
+		Blocked: gomodguard.Blocked{Modules: gomodguard.BlockedModules{{
+			"github.com/example/blocked": {PolicyURL: "https://wiki.example.com/policy/blocked"},
+		}}},
+		PolicyURLs: map[string]string{
+			gomodguard.RuleInBlockedList:    "https://wiki.example.com/policy/blocked-list",
+			gomodguard.RuleNotInAllowedList: "https://wiki.example.com/policy/allowed-list",
+		},
+	}
+
+	processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+	processor.SetBlockedModules()
+
+	results := processor.ProcessFiles([]string{filename})
+
+	want := map[string]string{
+		"github.com/example/blocked":  "https://wiki.example.com/policy/blocked",
+		"github.com/example/unlisted": "https://wiki.example.com/policy/allowed-list",
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("got '%+v' want '%v'", results, want)
+	}
+
+	for _, result := range results {
+		if result.PolicyURL != want[result.Module] {
+			t.Errorf("got '%s' want '%s'", result.PolicyURL, want[result.Module])
+		}
+
+		if !strings.HasSuffix(result.String(), "(policy: "+want[result.Module]+")") {
+			t.Errorf("got '%s' want the policy url", result.String())
+		}
+	}
+
+	for _, policyURLs := range []map[string]string{
+		{"no_such_rule": "https://wiki.example.com"},
+		{gomodguard.RuleInBlockedList: "wiki/policy"},
+		{gomodguard.RuleInBlockedList: "ftp://wiki.example.com/policy"},
+	} {
+		config.PolicyURLs = policyURLs
+
+		_, err = gomodguard.NewProcessorFromRequires(&config, "github.com/ryancurrah/example", nil)
+		if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+			t.Errorf("got '%v' want an invalid configuration error", err)
+		}
+	}
+}
+
+func TestWriteReportPolicyURL(t *testing.T) {
+	results := []gomodguard.Result{
+		{
+			FileName:   "main.go",
+			LineNumber: 4,
+			Position:   token.Position{Filename: "main.go", Line: 4, Column: 2},
+			Reason:     "import of package `github.com/foo/bar` is blocked, 100% <not> allowed.",
+			Severity:   gomodguard.SeverityError,
+			Rule:       gomodguard.RuleInBlockedList,
+			Module:     "github.com/foo/bar",
+			PolicyURL:  "https://wiki.example.com/policy?rule=blocked&id=1",
+		},
+		{
+			FileName:   "pkg/a,b.go",
+			LineNumber: 3,
+			Reason:     "import of package `github.com/foo/baz` is blocked.",
+			Severity:   gomodguard.SeverityWarning,
+			Rule:       gomodguard.RuleNotInAllowedList,
+			Module:     "github.com/foo/baz",
+		},
+	}
+
+	var tests = []struct {
+		testName string
+		format   string
+		want     []string
+	}{
+		{
+			"github",
+			gomodguard.ReportGitHub,
+			[]string{
+				"::error file=main.go,line=4,col=2,title=gomodguard in_blocked_list::import of package `github.com/foo/bar` is blocked, 100%25 <not> allowed. See https://wiki.example.com/policy?rule=blocked&id=1\n",
+				"::warning file=pkg/a%2Cb.go,line=3,title=gomodguard not_in_allowed_list::import of package `github.com/foo/baz` is blocked.\n",
+			},
+		},
+		{
+			"html",
+			gomodguard.ReportHTML,
+			[]string{
+				`<a href="https://wiki.example.com/policy?rule=blocked&amp;id=1">Policy</a>`,
+				"100% &lt;not&gt; allowed.",
+				"<p>2 result(s)</p>",
+			},
+		},
+		{
+			"sarif",
+			gomodguard.ReportSARIF,
+			[]string{`"markdown": "import of package ` + "`github.com/foo/bar`" + ` is blocked, 100% \u003cnot\u003e allowed. [Policy](https://wiki.example.com/policy?rule=blocked\u0026id=1)"`},
+		},
+		{
+			"json",
+			gomodguard.ReportJSON,
+			[]string{`"policy_url":"https://wiki.example.com/policy?rule=blocked\u0026id=1"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := gomodguard.WriteReport(&buf, tt.format, results)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("got '%s' want '%s'", buf.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGitHubAPIURL   = "https://api.github.com"
+	popularityTimeout     = 10 * time.Second
+	errFetchingPopularity = "unable to fetch popularity of %s: %w"
+	errPopularityStatus   = "unexpected popularity api status code %d for %s"
+	popularityUnknown     = -1
+)
+
+var (
+	blockReasonTooFewStars        = "import of package `%%s` is discouraged because the module has %d stars which is below the minimum of %d."
+	blockReasonTooFewDependents   = "import of package `%%s` is discouraged because the module has %d dependents which is below the minimum of %d."
+	blockReasonTooFewContributors = "import of package `%%s` is discouraged because the module has %d contributors which is below the minimum of %d."
+)
+
+// Popularity warns about direct modules with low adoption or a single
+// maintainer. Stars and dependents are fetched from deps.dev and contributors
+// from GitHub, only modules hosted on github.com have stars and contributors.
+// Results are warnings unless enforced.
+type Popularity struct {
+	MinStars        int    `yaml:"min_stars" json:"min_stars"`
+	MinDependents   int    `yaml:"min_dependents" json:"min_dependents"`
+	MinContributors int    `yaml:"min_contributors" json:"min_contributors"`
+	APIURL          string `yaml:"api_url" json:"api_url"`
+	GitHubAPIURL    string `yaml:"github_api_url" json:"github_api_url"`
+	Enforce         bool   `yaml:"enforce" json:"enforce"`
+	transport       http.RoundTripper
+}
+
+// PopularityMetrics of a module version, unknown metrics are -1.
+type PopularityMetrics struct {
+	Stars        int
+	Dependents   int
+	Contributors int
+}
+
+// IsEnabled returns true if any minimum is configured.
+func (p *Popularity) IsEnabled() bool {
+	return p.MinStars > 0 || p.MinDependents > 0 || p.MinContributors > 0
+}
+
+// Lookup returns the popularity metrics of a module version. Only the metrics
+// with a configured minimum are fetched.
+func (p *Popularity) Lookup(moduleName, moduleVersion string) (*PopularityMetrics, error) {
+	metrics := &PopularityMetrics{Stars: popularityUnknown, Dependents: popularityUnknown, Contributors: popularityUnknown}
+	project, hasProject := scorecardProject(moduleName)
+
+	depsDevURL := p.APIURL
+	if depsDevURL == "" {
+		depsDevURL = defaultDepsDevAPIURL
+	}
+
+	gitHubURL := p.GitHubAPIURL
+	if gitHubURL == "" {
+		gitHubURL = defaultGitHubAPIURL
+	}
+
+	if p.MinStars > 0 && hasProject {
+		result := struct {
+			StarsCount int `json:"starsCount"`
+		}{}
+
+		err := p.get(moduleName, fmt.Sprintf("%s/v3/projects/%s", strings.TrimRight(depsDevURL, "/"), url.PathEscape(project)), &result)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics.Stars = result.StarsCount
+	}
+
+	if p.MinDependents > 0 {
+		result := struct {
+			DependentCount int `json:"dependentCount"`
+		}{}
+
+		err := p.get(moduleName, fmt.Sprintf("%s/v3alpha/systems/go/packages/%s/versions/%s:dependents",
+			strings.TrimRight(depsDevURL, "/"), url.PathEscape(moduleName), url.PathEscape(moduleVersion)), &result)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics.Dependents = result.DependentCount
+	}
+
+	if p.MinContributors > 0 && hasProject {
+		result := []struct {
+			Login string `json:"login"`
+		}{}
+
+		err := p.get(moduleName, fmt.Sprintf("%s/repos/%s/contributors?per_page=%d",
+			strings.TrimRight(gitHubURL, "/"), strings.TrimPrefix(project, "github.com/"), p.MinContributors), &result)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics.Contributors = len(result)
+	}
+
+	return metrics, nil
+}
+
+// get decodes the response of the api url into v.
+func (p *Popularity) get(moduleName, apiURL string, v interface{}) error {
+	client := &http.Client{Timeout: popularityTimeout, Transport: p.transport}
+
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		return fmt.Errorf(errFetchingPopularity, moduleName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(errPopularityStatus, resp.StatusCode, moduleName)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf(errFetchingPopularity, moduleName, err)
+	}
+
+	return nil
+}
+
+// Reasons returns the reasons the module is discouraged by the popularity rules.
+func (p *Popularity) Reasons(metrics *PopularityMetrics) []string {
+	reasons := []string{}
+
+	if p.MinStars > 0 && metrics.Stars != popularityUnknown && metrics.Stars < p.MinStars {
+		reasons = append(reasons, fmt.Sprintf(blockReasonTooFewStars, metrics.Stars, p.MinStars))
+	}
+
+	if p.MinDependents > 0 && metrics.Dependents != popularityUnknown && metrics.Dependents < p.MinDependents {
+		reasons = append(reasons, fmt.Sprintf(blockReasonTooFewDependents, metrics.Dependents, p.MinDependents))
+	}
+
+	if p.MinContributors > 0 && metrics.Contributors != popularityUnknown && metrics.Contributors < p.MinContributors {
+		reasons = append(reasons, fmt.Sprintf(blockReasonTooFewContributors, metrics.Contributors, p.MinContributors))
+	}
+
+	return reasons
+}
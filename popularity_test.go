@@ -0,0 +1,82 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestPopularityLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/projects/github.com%2Fsomeorg%2Fsomemodule":
+			fmt.Fprint(w, `{"starsCount": 3}`)
+		case "/v3alpha/systems/go/packages/github.com%2Fsomeorg%2Fsomemodule/versions/v1.0.0:dependents":
+			fmt.Fprint(w, `{"dependentCount": 12}`)
+		case "/repos/someorg/somemodule/contributors":
+			fmt.Fprint(w, `[{"login": "someone"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	popularity := gomodguard.Popularity{
+		MinStars:        10,
+		MinDependents:   10,
+		MinContributors: 2,
+		APIURL:          server.URL,
+		GitHubAPIURL:    server.URL,
+	}
+
+	var tests = []struct {
+		testName    string
+		moduleName  string
+		wantErr     bool
+		wantMetrics *gomodguard.PopularityMetrics
+		wantReasons []string
+	}{
+		{
+			"github module",
+			"github.com/someorg/somemodule",
+			false,
+			&gomodguard.PopularityMetrics{Stars: 3, Dependents: 12, Contributors: 1},
+			[]string{
+				"import of package `%s` is discouraged because the module has 3 stars which is below the minimum of 10.",
+				"import of package `%s` is discouraged because the module has 1 contributors which is below the minimum of 2.",
+			},
+		},
+		{
+			"module without metadata",
+			"example.com/somemodule",
+			true,
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			metrics, err := popularity.Lookup(tt.moduleName, "v1.0.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(metrics, tt.wantMetrics) {
+				t.Errorf("got '%+v' want '%+v'", metrics, tt.wantMetrics)
+			}
+
+			if reasons := popularity.Reasons(metrics); !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("got '%+v' want '%+v'", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
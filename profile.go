@@ -0,0 +1,136 @@
+package gomodguard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	errUnknownProfile      = "unknown profile %s, must be one of %s"
+	errUnknownSeverity     = "unknown severity %s of %s, must be one of %s"
+	errUnknownSeverityRule = "unknown rule or rule family %s in severities"
+	severityOff            = "off"
+)
+
+// Severities a rule or rule family can be configured with, off drops the
+// results of the rule.
+var Severities = []string{string(SeverityError), string(SeverityWarning), severityOff}
+
+// Profile bundles rule family settings and severities, so one configuration
+// can serve repositories needing different strictness.
+type Profile struct {
+	Rules      map[string]bool   `yaml:"rules" json:"rules"`
+	Severities map[string]string `yaml:"severities" json:"severities"`
+}
+
+// ApplyProfile applies the rule families and severities of the profile on
+// top of the configured ones.
+func (c *Configuration) ApplyProfile(name string) error {
+	name = strings.TrimSpace(name)
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf(errUnknownProfile, name, strings.Join(c.profileNames(), ", "))
+	}
+
+	// The maps may be shared with the configurations of other modules.
+	rules := make(map[string]bool, len(c.Rules)+len(profile.Rules))
+	for family, enabled := range c.Rules {
+		rules[family] = enabled
+	}
+
+	for family, enabled := range profile.Rules {
+		rules[strings.TrimSpace(family)] = enabled
+	}
+
+	severities := make(map[string]string, len(c.Severities)+len(profile.Severities))
+	for rule, severity := range c.Severities {
+		severities[rule] = severity
+	}
+
+	for rule, severity := range profile.Severities {
+		severities[strings.TrimSpace(rule)] = severity
+	}
+
+	c.Rules, c.Severities, c.Profile = rules, severities, name
+
+	return nil
+}
+
+// profileNames returns the sorted names of the profiles.
+func (c *Configuration) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// RuleSeverity returns the configured severity of the rule, falling back to
+// the severity of its rule family and then to the given severity. False is
+// returned if the rule is off.
+func (c *Configuration) RuleSeverity(rule string, s Severity) (Severity, bool) {
+	configured, ok := c.Severities[rule]
+	if !ok {
+		configured, ok = c.Severities[ruleFamilies[rule]]
+	}
+
+	if !ok {
+		return s, true
+	}
+
+	configured = strings.TrimSpace(strings.ToLower(configured))
+	if configured == severityOff {
+		return s, false
+	}
+
+	return Severity(configured), true
+}
+
+// validateSeverities returns an error if a severity of the configuration or a
+// profile is unknown or configured for an unknown rule or rule family.
+func validateSeverities(config *Configuration) error {
+	all := []map[string]string{config.Severities}
+	for _, name := range config.profileNames() {
+		all = append(all, config.Profiles[name].Severities)
+	}
+
+	families := RuleFamilies()
+
+	for _, severities := range all {
+		for rule, severity := range severities {
+			if _, ok := ruleFamilies[rule]; !ok && !containsString(families, rule) {
+				return fmt.Errorf(errUnknownSeverityRule, rule)
+			}
+
+			if !containsString(Severities, strings.TrimSpace(strings.ToLower(severity))) {
+				return fmt.Errorf(errUnknownSeverity, severity, rule, strings.Join(Severities, ", "))
+			}
+		}
+	}
+
+	for _, name := range config.profileNames() {
+		for family := range config.Profiles[name].Rules {
+			if !containsString(families, family) {
+				return fmt.Errorf(errUnknownRuleFamily, family, strings.Join(families, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// containsString returns true if the value is in the values.
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/module"
+)
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requires := []module.Version{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/foo/baz", Version: "v1.0.0"},
+	}
+
+	profiles := map[string]gomodguard.Profile{
+		"lenient": {Severities: map[string]string{gomodguard.RuleFamilyModule: "warning", gomodguard.RuleBlockedVersion: "off"}},
+		"strict":  {Severities: map[string]string{gomodguard.RuleInBlockedList: "error"}},
+	}
+
+	var tests = []struct {
+		testName     string
+		profile      string
+		wantSeverity map[string]gomodguard.Severity
+	}{
+		{
+			"no profile",
+			"",
+			map[string]gomodguard.Severity{gomodguard.RuleInBlockedList: gomodguard.SeverityWarning, gomodguard.RuleBlockedVersion: gomodguard.SeverityError},
+		},
+		{
+			"lenient",
+			"lenient",
+			map[string]gomodguard.Severity{gomodguard.RuleInBlockedList: gomodguard.SeverityWarning},
+		},
+		{
+			"strict",
+			"strict",
+			map[string]gomodguard.Severity{gomodguard.RuleInBlockedList: gomodguard.SeverityError, gomodguard.RuleBlockedVersion: gomodguard.SeverityError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := &gomodguard.Configuration{
+				Mode: gomodguard.ModeBlock,
+				Blocked: gomodguard.Blocked{
+					Modules:  gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{EnforceAfter: "2999-01-01"}}},
+					Versions: gomodguard.BlockedVersions{{"github.com/foo/baz": gomodguard.BlockedVersion{Version: "< 1.2.0"}}},
+				},
+				Profiles: profiles,
+			}
+
+			if tt.profile != "" {
+				err := config.ApplyProfile(tt.profile)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			processor, err := gomodguard.NewProcessorFromRequires(config, "github.com/ryancurrah/example", requires)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]gomodguard.Severity{}
+			for _, result := range processor.ProcessFiles([]string{filename}) {
+				got[result.Rule] = result.Severity
+			}
+
+			if len(got) != len(tt.wantSeverity) {
+				t.Fatalf("got '%v' want '%v'", got, tt.wantSeverity)
+			}
+
+			for rule, severity := range tt.wantSeverity {
+				if got[rule] != severity {
+					t.Errorf("got '%v' want '%v'", got, tt.wantSeverity)
+				}
+			}
+		})
+	}
+
+	config := &gomodguard.Configuration{Profiles: profiles}
+	if err := config.ApplyProfile("unknown"); err == nil {
+		t.Errorf("got no error want an error for an unknown profile")
+	}
+
+	config = &gomodguard.Configuration{Severities: map[string]string{gomodguard.RuleInBlockedList: "fatal"}}
+	if _, err := gomodguard.NewProcessorFromRequires(config, "github.com/ryancurrah/example", nil); err == nil {
+		t.Errorf("got no error want an error for an unknown severity")
+	}
+}
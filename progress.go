@@ -0,0 +1,54 @@
+package gomodguard
+
+import (
+	"encoding/json"
+)
+
+// Progress events written as JSON lines to the progress writer of a
+// Processor.
+const (
+	progressStarted  = "started"
+	progressFinished = "finished"
+	progressFinding  = "finding"
+)
+
+// progressEvent is a progress event. Findings are the results of a file once
+// the exemptions of its package are applied.
+type progressEvent struct {
+	Event   string      `json:"event"`
+	File    string      `json:"file"`
+	Finding *jsonResult `json:"finding,omitempty"`
+}
+
+// emitProgress writes a progress event if a progress writer is set. Progress
+// is best effort, write errors do not fail linting.
+func (p *Processor) emitProgress(event, filename string, result *Result) {
+	if p.Progress == nil {
+		return
+	}
+
+	e := progressEvent{Event: event, File: filename}
+
+	if result != nil {
+		finding := newJSONResult(result)
+		e.Finding = &finding
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	_, _ = p.Progress.Write(append(data, '\n'))
+}
+
+// emitFindings writes a finding event for each result.
+func (p *Processor) emitFindings(results []Result) {
+	if p.Progress == nil {
+		return
+	}
+
+	for i := range results {
+		p.emitProgress(progressFinding, results[i].FileName, &results[i])
+	}
+}
@@ -0,0 +1,56 @@
+package gomodguard_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorProgress(t *testing.T) {
+	filteredFiles := gomodguard.GetFilteredFiles(cwd, false, []string{"./..."})
+
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	processor.Progress = &buf
+
+	results := processor.ProcessFiles(filteredFiles)
+
+	events := map[string]int{}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event struct {
+			Event   string `json:"event"`
+			File    string `json:"file"`
+			Finding *struct {
+				Rule string `json:"rule"`
+			} `json:"finding"`
+		}
+
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			t.Fatalf("got invalid event '%s': %v", scanner.Text(), err)
+		}
+
+		if event.File == "" || (event.Event == "finding") != (event.Finding != nil) {
+			t.Errorf("got event '%s'", scanner.Text())
+		}
+
+		events[event.Event]++
+	}
+
+	want := map[string]int{"started": len(filteredFiles), "finished": len(filteredFiles), "finding": len(results)}
+
+	for event, count := range want {
+		if events[event] != count {
+			t.Errorf("got '%d' %s events want '%d'", events[event], event, count)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gomodguard
+
+import (
+	"strings"
+)
+
+// configKeySeparator separates the keys of a configuration key path, module
+// paths contain dots so they cannot be joined with dots.
+const configKeySeparator = "\x00"
+
+// ruleConfigKeys are the configuration keys results of a rule come from when
+// the result was not matched by an entry.
+var ruleConfigKeys = map[string][]string{
+	RuleNotInAllowedList:      {"allowed"},
+	RuleInBlockedList:         {"blocked", "modules"},
+	RuleBlockedVersion:        {"blocked", "versions"},
+	RuleLocalReplaceDirective: {"blocked", "local_replace_directives"},
+	RuleScorecard:             {"blocked", "scorecard"},
+	RuleLicense:               {"blocked", "deps_dev", "licenses"},
+	RuleDepsDev:               {"blocked", "deps_dev"},
+	RuleMajorVersionMismatch:  {"blocked", "major_version_mismatch"},
+	RuleVCS:                   {"blocked", "vcs"},
+	RulePopularity:            {"blocked", "popularity"},
+	RuleLayerViolation:        {"internal", "layers"},
+	RuleImportCycle:           {"internal", "import_cycles"},
+	RuleConfusableImport:      {"blocked", "confusable_imports"},
+	RuleTyposquat:             {"blocked", "typosquatting"},
+	RuleCooldown:              {"blocked", "cooldown"},
+	RuleBOMDrift:              {"bom"},
+	RuleDuplicateCategory:     {"categories"},
+	RuleSizeBudget:            {"blocked", "size_budget"},
+	RuleDisallowedReplace:     {"blocked", "replaces", "disallowed_targets"},
+	RuleBlockedRequirement:    {"deep"},
+}
+
+// Provenance is the configuration file and line a result was matched by, so
+// policies spread over several files can be debugged from reports. Key is
+// the dotted configuration key, Line is 0 for entries of a blocked modules
+// url.
+type Provenance struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	Key  string `json:"key"`
+}
+
+// configSource is the file a configuration was read from and the lines of
+// its keys, by key path.
+type configSource struct {
+	filename string
+	lines    map[string]int
+}
+
+// parseConfigSource returns the lines of the keys of a block style yaml
+// configuration. Keys of list items are keys of the list, so the entry of a
+// blocked module is at blocked, modules and its module path. Scalar list
+// items are recorded as keys of the list as well.
+//
+// The yaml package does not report lines, so the lines are found by the
+// indentation of the keys. Keys inside flow collections, such as
+// modules: [a, b], and keys merged from anchors are not found, results they
+// match get the line of the nearest enclosing key found instead.
+func parseConfigSource(filename string, data []byte) *configSource {
+	type parent struct {
+		indent int
+		key    string
+	}
+
+	source := &configSource{filename: filename, lines: map[string]int{}}
+	parents := []parent{}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+
+		content := strings.TrimLeft(line, " ")
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" {
+			continue
+		}
+
+		indent := len(line) - len(content)
+		item := false
+
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			rest := strings.TrimLeft(content[1:], " ")
+			indent += len(content) - len(rest)
+			content, item = rest, true
+		}
+
+		// A bare list item has its value on the lines below.
+		if content == "" {
+			continue
+		}
+
+		key, isMapping := yamlKey(content)
+		if !isMapping {
+			if !item {
+				continue
+			}
+
+			key = yamlScalar(content)
+		}
+
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		path := make([]string, 0, len(parents)+1)
+		for _, p := range parents {
+			path = append(path, p.key)
+		}
+
+		path = append(path, key)
+
+		if _, ok := source.lines[strings.Join(path, configKeySeparator)]; !ok {
+			source.lines[strings.Join(path, configKeySeparator)] = n + 1
+		}
+
+		if isMapping {
+			parents = append(parents, parent{indent: indent, key: key})
+		}
+	}
+
+	return source
+}
+
+// yamlKey returns the key of a yaml mapping line, false if the line is not a
+// mapping.
+func yamlKey(content string) (string, bool) {
+	if content == "" || strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+		return "", false
+	}
+
+	if quote := content[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(content[1:], quote)
+		if end < 0 {
+			return "", false
+		}
+
+		rest := content[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", false
+		}
+
+		return content[1 : end+1], true
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] == '#' && i > 0 && content[i-1] == ' ' {
+			return "", false
+		}
+
+		if content[i] == ':' && (i == len(content)-1 || content[i+1] == ' ') {
+			return strings.TrimSpace(content[:i]), true
+		}
+	}
+
+	return "", false
+}
+
+// yamlScalar returns a scalar yaml value without its comment and quotes.
+func yamlScalar(content string) string {
+	if i := strings.Index(content, " #"); i >= 0 {
+		content = content[:i]
+	}
+
+	content = strings.TrimSpace(content)
+
+	if len(content) >= 2 && (content[0] == '"' || content[0] == '\'') && content[len(content)-1] == content[0] {
+		content = content[1 : len(content)-1]
+	}
+
+	return strings.TrimSpace(content)
+}
+
+// provenance returns the provenance of the longest prefix of the key path
+// found in the configuration file, nil if no prefix is found.
+func (s *configSource) provenance(path []string) *Provenance {
+	for n := len(path); n > 0; n-- {
+		if line, ok := s.lines[strings.Join(path[:n], configKeySeparator)]; ok {
+			return &Provenance{File: s.filename, Line: line, Key: strings.Join(path[:n], ".")}
+		}
+	}
+
+	return nil
+}
+
+// provenance returns the provenance of the block reason, the configuration
+// entry matching the result or otherwise the configuration of its rule. Nil
+// is returned if the configuration was not read from a file or the rule is
+// not configured in the file, such as rules enabled by flags. Blocked modules
+// not in the file come from the blocked modules url.
+func (p *Processor) provenance(r blockReason) *Provenance {
+	if p.Config == nil || p.Config.source == nil {
+		return nil
+	}
+
+	path := r.source
+	if len(path) == 0 {
+		path = ruleConfigKeys[r.rule]
+	}
+
+	if len(path) == 0 {
+		return nil
+	}
+
+	if len(path) == 3 && path[0] == "blocked" && path[1] == "modules" && p.Config.Blocked.ModulesURL != "" {
+		if _, ok := p.Config.source.lines[strings.Join(path, configKeySeparator)]; !ok {
+			return &Provenance{File: p.Config.Blocked.ModulesURL, Key: strings.Join(path, ".")}
+		}
+	}
+
+	return p.Config.source.provenance(path)
+}
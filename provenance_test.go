@@ -0,0 +1,116 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestResultProvenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, ".gomodguard.yaml")
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\nrequire (\n\tgithub.com/example/blocked v1.0.0\n\tgithub.com/example/old v1.0.0\n\tgithub.com/example/regex v1.0.0\n\tgithub.com/example/unlisted v1.0.0\n)\n",
+		"app.go": "package app\n\nimport (\n\t_ \"github.com/example/blocked\"\n\t_ \"github.com/example/old\"\n\t_ \"github.com/example/regex\"\n\t_ \"github.com/example/unlisted\"\n)\n",
+		".gomodguard.yaml": `# Policy of the app.
+allowed:
+  modules:
+    - github.com/example/old  # Allowed at recent versions
+    - github.com/example/blocked
+blocked:
+  modules:
+    - github.com/example/other:
+        reason: "not used."
+    - "github.com/example/blocked":
+        reason: "use github.com/example/other."
+  regex:
+    - ^github\.com/example/re.*$:
+        reason: "no regex."
+  versions:
+    - github.com/example/old:
+        version: "< 2.0.0"
+`,
+	}
+
+	writeTree(t, dir, files)
+
+	config, err := gomodguard.GetConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.GoEnv = map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")}
+
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filepath.Join(dir, "app.go")})
+
+	want := map[string]gomodguard.Provenance{
+		gomodguard.RuleInBlockedList + " github.com/example/blocked":     {File: configFile, Line: 10, Key: "blocked.modules.github.com/example/blocked"},
+		gomodguard.RuleBlockedVersion + " github.com/example/old":        {File: configFile, Line: 16, Key: "blocked.versions.github.com/example/old"},
+		gomodguard.RuleInBlockedList + " github.com/example/regex":       {File: configFile, Line: 13, Key: `blocked.regex.^github\.com/example/re.*$`},
+		gomodguard.RuleNotInAllowedList + " github.com/example/unlisted": {File: configFile, Line: 2, Key: "allowed"},
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("got '%+v' want '%+v'", results, want)
+	}
+
+	for _, result := range results {
+		key := result.Rule + " " + result.Module
+
+		if result.Provenance == nil || *result.Provenance != want[key] {
+			t.Errorf("got '%+v' want '%+v' of %s", result.Provenance, want[key], key)
+		}
+	}
+}
+
+func TestResultProvenanceBareListItem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, ".gomodguard.yaml")
+
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\nrequire github.com/example/blocked v1.0.0\n",
+		"app.go":           "package app\n\nimport _ \"github.com/example/blocked\"\n",
+		".gomodguard.yaml": "blocked:\n  modules:\n    -\n      github.com/example/blocked:\n        reason: x\n",
+	}
+
+	writeTree(t, dir, files)
+
+	config, err := gomodguard.GetConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.GoEnv = map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")}
+
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filepath.Join(dir, "app.go")})
+
+	want := gomodguard.Provenance{File: configFile, Line: 4, Key: "blocked.modules.github.com/example/blocked"}
+
+	if len(results) != 1 || results[0].Provenance == nil || *results[0].Provenance != want {
+		t.Fatalf("got '%+v' want '%+v'", results, want)
+	}
+}
@@ -0,0 +1,373 @@
+package gomodguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// Built-in metadata providers.
+const (
+	MetadataProviderDepsDev  = "deps_dev"
+	MetadataProviderOSV      = "osv"
+	MetadataProviderProxy    = "proxy"
+	MetadataProviderModCache = "mod_cache"
+)
+
+const (
+	defaultOSVAPIURL             = "https://api.osv.dev"
+	osvTimeout                   = 10 * time.Second
+	osvEcosystem                 = "Go"
+	errFetchingOSV               = "unable to fetch osv vulnerabilities for %s@%s: %w"
+	errOSVStatus                 = "unexpected osv api status code %d for %s@%s"
+	errFetchingLatestVersion     = "unable to fetch latest version of %s: %w"
+	errUnknownMetadataProvider   = "unknown metadata provider %s, must be one of %s"
+	errDuplicateMetadataProvider = "metadata provider %s is already registered"
+)
+
+// ErrMetadataNotFound is returned, possibly wrapped, by metadata providers
+// without metadata for a module version. Other providers are still used.
+var ErrMetadataNotFound = fmt.Errorf("module metadata not found")
+
+// MetadataProvider provides the metadata of module versions, such as their
+// licenses, deprecation and vulnerabilities. Providers without metadata for a
+// module version return ErrMetadataNotFound. Providers not knowing licenses
+// leave them nil, an empty list is a module without licenses.
+type MetadataProvider interface {
+	Lookup(moduleName, moduleVersion string) (*ModuleMetadata, error)
+}
+
+// Metadata configures where module metadata is read from. Providers are
+// looked up in order and their metadata is merged, the first provider
+// knowing the licenses, deprecation or dependency count of a module wins and
+// advisories are combined. Without providers deps.dev is
+// used. Built-in providers are deps_dev, osv, proxy and mod_cache, others
+// are registered with RegisterMetadataProvider.
+type Metadata struct {
+	Providers []string `yaml:"providers" json:"providers"`
+	OSVURL    string   `yaml:"osv_url" json:"osv_url"`
+	ProxyURL  string   `yaml:"proxy_url" json:"proxy_url"`
+}
+
+var (
+	metadataProvidersMu sync.Mutex
+	metadataProviders   = map[string]MetadataProvider{}
+)
+
+// RegisterMetadataProvider registers a metadata provider under a name, so
+// configurations can list it in the metadata providers, for example to back
+// the license and deprecation rules with an internal system. Built-in
+// providers cannot be replaced and a name can only be registered once.
+func RegisterMetadataProvider(name string, provider MetadataProvider) error {
+	name = strings.TrimSpace(name)
+
+	metadataProvidersMu.Lock()
+	defer metadataProvidersMu.Unlock()
+
+	if _, ok := metadataProviders[name]; ok || isBuiltinMetadataProvider(name) {
+		return fmt.Errorf(errDuplicateMetadataProvider, name)
+	}
+
+	metadataProviders[name] = provider
+
+	return nil
+}
+
+// registeredMetadataProvider returns the registered metadata provider.
+func registeredMetadataProvider(name string) (MetadataProvider, bool) {
+	metadataProvidersMu.Lock()
+	defer metadataProvidersMu.Unlock()
+
+	provider, ok := metadataProviders[name]
+
+	return provider, ok
+}
+
+// isBuiltinMetadataProvider returns true for the names of the built-in
+// metadata providers.
+func isBuiltinMetadataProvider(name string) bool {
+	switch name {
+	case MetadataProviderDepsDev, MetadataProviderOSV, MetadataProviderProxy, MetadataProviderModCache:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateMetadataProviders returns an error if a metadata provider is
+// neither built-in nor registered.
+func validateMetadataProviders(config *Configuration) error {
+	for _, name := range config.Metadata.Providers {
+		name = strings.TrimSpace(name)
+		if isBuiltinMetadataProvider(name) {
+			continue
+		}
+
+		if _, ok := registeredMetadataProvider(name); ok {
+			continue
+		}
+
+		metadataProvidersMu.Lock()
+		names := []string{MetadataProviderDepsDev, MetadataProviderModCache, MetadataProviderOSV, MetadataProviderProxy}
+		for registered := range metadataProviders {
+			names = append(names, registered)
+		}
+		metadataProvidersMu.Unlock()
+
+		sort.Strings(names)
+
+		return fmt.Errorf(errUnknownMetadataProvider, name, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// metadataProvider returns the metadata provider of the name.
+func (p *Processor) metadataProvider(name string) MetadataProvider {
+	switch name {
+	case MetadataProviderDepsDev:
+		return &p.network().depsDev
+	case MetadataProviderOSV:
+		return &OSV{APIURL: p.Config.Metadata.OSVURL, transport: p.network().transport}
+	case MetadataProviderProxy:
+		proxyURL := p.Config.Metadata.ProxyURL
+		if proxyURL == "" {
+			proxyURL = goProxyURL(p.goEnv["GOPROXY"])
+		}
+
+		return &ProxyMetadata{ProxyURL: proxyURL, transport: p.network().transport}
+	case MetadataProviderModCache:
+		return p.modCache()
+	}
+
+	provider, _ := registeredMetadataProvider(name) // Unknown providers are reported by NewProcessor.
+
+	return provider
+}
+
+// providersLookup returns a lookup merging the metadata of the configured
+// providers.
+func (p *Processor) providersLookup() func(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	providers := make([]MetadataProvider, 0, len(p.Config.Metadata.Providers))
+
+	for _, name := range p.Config.Metadata.Providers {
+		if provider := p.metadataProvider(strings.TrimSpace(name)); provider != nil {
+			providers = append(providers, provider)
+		}
+	}
+
+	return func(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+		return lookupProviders(providers, moduleName, moduleVersion)
+	}
+}
+
+// lookupProviders returns the merged metadata of the providers. Any error
+// other than missing metadata is returned, since rules evaluated on partial
+// metadata, such as licenses, would block or allow modules wrongly.
+func lookupProviders(providers []MetadataProvider, moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	var merged *ModuleMetadata
+
+	for _, provider := range providers {
+		metadata, err := provider.Lookup(moduleName, moduleVersion)
+
+		switch {
+		case isMetadataNotFound(err):
+			continue
+		case err != nil:
+			return nil, err
+		case merged == nil:
+			merged = &ModuleMetadata{}
+		}
+
+		merged.merge(metadata)
+	}
+
+	if merged == nil {
+		return nil, fmt.Errorf("%w: %s@%s", ErrMetadataNotFound, moduleName, moduleVersion)
+	}
+
+	return merged, nil
+}
+
+// isMetadataNotFound returns true if the error is a provider without
+// metadata for the module version.
+func isMetadataNotFound(err error) bool {
+	return errors.Is(err, ErrMetadataNotFound) || errors.Is(err, errDepsDevNotFound) || errors.Is(err, errModCacheNotFound)
+}
+
+// merge adds the metadata the module metadata does not know yet. Licenses
+// stay nil unless a provider knows them, so the license rules are skipped
+// for providers without licenses.
+func (m *ModuleMetadata) merge(other *ModuleMetadata) {
+	if other == nil {
+		return
+	}
+
+	if len(m.Licenses) == 0 && other.Licenses != nil {
+		m.Licenses = other.Licenses
+	}
+
+	for _, advisory := range other.Advisories {
+		found := false
+
+		for i := range m.Advisories {
+			if m.Advisories[i] == advisory {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			m.Advisories = append(m.Advisories, advisory)
+		}
+	}
+
+	if m.DependencyCount == 0 {
+		m.DependencyCount = other.DependencyCount
+	}
+
+	if m.Deprecated == "" {
+		m.Deprecated = other.Deprecated
+	}
+}
+
+// OSV provides the vulnerabilities of module versions from the OSV api.
+type OSV struct {
+	APIURL    string
+	transport http.RoundTripper
+}
+
+// Lookup returns the ids of the OSV vulnerabilities of the module version as
+// advisories.
+func (o *OSV) Lookup(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	apiURL := o.APIURL
+	if apiURL == "" {
+		apiURL = defaultOSVAPIURL
+	}
+
+	query := struct {
+		Version string `json:"version"`
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+	}{Version: moduleVersion}
+
+	query.Package.Name = moduleName
+	query.Package.Ecosystem = osvEcosystem
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingOSV, moduleName, moduleVersion, err)
+	}
+
+	client := &http.Client{Timeout: osvTimeout, Transport: o.transport}
+
+	resp, err := client.Post(strings.TrimRight(apiURL, "/")+"/v1/query", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingOSV, moduleName, moduleVersion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(errOSVStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	vulns := struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&vulns)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingOSV, moduleName, moduleVersion, err)
+	}
+
+	metadata := &ModuleMetadata{Advisories: make([]string, 0, len(vulns.Vulns))}
+
+	for i := range vulns.Vulns {
+		metadata.Advisories = append(metadata.Advisories, vulns.Vulns[i].ID)
+	}
+
+	return metadata, nil
+}
+
+// ProxyMetadata provides the deprecation of modules from the module proxy.
+// Modules are deprecated by the go.mod file of their latest version.
+type ProxyMetadata struct {
+	ProxyURL  string
+	transport http.RoundTripper
+}
+
+// Lookup returns the deprecation of the module in its latest version.
+func (m *ProxyMetadata) Lookup(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingLatestVersion, moduleName, err)
+	}
+
+	proxyURL := strings.TrimRight(m.ProxyURL, "/")
+	if proxyURL == "" {
+		proxyURL = defaultProxyURL
+	}
+
+	client := &http.Client{Timeout: proxyTimeout, Transport: m.transport}
+
+	resp, err := client.Get(fmt.Sprintf("%s/%s/@latest", proxyURL, escapedPath))
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingLatestVersion, moduleName, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%w: %s@%s", ErrMetadataNotFound, moduleName, moduleVersion)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf(errProxyStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	latest := struct {
+		Version string
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&latest)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingLatestVersion, moduleName, err)
+	}
+
+	metadata := &ModuleMetadata{}
+
+	escapedVersion, err := module.EscapeVersion(latest.Version)
+	if err != nil {
+		return metadata, nil
+	}
+
+	goModResp, err := client.Get(fmt.Sprintf("%s/%s/@v/%s.mod", proxyURL, escapedPath, escapedVersion))
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingLatestVersion, moduleName, err)
+	}
+	defer goModResp.Body.Close()
+
+	if goModResp.StatusCode != http.StatusOK {
+		return metadata, nil
+	}
+
+	data, err := ioutil.ReadAll(goModResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingLatestVersion, moduleName, err)
+	}
+
+	metadata.Deprecated = modFileDeprecation(data)
+
+	return metadata, nil
+}
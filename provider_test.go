@@ -0,0 +1,143 @@
+package gomodguard_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+// cmdbProvider is a custom metadata provider knowing the licenses of
+// modules.
+type cmdbProvider map[string][]string
+
+func (c cmdbProvider) Lookup(moduleName, moduleVersion string) (*gomodguard.ModuleMetadata, error) {
+	licenses, ok := c[moduleName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s@%s", gomodguard.ErrMetadataNotFound, moduleName, moduleVersion)
+	}
+
+	return &gomodguard.ModuleMetadata{Licenses: licenses}, nil
+}
+
+func TestMetadataProviders(t *testing.T) {
+	err := gomodguard.RegisterMetadataProvider("cmdb", cmdbProvider{
+		"github.com/example/gpl": {"GPL-3.0"},
+		"github.com/example/mit": {"MIT"},
+	})
+	if err != nil && !strings.Contains(err.Error(), "already registered") {
+		t.Fatal(err)
+	}
+
+	err = gomodguard.RegisterMetadataProvider(gomodguard.MetadataProviderOSV, cmdbProvider{})
+	if err == nil {
+		t.Errorf("got no error want an error replacing a built-in provider")
+	}
+
+	osv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+		}{}
+
+		_ = json.NewDecoder(r.Body).Decode(&query)
+
+		if r.Method != http.MethodPost || query.Package.Name != "github.com/example/mit" {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"vulns":[{"id":"GO-2024-0001"}]}`))
+	}))
+	defer osv.Close()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/example/old/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		case "/github.com/example/old/@v/v1.2.0.mod":
+			_, _ = w.Write([]byte("// Deprecated: use github.com/example/new.\nmodule github.com/example/old\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer proxy.Close()
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/example/gpl v1.0.0\n\tgithub.com/example/mit v1.0.0\n\tgithub.com/example/old v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := gomodguard.Configuration{
+		LintGoMod: true,
+		Blocked: gomodguard.Blocked{
+			DepsDev: gomodguard.DepsDev{Licenses: []string{"GPL-3.0"}, Advisories: true, Deprecated: true},
+		},
+		Metadata: gomodguard.Metadata{
+			Providers: []string{"cmdb", gomodguard.MetadataProviderOSV, gomodguard.MetadataProviderProxy},
+			OSVURL:    osv.URL,
+			ProxyURL:  proxy.URL,
+		},
+		Mode: gomodguard.ModeBlock,
+	}
+
+	processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+	processor.SetBlockedModules()
+
+	got := []string{}
+	for _, result := range processor.ProcessFiles([]string{}) {
+		got = append(got, result.Module+" "+result.Rule)
+	}
+
+	sort.Strings(got)
+
+	want := []string{
+		"github.com/example/gpl " + gomodguard.RuleLicense,
+		"github.com/example/mit " + gomodguard.RuleDepsDev,
+		"github.com/example/old " + gomodguard.RuleDepsDev,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+
+	// Without a provider knowing licenses the license rules are skipped.
+	config.Blocked.DepsDev.UnresolvableLicenses = true
+	config.Metadata.Providers = []string{gomodguard.MetadataProviderOSV, gomodguard.MetadataProviderProxy}
+
+	processor = gomodguard.Processor{Config: &config, Modfile: modFile}
+	processor.SetBlockedModules()
+
+	got = []string{}
+	for _, result := range processor.ProcessFiles([]string{}) {
+		got = append(got, result.Module+" "+result.Rule)
+	}
+
+	sort.Strings(got)
+
+	want = []string{
+		"github.com/example/mit " + gomodguard.RuleDepsDev,
+		"github.com/example/old " + gomodguard.RuleDepsDev,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+
+	config.Metadata = gomodguard.Metadata{Providers: []string{"unknown"}}
+
+	_, err = gomodguard.NewProcessorFromModBytes(&config, []byte(goMod))
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/quick"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorProcessFilesQuick(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "quick.go")
+
+	processFile := func(src []byte) bool {
+		err := ioutil.WriteFile(filename, src, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		processor.Result = []gomodguard.Result{}
+
+		for _, result := range processor.ProcessFiles([]string{filename}) {
+			if result.Rule == gomodguard.ResultInternalError {
+				t.Log(result.String())
+				return false
+			}
+		}
+
+		return true
+	}
+
+	err = quick.Check(func(src []byte) bool {
+		return processFile(src)
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = quick.Check(func(importPath string) bool {
+		return processFile([]byte(fmt.Sprintf("package quick\n\nimport (\n\t%q\n\t`%s`\n)\n", importPath, importPath)))
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProcessorSetBlockedModulesQuick(t *testing.T) {
+	err := quick.Check(func(moduleName, moduleVersion, replacePath string) bool {
+		goMod := fmt.Sprintf("module %s\n\nrequire %s %s\n\nreplace %s => %s\n", moduleName, moduleName, moduleVersion, moduleName, replacePath)
+
+		modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+		if err != nil {
+			return true
+		}
+
+		processor := gomodguard.Processor{Config: config, Modfile: modFile}
+		processor.SetBlockedModules()
+
+		return true
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	var processor gomodguard.Processor
+
+	processor.SetBlockedModules()
+	processor.ProcessFiles([]string{"blocked_example.go", "does_not_exist.go"})
+}
+
+func TestMatchersQuick(t *testing.T) {
+	err := quick.Check(func(moduleName, pattern, version string, recommendations []string) bool {
+		allowed := gomodguard.Allowed{Modules: []string{pattern}, Domains: []string{pattern}}
+		allowed.IsAllowedModule(moduleName)
+		allowed.IsAllowedModuleDomain(moduleName)
+
+		blockedVersion := gomodguard.BlockedVersion{Version: pattern}
+		blockedVersion.IsLintedModuleVersionBlocked(version)
+		blockedVersion.Message(version)
+
+		blockedModule := gomodguard.BlockedModule{Recommendations: recommendations, Reason: pattern}
+		blockedModule.IsCurrentModuleARecommendation(moduleName)
+		blockedModule.Message()
+
+		blockedModules := gomodguard.BlockedModules{{pattern: blockedModule}}
+		blockedModules.GetBlockReason(moduleName)
+
+		return true
+	}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,113 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const errReadingRatchetFile = "unable to read ratchet file %s: %w"
+
+// Ratchet is the number of error results of each directory. A ratchet only
+// allows the counts to go down, a directory whose count increases fails.
+type Ratchet map[string]int
+
+// RatchetRegression is a directory with more error results than recorded.
+type RatchetRegression struct {
+	Dir      string
+	Recorded int
+	Count    int
+}
+
+// String returns the directory and its recorded and current counts.
+func (r RatchetRegression) String() string {
+	return fmt.Sprintf("%s has %d results, %d recorded", r.Dir, r.Count, r.Recorded)
+}
+
+// Add counts the result if it is an error.
+func (r Ratchet) Add(result Result) {
+	if result.IsWarning() {
+		return
+	}
+
+	r[normalizePath(filepath.Dir(result.FileName))]++
+}
+
+// Regressions returns the directories whose count is higher than the count
+// recorded in the given ratchet, sorted by directory.
+func (r Ratchet) Regressions(recorded Ratchet) []RatchetRegression {
+	regressions := []RatchetRegression{}
+
+	normalized := make(Ratchet, len(recorded))
+	for dir, count := range recorded {
+		normalized[normalizePath(dir)] += count
+	}
+
+	for dir, count := range r {
+		if count > normalized[dir] {
+			regressions = append(regressions, RatchetRegression{Dir: dir, Recorded: normalized[dir], Count: count})
+		}
+	}
+
+	sort.Slice(regressions, func(i, j int) bool { return regressions[i].Dir < regressions[j].Dir })
+
+	return regressions
+}
+
+// Merge returns the ratchet to record after linting the given files. The
+// directories of the files get their current counts, the other directories
+// keep their recorded counts, so a run linting only part of the tree does
+// not drop the counts of the rest.
+func (r Ratchet) Merge(recorded Ratchet, files []string) Ratchet {
+	merged := make(Ratchet, len(recorded)+len(r))
+	for dir, count := range recorded {
+		merged[normalizePath(dir)] += count
+	}
+
+	for _, file := range files {
+		delete(merged, normalizePath(filepath.Dir(file)))
+	}
+
+	for dir, count := range r {
+		merged[dir] = count
+	}
+
+	return merged
+}
+
+// ReadRatchet reads a ratchet file. A missing file is an empty ratchet and
+// false is returned.
+func ReadRatchet(filename string) (Ratchet, bool, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return Ratchet{}, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf(errReadingRatchetFile, filename, err)
+	}
+
+	ratchet := Ratchet{}
+
+	err = json.Unmarshal(data, &ratchet)
+	if err != nil {
+		return nil, false, fmt.Errorf(errReadingRatchetFile, filename, err)
+	}
+
+	return ratchet, true, nil
+}
+
+// WriteRatchet writes the ratchet file.
+func WriteRatchet(filename string, ratchet Ratchet) error {
+	data, err := json.MarshalIndent(ratchet, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+}
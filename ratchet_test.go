@@ -0,0 +1,111 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestRatchetRegressions(t *testing.T) {
+	results := []gomodguard.Result{
+		{FileName: "main.go", Severity: gomodguard.SeverityError},
+		{FileName: "pkg/a/a.go", Severity: gomodguard.SeverityError},
+		{FileName: "pkg/a/b.go", Severity: gomodguard.SeverityError},
+		{FileName: "pkg/b/a.go", Severity: gomodguard.SeverityWarning},
+		{FileName: "pkg/c/a.go", Severity: gomodguard.SeverityError},
+	}
+
+	ratchet := gomodguard.Ratchet{}
+	for _, result := range results {
+		ratchet.Add(result)
+	}
+
+	wantRatchet := gomodguard.Ratchet{".": 1, "pkg/a": 2, "pkg/c": 1}
+	if !reflect.DeepEqual(ratchet, wantRatchet) {
+		t.Errorf("got '%+v' want '%+v'", ratchet, wantRatchet)
+	}
+
+	var tests = []struct {
+		testName string
+		recorded gomodguard.Ratchet
+		want     []gomodguard.RatchetRegression
+	}{
+		{
+			"unchanged",
+			gomodguard.Ratchet{".": 1, "pkg/a": 2, "pkg/c": 1},
+			[]gomodguard.RatchetRegression{},
+		},
+		{
+			"reduced",
+			gomodguard.Ratchet{".": 3, "pkg/a": 2, "pkg/b": 4, "pkg/c": 1},
+			[]gomodguard.RatchetRegression{},
+		},
+		{
+			"increased",
+			gomodguard.Ratchet{".": 1, "pkg/a": 1},
+			[]gomodguard.RatchetRegression{{Dir: "pkg/a", Recorded: 1, Count: 2}, {Dir: "pkg/c", Recorded: 0, Count: 1}},
+		},
+		{
+			"recorded with unclean directories",
+			gomodguard.Ratchet{"./": 1, "./pkg/a": 2, "pkg/c/": 1},
+			[]gomodguard.RatchetRegression{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := ratchet.Regressions(tt.recorded)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got '%+v' want '%+v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatchetMerge(t *testing.T) {
+	ratchet := gomodguard.Ratchet{"pkg/a": 1}
+	recorded := gomodguard.Ratchet{".": 3, "./pkg/a": 2, "pkg/b": 4, "pkg/c": 1}
+	linted := []string{"pkg/a/a.go", "pkg/c/c.go"}
+
+	want := gomodguard.Ratchet{".": 3, "pkg/a": 1, "pkg/b": 4}
+
+	got := ratchet.Merge(recorded, linted)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+}
+
+func TestRatchetFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "ratchet.json")
+
+	ratchet, exists, err := gomodguard.ReadRatchet(filename)
+	if err != nil || exists || len(ratchet) != 0 {
+		t.Fatalf("got '%+v' '%v' '%v' want an empty ratchet for a missing file", ratchet, exists, err)
+	}
+
+	want := gomodguard.Ratchet{".": 1, "pkg/a": 2}
+
+	err = gomodguard.WriteRatchet(filename, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ratchet, exists, err = gomodguard.ReadRatchet(filename)
+	if err != nil || !exists {
+		t.Fatalf("got '%v' '%v' want the ratchet file to be read", exists, err)
+	}
+
+	if !reflect.DeepEqual(ratchet, want) {
+		t.Errorf("got '%+v' want '%+v'", ratchet, want)
+	}
+}
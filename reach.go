@@ -0,0 +1,134 @@
+package gomodguard
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+// ImportChain is a chain of first-party imports from a package of the linted
+// module to a package of another module. Packages starts with the reaching
+// package and ends with the imported package of the module, Position is the
+// import of that package by the last first-party package of the chain.
+type ImportChain struct {
+	Package  string
+	Packages []string
+	Position token.Position
+}
+
+// String returns the packages of the chain and the position of the import of
+// the module.
+func (c ImportChain) String() string {
+	return fmt.Sprintf("%s (%s:%d)", strings.Join(c.Packages, requirePathSeparator), c.Position.Filename, c.Position.Line)
+}
+
+// ReachingPackages lints the files and returns the shortest chain of
+// first-party imports from each package of the linted module that can reach
+// a package of the module, so migrations off a blocked module can plan where
+// to introduce abstraction layers. Chains are sorted by package.
+func (p *Processor) ReachingPackages(filenames []string, modulePath string) []ImportChain {
+	return reachingChains(p.processImportGraph(filenames), strings.TrimSpace(modulePath))
+}
+
+// processImportGraph lints the files and returns the imports of the packages
+// of the linted module.
+func (p *Processor) processImportGraph(filenames []string) map[string]map[string]token.Position {
+	graph := map[string]map[string]token.Position{}
+
+	p.importGraph = graph
+	defer func() { p.importGraph = nil }()
+
+	p.ProcessFiles(filenames)
+
+	return graph
+}
+
+// recordGraphImport records an import of a package of the linted module for
+// reachability queries.
+func (p *Processor) recordGraphImport(packagePath, importedPkg string, position token.Position) {
+	if p.importGraph[packagePath] == nil {
+		p.importGraph[packagePath] = map[string]token.Position{}
+	}
+
+	if _, ok := p.importGraph[packagePath][importedPkg]; !ok {
+		p.importGraph[packagePath][importedPkg] = position
+	}
+}
+
+// reachingChains returns the shortest import chain of each package of the
+// graph reaching a package of the module. Packages importing the module are
+// found first, the packages importing them are found by walking the imports
+// backwards breadth first in lexical order.
+func reachingChains(graph map[string]map[string]token.Position, modulePath string) []ImportChain {
+	importedBy := map[string][]string{}
+	queue := []string{}
+	chains := map[string]ImportChain{}
+
+	packages := make([]string, 0, len(graph))
+	for pkg := range graph {
+		packages = append(packages, pkg)
+	}
+
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		for _, imported := range sortedImports(graph[pkg]) {
+			if match.Module(modulePath, imported) {
+				if _, ok := chains[pkg]; !ok {
+					chains[pkg] = ImportChain{Package: pkg, Packages: []string{pkg, imported}, Position: graph[pkg][imported]}
+					queue = append(queue, pkg)
+				}
+
+				continue
+			}
+
+			if _, ok := graph[imported]; ok {
+				importedBy[imported] = append(importedBy[imported], pkg)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		for _, importer := range importedBy[pkg] {
+			if _, ok := chains[importer]; ok {
+				continue
+			}
+
+			chain := chains[pkg]
+			chains[importer] = ImportChain{
+				Package:  importer,
+				Packages: append([]string{importer}, chain.Packages...),
+				Position: chain.Position,
+			}
+			queue = append(queue, importer)
+		}
+	}
+
+	reaching := make([]ImportChain, 0, len(chains))
+	for _, pkg := range packages {
+		if chain, ok := chains[pkg]; ok {
+			reaching = append(reaching, chain)
+		}
+	}
+
+	return reaching
+}
+
+// WriteImportChains writes the import chains, one per line.
+func WriteImportChains(w io.Writer, chains []ImportChain) error {
+	for i := range chains {
+		_, err := fmt.Fprintln(w, chains[i].String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestProcessorReachingPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":         "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/bar/v2 v2.0.0\n)\n",
+		"cmd/main.go":    "package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/ryancurrah/example/handler\"\n)\n",
+		"handler/h.go":   "package handler\n\nimport (\n\t\"github.com/ryancurrah/example/service\"\n\t\"github.com/ryancurrah/example/store\"\n)\n",
+		"service/s.go":   "package service\n\nimport \"github.com/ryancurrah/example/store\"\n",
+		"store/store.go": "package store\n\nimport (\n\t\"github.com/foo/bar/sql\"\n\t\"github.com/foo/bar\"\n)\n",
+		"clean/clean.go": "package clean\n\nimport \"github.com/foo/bar/v2\"\n",
+	}
+
+	filenames := writeTree(t, dir, files)
+
+	processor, err := gomodguard.NewProcessor(&gomodguard.Configuration{
+		GoEnv: map[string]string{"GOFLAGS": "-modfile=" + filepath.Join(dir, "go.mod")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chains := processor.ReachingPackages(filenames, "github.com/foo/bar")
+
+	want := []string{
+		"github.com/ryancurrah/example/cmd -> github.com/ryancurrah/example/handler -> github.com/ryancurrah/example/store -> github.com/foo/bar (" + filepath.Join(dir, "store/store.go") + ":5)",
+		"github.com/ryancurrah/example/handler -> github.com/ryancurrah/example/store -> github.com/foo/bar (" + filepath.Join(dir, "store/store.go") + ":5)",
+		"github.com/ryancurrah/example/service -> github.com/ryancurrah/example/store -> github.com/foo/bar (" + filepath.Join(dir, "store/store.go") + ":5)",
+		"github.com/ryancurrah/example/store -> github.com/foo/bar (" + filepath.Join(dir, "store/store.go") + ":5)",
+	}
+
+	got := make([]string, 0, len(chains))
+	for _, chain := range chains {
+		got = append(got, chain.String())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+
+	var buf bytes.Buffer
+
+	err = gomodguard.WriteImportChains(&buf, chains[3:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != want[3]+"\n" {
+		t.Errorf("got '%v' want '%v'", buf.String(), want[3]+"\n")
+	}
+}
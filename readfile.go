@@ -0,0 +1,55 @@
+package gomodguard
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which read buffers are not
+// returned to the pool, so one very large file does not pin its memory for
+// the rest of the run.
+const maxPooledBufferSize = 8 << 20
+
+// readBuffers pools the buffers files are read into.
+var readBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// readFile reads a file of the file system into a pooled buffer. The buffer
+// must be released with releaseBuffer once the file contents are no longer
+// referenced.
+func readFile(files fileSystem, filename string) (*bytes.Buffer, error) {
+	f, err := files.open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf, _ := readBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	if s, ok := f.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := s.Stat(); err == nil && info.Size() < math.MaxInt32 {
+			buf.Grow(int(info.Size()) + bytes.MinRead)
+		}
+	}
+
+	if _, err := buf.ReadFrom(f); err != nil && err != io.EOF {
+		releaseBuffer(buf)
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// releaseBuffer returns a buffer to the pool.
+func releaseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	readBuffers.Put(buf)
+}
@@ -0,0 +1,86 @@
+package gomodguard
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const errInvalidRegex = "invalid regex %s: %w"
+
+// blockedRegex is a compiled blocked regex entry.
+type blockedRegex struct {
+	name    string
+	pattern *regexp.Regexp
+	module  BlockedModule
+}
+
+// compileRegexes compiles the allowed and blocked regex entries. Invalid
+// patterns are skipped and the first error is returned.
+func (p *Processor) compileRegexes() error {
+	var firstErr error
+
+	p.allowedRegex = make([]*regexp.Regexp, 0, len(p.Config.Allowed.Regex))
+	p.blockedRegex = make([]blockedRegex, 0, len(p.Config.Blocked.Regex))
+
+	for _, pattern := range p.Config.Allowed.Regex {
+		re, err := regexp.Compile(strings.TrimSpace(pattern))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf(errInvalidRegex, strings.TrimSpace(pattern), err)
+			}
+
+			continue
+		}
+
+		p.allowedRegex = append(p.allowedRegex, re)
+	}
+
+	for n := range p.Config.Blocked.Regex {
+		for pattern, blockedModule := range p.Config.Blocked.Regex[n] {
+			re, err := regexp.Compile(strings.TrimSpace(pattern))
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf(errInvalidRegex, strings.TrimSpace(pattern), err)
+				}
+
+				continue
+			}
+
+			p.blockedRegex = append(p.blockedRegex, blockedRegex{name: strings.TrimSpace(pattern), pattern: re, module: blockedModule})
+		}
+	}
+
+	return firstErr
+}
+
+// isAllowedByRegex returns true if the module or package path matches an
+// allowed regex.
+func (p *Processor) isAllowedByRegex(path string) bool {
+	for _, re := range p.allowedRegex {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// blockedRegexModule returns the pattern and the blocked module of the first
+// blocked regex matching the module, if any.
+func (p *Processor) blockedRegexModule(moduleName string) (string, *BlockedModule) {
+	for i := range p.blockedRegex {
+		if p.blockedRegex[i].pattern.MatchString(moduleName) {
+			blockedModule := p.blockedRegex[i].module
+			return p.blockedRegex[i].name, &blockedModule
+		}
+	}
+
+	return "", nil
+}
+
+// isAllowedPackage returns true if the package is allowed by the allowed
+// list or an allowed regex.
+func (p *Processor) isAllowedPackage(packageName string) bool {
+	return p.Config.Allowed.isAllowedPackage(packageName) || p.isAllowedByRegex(packageName)
+}
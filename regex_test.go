@@ -0,0 +1,84 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/module"
+)
+
+func TestRegexRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/org1/foo\"\n\t\"github.com/org2/bar/pkg\"\n\t\"github.com/org2/legacy\"\n\t\"github.com/org3/baz\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requires := []module.Version{
+		{Path: "github.com/org1/foo", Version: "v1.0.0"},
+		{Path: "github.com/org2/bar", Version: "v1.0.0"},
+		{Path: "github.com/org2/legacy", Version: "v1.0.0"},
+		{Path: "github.com/org3/baz", Version: "v1.0.0"},
+	}
+
+	processor, err := gomodguard.NewProcessorFromRequires(&gomodguard.Configuration{
+		Allowed: gomodguard.Allowed{Regex: []string{`^github\.com/(org1|org2)/`}},
+		Blocked: gomodguard.Blocked{
+			Regex: gomodguard.BlockedModules{{`^github\.com/[^/]+/legacy$`: gomodguard.BlockedModule{Reason: "legacy modules are retired."}}},
+		},
+	}, "github.com/ryancurrah/example", requires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := processor.ProcessFiles([]string{filename})
+
+	got := []string{}
+	for i := range results {
+		got = append(got, results[i].Module+" "+results[i].Rule)
+	}
+
+	sort.Strings(got)
+
+	want := []string{"github.com/org2/legacy in_blocked_list", "github.com/org3/baz not_in_allowed_list"}
+
+	if len(got) != len(want) {
+		t.Fatalf("got '%v' want '%v'", got, want)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got '%v' want '%v'", got, want)
+		}
+	}
+
+	var tests = []struct {
+		testName string
+		config   gomodguard.Configuration
+	}{
+		{"invalid allowed regex", gomodguard.Configuration{Allowed: gomodguard.Allowed{Regex: []string{"github.com/(org1"}}}},
+		{"invalid blocked regex", gomodguard.Configuration{Blocked: gomodguard.Blocked{Regex: gomodguard.BlockedModules{{"github.com/[org": gomodguard.BlockedModule{}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+
+			_, err := gomodguard.NewProcessorFromRequires(&config, "github.com/ryancurrah/example", nil)
+			if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+				t.Errorf("got '%v' want a configuration error", err)
+			}
+		})
+	}
+}
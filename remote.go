@@ -0,0 +1,161 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryancurrah/gomodguard/match"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	remoteModulesTimeout     = 10 * time.Second
+	remoteModulesTTL         = 5 * time.Minute
+	errFetchingRemoteModules = "unable to fetch blocked modules from %s: %w"
+	errRemoteModulesStatus   = "unexpected status code %d fetching blocked modules from %s"
+	warnOfflineRemoteModules = "skipping blocked modules from %s, running offline"
+)
+
+var (
+	// remoteModulesMu guards remoteModules.
+	remoteModulesMu sync.Mutex
+	// remoteModules are the blocked modules fetched by url, so processors of
+	// a run share a single request.
+	remoteModules = map[string]remoteModulesFetch{}
+)
+
+// remoteModulesFetch are the blocked modules fetched from a url.
+type remoteModulesFetch struct {
+	modules   BlockedModules
+	fetchedAt time.Time
+}
+
+// FetchBlockedModules fetches a list of blocked modules from a central mapping
+// service. The response must be a YAML or JSON list in the same format as the
+// blocked modules configuration.
+func FetchBlockedModules(url string) (BlockedModules, error) {
+	client := &http.Client{Timeout: remoteModulesTimeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingRemoteModules, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(errRemoteModulesStatus, resp.StatusCode, url)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingRemoteModules, url, err)
+	}
+
+	blockedModules := BlockedModules{}
+
+	err = yaml.Unmarshal(data, &blockedModules)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingRemoteModules, url, err)
+	}
+
+	return blockedModules, nil
+}
+
+// fetchBlockedModulesOnce returns the blocked modules of the url, fetched
+// again once older than remoteModulesTTL so long-running processes pick up
+// changes. Errors are not cached, the next call fetches the url again.
+func fetchBlockedModulesOnce(url string) (BlockedModules, error) {
+	remoteModulesMu.Lock()
+	defer remoteModulesMu.Unlock()
+
+	fetch, ok := remoteModules[url]
+	if ok && time.Since(fetch.fetchedAt) < remoteModulesTTL {
+		return fetch.modules, nil
+	}
+
+	modules, err := FetchBlockedModules(url)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteModules[url] = remoteModulesFetch{modules: modules, fetchedAt: time.Now()}
+
+	return modules, nil
+}
+
+// Merge returns the blocked modules augmented by the other blocked modules.
+// Recommendations of modules in both lists are combined and the local
+// reason takes precedence. Modules only in the other list are appended.
+func (b BlockedModules) Merge(other BlockedModules) BlockedModules {
+	merged := make(BlockedModules, 0, len(b)+len(other))
+	index := map[string]int{}
+
+	for n := range b {
+		for moduleName, blockedModule := range b[n] {
+			index[strings.TrimSpace(moduleName)] = len(merged)
+			merged = append(merged, map[string]BlockedModule{moduleName: blockedModule})
+		}
+	}
+
+	for n := range other {
+		for moduleName, otherModule := range other[n] {
+			i, ok := index[strings.TrimSpace(moduleName)]
+			if !ok {
+				index[strings.TrimSpace(moduleName)] = len(merged)
+				merged = append(merged, map[string]BlockedModule{moduleName: otherModule})
+
+				continue
+			}
+
+			for localModuleName, localModule := range merged[i] {
+				localModule.Recommendations = mergeRecommendations(localModule.Recommendations, otherModule.Recommendations)
+
+				if localModule.Reason == "" {
+					localModule.Reason = otherModule.Reason
+				}
+
+				if localModule.Docs == "" {
+					localModule.Docs = otherModule.Docs
+				}
+
+				if localModule.PolicyURL == "" {
+					localModule.PolicyURL = otherModule.PolicyURL
+				}
+
+				if localModule.Mapping == nil {
+					localModule.Mapping = otherModule.Mapping
+				}
+
+				merged[i][localModuleName] = localModule
+			}
+		}
+	}
+
+	return merged
+}
+
+// mergeRecommendations appends the other recommendations that are not already recommended.
+func mergeRecommendations(recommendations, other []string) []string {
+	merged := append([]string{}, recommendations...)
+
+	for _, recommendation := range other {
+		found := false
+
+		for _, existing := range merged {
+			if match.Exact(existing, recommendation) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			merged = append(merged, recommendation)
+		}
+	}
+
+	return merged
+}
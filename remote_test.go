@@ -0,0 +1,110 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestFetchBlockedModules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"github.com/someblocked/module": {"recommendations": ["github.com/somerecommended/module"], "reason": "Some reason."}}]`)
+	}))
+	defer server.Close()
+
+	blockedModules, err := gomodguard.FetchBlockedModules(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantBlockedModules := gomodguard.BlockedModules{
+		{"github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module"}, Reason: "Some reason."}},
+	}
+
+	if !reflect.DeepEqual(blockedModules, wantBlockedModules) {
+		t.Errorf("got '%+v' want '%+v'", blockedModules, wantBlockedModules)
+	}
+}
+
+func TestBlockedModulesMerge(t *testing.T) {
+	local := gomodguard.BlockedModules{
+		{"github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module"}, Reason: "Local reason."}},
+	}
+
+	remote := gomodguard.BlockedModules{
+		{"github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module", "github.com/someotherrecommended/module"}, Reason: "Remote reason."}},
+		{"github.com/someotherblocked/module": gomodguard.BlockedModule{Reason: "Remote reason."}},
+	}
+
+	wantMerged := gomodguard.BlockedModules{
+		{"github.com/someblocked/module": gomodguard.BlockedModule{Recommendations: []string{"github.com/somerecommended/module", "github.com/someotherrecommended/module"}, Reason: "Local reason."}},
+		{"github.com/someotherblocked/module": gomodguard.BlockedModule{Reason: "Remote reason."}},
+	}
+
+	merged := local.Merge(remote)
+	if !reflect.DeepEqual(merged, wantMerged) {
+		t.Errorf("got '%+v' want '%+v'", merged, wantMerged)
+	}
+
+	if len(local[0]["github.com/someblocked/module"].Recommendations) != 1 {
+		t.Error("merge should not modify the local blocked modules")
+	}
+}
+
+func TestResolveModulesURL(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprint(w, `[{"github.com/someblocked/module": {"reason": "Some reason."}}]`)
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		testName     string
+		path         string
+		offline      bool
+		wantErr      bool
+		wantModules  int
+		wantRequests int
+	}{
+		{"fetched", "/blocked", false, false, 1, 1},
+		{"fetched once", "/blocked", false, false, 1, 1},
+		{"offline", "/offline", true, false, 0, 1},
+		{"down", "/down", false, true, 0, 2},
+		{"down fetched again", "/down", false, true, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := gomodguard.Configuration{
+				Blocked: gomodguard.Blocked{ModulesURL: server.URL + tt.path},
+				GoEnv:   map[string]string{"GOPROXY": "direct"},
+				Offline: tt.offline,
+			}
+
+			resolved, err := config.Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if err == nil && len(resolved.Blocked.Modules) != tt.wantModules {
+				t.Errorf("got '%+v' want %d modules", resolved.Blocked.Modules, tt.wantModules)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("got %d requests want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
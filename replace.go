@@ -0,0 +1,118 @@
+package gomodguard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// Replaces are the policies applying to the replace directives of go.mod.
+type Replaces struct {
+	// SatisfiedByAllowed does not report required modules as not allowed,
+	// blocked or of a blocked version when they are replaced by an allowed
+	// module, since the replacement is built instead of them.
+	SatisfiedByAllowed bool `yaml:"satisfied_by_allowed" json:"satisfied_by_allowed"`
+	// DisallowedTargets reports required modules replaced by a module that
+	// is not allowed or is blocked.
+	DisallowedTargets bool `yaml:"disallowed_targets" json:"disallowed_targets"`
+	// LocalPaths are the filesystem paths local replace directives may point
+	// at, or inside of, without being reported as local replace directives.
+	// Relative paths are relative to the directory of go.mod.
+	LocalPaths []string `yaml:"local_paths" json:"local_paths"`
+}
+
+// moduleReplacement returns the replacement of the required module. Like the
+// go command a replace directive of the required version takes precedence
+// over one of all versions. Local replacements have an empty version.
+func (p *Processor) moduleReplacement(mod module.Version) (module.Version, bool) {
+	var (
+		replacement module.Version
+		found       bool
+	)
+
+	for _, replace := range p.Modfile.Replace {
+		if replace == nil || strings.TrimSpace(replace.Old.Path) != strings.TrimSpace(mod.Path) {
+			continue
+		}
+
+		oldVersion := strings.TrimSpace(replace.Old.Version)
+
+		switch {
+		case oldVersion == strings.TrimSpace(mod.Version):
+			return module.Version{Path: strings.TrimSpace(replace.New.Path), Version: strings.TrimSpace(replace.New.Version)}, true
+		case oldVersion == "":
+			replacement, found = module.Version{Path: strings.TrimSpace(replace.New.Path), Version: strings.TrimSpace(replace.New.Version)}, true
+		}
+	}
+
+	return replacement, found
+}
+
+// isAllowedReplaceTarget returns true if the replacement is a module that is
+// allowed and is neither blocked nor of a blocked version. Local replacements
+// are not modules and are never allowed.
+func (p *Processor) isAllowedReplaceTarget(target module.Version, goSum map[module.Version][]string) bool {
+	if target.Version == "" {
+		return false
+	}
+
+	canonicalModuleName := p.Config.CanonicalModulePath(target.Path)
+
+	if _, blockedModule := p.lookupBlockedModule(canonicalModuleName); blockedModule != nil {
+		return false
+	}
+
+	if _, blockedModule := p.blockedRegexModule(canonicalModuleName); blockedModule != nil {
+		return false
+	}
+
+	if _, blockedVersion := p.lookupBlockedVersion(canonicalModuleName); blockedVersion != nil &&
+		blockedVersion.IsLintedModuleVersionBlocked(target.Version) {
+		return false
+	}
+
+	return p.isAllowedModule(canonicalModuleName, target, goSum)
+}
+
+// isAllowedLocalReplacePath returns true if the local replacement is one of
+// the allowed local paths or inside one of them.
+func (p *Processor) isAllowedLocalReplacePath(replacement string) bool {
+	if len(p.Config.Blocked.Replaces.LocalPaths) == 0 {
+		return false
+	}
+
+	moduleDir := p.moduleDir
+	if moduleDir == "" {
+		moduleDir, _ = os.Getwd()
+	}
+
+	target := localReplacePath(moduleDir, replacement)
+
+	for _, localPath := range p.Config.Blocked.Replaces.LocalPaths {
+		if strings.TrimSpace(localPath) == "" {
+			continue
+		}
+
+		allowed := localReplacePath(moduleDir, localPath)
+
+		rel, err := filepath.Rel(allowed, target)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// localReplacePath returns the cleaned absolute path of a local replacement,
+// resolving relative paths against the module directory.
+func localReplacePath(moduleDir, replacement string) string {
+	replacement = filepath.FromSlash(strings.TrimSpace(replacement))
+	if !filepath.IsAbs(replacement) {
+		replacement = filepath.Join(moduleDir, replacement)
+	}
+
+	return filepath.Clean(replacement)
+}
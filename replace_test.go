@@ -0,0 +1,209 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorReplaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := `module github.com/ryancurrah/example
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/baz v1.0.0
+	github.com/foo/qux v1.0.0
+	github.com/foo/local v1.0.0
+)
+
+replace github.com/foo/bar => github.com/myorg/bar v1.0.1
+
+replace github.com/foo/baz v1.0.0 => github.com/evil/baz v1.0.0
+
+replace github.com/foo/baz => github.com/myorg/baz v1.0.0
+
+replace github.com/foo/qux v0.9.0 => github.com/myorg/qux v1.0.0
+
+replace github.com/foo/local => ../local
+`
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	source := "package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n\t\"github.com/foo/qux\"\n\t\"github.com/foo/local\"\n)\n"
+
+	err = ioutil.WriteFile(filename, []byte(source), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := gomodguard.Allowed{Domains: []string{"github.com/myorg"}}
+	blocked := gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}
+
+	var tests = []struct {
+		testName  string
+		config    gomodguard.Configuration
+		wantRules map[string][]string
+	}{
+		{
+			"replaces ignored",
+			gomodguard.Configuration{Allowed: allowed, Blocked: gomodguard.Blocked{Modules: blocked}},
+			map[string][]string{
+				"github.com/foo/bar":   {gomodguard.RuleInBlockedList},
+				"github.com/foo/baz":   {gomodguard.RuleNotInAllowedList},
+				"github.com/foo/qux":   {gomodguard.RuleNotInAllowedList},
+				"github.com/foo/local": {gomodguard.RuleNotInAllowedList},
+			},
+		},
+		{
+			"satisfied by allowed replacement",
+			gomodguard.Configuration{Allowed: allowed, Blocked: gomodguard.Blocked{
+				Modules:  blocked,
+				Replaces: gomodguard.Replaces{SatisfiedByAllowed: true},
+			}},
+			map[string][]string{
+				"github.com/foo/baz":   {gomodguard.RuleNotInAllowedList},
+				"github.com/foo/qux":   {gomodguard.RuleNotInAllowedList},
+				"github.com/foo/local": {gomodguard.RuleNotInAllowedList},
+			},
+		},
+		{
+			"disallowed replacement",
+			gomodguard.Configuration{Allowed: allowed, Blocked: gomodguard.Blocked{
+				Modules:  blocked,
+				Replaces: gomodguard.Replaces{DisallowedTargets: true},
+			}},
+			map[string][]string{
+				"github.com/foo/bar":   {gomodguard.RuleInBlockedList},
+				"github.com/foo/baz":   {gomodguard.RuleDisallowedReplace, gomodguard.RuleNotInAllowedList},
+				"github.com/foo/qux":   {gomodguard.RuleNotInAllowedList},
+				"github.com/foo/local": {gomodguard.RuleNotInAllowedList},
+			},
+		},
+		{
+			"blocked replacement in block list mode",
+			gomodguard.Configuration{Blocked: gomodguard.Blocked{
+				Modules:  gomodguard.BlockedModules{{"github.com/myorg/bar": gomodguard.BlockedModule{}}},
+				Replaces: gomodguard.Replaces{DisallowedTargets: true},
+			}},
+			map[string][]string{
+				"github.com/foo/bar": {gomodguard.RuleDisallowedReplace},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := tt.config
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			got := map[string][]string{}
+			for i := range results {
+				got[results[i].Module] = append(got[results[i].Module], results[i].Rule)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantRules) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestProcessorLocalReplacePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goMod := `module github.com/ryancurrah/example
+
+require (
+	github.com/foo/bar v1.0.0
+	github.com/foo/baz v1.0.0
+)
+
+replace github.com/foo/bar => ../shared/bar
+
+replace github.com/foo/baz => ../local/baz
+`
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	source := "package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n"
+
+	err = ioutil.WriteFile(filename, []byte(source), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		localPaths  []string
+		wantModules []string
+	}{
+		{"no allowed paths", nil, []string{"github.com/foo/bar", "github.com/foo/baz"}},
+		{"allowed path", []string{"../shared"}, []string{"github.com/foo/baz"}},
+		{"allowed replacement", []string{"../shared/bar/"}, []string{"github.com/foo/baz"}},
+		{"allowed absolute path", []string{filepath.Join(filepath.Dir(cwd), "local")}, []string{"github.com/foo/bar"}},
+		{"allowed parent path", []string{".."}, []string{}},
+		{"path with same prefix", []string{"../share", "../local/ba"}, []string{"github.com/foo/bar", "github.com/foo/baz"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := gomodguard.Configuration{Blocked: gomodguard.Blocked{
+				LocalReplaceDirectives: true,
+				Replaces:               gomodguard.Replaces{LocalPaths: tt.localPaths},
+			}}
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			got := []string{}
+			for i := range results {
+				if results[i].Rule != gomodguard.RuleLocalReplaceDirective {
+					t.Errorf("got rule '%s' want '%s'", results[i].Rule, gomodguard.RuleLocalReplaceDirective)
+				}
+
+				got = append(got, results[i].Module)
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.wantModules) {
+				t.Errorf("got '%v' want '%v'", got, tt.wantModules)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	errUnknownReportFormat      = "unknown report format %s, must be one of %s"
+	errReportSeveritiesFormat   = "report severities are only supported by the %s report format"
+	errReportRuleSourcesFormat  = "report rule sources are only supported by the %s report format"
+	errUnknownReportSeverity    = "unknown checkstyle severity %s of rule %s, must be one of %s"
+	errUnknownReportSeverityKey = "unknown rule %s in report severities, must be one of %s"
+	reportFileMode              = 0644
+)
+
+// Report formats.
+const (
+	ReportText       = "text"
+	ReportCheckstyle = "checkstyle"
+	ReportJSON       = "json"
+	ReportSARIF      = "sarif"
+	ReportJUnit      = "junit"
+	ReportGitHub     = "github"
+	ReportHTML       = "html"
+	ReportWebhook    = "webhook"
+)
+
+// ReportFormats is the list of all report formats, formats added with
+// RegisterReporter are appended.
+var ReportFormats = []string{
+	ReportText,
+	ReportCheckstyle,
+	ReportJSON,
+	ReportSARIF,
+	ReportJUnit,
+	ReportGitHub,
+	ReportHTML,
+	ReportWebhook,
+}
+
+// Report is a report format and the file it is written to. Reports
+// without a file or with the file - are written to stdout. Webhook reports
+// are posted to their url, or file, and the routes of result owners.
+// Checkstyle reports map the results of the rules in severities to a
+// checkstyle severity instead of the severity of the result, and with
+// RuleSources report gomodguard.<rule> as source instead of gomodguard.
+type Report struct {
+	Format      string            `yaml:"format" json:"format"`
+	File        string            `yaml:"file" json:"file"`
+	URL         string            `yaml:"url" json:"url"`
+	Routes      map[string]string `yaml:"routes" json:"routes"`
+	Severities  map[string]string `yaml:"severities" json:"severities"`
+	RuleSources bool              `yaml:"rule_sources" json:"rule_sources"`
+}
+
+// IsStdout returns true if the report is written to stdout.
+func (r *Report) IsStdout() bool {
+	return !strings.EqualFold(r.Format, ReportWebhook) && (r.File == "" || r.File == "-")
+}
+
+// WriteReports writes the results to all reports.
+func WriteReports(reports []Report, results []Result) error {
+	for i := range reports {
+		err := writeReport(reports[i], results)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeReport writes the results to the report file or stdout.
+func writeReport(report Report, results []Result) error {
+	if strings.EqualFold(report.Format, ReportWebhook) {
+		return sendWebhook(report, results)
+	}
+
+	if report.IsStdout() {
+		return writeReportTo(os.Stdout, report, results)
+	}
+
+	return writeFileAtomic(report.File, func(w io.Writer) error {
+		return writeReportTo(w, report, results)
+	})
+}
+
+// writeFileAtomic writes a file by writing to a temporary file in the same
+// directory and renaming it, so a partially written file is never visible.
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	err = write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(f.Name(), reportFileMode)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
+
+// WriteReport writes the results in the report format.
+func WriteReport(w io.Writer, format string, results []Result) error {
+	return writeReportTo(w, Report{Format: format}, results)
+}
+
+// writeReportTo writes the results of the report to w.
+func writeReportTo(w io.Writer, report Report, results []Result) error {
+	reporter, err := newReportReporter(w, report)
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		reporter.Report(results[i])
+	}
+
+	return reporter.Flush()
+}
+
+// newReportReporter returns the reporter of the report format writing to w,
+// configured with the options of the report.
+func newReportReporter(w io.Writer, report Report) (Reporter, error) {
+	reporter, err := newReporter(w, report.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := reporter.(*checkstyleReporter); ok {
+		r.severities = report.Severities
+		r.ruleSources = report.RuleSources
+	}
+
+	return reporter, nil
+}
+
+// validateReports returns an error if a report has an unknown format or
+// unknown severities.
+func validateReports(reports []Report) error {
+	for i := range reports {
+		if !isReportFormat(reports[i].Format) {
+			return fmt.Errorf(errUnknownReportFormat, reports[i].Format, strings.Join(ReportFormats, ", "))
+		}
+
+		err := validateReportSeverities(reports[i])
+		if err != nil {
+			return err
+		}
+
+		if reports[i].RuleSources && !strings.EqualFold(strings.TrimSpace(reports[i].Format), ReportCheckstyle) {
+			return fmt.Errorf(errReportRuleSourcesFormat, ReportCheckstyle)
+		}
+
+		if strings.EqualFold(reports[i].Format, ReportWebhook) && reports[i].URL == "" && reports[i].File == "" && len(reports[i].Routes) == 0 {
+			return fmt.Errorf(errWebhookURL)
+		}
+	}
+
+	return nil
+}
+
+// validateReportSeverities returns an error if the report maps severities
+// but is not a checkstyle report, or maps an unknown rule or severity.
+func validateReportSeverities(report Report) error {
+	if len(report.Severities) == 0 {
+		return nil
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(report.Format), ReportCheckstyle) {
+		return fmt.Errorf(errReportSeveritiesFormat, ReportCheckstyle)
+	}
+
+	for rule, severity := range report.Severities {
+		if !isRule(rule) {
+			return fmt.Errorf(errUnknownReportSeverityKey, rule, strings.Join(Rules, ", "))
+		}
+
+		if !isCheckstyleSeverity(severity) {
+			return fmt.Errorf(errUnknownReportSeverity, severity, rule, strings.Join(CheckstyleSeverities, ", "))
+		}
+	}
+
+	return nil
+}
+
+// isCheckstyleSeverity returns true if the name is a checkstyle severity.
+func isCheckstyleSeverity(name string) bool {
+	for _, severity := range CheckstyleSeverities {
+		if severity == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isReportFormat returns true if the name is a known report format.
+func isReportFormat(name string) bool {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+
+	name = strings.TrimSpace(strings.ToLower(name))
+	_, ok := reporters[name]
+
+	return ok || name == ReportWebhook
+}
@@ -0,0 +1,210 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestWriteReports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := []gomodguard.Result{
+		{FileName: "main.go", LineNumber: 3, Reason: "import of package `github.com/foo/bar` is blocked.", Severity: gomodguard.SeverityError},
+	}
+
+	reports := []gomodguard.Report{
+		{Format: gomodguard.ReportText, File: filepath.Join(dir, "report.txt")},
+		{Format: gomodguard.ReportCheckstyle, File: filepath.Join(dir, "report.xml")},
+	}
+
+	err = gomodguard.WriteReports(reports, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName     string
+		file         string
+		wantContains string
+	}{
+		{
+			"text report",
+			"report.txt",
+			"main.go:3:1 import of package `github.com/foo/bar` is blocked.\n",
+		},
+		{
+			"checkstyle report",
+			"report.xml",
+			`<file name="main.go">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(data), tt.wantContains) {
+				t.Errorf("got '%s' want it to contain '%s'", data, tt.wantContains)
+			}
+		})
+	}
+
+	err = gomodguard.WriteReports([]gomodguard.Report{{Format: "unknown", File: filepath.Join(dir, "report.txt")}}, results)
+	if err == nil {
+		t.Error("want error for unknown report format")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != len(reports) {
+		t.Errorf("got '%d' files want '%d', temporary files must be removed", len(files), len(reports))
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "report.txt"))
+	if err != nil || len(data) == 0 {
+		t.Errorf("got '%s' '%v' want the report of the failed write to be left intact", data, err)
+	}
+}
+
+func TestCheckstyleSeverities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := []gomodguard.Result{
+		{FileName: "main.go", LineNumber: 3, Reason: "blocked", Severity: gomodguard.SeverityError, Rule: gomodguard.RuleInBlockedList},
+		{FileName: "main.go", LineNumber: 4, Reason: "license", Severity: gomodguard.SeverityError, Rule: gomodguard.RuleLicense},
+		{FileName: "main.go", LineNumber: 5, Reason: "popularity", Severity: gomodguard.SeverityWarning, Rule: gomodguard.RulePopularity},
+	}
+
+	report := gomodguard.Report{
+		Format: gomodguard.ReportCheckstyle,
+		File:   filepath.Join(dir, "report.xml"),
+		Severities: map[string]string{
+			gomodguard.RuleLicense:    "warning",
+			gomodguard.RulePopularity: "info",
+		},
+	}
+
+	err = gomodguard.WriteReports([]gomodguard.Report{report}, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(report.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName     string
+		wantContains string
+	}{
+		{
+			"unmapped rule keeps the result severity",
+			`severity="error" message="blocked" source="gomodguard"`,
+		},
+		{
+			"mapped error",
+			`severity="warning" message="license" source="gomodguard"`,
+		},
+		{
+			"mapped warning",
+			`severity="info" message="popularity" source="gomodguard"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if !strings.Contains(string(data), tt.wantContains) {
+				t.Errorf("got '%s' want it to contain '%s'", data, tt.wantContains)
+			}
+		})
+	}
+
+	report.RuleSources = true
+
+	err = gomodguard.WriteReports([]gomodguard.Report{report}, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = ioutil.ReadFile(report.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `severity="error" message="blocked" source="gomodguard.in_blocked_list"`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("got '%s' want it to contain '%s'", data, want)
+	}
+}
+
+// countReporter writes the number of results.
+type countReporter struct {
+	w     io.Writer
+	count int
+}
+
+func (r *countReporter) Report(gomodguard.Result) { r.count++ }
+
+func (r *countReporter) Flush() error {
+	_, err := fmt.Fprintf(r.w, "%d results\n", r.count)
+	return err
+}
+
+func TestRegisterReporter(t *testing.T) {
+	gomodguard.RegisterReporter("Count", func(w io.Writer) gomodguard.Reporter {
+		return &countReporter{w: w}
+	})
+
+	results := []gomodguard.Result{
+		{FileName: "main.go", LineNumber: 3, Reason: "import of package `github.com/foo/bar` is blocked."},
+		{FileName: "main.go", LineNumber: 4, Reason: "import of package `github.com/foo/baz` is blocked."},
+	}
+
+	var buf bytes.Buffer
+
+	err := gomodguard.WriteReport(&buf, "count", results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "2 results\n"; got != want {
+		t.Errorf("got '%s' want '%s'", got, want)
+	}
+
+	if got := gomodguard.ReportFormats[len(gomodguard.ReportFormats)-1]; got != "count" {
+		t.Errorf("got '%s' want 'count' in the report formats", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic when registering a format twice")
+		}
+	}()
+
+	gomodguard.RegisterReporter("count", func(w io.Writer) gomodguard.Reporter {
+		return &countReporter{w: w}
+	})
+}
@@ -0,0 +1,221 @@
+package gomodguard
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/phayes/checkstyle"
+)
+
+const (
+	errReporterRegistered = "reporter for report format %s is already registered"
+	errReporterNil        = "reporter for report format %s is nil"
+)
+
+// Reporter writes results in a report format. Report is called for every
+// result in the order they were found, Flush once after the last result.
+// Errors of Report are returned by Flush.
+type Reporter interface {
+	Report(result Result)
+	Flush() error
+}
+
+// NewReporterFunc returns a Reporter writing to w.
+type NewReporterFunc func(w io.Writer) Reporter
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = map[string]NewReporterFunc{
+		ReportText:       newTextReporter,
+		ReportCheckstyle: newCheckstyleReporter,
+		ReportJSON:       newJSONReporter,
+		ReportSARIF:      newSARIFReporter,
+		ReportJUnit:      newJUnitReporter,
+		ReportGitHub:     newGitHubReporter,
+		ReportHTML:       newHTMLReporter,
+	}
+)
+
+// RegisterReporter makes a report format available to the -r flag and the
+// reports of the configuration. It is meant to be called before Run by
+// programs embedding the command line, formats are case-insensitive and
+// registering a format twice panics.
+func RegisterReporter(format string, newReporter NewReporterFunc) {
+	format = strings.TrimSpace(strings.ToLower(format))
+
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+
+	if newReporter == nil {
+		panic(fmt.Sprintf(errReporterNil, format))
+	}
+
+	if _, ok := reporters[format]; ok || format == ReportWebhook {
+		panic(fmt.Sprintf(errReporterRegistered, format))
+	}
+
+	reporters[format] = newReporter
+	ReportFormats = append(ReportFormats, format)
+}
+
+// newReporter returns the reporter of the format writing to w.
+func newReporter(w io.Writer, format string) (Reporter, error) {
+	reportersMu.RLock()
+	newReporter, ok := reporters[strings.TrimSpace(strings.ToLower(format))]
+	reportersMu.RUnlock()
+
+	if !ok {
+		if strings.EqualFold(strings.TrimSpace(format), ReportWebhook) {
+			return nil, fmt.Errorf(errWebhookWriter)
+		}
+
+		return nil, fmt.Errorf(errUnknownReportFormat, format, strings.Join(ReportFormats, ", "))
+	}
+
+	return newReporter(w), nil
+}
+
+// textReporter writes the results one per line.
+type textReporter struct {
+	w   io.Writer
+	err error
+}
+
+func newTextReporter(w io.Writer) Reporter {
+	return &textReporter{w: w}
+}
+
+func (r *textReporter) Report(result Result) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintln(r.w, result.String())
+	}
+}
+
+func (r *textReporter) Flush() error {
+	return r.err
+}
+
+// checkstyleReporter writes the results in the checkstyle format one file
+// element at a time. Consecutive results of the same file share an element.
+// Results of rules in severities are reported with the mapped severity. The
+// source of errors is gomodguard, or gomodguard.<rule> with ruleSources.
+type checkstyleReporter struct {
+	w           io.Writer
+	enc         *xml.Encoder
+	root        xml.StartElement
+	file        *checkstyle.File
+	severities  map[string]string
+	ruleSources bool
+	started     bool
+	err         error
+}
+
+func newCheckstyleReporter(w io.Writer) Reporter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	return &checkstyleReporter{
+		w:   w,
+		enc: enc,
+		root: xml.StartElement{
+			Name: xml.Name{Local: "checkstyle"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "version"}, Value: checkstyle.New().Version}},
+		},
+	}
+}
+
+// start writes the xml header and the opening root element once.
+func (r *checkstyleReporter) start() {
+	if r.started || r.err != nil {
+		return
+	}
+
+	r.started = true
+
+	_, r.err = io.WriteString(r.w, xml.Header)
+	if r.err == nil {
+		r.err = r.enc.EncodeToken(r.root)
+	}
+}
+
+// flushFile writes the element of the current file.
+func (r *checkstyleReporter) flushFile() {
+	if r.file != nil && r.err == nil {
+		r.err = r.enc.EncodeElement(r.file, xml.StartElement{Name: xml.Name{Local: "file"}})
+	}
+
+	r.file = nil
+}
+
+func (r *checkstyleReporter) Report(result Result) {
+	r.start()
+
+	if r.file != nil && r.file.Name != result.FileName {
+		r.flushFile()
+	}
+
+	if r.file == nil {
+		r.file = &checkstyle.File{Name: result.FileName}
+	}
+
+	severity := checkstyleSeverity(result.Severity)
+	if mapped, ok := r.severities[result.Rule]; ok {
+		severity = checkstyle.Severity(mapped)
+	}
+
+	column := 1
+	if result.Position.Column > 0 {
+		column = result.Position.Column
+	}
+
+	source := "gomodguard"
+	if r.ruleSources && result.Rule != "" {
+		source += "." + result.Rule
+	}
+
+	r.file.AddError(checkstyle.NewError(result.LineNumber, column, severity, result.Reason, source))
+}
+
+func (r *checkstyleReporter) Flush() error {
+	r.start()
+	r.flushFile()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	err := r.enc.EncodeToken(r.root.End())
+	if err != nil {
+		return err
+	}
+
+	err = r.enc.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(r.w, "\n")
+
+	return err
+}
+
+// CheckstyleSeverities are the severities results can be mapped to in
+// checkstyle reports.
+var CheckstyleSeverities = []string{
+	string(checkstyle.SeverityError),
+	string(checkstyle.SeverityWarning),
+	string(checkstyle.SeverityInfo),
+	string(checkstyle.SeverityIgnore),
+}
+
+// checkstyleSeverity returns the checkstyle severity for a result severity.
+func checkstyleSeverity(severity Severity) checkstyle.Severity {
+	if severity == SeverityWarning {
+		return checkstyle.SeverityWarning
+	}
+
+	return checkstyle.SeverityError
+}
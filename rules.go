@@ -0,0 +1,124 @@
+package gomodguard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const errUnknownRuleFamily = "unknown rule family %s, must be one of %s"
+
+// Rule families group rules so whole capabilities can be enabled or
+// disabled independently. All rule families are enabled by default.
+const (
+	RuleFamilyModule   = "module-check"
+	RuleFamilyVersion  = "version-check"
+	RuleFamilyReplace  = "replace-check"
+	RuleFamilyLicense  = "license-check"
+	RuleFamilyMetadata = "metadata-check"
+	RuleFamilyGenerate = "generate-check"
+	RuleFamilyVCS      = "vcs-check"
+	RuleFamilyInternal = "internal-check"
+)
+
+// ruleFamilies maps each rule to its rule family.
+var ruleFamilies = map[string]string{
+	RuleNotInAllowedList:      RuleFamilyModule,
+	RuleInBlockedList:         RuleFamilyModule,
+	RuleBlockedVersion:        RuleFamilyVersion,
+	RuleMajorVersionMismatch:  RuleFamilyVersion,
+	RuleLocalReplaceDirective: RuleFamilyReplace,
+	RuleLicense:               RuleFamilyLicense,
+	RuleScorecard:             RuleFamilyMetadata,
+	RuleDepsDev:               RuleFamilyMetadata,
+	RuleVCS:                   RuleFamilyVCS,
+	RulePopularity:            RuleFamilyMetadata,
+	RuleLayerViolation:        RuleFamilyInternal,
+	RuleImportCycle:           RuleFamilyInternal,
+	RuleConfusableImport:      RuleFamilyModule,
+	RuleTyposquat:             RuleFamilyModule,
+	RuleCooldown:              RuleFamilyVersion,
+	RuleBOMDrift:              RuleFamilyVersion,
+	RuleDuplicateCategory:     RuleFamilyModule,
+	RuleSizeBudget:            RuleFamilyMetadata,
+	RuleDisallowedReplace:     RuleFamilyReplace,
+	RuleBlockedRequirement:    RuleFamilyModule,
+}
+
+// RuleFamilies returns the names of all rule families.
+func RuleFamilies() []string {
+	families := []string{RuleFamilyGenerate}
+
+	for _, family := range ruleFamilies {
+		found := false
+
+		for i := range families {
+			if families[i] == family {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			families = append(families, family)
+		}
+	}
+
+	sort.Strings(families)
+
+	return families
+}
+
+// IsRuleFamilyEnabled returns true if the rule family is not disabled.
+func (c *Configuration) IsRuleFamilyEnabled(family string) bool {
+	enabled, ok := c.Rules[family]
+
+	return !ok || enabled
+}
+
+// IsRuleEnabled returns true if the rule family of the rule is not disabled.
+func (c *Configuration) IsRuleEnabled(rule string) bool {
+	family, ok := ruleFamilies[rule]
+	if !ok {
+		return true
+	}
+
+	return c.IsRuleFamilyEnabled(family)
+}
+
+// SetRuleFamilies enables or disables the comma separated rule families.
+func (c *Configuration) SetRuleFamilies(families string, enabled bool) {
+	for _, family := range strings.Split(families, ",") {
+		if strings.TrimSpace(family) == "" {
+			continue
+		}
+
+		if c.Rules == nil {
+			c.Rules = map[string]bool{}
+		}
+
+		c.Rules[strings.TrimSpace(family)] = enabled
+	}
+}
+
+// validateRuleFamilies returns an error if an unknown rule family is configured.
+func validateRuleFamilies(config *Configuration) error {
+	families := RuleFamilies()
+
+	for family := range config.Rules {
+		known := false
+
+		for i := range families {
+			if families[i] == family {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return fmt.Errorf(errUnknownRuleFamily, family, strings.Join(families, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/ryancurrah/gomodguard"
+)
+
+// ruleDescriptions are the short descriptions of the rules in SARIF reports.
+var ruleDescriptions = map[string]string{
+	RuleNotInAllowedList:      "Module is not in the allowed list",
+	RuleInBlockedList:         "Module is in the blocked list",
+	RuleBlockedVersion:        "Module version is blocked",
+	RuleLocalReplaceDirective: "Module is replaced by a local directory",
+	RuleScorecard:             "Module scorecard is below the minimum",
+	RuleLicense:               "Module license is blocked",
+	RuleDepsDev:               "Module is blocked by its deps.dev metadata",
+	RuleMajorVersionMismatch:  "Import major version does not match go.mod",
+	RuleVCS:                   "Module version control system is not allowed",
+	RulePopularity:            "Module adoption is too low",
+	RuleLayerViolation:        "Import violates the layers of the module",
+	RuleImportCycle:           "Packages import each other",
+	RuleConfusableImport:      "Import looks like an allowed module",
+	RuleTyposquat:             "Module looks like an allowed module",
+	RuleCooldown:              "Module version is too new",
+	RuleBOMDrift:              "Module version diverges from the bill of materials",
+	RuleDuplicateCategory:     "Binary uses several modules of a category",
+	RuleSizeBudget:            "Module exceeds the size budget",
+	RuleDisallowedReplace:     "Module is replaced by a module that is not allowed",
+	RuleBlockedRequirement:    "Module requires a blocked module",
+	ResultReadError:           "File cannot be read",
+	ResultSyntaxError:         "File has invalid syntax",
+	ResultInvalidImport:       "Import path is invalid",
+	ResultInternalError:       "File cannot be linted",
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	RuleIndex  int              `json:"ruleIndex"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations"`
+	Fixes      []sarifFix       `json:"fixes,omitempty"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Docs       string      `json:"docs,omitempty"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+// sarifReporter writes the results as a SARIF 2.1.0 log for code scanning
+// dashboards. The rules of the log are the rules of the results, so the log
+// is written on Flush.
+type sarifReporter struct {
+	w         io.Writer
+	run       sarifRun
+	ruleIndex map[string]int
+}
+
+func newSARIFReporter(w io.Writer) Reporter {
+	return &sarifReporter{
+		w: w,
+		run: sarifRun{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gomodguard",
+				InformationURI: sarifToolURI,
+				Rules:          []sarifRule{},
+			}},
+			Results: []sarifResult{},
+		},
+		ruleIndex: map[string]int{},
+	}
+}
+
+func (r *sarifReporter) Report(result Result) {
+	index, ok := r.ruleIndex[result.Rule]
+	if !ok {
+		index = len(r.run.Tool.Driver.Rules)
+		r.ruleIndex[result.Rule] = index
+
+		rule := sarifRule{ID: result.Rule, ShortDescription: sarifMessage{Text: ruleDescriptions[result.Rule]}}
+		if rule.ShortDescription.Text == "" {
+			rule.ShortDescription.Text = result.Rule
+		}
+
+		r.run.Tool.Driver.Rules = append(r.run.Tool.Driver.Rules, rule)
+	}
+
+	level := "error"
+	if result.IsWarning() {
+		level = "warning"
+	}
+
+	uri := normalizePath(result.FileName)
+	location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}}
+
+	if result.LineNumber > 0 {
+		location.Region = &sarifRegion{StartLine: result.LineNumber, StartColumn: result.Position.Column}
+	}
+
+	r.run.Results = append(r.run.Results, sarifResult{
+		RuleID:     result.Rule,
+		RuleIndex:  index,
+		Level:      level,
+		Message:    sarifResultMessage(result),
+		Locations:  []sarifLocation{{PhysicalLocation: location}},
+		Fixes:      sarifFixes(result, uri),
+		Properties: sarifResultProperties(result),
+	})
+}
+
+// sarifResultMessage returns the message of the result, linking the policy
+// page of the result in markdown.
+func sarifResultMessage(result Result) sarifMessage {
+	message := sarifMessage{Text: result.Reason}
+
+	if result.PolicyURL != "" {
+		message.Text += " See " + result.PolicyURL
+		message.Markdown = fmt.Sprintf("%s [Policy](%s)", result.Reason, result.PolicyURL)
+	}
+
+	return message
+}
+
+// sarifResultProperties returns the properties of the result, nil if it
+// has none. The migration guide is kept on the result since it belongs to
+// the blocked module, not to the rule.
+func sarifResultProperties(result Result) *sarifProperties {
+	properties := sarifProperties{Provenance: result.Provenance}
+	if result.Replacement != nil {
+		properties.Docs = result.Replacement.Docs
+	}
+
+	if properties == (sarifProperties{}) {
+		return nil
+	}
+
+	return &properties
+}
+
+// sarifFixes returns the fix replacing the import path of the result with
+// the mapped replacement package, if any.
+func sarifFixes(result Result, uri string) []sarifFix {
+	if result.Package == "" || result.LineNumber < 1 || result.Position.Column < 1 {
+		return nil
+	}
+
+	replacement, ok := result.Replacement.PackagePath(result.Package)
+	if !ok {
+		return nil
+	}
+
+	quoted := strconv.Quote(replacement)
+
+	return []sarifFix{{
+		Description: sarifMessage{Text: fmt.Sprintf("Import %s instead", replacement)},
+		ArtifactChanges: []sarifArtifactChange{{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+			Replacements: []sarifReplacement{{
+				DeletedRegion: sarifRegion{
+					StartLine:   result.LineNumber,
+					StartColumn: result.Position.Column,
+					EndColumn:   result.Position.Column + len(strconv.Quote(result.Package)),
+				},
+				InsertedContent: sarifMessage{Text: quoted},
+			}},
+		}},
+	}}
+}
+
+func (r *sarifReporter) Flush() error {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{r.run}})
+}
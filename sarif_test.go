@@ -0,0 +1,133 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestWriteReportSARIF(t *testing.T) {
+	results := []gomodguard.Result{
+		{
+			FileName:   "main.go",
+			LineNumber: 4,
+			Position:   token.Position{Filename: "main.go", Line: 4, Column: 2},
+			Reason:     "import of package `github.com/foo/bar/baz` is blocked.",
+			Severity:   gomodguard.SeverityError,
+			Rule:       gomodguard.RuleInBlockedList,
+			Package:    "github.com/foo/bar/baz",
+			Module:     "github.com/foo/bar",
+			Replacement: &gomodguard.Replacement{
+				Modules: []string{"github.com/foo/qux"},
+				Docs:    "https://example.com/migrate",
+				Mapping: map[string]string{"github.com/foo/bar": "github.com/foo/qux"},
+			},
+		},
+		{
+			FileName:   "pkg/a.go",
+			LineNumber: 3,
+			Position:   token.Position{Filename: "pkg/a.go", Line: 3, Column: 8},
+			Reason:     "import of package `github.com/foo/baz` is blocked.",
+			Severity:   gomodguard.SeverityWarning,
+			Rule:       gomodguard.RuleNotInAllowedList,
+			Package:    "github.com/foo/baz",
+			Module:     "github.com/foo/baz",
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err := gomodguard.WriteReport(&buf, gomodguard.ReportSARIF, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID      string `json:"id"`
+						HelpURI string `json:"helpUri"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Fixes []struct {
+					ArtifactChanges []struct {
+						Replacements []struct {
+							DeletedRegion struct {
+								StartColumn int `json:"startColumn"`
+								EndColumn   int `json:"endColumn"`
+							} `json:"deletedRegion"`
+							InsertedContent struct {
+								Text string `json:"text"`
+							} `json:"insertedContent"`
+						} `json:"replacements"`
+					} `json:"artifactChanges"`
+				} `json:"fixes"`
+				Properties struct {
+					Docs string `json:"docs"`
+				} `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+
+	err = json.Unmarshal(buf.Bytes(), &log)
+	if err != nil {
+		t.Fatalf("got '%s' want a json log: %s", buf.String(), err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("got '%s' want a SARIF 2.1.0 log with 2 results", buf.String())
+	}
+
+	run := log.Runs[0]
+
+	var tests = []struct {
+		testName string
+		got      interface{}
+		want     interface{}
+	}{
+		{"rule ids", len(run.Tool.Driver.Rules), 2},
+		{"no rule help from a result", run.Tool.Driver.Rules[0].HelpURI, ""},
+		{"result docs", run.Results[0].Properties.Docs, "https://example.com/migrate"},
+		{"no result docs", run.Results[1].Properties.Docs, ""},
+		{"rule index", run.Results[1].RuleIndex, 1},
+		{"warning level", run.Results[1].Level, "warning"},
+		{"location", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI, "pkg/a.go"},
+		{"line", run.Results[1].Locations[0].PhysicalLocation.Region.StartLine, 3},
+		{"fixes", len(run.Results[0].Fixes), 1},
+		{"no fix without mapping", len(run.Results[1].Fixes), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got '%v' want '%v'", tt.got, tt.want)
+			}
+		})
+	}
+
+	replacement := run.Results[0].Fixes[0].ArtifactChanges[0].Replacements[0]
+	if replacement.InsertedContent.Text != `"github.com/foo/qux/baz"` || replacement.DeletedRegion.StartColumn != 2 || replacement.DeletedRegion.EndColumn != 26 {
+		t.Errorf("got '%+v' want the import path replaced with the mapped package", replacement)
+	}
+}
@@ -0,0 +1,127 @@
+package gomodguard
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+)
+
+// scanImports extracts the package clause, the imports and the comments up to
+// the end of the import declarations of a file by tokenizing only up to
+// there. False is returned when the tokens are not a well formed package
+// clause and import declarations, the file must then be parsed.
+func scanImports(fileSet *token.FileSet, filename string, data []byte) (*ast.File, bool) {
+	var (
+		s      scanner.Scanner
+		failed bool
+	)
+
+	tokenFile := fileSet.AddFile(filename, -1, len(data))
+	s.Init(tokenFile, data, func(token.Position, string) { failed = true }, scanner.ScanComments)
+
+	file := &ast.File{}
+
+	// next returns the next token that is not a comment, comments are
+	// collected for the exempt, ignore and nolint directives.
+	next := func() (token.Pos, token.Token, string) {
+		for {
+			pos, tok, lit := s.Scan()
+			if tok != token.COMMENT {
+				return pos, tok, lit
+			}
+
+			comment := &ast.Comment{Slash: pos, Text: lit}
+
+			if n := len(file.Comments); n > 0 && tokenFile.Line(file.Comments[n-1].End())+1 >= tokenFile.Line(pos) {
+				file.Comments[n-1].List = append(file.Comments[n-1].List, comment)
+			} else {
+				file.Comments = append(file.Comments, &ast.CommentGroup{List: []*ast.Comment{comment}})
+			}
+		}
+	}
+
+	pos, tok, _ := next()
+	if tok != token.PACKAGE {
+		return nil, false
+	}
+
+	file.Package = pos
+
+	pos, tok, lit := next()
+	if tok != token.IDENT {
+		return nil, false
+	}
+
+	file.Name = &ast.Ident{NamePos: pos, Name: lit}
+
+	if _, tok, _ = next(); tok != token.SEMICOLON {
+		return nil, false
+	}
+
+	for {
+		_, tok, _ = next()
+		if tok != token.IMPORT {
+			break
+		}
+
+		pos, tok, lit = next()
+
+		if tok != token.LPAREN {
+			spec, ok := scanImportSpec(next, pos, tok, lit)
+			if !ok {
+				return nil, false
+			}
+
+			file.Imports = append(file.Imports, spec)
+		} else {
+			for {
+				pos, tok, lit = next()
+				if tok == token.RPAREN {
+					break
+				}
+
+				spec, ok := scanImportSpec(next, pos, tok, lit)
+				if !ok {
+					return nil, false
+				}
+
+				file.Imports = append(file.Imports, spec)
+
+				if _, tok, _ = next(); tok == token.RPAREN {
+					break
+				} else if tok != token.SEMICOLON {
+					return nil, false
+				}
+			}
+		}
+
+		if _, tok, _ = next(); tok != token.SEMICOLON && tok != token.EOF {
+			return nil, false
+		}
+	}
+
+	return file, !failed
+}
+
+// scanImportSpec returns the import spec starting with the given token, an
+// optional package name or period followed by the import path.
+func scanImportSpec(next func() (token.Pos, token.Token, string), pos token.Pos, tok token.Token, lit string) (*ast.ImportSpec, bool) {
+	spec := &ast.ImportSpec{}
+
+	switch tok {
+	case token.IDENT:
+		spec.Name = &ast.Ident{NamePos: pos, Name: lit}
+		pos, tok, lit = next()
+	case token.PERIOD:
+		spec.Name = &ast.Ident{NamePos: pos, Name: "."}
+		pos, tok, lit = next()
+	}
+
+	if tok != token.STRING {
+		return nil, false
+	}
+
+	spec.Path = &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: lit}
+
+	return spec, true
+}
@@ -0,0 +1,93 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorFastImports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modFile, err := modfile.Parse("go.mod", []byte("module github.com/ryancurrah/example\n\nrequire github.com/foo/bar v1.0.0\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		content     string
+		wantResults int
+	}{
+		{
+			"import declaration",
+			"package example\n\nimport \"github.com/foo/bar\"\n",
+			1,
+		},
+		{
+			"grouped imports",
+			"// Package example.\npackage example\n\nimport (\n\t\"fmt\" // fmt\n\n\tb \"github.com/foo/bar\"\n\t. \"github.com/foo/bar/baz\"\n\t_ \"github.com/foo/bar/qux\"\n)\n\nfunc main() { fmt.Println(b.X) }\n",
+			3,
+		},
+		{
+			"multiple import declarations",
+			"package example\n\nimport \"fmt\"\nimport (\"github.com/foo/bar\"; \"os\")\n\nvar _ = fmt.Println\n",
+			1,
+		},
+		{
+			"exempt directive",
+			"//gomodguard:exempt rules=in_blocked_list\n\npackage example\n\nimport \"github.com/foo/bar\"\n",
+			0,
+		},
+		{
+			"malformed imports",
+			"package example\n\nimport (\n\t\"github.com/foo/bar\"\n\tfunc\n)\n",
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			filename := filepath.Join(dir, "example.go")
+
+			err := ioutil.WriteFile(filename, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := map[bool][]string{}
+
+			for _, fastImports := range []bool{false, true} {
+				processor := gomodguard.Processor{
+					Config: &gomodguard.Configuration{
+						Blocked:     gomodguard.Blocked{Modules: gomodguard.BlockedModules{{"github.com/foo/bar": gomodguard.BlockedModule{}}}},
+						FastImports: fastImports,
+					},
+					Modfile: modFile,
+				}
+				processor.SetBlockedModules()
+
+				for _, result := range processor.ProcessFiles([]string{filename}) {
+					results[fastImports] = append(results[fastImports], result.String())
+				}
+			}
+
+			if len(results[false]) != tt.wantResults {
+				t.Fatalf("got '%+v' want %d results", results[false], tt.wantResults)
+			}
+
+			if !reflect.DeepEqual(results[true], results[false]) {
+				t.Errorf("got '%+v' want '%+v'", results[true], results[false])
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// ConfigurationSchema returns a JSON Schema of the configuration file. The
+// schema is generated from the configuration types so it never drifts from
+// the configuration that is read.
+func ConfigurationSchema() ([]byte, error) {
+	schema := jsonSchema(reflect.TypeOf(Configuration{}))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "gomodguard configuration"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// jsonSchema returns the JSON Schema of a type. Struct fields are named by
+// their yaml tag and unknown properties are not allowed.
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+
+			properties[name] = jsonSchema(field.Type)
+		}
+
+		return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,148 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultScorecardAPIURL = "https://api.securityscorecards.dev"
+	scorecardTimeout       = 10 * time.Second
+	errFetchingScorecard   = "unable to fetch scorecard for %s: %w"
+	errScorecardStatus     = "unexpected scorecard api status code %d for %s"
+)
+
+var (
+	blockReasonBelowScorecardThreshold = "import of package `%%s` is blocked because the module has an OpenSSF Scorecard score of %.1f which is below the threshold of %.1f."
+	errScorecardNotFound               = fmt.Errorf("scorecard not found")
+)
+
+// Scorecard blocks direct modules with an OpenSSF Scorecard
+// score below a threshold. Only modules hosted on github.com
+// are checked.
+type Scorecard struct {
+	Threshold    float64 `yaml:"threshold" json:"threshold"`
+	APIURL       string  `yaml:"api_url" json:"api_url"`
+	EnforceAfter string  `yaml:"enforce_after" json:"enforce_after"`
+	results      *sync.Map
+	transport    http.RoundTripper
+}
+
+// ScorecardCheck is the score of a single scorecard check.
+type ScorecardCheck struct {
+	Name   string `json:"name"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// ScorecardResult is the scorecard of a project.
+type ScorecardResult struct {
+	Score  float64          `json:"score"`
+	Checks []ScorecardCheck `json:"checks"`
+}
+
+// IsEnabled returns true if a threshold is configured.
+func (s *Scorecard) IsEnabled() bool {
+	return s.Threshold > 0
+}
+
+// IsEnforced returns true if the scorecard rule is enforced at the given time.
+func (s *Scorecard) IsEnforced(now time.Time) bool {
+	return isEnforced(s.EnforceAfter, now)
+}
+
+// Lookup returns the scorecard of the project hosting the module. Results are
+// cached by project so modules from the same repository are only fetched once.
+func (s *Scorecard) Lookup(moduleName string) (*ScorecardResult, error) {
+	project, ok := scorecardProject(moduleName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not hosted on github.com", errScorecardNotFound, moduleName)
+	}
+
+	if s.results == nil {
+		s.results = &sync.Map{}
+	}
+
+	if result, ok := s.results.Load(project); ok {
+		return result.(*ScorecardResult), nil
+	}
+
+	apiURL := s.APIURL
+	if apiURL == "" {
+		apiURL = defaultScorecardAPIURL
+	}
+
+	client := &http.Client{Timeout: scorecardTimeout, Transport: s.transport}
+
+	resp, err := client.Get(fmt.Sprintf("%s/projects/%s", strings.TrimRight(apiURL, "/"), project))
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingScorecard, project, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", errScorecardNotFound, project)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf(errScorecardStatus, resp.StatusCode, project)
+	}
+
+	result := &ScorecardResult{}
+
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchingScorecard, project, err)
+	}
+
+	s.results.Store(project, result)
+
+	return result, nil
+}
+
+// IsBelowThreshold returns true if the score is below the given threshold.
+func (r *ScorecardResult) IsBelowThreshold(threshold float64) bool {
+	return r.Score < threshold
+}
+
+// FailingChecks returns the checks that scored below the threshold.
+// Checks with a negative score could not be run and are ignored.
+func (r *ScorecardResult) FailingChecks(threshold float64) []ScorecardCheck {
+	failing := []ScorecardCheck{}
+
+	for i := range r.Checks {
+		if r.Checks[i].Score >= 0 && float64(r.Checks[i].Score) < threshold {
+			failing = append(failing, r.Checks[i])
+		}
+	}
+
+	return failing
+}
+
+// Message returns the failing checks with their scores.
+func (r *ScorecardResult) Message(threshold float64) string {
+	failing := r.FailingChecks(threshold)
+	if len(failing) == 0 {
+		return ""
+	}
+
+	checks := make([]string, 0, len(failing))
+	for i := range failing {
+		checks = append(checks, fmt.Sprintf("%s (%d)", failing[i].Name, failing[i].Score))
+	}
+
+	return fmt.Sprintf("Failing checks: %s.", strings.Join(checks, ", "))
+}
+
+// scorecardProject returns the github.com/<owner>/<repo> project of a module.
+func scorecardProject(moduleName string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(moduleName), "/")
+	if len(parts) < 3 || !strings.EqualFold(parts[0], "github.com") {
+		return "", false
+	}
+
+	return strings.Join(parts[:3], "/"), true
+}
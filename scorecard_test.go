@@ -0,0 +1,81 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestScorecardLookup(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path != "/projects/github.com/someorg/somemodule" {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprint(w, `{"score": 3.5, "checks": [{"name": "Maintained", "score": 0}, {"name": "Code-Review", "score": 8}, {"name": "Fuzzing", "score": -1}]}`)
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		testName    string
+		moduleName  string
+		wantErr     bool
+		wantMessage string
+	}{
+		{
+			"module has a scorecard",
+			"github.com/someorg/somemodule/v2",
+			false,
+			"Failing checks: Maintained (0).",
+		},
+		{
+			"module has no scorecard",
+			"github.com/someorg/othermodule",
+			true,
+			"",
+		},
+		{
+			"module not hosted on github",
+			"golang.org/x/mod",
+			true,
+			"",
+		},
+	}
+
+	scorecard := gomodguard.Scorecard{Threshold: 5, APIURL: server.URL}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			result, err := scorecard.Lookup(tt.moduleName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error '%v' want error '%v'", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !result.IsBelowThreshold(scorecard.Threshold) {
+				t.Errorf("got score '%v' want below threshold '%v'", result.Score, scorecard.Threshold)
+			}
+
+			if message := result.Message(scorecard.Threshold); message != tt.wantMessage {
+				t.Errorf("got '%s' want '%s'", message, tt.wantMessage)
+			}
+		})
+	}
+
+	_, _ = scorecard.Lookup("github.com/someorg/somemodule/otherpkg")
+
+	if requests != 2 {
+		t.Errorf("got '%d' requests want '%d', scorecards should be cached by project", requests, 2)
+	}
+}
@@ -0,0 +1,252 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+	"golang.org/x/mod/module"
+)
+
+const (
+	errInvalidSize     = "invalid size_budget size %s of %s, must be a number of bytes with an optional unit such as 500KB or 20MiB"
+	errParsingSize     = "invalid size %s"
+	errFetchingZipSize = "unable to fetch zip size of %s@%s: %w"
+	sizeBudgetMaxSize  = "max_size"
+)
+
+var blockReasonSizeBudget = "import of package `%%s` is blocked because version `%s` of the module is %s, modules must be at most %s."
+
+// sizeUnits are the units of sizes, decimal units are powers of 1000 and
+// binary units powers of 1024. Longer suffixes are listed first.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// SizeBudget blocks direct modules whose zip file exceeds a maximum size, to
+// keep container images and build times in check. MaxSize applies to all
+// modules and Modules overrides it per module path or domain, the longest
+// match wins and 0 exempts a module. Sizes are a number of bytes with an
+// optional unit such as 500KB or 20MiB. Zip sizes are read from the module
+// proxy, or the module cache when offline or for private modules.
+type SizeBudget struct {
+	MaxSize   string            `yaml:"max_size" json:"max_size"`
+	Modules   map[string]string `yaml:"modules" json:"modules"`
+	ProxyURL  string            `yaml:"proxy_url" json:"proxy_url"`
+	transport http.RoundTripper
+}
+
+// IsEnabled returns true if a maximum size is configured.
+func (s *SizeBudget) IsEnabled() bool {
+	if size, err := ParseSize(s.MaxSize); err == nil && size > 0 {
+		return true
+	}
+
+	for _, maxSize := range s.Modules {
+		if size, err := ParseSize(maxSize); err == nil && size > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MaxBytes returns the maximum zip size in bytes of the module, 0 if the
+// module has no maximum.
+func (s *SizeBudget) MaxBytes(moduleName string) int64 {
+	maxSize, longest := s.MaxSize, ""
+
+	for path, pathSize := range s.Modules {
+		if match.Domain(path, moduleName) && len(strings.TrimSpace(path)) > len(longest) {
+			maxSize, longest = pathSize, strings.TrimSpace(path)
+		}
+	}
+
+	size, err := ParseSize(maxSize)
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// ZipSize returns the size of the zip file of the module version served by the
+// module proxy. The configured proxy is used, otherwise the first proxy of
+// GOPROXY or proxy.golang.org.
+func (s *SizeBudget) ZipSize(moduleName, moduleVersion, goProxy string) (int64, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return 0, fmt.Errorf(errFetchingZipSize, moduleName, moduleVersion, err)
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return 0, fmt.Errorf(errFetchingZipSize, moduleName, moduleVersion, err)
+	}
+
+	proxyURL := s.ProxyURL
+	if proxyURL == "" {
+		proxyURL = goProxyURL(goProxy)
+	}
+
+	url := fmt.Sprintf("%s/%s/@v/%s.zip", strings.TrimRight(proxyURL, "/"), escapedPath, escapedVersion)
+	client := &http.Client{Timeout: proxyTimeout, Transport: s.transport}
+
+	resp, err := client.Head(url)
+	if err != nil {
+		return 0, fmt.Errorf(errFetchingZipSize, moduleName, moduleVersion, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && resp.ContentLength >= 0 {
+		return resp.ContentLength, nil
+	}
+
+	// Proxies not answering HEAD requests with the length are downloaded.
+	resp, err = client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf(errFetchingZipSize, moduleName, moduleVersion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf(errProxyStatus, resp.StatusCode, moduleName, moduleVersion)
+	}
+
+	size, err := io.Copy(ioutil.Discard, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf(errFetchingZipSize, moduleName, moduleVersion, err)
+	}
+
+	return size, nil
+}
+
+// ZipSize returns the size of the zip file of the module version in the
+// module cache.
+func (c modCache) ZipSize(moduleName, moduleVersion string) (int64, error) {
+	escapedPath, err := module.EscapePath(moduleName)
+	if err != nil {
+		return 0, err
+	}
+
+	escapedVersion, err := module.EscapeVersion(moduleVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(filepath.Join(c.dir, "cache", "download", filepath.FromSlash(escapedPath), "@v", escapedVersion+".zip"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s@%s", errModCacheNotFound, moduleName, moduleVersion)
+	}
+
+	return info.Size(), nil
+}
+
+// ParseSize returns the number of bytes of a size such as 500KB or 20MiB. A
+// size without unit is a number of bytes and an empty size is 0.
+func ParseSize(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(strings.ToUpper(size), strings.ToUpper(unit.suffix)) {
+			size, multiplier = strings.TrimSpace(size[:len(size)-len(unit.suffix)]), unit.bytes
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(size, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf(errParsingSize, size)
+	}
+
+	return int64(number * float64(multiplier)), nil
+}
+
+// formatSize returns the size in bytes in the largest decimal unit it fills.
+func formatSize(size int64) string {
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1000 * 1000 * 1000}, {"MB", 1000 * 1000}, {"KB", 1000}} {
+		if size >= unit.bytes {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(unit.bytes), unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%d B", size)
+}
+
+// validateSizeBudget returns an error if a maximum size is malformed.
+func validateSizeBudget(config *Configuration) error {
+	budget := &config.Blocked.SizeBudget
+
+	if _, err := ParseSize(budget.MaxSize); err != nil {
+		return fmt.Errorf(errInvalidSize, budget.MaxSize, sizeBudgetMaxSize)
+	}
+
+	for path, maxSize := range budget.Modules {
+		if _, err := ParseSize(maxSize); err != nil {
+			return fmt.Errorf(errInvalidSize, maxSize, strings.TrimSpace(path))
+		}
+	}
+
+	return nil
+}
+
+// sizeBudgetBlockReason returns a block reason if the zip file of the module
+// version exceeds its maximum size.
+func (p *Processor) sizeBudgetBlockReason(canonicalModuleName, lintedModuleName, lintedModuleVersion string) (blockReason, bool) {
+	budget := &p.network().sizeBudget
+
+	maxBytes := budget.MaxBytes(canonicalModuleName)
+	if maxBytes <= 0 {
+		return blockReason{}, false
+	}
+
+	var (
+		size int64
+		err  error
+	)
+
+	if p.isOffline() || p.goEnv.isPrivateModule(lintedModuleName) {
+		size, err = p.modCache().ZipSize(lintedModuleName, lintedModuleVersion)
+	} else {
+		size, err = budget.ZipSize(lintedModuleName, lintedModuleVersion, p.goEnv["GOPROXY"])
+	}
+
+	if err != nil {
+		p.warnf("%s", err)
+		return blockReason{}, false
+	}
+
+	if size <= maxBytes {
+		return blockReason{}, false
+	}
+
+	return blockReason{
+		rule:     RuleSizeBudget,
+		reason:   fmt.Sprintf(blockReasonSizeBudget, escapeReason(lintedModuleVersion), formatSize(size), formatSize(maxBytes)),
+		severity: SeverityError,
+		data:     MessageData{Module: lintedModuleName, Version: lintedModuleVersion},
+	}, true
+}
@@ -0,0 +1,139 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestParseSize(t *testing.T) {
+	var tests = []struct {
+		testName string
+		size     string
+		want     int64
+		wantErr  bool
+	}{
+		{"empty", "", 0, false},
+		{"bytes", "512", 512, false},
+		{"bytes unit", "512B", 512, false},
+		{"decimal unit", "20MB", 20 * 1000 * 1000, false},
+		{"binary unit", "1.5 KiB", 1536, false},
+		{"lowercase unit", "2gb", 2 * 1000 * 1000 * 1000, false},
+		{"unknown unit", "20TB", 0, true},
+		{"negative", "-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := gomodguard.ParseSize(tt.size)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("got '%v' '%v' want '%v' error '%v'", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSizeBudget(t *testing.T) {
+	sizes := map[string]int{
+		"/github.com/example/large/@v/v1.0.0.zip":  3 * 1000 * 1000,
+		"/github.com/example/small/@v/v1.0.0.zip":  200 * 1000,
+		"/github.com/example/exempt/@v/v1.0.0.zip": 3 * 1000 * 1000,
+		"/github.com/example/nohead/@v/v1.0.0.zip": 2 * 1000 * 1000,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size, ok := sizes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method == http.MethodHead {
+			if strings.Contains(r.URL.Path, "nohead") {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			w.Header().Set("Content-Length", strconv.Itoa(size))
+
+			return
+		}
+
+		_, _ = w.Write(make([]byte, size))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modules := []string{"large", "small", "exempt", "nohead", "unknown"}
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n"
+	src := "package example\n\nimport (\n"
+
+	for _, name := range modules {
+		goMod += "\tgithub.com/example/" + name + " v1.0.0\n"
+		src += "\t_ \"github.com/example/" + name + "\"\n"
+	}
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod+")\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte(src+")\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := gomodguard.Configuration{
+		Mode: gomodguard.ModeBlock,
+		Blocked: gomodguard.Blocked{SizeBudget: gomodguard.SizeBudget{
+			MaxSize:  "1MB",
+			Modules:  map[string]string{"github.com/example/exempt": "0"},
+			ProxyURL: server.URL,
+		}},
+	}
+
+	processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+	processor.SetBlockedModules()
+
+	results := processor.ProcessFiles([]string{filename})
+
+	got := []string{}
+	for i := range results {
+		if results[i].Rule == gomodguard.RuleSizeBudget {
+			got = append(got, results[i].Module)
+		}
+	}
+
+	sort.Strings(got)
+
+	want := []string{"github.com/example/large", "github.com/example/nohead"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%v' want '%v'", got, want)
+	}
+
+	config.Blocked.SizeBudget.MaxSize = "1 parsec"
+
+	_, err = gomodguard.NewProcessorFromRequires(&config, "github.com/ryancurrah/example", nil)
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package gomodguard
+
+import (
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Batch is a lint of files given by their contents, for code review bots
+// and serverless functions without a checkout of the module. Files maps file
+// names to their contents and GoMod is the contents of the go.mod file.
+// MetadataLookup, if set, provides the module metadata instead of deps.dev.
+type Batch struct {
+	Config         *Configuration
+	GoMod          []byte
+	Files          map[string][]byte
+	MetadataLookup func(moduleName, moduleVersion string) (*ModuleMetadata, error)
+}
+
+// BatchResult are the results of a batch and the warnings of the rules that
+// could not be evaluated, such as unreachable module metadata services.
+type BatchResult struct {
+	Results  []Result
+	Warnings []string
+}
+
+// LintBatch lints the files of the batch in one call. No files are read
+// besides the ones referenced by the configuration, such as a messages file,
+// the go command is not run and warnings are returned instead of logged.
+// Errors of the configuration are ExitConfigInvalid failures.
+func LintBatch(batch Batch) (*BatchResult, error) {
+	config := batch.Config
+	if config == nil {
+		config = &Configuration{}
+	}
+
+	p, err := lintSources(config, batch.GoMod, batch.Files, batch.MetadataLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchResult{Results: p.Result, Warnings: p.warnings}, nil
+}
+
+// lintSources lints files given by their contents against the go.mod
+// contents, without reading files or running the go command. The go
+// environment is only taken from the configuration. Files are linted in
+// name order and warnings are collected by the returned processor.
+func lintSources(config *Configuration, goMod []byte, sources map[string][]byte,
+	lookup func(moduleName, moduleVersion string) (*ModuleMetadata, error)) (*Processor, error) {
+	modFile, err := modfile.Parse(goModFilename, goMod, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	env := goEnv{}
+	for key, value := range config.GoEnv {
+		env[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	p, err := newProcessor(config, env, modFile, true)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MetadataLookup = lookup
+	p.goListLoaded = true // Packages resolve to the requires of the go.mod contents.
+	p.SetBlockedModules()
+
+	filenames := make([]string, 0, len(sources))
+	for filename := range sources {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	p.fileSet = token.NewFileSet()
+
+	for _, filename := range filenames {
+		data := sources[filename]
+		p.processSafely(filename, func() { p.process(filename, data) })
+	}
+
+	p.processPackageRules()
+
+	p.applyExemptions(0)
+
+	return p, nil
+}
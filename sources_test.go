@@ -0,0 +1,119 @@
+package gomodguard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestLintBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	batch := gomodguard.Batch{
+		Config: &gomodguard.Configuration{
+			Mode:    gomodguard.ModeBlock,
+			Allowed: gomodguard.Allowed{Modules: []string{"github.com/foo/bar"}},
+			Blocked: gomodguard.Blocked{
+				Modules:   gomodguard.BlockedModules{{"github.com/foo/baz": {}}},
+				Scorecard: gomodguard.Scorecard{Threshold: 5, APIURL: server.URL},
+			},
+		},
+		GoMod: []byte("module example.com/m\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n"),
+		Files: map[string][]byte{
+			"b.go": []byte("package m\n\nimport _ \"github.com/foo/baz/qux\"\n"),
+			"a.go": []byte("package m\n\nimport (\n\t_ \"github.com/foo/bar\"\n\t_ \"github.com/foo/baz\"\n)\n"),
+		},
+	}
+
+	got, err := gomodguard.LintBatch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a.go:5 github.com/foo/baz", "b.go:3 github.com/foo/baz/qux"}
+
+	if len(got.Results) != len(want) {
+		t.Fatalf("got '%+v' want '%v'", got.Results, want)
+	}
+
+	for i, result := range got.Results {
+		if result.FileName+":"+strconv.Itoa(result.LineNumber)+" "+result.Package != want[i] {
+			t.Errorf("got '%s:%d %s' want '%s'", result.FileName, result.LineNumber, result.Package, want[i])
+		}
+	}
+
+	// The allowed list is ignored and the scorecards of both modules cannot
+	// be fetched.
+	if len(got.Warnings) != 3 {
+		t.Errorf("got '%v' want 3 warnings", got.Warnings)
+	}
+
+	batch.Config = &gomodguard.Configuration{Mode: "strict"}
+
+	_, err = gomodguard.LintBatch(batch)
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
+
+func TestLintBatchModulesURL(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		fmt.Fprint(w, `[{"github.com/foo/bar": {"reason": "Some reason."}}]`)
+	}))
+	defer server.Close()
+
+	// Only the go environment of the configuration is used, not the one of
+	// the process.
+	var tests = []struct {
+		testName     string
+		path         string
+		goEnv        map[string]string
+		wantResults  int
+		wantWarnings int
+		wantRequests int
+	}{
+		{"offline", "/offline", map[string]string{"GOPROXY": "off"}, 0, 1, 0},
+		{"online", "/online", map[string]string{}, 1, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			requests = 0
+
+			got, err := gomodguard.LintBatch(gomodguard.Batch{
+				Config: &gomodguard.Configuration{
+					Blocked: gomodguard.Blocked{ModulesURL: server.URL + tt.path},
+					GoEnv:   tt.goEnv,
+				},
+				GoMod: []byte("module example.com/m\n\nrequire github.com/foo/bar v1.0.0\n"),
+				Files: map[string][]byte{"a.go": []byte("package m\n\nimport _ \"github.com/foo/bar\"\n")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got.Results) != tt.wantResults {
+				t.Errorf("got '%+v' want %d results", got.Results, tt.wantResults)
+			}
+
+			if len(got.Warnings) != tt.wantWarnings {
+				t.Errorf("got '%v' want %d warnings", got.Warnings, tt.wantWarnings)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("got %d requests want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package gomodguard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResultStream is a sequence of results too large to keep in memory, such as
+// the results of an organization wide scan. Up to limit results are kept in
+// memory, further results spill the buffered results to a temporary file as
+// JSON lines. A limit of zero keeps all results in memory.
+type ResultStream struct {
+	limit   int
+	results []Result
+	file    *os.File
+	writer  *bufio.Writer
+	spilled int
+}
+
+// NewResultStream returns a stream keeping at most limit results in memory.
+func NewResultStream(limit int) *ResultStream {
+	return &ResultStream{limit: limit}
+}
+
+// Add adds results to the stream.
+func (s *ResultStream) Add(results ...Result) error {
+	s.results = append(s.results, results...)
+
+	if s.limit <= 0 || len(s.results) <= s.limit {
+		return nil
+	}
+
+	return s.spill()
+}
+
+// spill appends the results in memory to the temporary file.
+func (s *ResultStream) spill() error {
+	if s.file == nil {
+		f, err := ioutil.TempFile("", "gomodguard-results-*.jsonl")
+		if err != nil {
+			return err
+		}
+
+		s.file, s.writer = f, bufio.NewWriter(f)
+	}
+
+	enc := json.NewEncoder(s.writer)
+
+	for i := range s.results {
+		err := enc.Encode(&s.results[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	s.spilled += len(s.results)
+	s.results = s.results[:0]
+
+	return nil
+}
+
+// Len returns the number of results in the stream.
+func (s *ResultStream) Len() int {
+	return s.spilled + len(s.results)
+}
+
+// Each calls fn for every result of the stream in the order they were added.
+func (s *ResultStream) Each(fn func(Result) error) error {
+	if s.file != nil {
+		err := s.writer.Flush()
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(s.file.Name())
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		dec := json.NewDecoder(bufio.NewReader(f))
+
+		for {
+			var result Result
+
+			err = dec.Decode(&result)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			err = fn(result)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range s.results {
+		err := fn(s.results[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Results returns all results of the stream in memory.
+func (s *ResultStream) Results() ([]Result, error) {
+	results := make([]Result, 0, s.Len())
+
+	err := s.Each(func(result Result) error {
+		results = append(results, result)
+		return nil
+	})
+
+	return results, err
+}
+
+// Close removes the temporary file of spilled results.
+func (s *ResultStream) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	s.file.Close()
+	err := os.Remove(s.file.Name())
+	s.file, s.writer, s.spilled = nil, nil, 0
+
+	return err
+}
+
+// ProcessFilesStream lints the files like ProcessFiles but adds the results
+// to the stream instead of keeping them. Files are linted one directory at a
+// time, so the exemptions of a package apply before its results are added.
+// Files are read and parsed ahead by the workers across directories.
+func (p *Processor) ProcessFilesStream(filenames []string, stream *ResultStream) error {
+	return p.ProcessFilesStreamContext(context.Background(), filenames, stream)
+}
+
+// ProcessFilesStreamContext lints the files like ProcessFilesStream until the
+// context is cancelled or its deadline passes. On cancellation the results of
+// the directories linted so far are in the stream and the error of the
+// context is returned.
+func (p *Processor) ProcessFilesStreamContext(ctx context.Context, filenames []string, stream *ResultStream) error {
+	dirs := []string{}
+	byDir := map[string][]string{}
+
+	for _, filename := range filenames {
+		dir := filepath.Dir(filename)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+
+		byDir[dir] = append(byDir[dir], filename)
+	}
+
+	ordered := make([]string, 0, len(filenames))
+	dirEnds := map[int]bool{}
+
+	for _, dir := range dirs {
+		ordered = append(ordered, byDir[dir]...)
+		dirEnds[len(ordered)-1] = true
+	}
+
+	p.Result = []Result{}
+
+	err := p.processFiles(ctx, ordered, func(i int) error {
+		if !dirEnds[i] {
+			return nil
+		}
+
+		p.applyExemptions(0)
+		p.emitFindings(p.Result)
+
+		err := stream.Add(p.Result...)
+		p.Result = []Result{}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	p.processPackageRules()
+	p.applyExemptions(0)
+	p.emitFindings(p.Result)
+
+	err = stream.Add(p.Result...)
+	p.Result = []Result{}
+
+	return err
+}
+
+// WriteReportsStream writes the results of the stream to all reports.
+// Webhook reports group results by owner and read the stream into memory.
+func WriteReportsStream(reports []Report, stream *ResultStream) error {
+	for i := range reports {
+		var err error
+
+		switch {
+		case strings.EqualFold(reports[i].Format, ReportWebhook):
+			var results []Result
+
+			results, err = stream.Results()
+			if err == nil {
+				err = sendWebhook(reports[i], results)
+			}
+		case reports[i].IsStdout():
+			err = writeReportStreamTo(os.Stdout, reports[i], stream)
+		default:
+			err = writeFileAtomic(reports[i].File, func(w io.Writer) error {
+				return writeReportStreamTo(w, reports[i], stream)
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteReportStream writes the results of the stream in the report format
+// without reading the stream into memory.
+func WriteReportStream(w io.Writer, format string, stream *ResultStream) error {
+	return writeReportStreamTo(w, Report{Format: format}, stream)
+}
+
+// writeReportStreamTo writes the results of the stream to w as configured
+// by the report.
+func writeReportStreamTo(w io.Writer, report Report, stream *ResultStream) error {
+	reporter, err := newReportReporter(w, report)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Each(func(result Result) error {
+		reporter.Report(result)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return reporter.Flush()
+}
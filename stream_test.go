@@ -0,0 +1,127 @@
+package gomodguard_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestResultStream(t *testing.T) {
+	var tests = []struct {
+		testName string
+		limit    int
+	}{
+		{"in memory", 0},
+		{"spilled", 2},
+		{"spilled every result", 1},
+	}
+
+	want := make([]gomodguard.Result, 0, 5)
+	for i := 1; i <= 5; i++ {
+		want = append(want, gomodguard.Result{
+			FileName:    fmt.Sprintf("file%d.go", i),
+			LineNumber:  i,
+			Reason:      "import of package `github.com/foo/bar` is blocked.",
+			Severity:    gomodguard.SeverityError,
+			Replacement: &gomodguard.Replacement{Modules: []string{"github.com/foo/baz"}},
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			stream := gomodguard.NewResultStream(tt.limit)
+			defer stream.Close()
+
+			for i := range want {
+				err := stream.Add(want[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if stream.Len() != len(want) {
+				t.Errorf("got '%d' want '%d'", stream.Len(), len(want))
+			}
+
+			got, err := stream.Results()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got '%+v' want '%+v'", got, want)
+			}
+
+			var buf bytes.Buffer
+
+			err = gomodguard.WriteReportStream(&buf, gomodguard.ReportText, stream)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != len(want) {
+				t.Errorf("got '%d' lines want '%d'", lines, len(want))
+			}
+		})
+	}
+}
+
+func TestProcessorProcessFilesStream(t *testing.T) {
+	filteredFiles := gomodguard.GetFilteredFiles(cwd, false, []string{"./..."})
+
+	processor, err := gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := append([]gomodguard.Result{}, processor.ProcessFiles(filteredFiles)...)
+
+	processor, err = gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := gomodguard.NewResultStream(1)
+	defer stream.Close()
+
+	err = processor.ProcessFilesStream(filteredFiles, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := stream.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) == 0 || len(got) != len(want) {
+		t.Fatalf("got '%+v' want '%+v'", got, want)
+	}
+
+	for i := range got {
+		if got[i].String() != want[i].String() {
+			t.Errorf("got '%s' want '%s'", got[i].String(), want[i].String())
+		}
+	}
+
+	processor, err = gomodguard.NewProcessor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cancelled := gomodguard.NewResultStream(0)
+	defer cancelled.Close()
+
+	err = processor.ProcessFilesStreamContext(ctx, filteredFiles, cancelled)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got '%v' want '%v'", err, context.Canceled)
+	}
+}
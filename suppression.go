@@ -0,0 +1,121 @@
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+const errReadingSuppressionAgesFile = "unable to read suppression ages file %s: %w"
+
+// SuppressionAge records when a suppressed finding was first seen. Findings
+// are identified by file, rule and module so they keep their age when lines
+// move.
+type SuppressionAge struct {
+	FileName  string    `json:"file"`
+	Rule      string    `json:"rule"`
+	Module    string    `json:"module,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// Days returns the number of whole days the finding has been suppressed.
+func (s SuppressionAge) Days(now time.Time) int {
+	return int(now.Sub(s.FirstSeen).Hours() / 24)
+}
+
+// String returns the file, rule and module of the finding.
+func (s SuppressionAge) String() string {
+	if s.Module == "" {
+		return fmt.Sprintf("%s %s", s.FileName, s.Rule)
+	}
+
+	return fmt.Sprintf("%s %s of %s", s.FileName, s.Rule, s.Module)
+}
+
+// UpdateSuppressionAges returns the ages of the suppressed results. Findings
+// already recorded keep their first seen time, new findings are first seen
+// now and findings no longer suppressed are dropped. The ages are sorted
+// oldest first.
+func UpdateSuppressionAges(recorded []SuppressionAge, suppressed []Result, now time.Time) []SuppressionAge {
+	firstSeen := make(map[SuppressionAge]time.Time, len(recorded))
+
+	for _, age := range recorded {
+		key := SuppressionAge{FileName: normalizePath(age.FileName), Rule: age.Rule, Module: age.Module}
+		if seen, ok := firstSeen[key]; !ok || age.FirstSeen.Before(seen) {
+			firstSeen[key] = age.FirstSeen
+		}
+	}
+
+	ages := []SuppressionAge{}
+	added := map[SuppressionAge]bool{}
+
+	for i := range suppressed {
+		key := SuppressionAge{
+			FileName: normalizePath(suppressed[i].FileName),
+			Rule:     suppressed[i].Rule,
+			Module:   suppressed[i].Module,
+		}
+
+		if added[key] {
+			continue
+		}
+
+		added[key] = true
+
+		age := key
+		age.FirstSeen = now
+
+		if seen, ok := firstSeen[key]; ok {
+			age.FirstSeen = seen
+		}
+
+		ages = append(ages, age)
+	}
+
+	sort.SliceStable(ages, func(i, j int) bool {
+		if !ages[i].FirstSeen.Equal(ages[j].FirstSeen) {
+			return ages[i].FirstSeen.Before(ages[j].FirstSeen)
+		}
+
+		return ages[i].String() < ages[j].String()
+	})
+
+	return ages
+}
+
+// ReadSuppressionAges reads a suppression ages file. A missing file has no
+// recorded ages.
+func ReadSuppressionAges(filename string) ([]SuppressionAge, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []SuppressionAge{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf(errReadingSuppressionAgesFile, filename, err)
+	}
+
+	ages := []SuppressionAge{}
+
+	err = json.Unmarshal(data, &ages)
+	if err != nil {
+		return nil, fmt.Errorf(errReadingSuppressionAgesFile, filename, err)
+	}
+
+	return ages, nil
+}
+
+// WriteSuppressionAges writes the suppression ages file.
+func WriteSuppressionAges(filename string, ages []SuppressionAge) error {
+	data, err := json.MarshalIndent(ages, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+}
@@ -0,0 +1,80 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestUpdateSuppressionAges(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -30)
+	older := now.AddDate(0, 0, -90)
+
+	recorded := []gomodguard.SuppressionAge{
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", FirstSeen: old},
+		{FileName: "pkg/a.go", Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", FirstSeen: older},
+		{FileName: "fixed.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", FirstSeen: older},
+	}
+
+	suppressed := []gomodguard.Result{
+		{FileName: "main.go", LineNumber: 3, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar"},
+		{FileName: "main.go", LineNumber: 4, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar"},
+		{FileName: "new.go", LineNumber: 3, Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar"},
+		{FileName: "pkg/a.go", LineNumber: 5, Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz"},
+	}
+
+	got := gomodguard.UpdateSuppressionAges(recorded, suppressed, now)
+
+	want := []gomodguard.SuppressionAge{
+		{FileName: "pkg/a.go", Rule: gomodguard.RuleNotInAllowedList, Module: "github.com/foo/baz", FirstSeen: older},
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", FirstSeen: old},
+		{FileName: "new.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", FirstSeen: now},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+
+	if days := got[0].Days(now); days != 90 {
+		t.Errorf("got '%v' want '%v'", days, 90)
+	}
+}
+
+func TestSuppressionAgesFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "suppressions.json")
+
+	ages, err := gomodguard.ReadSuppressionAges(filename)
+	if err != nil || len(ages) != 0 {
+		t.Fatalf("got '%+v' '%v' want no ages for a missing file", ages, err)
+	}
+
+	want := []gomodguard.SuppressionAge{
+		{FileName: "main.go", Rule: gomodguard.RuleInBlockedList, Module: "github.com/foo/bar", FirstSeen: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	err = gomodguard.WriteSuppressionAges(filename, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gomodguard.ReadSuppressionAges(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got '%+v' want '%+v'", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package gomodguard
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Handling of symlinks found while walking the directories of package
+// patterns such as ./...
+const (
+	// SymlinksFiles follows symlinked files but not symlinked directories.
+	SymlinksFiles = "files"
+	// SymlinksFollow follows symlinked files and directories, symlinks
+	// looping back to a directory being walked are skipped with a warning.
+	SymlinksFollow = "follow"
+	// SymlinksRefuse skips symlinked files and directories.
+	SymlinksRefuse = "refuse"
+)
+
+const (
+	errUnknownSymlinks = "unknown symlinks %s, must be one of %s"
+	warnSymlinkLoop    = "skipping %s, it links to %s which is already being walked"
+)
+
+// skippedDirNames are the directories not walked, they hold the packages of
+// other languages and may be large.
+var skippedDirNames = []string{"node_modules"}
+
+// SymlinkPolicies are the valid handlings of symlinks during directory walks.
+var SymlinkPolicies = []string{SymlinksFiles, SymlinksFollow, SymlinksRefuse}
+
+// validateSymlinks returns an error if the handling of symlinks is unknown.
+func validateSymlinks(config *Configuration) error {
+	symlinks := strings.TrimSpace(config.Symlinks)
+	if symlinks == "" || containsString(SymlinkPolicies, symlinks) {
+		return nil
+	}
+
+	return fmt.Errorf(errUnknownSymlinks, symlinks, strings.Join(SymlinkPolicies, ", "))
+}
+
+// walkGoFiles returns the go files in the directory and its subdirectories in
+// lexical order, handling symlinks according to the policy. Directories are
+// walked once even if several symlinks lead to them, skipped directories are
+// not walked.
+func walkGoFiles(root, symlinks string) []string {
+	symlinks = strings.TrimSpace(symlinks)
+	foundFiles := []string{}
+	walking := map[string]bool{}
+	walked := map[string]bool{}
+
+	var walk func(dir string)
+
+	walk = func(dir string) {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return
+		}
+
+		if walking[realDir] {
+			logger.Printf("warning: "+warnSymlinkLoop, dir, realDir)
+			return
+		}
+
+		if walked[realDir] {
+			return
+		}
+
+		walking[realDir], walked[realDir] = true, true
+		defer delete(walking, realDir)
+
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+
+			if containsString(skippedDirNames, entry.Name()) {
+				continue
+			}
+
+			if entry.Mode()&os.ModeSymlink != 0 {
+				if symlinks == SymlinksRefuse {
+					continue
+				}
+
+				target, err := os.Stat(path)
+				if err != nil {
+					continue // Dangling symlink.
+				}
+
+				if target.IsDir() {
+					if symlinks == SymlinksFollow {
+						walk(path)
+					}
+
+					continue
+				}
+
+				entry = target
+			}
+
+			if entry.IsDir() {
+				walk(path)
+				continue
+			}
+
+			if strings.HasSuffix(entry.Name(), ".go") {
+				foundFiles = append(foundFiles, path)
+			}
+		}
+	}
+
+	walk(root)
+
+	return foundFiles
+}
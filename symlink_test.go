@@ -0,0 +1,89 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestGetFilteredFilesSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"repo/main.go", "generated/gen.go", "generated/nested/nested.go"} {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("package x\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		"repo/gen":             "../generated",
+		"repo/linked.go":       "../generated/gen.go",
+		"generated/nested/up":  "..",
+		"repo/dangling.go":     "../missing.go",
+		"repo/gen_again":       "../generated",
+		"generated/nested/top": "../../repo",
+	}
+
+	for name, target := range links {
+		err = os.Symlink(target, filepath.Join(dir, name))
+		if err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		symlinks string
+		want     []string
+	}{
+		{
+			symlinks: "",
+			want:     []string{"repo/linked.go", "repo/main.go"},
+		},
+		{
+			symlinks: gomodguard.SymlinksFiles,
+			want:     []string{"repo/linked.go", "repo/main.go"},
+		},
+		{
+			symlinks: gomodguard.SymlinksRefuse,
+			want:     []string{"repo/main.go"},
+		},
+		{
+			symlinks: gomodguard.SymlinksFollow,
+			want:     []string{"repo/gen/gen.go", "repo/gen/nested/nested.go", "repo/linked.go", "repo/main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symlinks, func(t *testing.T) {
+			got := gomodguard.GetFilteredFilesSymlinks(dir, false, tt.symlinks, []string{filepath.Join(dir, "repo") + "/..."})
+
+			want := make([]string, 0, len(tt.want))
+			for _, name := range tt.want {
+				want = append(want, filepath.FromSlash(name))
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got '%v' want '%v'", got, want)
+			}
+		})
+	}
+
+	_, err = gomodguard.NewProcessorFromModBytes(&gomodguard.Configuration{Symlinks: "always"}, []byte("module example.com/m\n"))
+	if gomodguard.FailureExitCode(gomodguard.ExitCodeModeMatrix, err) != gomodguard.ExitConfigInvalid {
+		t.Errorf("got '%v' want an invalid configuration error", err)
+	}
+}
@@ -0,0 +1,142 @@
+package gomodguard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ryancurrah/gomodguard/match"
+)
+
+var blockReasonTyposquat = "import of package `%%s` is blocked because the module `%s` looks like the allowed module `%s` and may be a typosquat."
+
+// Typosquatting blocks required modules that are not allowed but whose path
+// is within max distance edits of an allowed module or domain, after
+// lowercasing and replacing confusable characters. Insertions, deletions,
+// substitutions and transpositions of adjacent characters are one edit each.
+type Typosquatting struct {
+	MaxDistance int `yaml:"max_distance" json:"max_distance"`
+}
+
+// IsEnabled returns true if a maximum distance is configured.
+func (t *Typosquatting) IsEnabled() bool {
+	return t.MaxDistance > 0
+}
+
+// typosquatBlockReason returns a block reason if the module, which is not
+// allowed, looks like an allowed module or domain. The closest allowed path
+// is reported.
+func (p *Processor) typosquatBlockReason(moduleName, moduleVersion string) (blockReason, bool) {
+	maxDistance := p.Config.Blocked.Typosquatting.MaxDistance
+	moduleSkeleton := skeleton(moduleName)
+	closest, closestDistance := "", maxDistance+1
+
+	compare := func(allowed, compared string) {
+		allowedSkeleton := strings.TrimRight(skeleton(allowed), "/")
+
+		// Other major versions and sub modules of an allowed module are
+		// not lookalikes, glob patterns have no single spelling.
+		if allowedSkeleton == "" || match.IsGlob(allowed) || strings.HasPrefix(compared, allowedSkeleton+"/") || strings.HasPrefix(allowedSkeleton, compared+"/") {
+			return
+		}
+
+		distance := 0
+		if compared != allowedSkeleton {
+			distance = editDistance(compared, allowedSkeleton, maxDistance)
+		}
+
+		if distance < closestDistance {
+			closest, closestDistance = strings.TrimSpace(allowed), distance
+		}
+	}
+
+	for _, allowed := range p.Config.Allowed.Modules {
+		compare(allowed, moduleSkeleton)
+	}
+
+	// Domains are compared with as many path elements of the module.
+	elements := strings.Split(moduleSkeleton, "/")
+
+	for _, allowed := range p.Config.Allowed.Domains {
+		n := strings.Count(strings.Trim(skeleton(allowed), "/"), "/") + 1
+		if n > len(elements) {
+			n = len(elements)
+		}
+
+		compare(allowed, strings.Join(elements[:n], "/"))
+	}
+
+	if closest == "" {
+		return blockReason{}, false
+	}
+
+	return blockReason{
+		rule:     RuleTyposquat,
+		reason:   fmt.Sprintf(blockReasonTyposquat, escapeReason(moduleName), escapeReason(closest)),
+		severity: SeverityError,
+		data:     MessageData{Module: moduleName, Version: moduleVersion},
+	}, true
+}
+
+// editDistance returns the optimal string alignment distance of a and b, the
+// number of insertions, deletions, substitutions and adjacent transpositions
+// turning a into b. Distances above max are returned as max+1.
+func editDistance(a, b string, max int) int {
+	s, t := []rune(a), []rune(b)
+
+	if d := len(s) - len(t); d > max || -d > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+
+			rowMin = minInt(rowMin, curr[j])
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	if prev[len(t)] > max {
+		return max + 1
+	}
+
+	return prev[len(t)]
+}
+
+// minInt returns the smallest of the values.
+func minInt(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
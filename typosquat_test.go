@@ -0,0 +1,116 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestTyposquatting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modules := []string{
+		"github.com/sirupsen/logrus",
+		"github.com/Sirupsen/logrus",
+		"github.com/sirupsen/logrsu",
+		"github.com/siruspen/logrus-hooks",
+		"github.com/sirupsen/logrus/v2",
+		"github.com/rnyorg/tool",
+		"github.com/myorg/tool",
+		"github.com/stretchr/testify",
+	}
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n"
+	src := "package example\n\nimport (\n"
+
+	for _, module := range modules {
+		version := "v1.0.0"
+		if strings.HasSuffix(module, "/v2") {
+			version = "v2.0.0"
+		}
+
+		goMod += "\t" + module + " " + version + "\n"
+		src += "\t_ \"" + module + "\"\n"
+	}
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod+")\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte(src+")\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		maxDistance int
+		wantModules []string
+	}{
+		{
+			"disabled",
+			0,
+			[]string{},
+		},
+		{
+			"one edit",
+			1,
+			[]string{"github.com/Sirupsen/logrus", "github.com/sirupsen/logrsu"},
+		},
+		{
+			"two edits",
+			2,
+			[]string{"github.com/Sirupsen/logrus", "github.com/rnyorg/tool", "github.com/sirupsen/logrsu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			config := gomodguard.Configuration{
+				Mode: gomodguard.ModeBlock,
+				Allowed: gomodguard.Allowed{
+					Modules: []string{"github.com/sirupsen/logrus"},
+					Domains: []string{"github.com/myorg"},
+				},
+				Blocked: gomodguard.Blocked{Typosquatting: gomodguard.Typosquatting{MaxDistance: tt.maxDistance}},
+			}
+
+			processor := gomodguard.Processor{Config: &config, Modfile: modFile}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			got := []string{}
+			for i := range results {
+				if results[i].Rule == gomodguard.RuleTyposquat {
+					got = append(got, results[i].Module)
+				}
+			}
+
+			sort.Strings(got)
+
+			if len(got) != len(tt.wantModules) {
+				t.Fatalf("got '%v' want '%v'", got, tt.wantModules)
+			}
+
+			for i := range got {
+				if got[i] != tt.wantModules[i] {
+					t.Errorf("got '%v' want '%v'", got, tt.wantModules)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package gomodguard
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	errInvalidVCSRule = "invalid vcs rule %q, must be of the form pattern:vcs|vcs"
+	defaultGOVCS      = "public:git|hg,private:all"
+)
+
+var (
+	blockReasonVCSNotAllowed = "import of package `%%s` is blocked because the module is served by %s which is not allowed for the module, allowed: %s."
+	blockReasonVCSOff        = "import of package `%s` is blocked because no version control system is allowed for the module."
+
+	// vcsQualifiers are the version control qualifiers a module path
+	// element can end in, i.e. example.com/repo.git/pkg.
+	vcsQualifiers = []string{"bzr", "fossil", "git", "hg", "svn"}
+
+	// vcsHosts are well known code hosting sites and the version control
+	// system they serve, the same sites the go command knows about.
+	vcsHosts = map[string]string{
+		"github.com":        "git",
+		"bitbucket.org":     "git",
+		"hub.jazz.net":      "git",
+		"git.apache.org":    "git",
+		"git.openstack.org": "git",
+		"gopkg.in":          "git",
+		"chiselapp.com":     "fossil",
+		"launchpad.net":     "bzr",
+	}
+)
+
+// vcsRule is a single pattern:vcslist entry of a GOVCS setting.
+type vcsRule struct {
+	pattern string
+	vcs     []string
+}
+
+// parseVCSRules parses a GOVCS setting. The default GOVCS rules of the go
+// command are appended so modules not matched by any rule get the same
+// treatment as they would by the go command.
+func parseVCSRules(govcs string) ([]vcsRule, error) {
+	rules := []vcsRule{}
+
+	for _, entry := range strings.Split(govcs+","+defaultGOVCS, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 || i == len(entry)-1 {
+			return nil, fmt.Errorf(errInvalidVCSRule, entry)
+		}
+
+		rules = append(rules, vcsRule{
+			pattern: strings.TrimSpace(entry[:i]),
+			vcs:     strings.Split(strings.TrimSpace(entry[i+1:]), "|"),
+		})
+	}
+
+	return rules, nil
+}
+
+// allowedVCS returns the version control systems allowed for the module
+// by the first matching rule.
+func allowedVCS(rules []vcsRule, moduleName string, private bool) []string {
+	for _, rule := range rules {
+		switch {
+		case rule.pattern == "public" && !private,
+			rule.pattern == "private" && private,
+			rule.pattern != "public" && rule.pattern != "private" && module.MatchPrefixPatterns(rule.pattern, moduleName):
+			return rule.vcs
+		}
+	}
+
+	return []string{"all"}
+}
+
+// moduleVCS returns the version control system serving a module if it
+// can be determined from the module path alone.
+func moduleVCS(moduleName string) (string, bool) {
+	elements := strings.Split(moduleName, "/")
+
+	for _, element := range elements {
+		for _, qualifier := range vcsQualifiers {
+			if strings.HasSuffix(element, "."+qualifier) {
+				return qualifier, true
+			}
+		}
+	}
+
+	vcs, ok := vcsHosts[strings.ToLower(elements[0])]
+
+	return vcs, ok
+}
+
+// vcsBlockReason returns a block reason if the module is served by a version
+// control system not allowed by the GOVCS style rules. Modules whose version
+// control system cannot be determined are only blocked when no version
+// control system is allowed for them.
+func vcsBlockReason(rules []vcsRule, moduleName, moduleVersion string, private bool) (blockReason, bool) {
+	allowed := allowedVCS(rules, moduleName, private)
+
+	r := blockReason{
+		rule:     RuleVCS,
+		severity: SeverityError,
+		data:     MessageData{Module: moduleName, Version: moduleVersion},
+	}
+
+	if len(allowed) == 1 && allowed[0] == "off" {
+		r.reason = blockReasonVCSOff
+		return r, true
+	}
+
+	vcs, ok := moduleVCS(moduleName)
+	if !ok {
+		return blockReason{}, false
+	}
+
+	for _, a := range allowed {
+		if a == "all" || a == vcs {
+			return blockReason{}, false
+		}
+	}
+
+	r.reason = fmt.Sprintf(blockReasonVCSNotAllowed, vcs, strings.Join(allowed, ", "))
+
+	return r, true
+}
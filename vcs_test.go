@@ -0,0 +1,87 @@
+package gomodguard_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+	"golang.org/x/mod/modfile"
+)
+
+func TestProcessorVCS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomodguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goMod := "module github.com/ryancurrah/example\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\texample.com/repo.hg v1.0.0\n\tgit.example.com/team/lib v1.0.0\n)\n"
+
+	modFile, err := modfile.Parse("go.mod", []byte(goMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "example.go")
+
+	err = ioutil.WriteFile(filename, []byte("package example\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"example.com/repo.hg/pkg\"\n\t\"git.example.com/team/lib\"\n)\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		testName    string
+		vcs         string
+		wantModules []string
+	}{
+		{
+			"disabled",
+			"",
+			[]string{},
+		},
+		{
+			"git only",
+			"*:git",
+			[]string{"example.com/repo.hg"},
+		},
+		{
+			"self-hosted off",
+			"github.com:git,*:off",
+			[]string{"example.com/repo.hg", "git.example.com/team/lib"},
+		},
+		{
+			"default rules",
+			"github.com:git",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processor := gomodguard.Processor{
+				Config:  &gomodguard.Configuration{Blocked: gomodguard.Blocked{VCS: tt.vcs}},
+				Modfile: modFile,
+			}
+			processor.SetBlockedModules()
+
+			results := processor.ProcessFiles([]string{filename})
+
+			gotModules := make([]string, 0, len(results))
+			for _, result := range results {
+				gotModules = append(gotModules, result.Module)
+			}
+
+			if len(gotModules) != len(tt.wantModules) {
+				t.Fatalf("got '%+v' want '%+v'", gotModules, tt.wantModules)
+			}
+
+			for i := range gotModules {
+				if gotModules[i] != tt.wantModules[i] {
+					t.Errorf("got '%+v' want '%+v'", gotModules, tt.wantModules)
+				}
+			}
+		})
+	}
+}
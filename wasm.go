@@ -0,0 +1,116 @@
+//go:build js && wasm
+// +build js,wasm
+
+package gomodguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"gopkg.in/yaml.v2"
+)
+
+// jsCheckResult is the value returned to JavaScript, the results or the
+// error of the check.
+type jsCheckResult struct {
+	Results  []jsonResult `json:"results"`
+	Warnings []string     `json:"warnings,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// RegisterJSFunctions registers the gomodguardCheck function on the global
+// object of the JavaScript host, for web based code review tools running
+// the policy check client side. gomodguardCheck takes an object with
+//
+//	config: the contents of the .gomodguard.yaml file
+//	goMod:  the contents of the go.mod file
+//	files:  an object of file names to file contents
+//	lookup: an optional function(module, version) returning an object with
+//	        the licenses and deprecated message of the module version
+//
+// and returns a JSON string of an object with the results and warnings, or
+// the error.
+// No files are read and no commands are run, module metadata is only
+// provided by the lookup function.
+func RegisterJSFunctions() {
+	js.Global().Set("gomodguardCheck", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result := jsCheckResult{Results: []jsonResult{}}
+
+		p, err := jsCheck(args)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Warnings = p.warnings
+
+			for i := range p.Result {
+				result.Results = append(result.Results, newJSONResult(&p.Result[i]))
+			}
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Sprintf(`{"results":[],"error":%q}`, err.Error())
+		}
+
+		return string(data)
+	}))
+}
+
+// jsCheck lints the files of the options argument.
+func jsCheck(args []js.Value) (*Processor, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeObject {
+		return nil, fmt.Errorf("gomodguardCheck requires an options object")
+	}
+
+	options := args[0]
+
+	config := &Configuration{}
+	if configData := options.Get("config"); configData.Type() == js.TypeString {
+		err := yaml.Unmarshal([]byte(configData.String()), config)
+		if err != nil {
+			return nil, fmt.Errorf(errParsingConfigFile, err)
+		}
+	}
+
+	sources := map[string][]byte{}
+
+	if files := options.Get("files"); files.Type() == js.TypeObject {
+		names := js.Global().Get("Object").Call("keys", files)
+		for i := 0; i < names.Length(); i++ {
+			name := names.Index(i).String()
+			sources[name] = []byte(files.Get(name).String())
+		}
+	}
+
+	var lookup func(moduleName, moduleVersion string) (*ModuleMetadata, error)
+
+	if lookupFunc := options.Get("lookup"); lookupFunc.Type() == js.TypeFunction {
+		lookup = func(moduleName, moduleVersion string) (*ModuleMetadata, error) {
+			return jsModuleMetadata(lookupFunc.Invoke(moduleName, moduleVersion))
+		}
+	}
+
+	return lintSources(config, []byte(options.Get("goMod").String()), sources, lookup)
+}
+
+// jsModuleMetadata converts the value returned by the lookup function.
+func jsModuleMetadata(value js.Value) (*ModuleMetadata, error) {
+	if value.Type() != js.TypeObject {
+		return nil, errModCacheNotFound
+	}
+
+	metadata := &ModuleMetadata{Licenses: []string{}}
+
+	if licenses := value.Get("licenses"); licenses.Type() == js.TypeObject {
+		for i := 0; i < licenses.Length(); i++ {
+			metadata.Licenses = append(metadata.Licenses, licenses.Index(i).String())
+		}
+	}
+
+	if deprecated := value.Get("deprecated"); deprecated.Type() == js.TypeString {
+		metadata.Deprecated = deprecated.String()
+	}
+
+	return metadata, nil
+}
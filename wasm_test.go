@@ -0,0 +1,46 @@
+//go:build js && wasm
+// +build js,wasm
+
+package gomodguard_test
+
+import (
+	"encoding/json"
+	"syscall/js"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestRegisterJSFunctions(t *testing.T) {
+	gomodguard.RegisterJSFunctions()
+
+	files := js.Global().Get("Object").New()
+	files.Set("main.go", "package main\n\nimport (\n\t\"github.com/foo/bar\"\n\t\"github.com/foo/baz\"\n)\n")
+
+	options := js.Global().Get("Object").New()
+	options.Set("config", "blocked:\n  modules:\n    - github.com/foo/baz: {}\n")
+	options.Set("goMod", "module example.com/m\n\nrequire (\n\tgithub.com/foo/bar v1.0.0\n\tgithub.com/foo/baz v1.0.0\n)\n")
+	options.Set("files", files)
+
+	var got struct {
+		Results []struct {
+			File   string `json:"file"`
+			Line   int    `json:"line"`
+			Module string `json:"module"`
+		} `json:"results"`
+		Error string `json:"error"`
+	}
+
+	err := json.Unmarshal([]byte(js.Global().Call("gomodguardCheck", options).String()), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Error != "" || len(got.Results) != 1 {
+		t.Fatalf("got '%+v' want 1 result", got)
+	}
+
+	if got.Results[0].File != "main.go" || got.Results[0].Line != 5 || got.Results[0].Module != "github.com/foo/baz" {
+		t.Errorf("got '%+v' want main.go:5 github.com/foo/baz", got.Results[0])
+	}
+}
@@ -0,0 +1,87 @@
+package gomodguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	webhookTimeout     = 10 * time.Second
+	errWebhookURL      = "webhook report requires a url or routes"
+	errWebhookWriter   = "webhook report can only be sent to a url"
+	errSendingWebhook  = "unable to send results to webhook %s: %w"
+	errWebhookStatus   = "unexpected webhook status code %d for %s"
+	webhookContentType = "application/json"
+)
+
+// webhookPayload is the body posted to a webhook, the results of one owner.
+type webhookPayload struct {
+	Owner   string       `json:"owner"`
+	Results []jsonResult `json:"results"`
+}
+
+// sendWebhook posts the results grouped by owner. Results of an owner with a
+// route are posted to the route, all other results to the url of the report.
+func sendWebhook(report Report, results []Result) error {
+	defaultURL := report.URL
+	if defaultURL == "" {
+		defaultURL = report.File
+	}
+
+	byOwner := map[string][]jsonResult{}
+
+	for i := range results {
+		byOwner[results[i].Owner] = append(byOwner[results[i].Owner], newJSONResult(&results[i]))
+	}
+
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+
+	sort.Strings(owners)
+
+	for _, owner := range owners {
+		url, ok := report.Routes[owner]
+		if !ok {
+			url = defaultURL
+		}
+
+		if url == "" {
+			continue
+		}
+
+		err := postWebhook(url, webhookPayload{Owner: owner, Results: byOwner[owner]})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postWebhook posts the payload as json.
+func postWebhook(url string, payload webhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf(errSendingWebhook, url, err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+
+	resp, err := client.Post(url, webhookContentType, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf(errSendingWebhook, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf(errWebhookStatus, resp.StatusCode, url)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package gomodguard_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ryancurrah/gomodguard"
+)
+
+func TestWriteReportsWebhook(t *testing.T) {
+	received := map[string][]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := struct {
+			Owner   string `json:"owner"`
+			Results []struct {
+				Module string `json:"module"`
+			} `json:"results"`
+		}{}
+
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, result := range payload.Results {
+			received[r.URL.Path] = append(received[r.URL.Path], payload.Owner+" "+result.Module)
+		}
+	}))
+	defer server.Close()
+
+	results := []gomodguard.Result{
+		{FileName: "a.go", Module: "github.com/foo/bar", Owner: "team-a"},
+		{FileName: "b.go", Module: "github.com/foo/baz", Owner: "team-b"},
+		{FileName: "c.go", Module: "github.com/foo/qux"},
+	}
+
+	err := gomodguard.WriteReports([]gomodguard.Report{{
+		Format: gomodguard.ReportWebhook,
+		URL:    server.URL + "/default",
+		Routes: map[string]string{"team-a": server.URL + "/team-a"},
+	}}, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantReceived := map[string][]string{
+		"/team-a":  {"team-a github.com/foo/bar"},
+		"/default": {" github.com/foo/qux", "team-b github.com/foo/baz"},
+	}
+
+	if !reflect.DeepEqual(received, wantReceived) {
+		t.Errorf("got '%+v' want '%+v'", received, wantReceived)
+	}
+}
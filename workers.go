@@ -0,0 +1,107 @@
+package gomodguard
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"runtime"
+)
+
+const errNegativeWorkers = "workers must not be negative, got %d"
+
+// validateWorkers returns an error if the number of workers is negative.
+func validateWorkers(config *Configuration) error {
+	if config.Workers < 0 {
+		return fmt.Errorf(errNegativeWorkers, config.Workers)
+	}
+
+	return nil
+}
+
+// workers returns the number of files read and parsed concurrently,
+// GOMAXPROCS unless configured.
+func (p *Processor) workers() int {
+	if p.Config != nil && p.Config.Workers > 0 {
+		return p.Config.Workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// parsedFile is a file read and parsed by a worker. Panics of the worker are
+// kept to be reported as an internal error of the file.
+type parsedFile struct {
+	data     *bytes.Buffer
+	file     *ast.File
+	readErr  error
+	parseErr error
+	panicked interface{}
+}
+
+// parseFiles reads and parses the files concurrently into the file set. The
+// parsed file of each file is sent on its channel. A worker takes a slot
+// before reading a file and the slot is given back by receiving from slots
+// once the file is processed, so at most as many files as slots are read
+// ahead. No more files are read once done is closed, and files parsed after
+// it is closed are released by their worker.
+func (p *Processor) parseFiles(fileSet *token.FileSet, filenames []string, slots chan struct{}, done <-chan struct{}) []chan parsedFile {
+	parsed := make([]chan parsedFile, len(filenames))
+	for i := range parsed {
+		parsed[i] = make(chan parsedFile, 1)
+	}
+
+	go func() {
+		for i := range filenames {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			go func(i int) {
+				parsed[i] <- p.readAndParseFile(fileSet, filenames[i])
+
+				// The file is no longer received once done is closed.
+				select {
+				case <-done:
+					releaseParsed(parsed[i])
+				default:
+				}
+			}(i)
+		}
+	}()
+
+	return parsed
+}
+
+// releaseParsed releases the buffer of the parsed file waiting on the
+// channel, if any, without blocking.
+func releaseParsed(parsed chan parsedFile) {
+	select {
+	case file := <-parsed:
+		if file.data != nil {
+			releaseBuffer(file.data)
+		}
+	default:
+	}
+}
+
+// readAndParseFile reads and parses a file. It only reads the processor, so
+// files can be read and parsed concurrently.
+func (p *Processor) readAndParseFile(fileSet *token.FileSet, filename string) (parsed parsedFile) {
+	defer func() {
+		if r := recover(); r != nil {
+			parsed.panicked = r
+		}
+	}()
+
+	parsed.data, parsed.readErr = readFile(p.fileSystem(), filename)
+	if parsed.readErr != nil {
+		return parsed
+	}
+
+	parsed.file, parsed.parseErr = p.parseFile(fileSet, filename, parsed.data.Bytes())
+
+	return parsed
+}